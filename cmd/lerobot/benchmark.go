@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type BenchmarkCommand struct {
+	Ports []string `long:"port" description:"Serial port to benchmark (repeatable, default: configured arms)"`
+	Bauds []int    `long:"baud" description:"Baud rate to benchmark (repeatable, default: 1000000); servos only answer at their configured rate"`
+	IDs   []int    `long:"id" description:"Servo ID to include (repeatable, default: calibrated or configured motors)"`
+	Count int      `long:"count" default:"200" description:"Transactions per measurement"`
+}
+
+// benchmarkTarget is a port to benchmark and the servos on it.
+type benchmarkTarget struct {
+	name string
+	port string
+	ids  []int
+}
+
+func (c *BenchmarkCommand) Execute(args []string) error {
+	targets := c.targets()
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "No ports to benchmark. Run 'lerobot setup' first or pass --port.")
+		os.Exit(1)
+	}
+
+	bauds := c.Bauds
+	if len(bauds) == 0 {
+		bauds = []int{robot.DefaultBaudRate}
+	}
+
+	fmt.Println(headerStyle.Render("LeRobot Benchmark"))
+	fmt.Printf("%d transactions per measurement\n\n", c.Count)
+
+	ctx := context.Background()
+	for _, t := range targets {
+		fmt.Println(subHeaderStyle.Render(fmt.Sprintf("%s (%s, IDs %v)", t.name, t.port, t.ids)))
+		for _, baud := range bauds {
+			result, err := benchmarkPort(ctx, t.port, baud, t.ids, c.Count)
+			if err != nil {
+				fmt.Println(dimStyle.Render(fmt.Sprintf("  %d baud: %v", baud, err)))
+				continue
+			}
+			printBenchmark(baud, result)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// targets returns the ports given on the command line, or the configured
+// leader and follower.
+func (c *BenchmarkCommand) targets() []benchmarkTarget {
+	ids := c.IDs
+	if len(ids) == 0 {
+		ids = robot.MotorIDs(configuredMotors())
+	}
+
+	var targets []benchmarkTarget
+	for _, port := range c.Ports {
+		targets = append(targets, benchmarkTarget{name: port, port: port, ids: ids})
+	}
+	if len(targets) > 0 {
+		return targets
+	}
+
+	cfg, err := robot.LoadConfig(opts.Profile)
+	if err != nil {
+		return nil
+	}
+	cfg.ResolvePorts()
+	for _, a := range []struct {
+		name string
+		cfg  robot.ArmConfig
+	}{
+		{"Leader", cfg.Leader},
+		{"Follower", cfg.Follower},
+	} {
+		if a.cfg.Port == "" {
+			continue
+		}
+		armIDs := ids
+		if len(c.IDs) == 0 && a.cfg.IsCalibrated() {
+			armIDs = a.cfg.Calibration.MotorIDs()
+		}
+		targets = append(targets, benchmarkTarget{name: a.name, port: a.cfg.Port, ids: armIDs})
+	}
+	return targets
+}
+
+// benchmarkPort opens port at baud and runs the benchmark if the first
+// servo answers.
+func benchmarkPort(ctx context.Context, port string, baud int, ids []int, n int) (robot.BenchmarkResult, error) {
+	bus, err := feetech.NewBus(feetech.BusConfig{
+		Port:     port,
+		BaudRate: baud,
+		Protocol: feetech.ProtocolSTS,
+		Timeout:  100 * time.Millisecond,
+	})
+	if err != nil {
+		return robot.BenchmarkResult{}, err
+	}
+	defer bus.Close()
+
+	if _, err := bus.Ping(ctx, ids[0]); err != nil {
+		return robot.BenchmarkResult{}, fmt.Errorf("servo %d does not respond: %w", ids[0], err)
+	}
+	return robot.Benchmark(ctx, bus, ids, n)
+}
+
+func printBenchmark(baud int, r robot.BenchmarkResult) {
+	fmt.Printf("  %d baud\n", baud)
+	row := func(label string, t robot.Timing) {
+		fmt.Printf("    %-12s %s  %6.0f/s  errors %.1f%%\n", label, formatTiming(t), t.PerSecond(), 100*t.ErrorRate())
+	}
+	row("sync read", r.SyncRead)
+	row("state read", r.StateRead)
+	row("sync write", r.SyncWrite)
+	for _, id := range slices.Sorted(maps.Keys(r.Ping)) {
+		row(fmt.Sprintf("ping ID %d", id), r.Ping[id])
+	}
+
+	if r.SyncRead.Errors == r.SyncRead.Count || r.SyncWrite.Errors == r.SyncWrite.Count {
+		fmt.Println(warnStyle.Render("    every transaction failed, check wiring and power"))
+		return
+	}
+	fmt.Printf("    max --hz: %d for teleoperate, %d for record\n", r.MaxHz(0), r.MaxHz(1))
+	if r.SyncRead.ErrorRate() > 0.01 || r.SyncWrite.ErrorRate() > 0.01 {
+		fmt.Println(warnStyle.Render("    error rate above 1%, expect dropped cycles; check cables and power supply"))
+	}
+}
+
+func formatTiming(t robot.Timing) string {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return fmt.Sprintf("p50 %5.2fms  p95 %5.2fms  max %5.2fms", ms(t.P50), ms(t.P95), ms(t.Max))
+}
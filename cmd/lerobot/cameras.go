@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/gwillem/lerobot/pkg/camera"
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// previewInterval is how often the preview redraws.
+const previewInterval = 100 * time.Millisecond
+
+// maxListedModes is how many modes per camera are listed before "+N more".
+const maxListedModes = 4
+
+type CamerasCommand struct {
+	Preview bool `long:"preview" description:"Show a live preview in the terminal instead of listing cameras"`
+	Args    struct {
+		Cameras []string `positional-arg-name:"camera" description:"Device or name=device[@WIDTHxHEIGHT][+LATENCY] to preview (default: configured cameras, or all found)"`
+	} `positional-args:"yes"`
+}
+
+func (c *CamerasCommand) Execute(args []string) error {
+	if c.Preview {
+		return c.preview()
+	}
+
+	devices, err := camera.Discover()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing cameras: %v\n", err)
+		os.Exit(1)
+	}
+	if len(devices) == 0 {
+		fmt.Println("No cameras found")
+		return nil
+	}
+
+	var rows [][]string
+	for _, d := range devices {
+		modes := make([]string, 0, maxListedModes+1)
+		for i, m := range d.Modes {
+			if i == maxListedModes {
+				modes = append(modes, fmt.Sprintf("+%d more", len(d.Modes)-i))
+				break
+			}
+			modes = append(modes, m.String())
+		}
+		rows = append(rows, []string{strconv.Itoa(d.Index), d.Name, d.Device, strings.Join(modes, ", ")})
+	}
+	fmt.Println(renderTable([]string{"Index", "Name", "Device", "Modes"}, rows))
+	fmt.Println(dimStyle.Render(fmt.Sprintf("Record with e.g. --camera front=%s, check framing with 'lerobot cameras --preview %s'", devices[0].Device, devices[0].Device)))
+	return nil
+}
+
+// preview opens the cameras to preview and runs the preview TUI.
+func (c *CamerasCommand) preview() error {
+	specs := c.Args.Cameras
+	if len(specs) == 0 {
+		if cfg, err := robot.LoadConfig(opts.Profile); err == nil {
+			specs = cfg.Cameras
+		}
+	}
+	if len(specs) == 0 {
+		devices, err := camera.Discover()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing cameras: %v\n", err)
+			os.Exit(1)
+		}
+		for _, d := range devices {
+			specs = append(specs, d.Device)
+		}
+	}
+	if len(specs) == 0 {
+		fmt.Fprintln(os.Stderr, "No cameras found")
+		os.Exit(1)
+	}
+
+	var cams []*camera.Grabber
+	defer func() {
+		for _, g := range cams {
+			g.Close()
+		}
+	}()
+	for _, spec := range specs {
+		if !strings.Contains(spec, "=") {
+			spec = filepath.Base(spec) + "=" + spec
+		}
+		cfg, err := camera.ParseSpec(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		cam, err := camera.Open(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening camera %s: %v\n", cfg.Name, err)
+			os.Exit(1)
+		}
+		cams = append(cams, camera.NewGrabber(cam))
+	}
+
+	p := tea.NewProgram(previewModel{cams: cams}, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running preview: %v\n", err)
+		os.Exit(1)
+	}
+	return nil
+}
+
+type previewModel struct {
+	cams          []*camera.Grabber
+	current       int
+	width, height int
+}
+
+type previewTickMsg time.Time
+
+func previewTick() tea.Cmd {
+	return tea.Tick(previewInterval, func(t time.Time) tea.Msg { return previewTickMsg(t) })
+}
+
+func (m previewModel) Init() tea.Cmd { return previewTick() }
+
+func (m previewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "tab", "right":
+			m.current = (m.current + 1) % len(m.cams)
+		case "shift+tab", "left":
+			m.current = (m.current + len(m.cams) - 1) % len(m.cams)
+		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case previewTickMsg:
+		return m, previewTick()
+	}
+	return m, nil
+}
+
+func (m previewModel) View() string {
+	g := m.cams[m.current]
+	cam := g.Camera()
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render(fmt.Sprintf("%s (%dx%d)", cam.Name(), cam.Width(), cam.Height())))
+	sb.WriteString("  ")
+	sb.WriteString(dimStyle.Render(g.Timing().String()))
+	sb.WriteString("\n")
+
+	frame, ok := g.Latest()
+	switch {
+	case g.Err() != nil:
+		sb.WriteString(warnStyle.Render(g.Err().Error()))
+	case !ok:
+		sb.WriteString(dimStyle.Render("Waiting for the first frame..."))
+	default:
+		sb.WriteString(renderPreview(frame.Data, cam.Width(), cam.Height(), m.width, m.height-2))
+	}
+
+	sb.WriteString("\n")
+	help := "q: quit"
+	if len(m.cams) > 1 {
+		help = fmt.Sprintf("camera %d of %d, tab: next, q: quit", m.current+1, len(m.cams))
+	}
+	sb.WriteString(dimStyle.Render(help))
+	return sb.String()
+}
+
+// renderPreview scales an RGB24 image to fit cols x rows terminal cells,
+// keeping its aspect ratio. Every cell shows two pixels stacked, as an
+// upper half block in the color of the top pixel on the color of the
+// bottom one, so pixels come out about square.
+func renderPreview(rgb []byte, width, height, cols, rows int) string {
+	if cols <= 0 || rows <= 0 || width <= 0 || height <= 0 {
+		return ""
+	}
+	scale := min(float64(cols)/float64(width), float64(2*rows)/float64(height))
+	w, h := max(1, int(float64(width)*scale)), max(2, int(float64(height)*scale)/2*2)
+
+	pixel := func(x, y int) (byte, byte, byte) {
+		i := ((y*height/h)*width + x*width/w) * 3
+		return rgb[i], rgb[i+1], rgb[i+2]
+	}
+
+	var sb strings.Builder
+	for y := 0; y < h; y += 2 {
+		if y > 0 {
+			sb.WriteString("\n")
+		}
+		for x := range w {
+			tr, tg, tb := pixel(x, y)
+			br, bg, bb := pixel(x, y+1)
+			fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", tr, tg, tb, br, bg, bb)
+		}
+		sb.WriteString("\x1b[0m")
+	}
+	return sb.String()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+var (
+	warnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	fixStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+)
+
+type CheckCommand struct{}
+
+func (c *CheckCommand) Execute(args []string) error {
+	// Calibration problems that fail validation are explained below
+	cfg, _ := robot.LoadConfig(opts.Profile)
+	if cfg == nil {
+		cfg = loadConfig() // exits with the reason
+	}
+	cfg.ResolvePorts()
+
+	fmt.Println(headerStyle.Render("LeRobot Check"))
+	fmt.Println(dimStyle.Render("━━━━━━━━━━━━━━"))
+
+	total := 0
+	motors := robot.MotorNames(cfg.MotorList())
+	total += checkArm("leader", &cfg.Leader, motors)
+	total += checkArm("follower", &cfg.Follower, motors)
+
+	fmt.Println()
+	if total > 0 {
+		fmt.Println(warnStyle.Render(fmt.Sprintf("Found %d issue(s).", total)))
+		os.Exit(1)
+	}
+	fmt.Println(successStyle.Render("All checks passed."))
+	return nil
+}
+
+// checkArm runs static and live calibration checks on one arm and prints the
+// results. It returns the number of issues found.
+func checkArm(armName string, armConfig *robot.ArmConfig, motors []robot.MotorName) int {
+	fmt.Println()
+	fmt.Println(subHeaderStyle.Render(fmt.Sprintf("━━━ %s arm (%s) ━━━", armName, armConfig.Port)))
+
+	if !armConfig.IsCalibrated() {
+		printIssues([]robot.Issue{{
+			Problem: "not calibrated",
+			Fix:     "run 'lerobot setup'",
+		}})
+		return 1
+	}
+
+	issues := armConfig.Calibration.Check(motors)
+
+	verifyCfg := *armConfig
+	verifyCfg.SkipVerify = true // reported as an issue below
+	arm, err := robot.OpenArm(verifyCfg)
+	if err != nil {
+		issues = append(issues, robot.Issue{
+			Problem: fmt.Sprintf("cannot connect: %v", err),
+			Fix:     "check that the arm is plugged in and powered on",
+		})
+		printIssues(issues)
+		return len(issues)
+	}
+	defer arm.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := arm.VerifyServos(ctx); err != nil {
+		issues = append(issues, robot.Issue{
+			Problem: err.Error(),
+			Fix:     "replace the servos that differ, or set servo_model for a build with other servos",
+		})
+	}
+	raw, err := arm.ReadRawPositions(ctx)
+	if err != nil {
+		issues = append(issues, robot.Issue{
+			Problem: fmt.Sprintf("cannot read positions: %v", err),
+			Fix:     "check the cable and power supply",
+		})
+	} else {
+		issues = append(issues, armConfig.Calibration.CheckPositions(raw)...)
+	}
+
+	printIssues(issues)
+	return len(issues)
+}
+
+func printIssues(issues []robot.Issue) {
+	if len(issues) == 0 {
+		fmt.Println(successStyle.Render("  OK"))
+		return
+	}
+	for _, issue := range issues {
+		fmt.Println(warnStyle.Render("  ✗ " + issue.String()))
+		if issue.Fix != "" {
+			fmt.Println(fixStyle.Render("    → " + issue.Fix))
+		}
+	}
+}
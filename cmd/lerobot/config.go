@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// configPath returns the active configuration file of the --profile.
+func configPath() string {
+	return robot.ConfigPath(opts.Profile)
+}
+
+// flagOr returns the value of a flag that defaults to the configuration,
+// or configured if the flag wasn't given.
+func flagOr[T any](flag *T, configured T) T {
+	if flag != nil {
+		return *flag
+	}
+	return configured
+}
+
+// loadConfig loads the active configuration, exiting with a hint if there
+// is none or with the problems found if it is invalid.
+func loadConfig() *robot.Config {
+	cfg, err := robot.LoadConfig(opts.Profile)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "No configuration found. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Migrated() {
+		fmt.Println(dimStyle.Render(fmt.Sprintf("%s has calibration file paths; they are read for now and embedded when the configuration is next saved", cfg.Path())))
+	}
+	return cfg
+}
+
+type ConfigCommand struct {
+	Show     ConfigShowCommand     `command:"show" description:"Print the active configuration and where it is loaded from"`
+	Path     ConfigPathCommand     `command:"path" description:"Print the path of the active configuration file"`
+	Edit     ConfigEditCommand     `command:"edit" description:"Open the active configuration in $EDITOR"`
+	Profiles ConfigProfilesCommand `command:"profiles" description:"List configuration profiles"`
+}
+
+type ConfigShowCommand struct{}
+
+func (c *ConfigShowCommand) Execute(args []string) error {
+	path := configPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "No configuration at %s. Run 'lerobot setup' first.\n", path)
+		os.Exit(1)
+	}
+	fmt.Println(dimStyle.Render("# " + path))
+	fmt.Println(string(data))
+	return nil
+}
+
+type ConfigPathCommand struct{}
+
+func (c *ConfigPathCommand) Execute(args []string) error {
+	fmt.Println(configPath())
+	return nil
+}
+
+type ConfigEditCommand struct{}
+
+func (c *ConfigEditCommand) Execute(args []string) error {
+	path := configPath()
+	if !robot.ConfigExists(opts.Profile) {
+		// Start a new profile from an empty configuration
+		if err := (&robot.Config{}).SaveTo(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command(editor, path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running %s: %v\n", editor, err)
+		os.Exit(1)
+	}
+	if _, err := robot.LoadConfigFrom(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		os.Exit(1)
+	}
+	return nil
+}
+
+type ConfigProfilesCommand struct{}
+
+func (c *ConfigProfilesCommand) Execute(args []string) error {
+	names, err := robot.Profiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(names) == 0 {
+		fmt.Println("No profiles. Create one with 'lerobot --profile NAME setup'.")
+		return nil
+	}
+	for _, name := range names {
+		if name == opts.Profile {
+			fmt.Println(successStyle.Render("* " + name))
+		} else {
+			fmt.Println("  " + name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/gwillem/lerobot/pkg/camera"
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type ConfigureCommand struct{}
+
+func (c *ConfigureCommand) Execute(args []string) error {
+	cfg := loadConfig()
+	cfg.ResolvePorts()
+
+	ports, err := robot.ListPorts()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing serial ports: %v\n", err)
+		os.Exit(1)
+	}
+
+	leaderPort, followerPort := cfg.Leader.Port, cfg.Follower.Port
+	hz := strconv.Itoa(cmp.Or(cfg.Teleop.Hz, 60))
+	mirror := cfg.Teleop.Mirror
+	origDeadband := formatFloat(uniformDeadband(cfg))
+	deadband := origDeadband
+	smoothing := formatFloat(cfg.Teleop.Smoothing)
+	leaderAccel, followerAccel := strconv.Itoa(cfg.Leader.Acceleration), strconv.Itoa(cfg.Follower.Acceleration)
+	leaderSpeed, followerSpeed := strconv.Itoa(cfg.Leader.MaxSpeed), strconv.Itoa(cfg.Follower.MaxSpeed)
+	cameras := strings.Join(cfg.Cameras, ", ")
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Leader port").
+				Description("Checked by reading the leader's servos").
+				Options(portOptions(ports, leaderPort)...).
+				Value(&leaderPort).
+				Validate(func(port string) error { return probeArm(cfg.Leader, port) }),
+			huh.NewSelect[string]().
+				Title("Follower port").
+				Description("Checked by reading the follower's servos").
+				Options(portOptions(ports, followerPort)...).
+				Value(&followerPort).
+				Validate(func(port string) error { return probeArm(cfg.Follower, port) }),
+		).Title("Ports"),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Control loop frequency (Hz)").
+				Value(&hz).
+				Validate(intInRange(1, 1000)),
+			huh.NewConfirm().
+				Title("Mirror mode").
+				Description("Invert shoulder_pan and wrist_roll").
+				Value(&mirror),
+			huh.NewInput().
+				Title("Deadband (normalized units, all motors)").
+				Description("Skip follower writes for smaller leader movements, 0 disables").
+				Value(&deadband).
+				Validate(floatAtLeast(0)),
+			huh.NewInput().
+				Title("Smoothing (Hz)").
+				Description("Low-pass filter the follower targets with this cutoff, 0 disables").
+				Value(&smoothing).
+				Validate(floatAtLeast(0)),
+		).Title("Teleoperation"),
+		huh.NewGroup(
+			huh.NewInput().Title("Leader acceleration (100 steps/s², 0 = firmware default)").Value(&leaderAccel).Validate(intInRange(0, 254)),
+			huh.NewInput().Title("Follower acceleration (100 steps/s², 0 = firmware default)").Value(&followerAccel).Validate(intInRange(0, 254)),
+			huh.NewInput().Title("Leader max speed (steps/s, 0 = unlimited)").Value(&leaderSpeed).Validate(intInRange(0, 10000)),
+			huh.NewInput().Title("Follower max speed (steps/s, 0 = unlimited)").Value(&followerSpeed).Validate(intInRange(0, 10000)),
+		).Title("Safety limits"),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Cameras for 'lerobot record'").
+				Description("Comma separated name=device[@WIDTHxHEIGHT][+LATENCY], e.g. wrist=/dev/video0").
+				Value(&cameras).
+				Validate(validateCameras),
+		).Title("Cameras"),
+	)
+	if err := form.Run(); err != nil {
+		fmt.Println("Cancelled, nothing saved.")
+		return nil
+	}
+
+	setPort(&cfg.Leader, leaderPort)
+	setPort(&cfg.Follower, followerPort)
+	cfg.Teleop.Hz = atoi(hz)
+	if cfg.Teleop.Hz == 60 {
+		cfg.Teleop.Hz = 0 // keep the file free of defaults
+	}
+	cfg.Teleop.Mirror = mirror
+	if deadband != origDeadband {
+		setDeadband(cfg, deadband) // per-motor values are kept unless changed
+	}
+	cfg.Teleop.Smoothing, _ = strconv.ParseFloat(strings.TrimSpace(smoothing), 64)
+	cfg.Leader.Acceleration = atoi(leaderAccel)
+	cfg.Follower.Acceleration = atoi(followerAccel)
+	cfg.Leader.MaxSpeed = atoi(leaderSpeed)
+	cfg.Follower.MaxSpeed = atoi(followerSpeed)
+	cfg.Cameras = splitCameras(cameras)
+
+	if err := cfg.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(successStyle.Render("Configuration saved to " + cfg.Path()))
+	return nil
+}
+
+// portOptions lists the serial ports, keeping the configured one even if
+// it is not connected right now.
+func portOptions(ports []string, current string) []huh.Option[string] {
+	if current != "" && !slices.Contains(ports, current) {
+		ports = append([]string{current}, ports...)
+	}
+	options := make([]huh.Option[string], len(ports))
+	for i, port := range ports {
+		options[i] = huh.NewOption(port, port)
+	}
+	return options
+}
+
+// probeArm checks that a calibrated arm answers on a newly chosen port.
+// Uncalibrated arms and unchanged ports are accepted as they are, so other
+// settings can be edited while an arm is unplugged.
+func probeArm(arm robot.ArmConfig, port string) error {
+	if !arm.IsCalibrated() || robot.SamePort(port, arm.Port) {
+		return nil
+	}
+	arm.Port = port
+	arm.SkipVerify = true // only the port is being checked
+	a, err := robot.OpenArm(arm)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", port, err)
+	}
+	defer a.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := a.ReadPositions(ctx); err != nil {
+		return fmt.Errorf("no arm answering on %s: %w", port, err)
+	}
+	return nil
+}
+
+// setPort changes the arm's port, updating its USB identity to match.
+func setPort(arm *robot.ArmConfig, port string) {
+	if robot.SamePort(port, arm.Port) {
+		return
+	}
+	arm.Port = port
+	arm.USB = robot.LookupUSBIdentity(port)
+}
+
+// uniformDeadband returns the deadband if all motors share it, else the
+// largest one.
+func uniformDeadband(cfg *robot.Config) float64 {
+	var db float64
+	for _, v := range cfg.Deadband {
+		db = max(db, v)
+	}
+	return db
+}
+
+func setDeadband(cfg *robot.Config, value string) {
+	db, _ := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if db == 0 {
+		cfg.Deadband = nil
+		return
+	}
+	cfg.Deadband = make(map[robot.MotorName]float64)
+	for _, m := range cfg.MotorList() {
+		cfg.Deadband[m.Name] = db
+	}
+}
+
+func splitCameras(s string) []string {
+	var specs []string
+	for spec := range strings.SplitSeq(s, ",") {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// validateCameras parses every spec and checks that local devices exist.
+func validateCameras(s string) error {
+	for _, spec := range splitCameras(s) {
+		cam, err := camera.ParseSpec(spec)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(cam.Device, "/dev/") {
+			if _, err := os.Stat(cam.Device); err != nil {
+				return fmt.Errorf("camera %s: %s not found", cam.Name, cam.Device)
+			}
+		}
+	}
+	return nil
+}
+
+func intInRange(lo, hi int) func(string) error {
+	return func(s string) error {
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || v < lo || v > hi {
+			return fmt.Errorf("enter a whole number from %d to %d", lo, hi)
+		}
+		return nil
+	}
+}
+
+func floatAtLeast(lo float64) func(string) error {
+	return func(s string) error {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || v < lo {
+			return fmt.Errorf("enter a number of at least %g", lo)
+		}
+		return nil
+	}
+}
+
+// atoi parses a validated form field.
+func atoi(s string) int {
+	v, _ := strconv.Atoi(strings.TrimSpace(s))
+	return v
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
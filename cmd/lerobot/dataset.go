@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+
+	"github.com/gwillem/lerobot/pkg/dataset"
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type DatasetCommand struct {
+	List   DatasetListCommand   `command:"list" description:"List datasets in a directory"`
+	Info   DatasetInfoCommand   `command:"info" description:"Show episodes and joint statistics of a dataset"`
+	Delete DatasetDeleteCommand `command:"delete" description:"Delete episodes from a dataset"`
+	Merge  DatasetMergeCommand  `command:"merge" description:"Merge datasets into a new one"`
+	Stats  DatasetStatsCommand  `command:"stats" description:"Recompute meta/stats.json, e.g. for datasets recorded by older versions"`
+	Viz    DatasetVizCommand    `command:"viz" description:"Browse episodes in the terminal, or write an HTML report"`
+	Export DatasetExportCommand `command:"export" description:"Convert a dataset to CSV, Parquet or ROS 2 bags"`
+}
+
+type DatasetListCommand struct {
+	Args struct {
+		Dir string `positional-arg-name:"dir" description:"Directory containing datasets (default: current)"`
+	} `positional-args:"yes"`
+}
+
+func (c *DatasetListCommand) Execute(args []string) error {
+	dir := c.Args.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*", "meta", "info.json"))
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		fmt.Printf("No datasets found in %s\n", dir)
+		return nil
+	}
+	sort.Strings(matches)
+
+	var rows [][]string
+	for _, m := range matches {
+		root := filepath.Dir(filepath.Dir(m))
+		ds, err := dataset.Open(root)
+		if err != nil {
+			rows = append(rows, []string{root, "-", "-", "-", "-", err.Error()})
+			continue
+		}
+		info := ds.Info()
+		var total float64
+		for _, e := range ds.Episodes() {
+			total += ds.Duration(e)
+		}
+		rows = append(rows, []string{
+			root,
+			strconv.Itoa(info.TotalEpisodes),
+			strconv.Itoa(info.TotalFrames),
+			strconv.Itoa(info.FPS),
+			formatSeconds(total),
+			info.RobotType,
+		})
+	}
+
+	fmt.Println(renderTable([]string{"Dataset", "Episodes", "Frames", "FPS", "Duration", "Robot"}, rows))
+	return nil
+}
+
+type DatasetInfoCommand struct {
+	Args struct {
+		Dataset string `positional-arg-name:"dataset" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+// episodeIssues summarizes the quality checks of e: "-" for an episode
+// recorded before they existed.
+func episodeIssues(e dataset.Episode) string {
+	switch {
+	case e.Quality == nil:
+		return "-"
+	case len(e.Quality.Issues) == 0:
+		return "ok"
+	}
+	return strings.Join(e.Quality.Issues, "; ")
+}
+
+func (c *DatasetInfoCommand) Execute(args []string) error {
+	ds := openDataset(c.Args.Dataset)
+	info := ds.Info()
+
+	fmt.Println(headerStyle.Render(ds.Root()))
+	fmt.Printf("Robot: %s  FPS: %d  Episodes: %d  Frames: %d  Tasks: %d\n\n", info.RobotType, info.FPS, info.TotalEpisodes, info.TotalFrames, info.TotalTasks)
+
+	var rows [][]string
+	for _, e := range ds.Episodes() {
+		rows = append(rows, []string{
+			strconv.Itoa(e.Index),
+			strconv.Itoa(e.Length),
+			formatSeconds(ds.Duration(e)),
+			strings.Join(e.Tasks, ", "),
+			episodeIssues(e),
+		})
+	}
+	fmt.Println(subHeaderStyle.Render("Episodes"))
+	fmt.Println(renderTable([]string{"Episode", "Frames", "Duration", "Task", "Quality"}, rows))
+
+	stats, err := ds.Stats()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error computing statistics: %v\n", err)
+		os.Exit(1)
+	}
+	for _, feature := range []string{dataset.FeatureAction, dataset.FeatureState, dataset.FeatureEffort} {
+		st, ok := stats[feature]
+		if !ok {
+			continue
+		}
+		rows = rows[:0]
+		for i, m := range ds.Motors() {
+			rows = append(rows, []string{
+				string(m),
+				fmt.Sprintf("%.1f", st.Min[i]),
+				fmt.Sprintf("%.1f", st.Max[i]),
+				fmt.Sprintf("%.1f", st.Mean[i]),
+				fmt.Sprintf("%.1f", st.Std[i]),
+			})
+		}
+		fmt.Println()
+		fmt.Println(subHeaderStyle.Render(feature))
+		fmt.Println(renderTable([]string{"Motor", "Min", "Max", "Mean", "Std"}, rows))
+	}
+	return nil
+}
+
+type DatasetDeleteCommand struct {
+	Args struct {
+		Dataset  string `positional-arg-name:"dataset" required:"yes"`
+		Episodes []int  `positional-arg-name:"episode" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *DatasetDeleteCommand) Execute(args []string) error {
+	ds := openDataset(c.Args.Dataset)
+	if err := ds.DeleteEpisodes(c.Args.Episodes...); err != nil {
+		fmt.Fprintf(os.Stderr, "Error deleting episodes: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Deleted %d episode(s), %d remaining\n", len(c.Args.Episodes), len(ds.Episodes()))
+	return nil
+}
+
+type DatasetMergeCommand struct {
+	Output string `long:"output" short:"o" required:"true" description:"Directory for the merged dataset"`
+	Args   struct {
+		Datasets []string `positional-arg-name:"dataset" required:"2"`
+	} `positional-args:"yes"`
+}
+
+func (c *DatasetMergeCommand) Execute(args []string) error {
+	var srcs []*dataset.Dataset
+	for _, path := range c.Args.Datasets {
+		srcs = append(srcs, openDataset(path))
+	}
+
+	merged, err := dataset.Merge(c.Output, srcs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error merging datasets: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Merged %d dataset(s) into %s (%d episodes)\n", len(srcs), c.Output, len(merged.Episodes()))
+	return nil
+}
+
+type DatasetStatsCommand struct {
+	Args struct {
+		Datasets []string `positional-arg-name:"dataset" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *DatasetStatsCommand) Execute(args []string) error {
+	for _, path := range c.Args.Datasets {
+		ds := openDataset(path)
+		if err := ds.WriteStats(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error computing statistics of %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote statistics of %d episode(s) to %s\n", len(ds.Episodes()), filepath.Join(path, "meta", "stats.json"))
+	}
+	return nil
+}
+
+type DatasetExportCommand struct {
+	Format     string `long:"format" short:"f" default:"csv" choice:"csv" choice:"parquet" choice:"rosbag" description:"Output format"`
+	Output     string `long:"output" short:"o" required:"true" description:"File to write, - for stdout, or with rosbag the directory for a bag per episode"`
+	Normalized bool   `long:"normalized" description:"With rosbag: keep positions in normalized units instead of converting them to radians with the follower calibration"`
+	Args       struct {
+		Dataset string `positional-arg-name:"dataset" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+func (c *DatasetExportCommand) Execute(args []string) error {
+	ds := openDataset(c.Args.Dataset)
+
+	if c.Format == "rosbag" {
+		var arm *robot.ArmConfig
+		if !c.Normalized {
+			cfg, err := robot.LoadConfig(opts.Profile)
+			if err != nil || !cfg.Follower.IsCalibrated() {
+				fmt.Fprintln(os.Stderr, "Converting positions to radians needs the follower calibration. Run 'lerobot setup' first, or pass --normalized.")
+				os.Exit(1)
+			}
+			arm = &cfg.Follower
+		}
+		if err := ds.WriteRosbag(c.Output, arm); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %d bag(s) to %s\n", len(ds.Episodes()), c.Output)
+		return nil
+	}
+
+	out := io.WriteCloser(nopCloser{os.Stdout})
+	if c.Output != "-" {
+		f, err := os.Create(c.Output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		out = f
+	}
+	write := ds.WriteCSV
+	if c.Format == "parquet" {
+		write = ds.WriteParquet
+	}
+	err := write(out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting: %v\n", err)
+		os.Exit(1)
+	}
+	if c.Output != "-" {
+		fmt.Printf("Wrote %d frame(s) to %s\n", ds.Info().TotalFrames, c.Output)
+	}
+	return nil
+}
+
+func openDataset(path string) *dataset.Dataset {
+	ds, err := dataset.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening dataset %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	return ds
+}
+
+func formatSeconds(s float64) string {
+	return fmt.Sprintf("%.1fs", s)
+}
+
+// renderTable renders a simple table in the style used throughout the CLI.
+func renderTable(headers []string, rows [][]string) string {
+	cellStyle := lipgloss.NewStyle().Padding(0, 1)
+	headerCellStyle := cellStyle.Bold(true).Foreground(lipgloss.Color("12"))
+
+	return table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(dimStyle).
+		Headers(headers...).
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerCellStyle
+			}
+			return cellStyle
+		}).
+		Render()
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type MotorsFlashSettingsCommand struct {
+	Arm    string `long:"arm" default:"both" choice:"leader" choice:"follower" choice:"both" description:"Arm to configure"`
+	Backup string `long:"backup" description:"File to save the previous values to (default: eeprom-backup-TIME.json)"`
+	Yes    bool   `long:"yes" short:"y" description:"Write without asking"`
+}
+
+// eepromBackup holds servo register values per arm, "leader" or
+// "follower", as saved by 'motors backup' and before 'motors
+// flash-settings' writes.
+type eepromBackup map[string]robot.RegisterValues
+
+// configArm is an arm of the configuration, by role.
+type configArm struct {
+	role string
+	cfg  robot.ArmConfig
+}
+
+// selectArms returns the configured arms for --arm: leader, follower or
+// both.
+func selectArms(cfg *robot.Config, which string) []configArm {
+	var arms []configArm
+	if which != "follower" {
+		arms = append(arms, configArm{"leader", cfg.Leader})
+	}
+	if which != "leader" {
+		arms = append(arms, configArm{"follower", cfg.Follower})
+	}
+	for _, a := range arms {
+		if a.cfg.Port == "" || !a.cfg.IsCalibrated() {
+			fmt.Fprintf(os.Stderr, "The %s arm is not set up. Run 'lerobot setup' first.\n", a.role)
+			os.Exit(1)
+		}
+	}
+	return arms
+}
+
+// openConfigArm opens an arm without applying its acceleration, speed and
+// torque limits, which live in RAM and don't matter for EEPROM settings.
+// A servo mismatch only warns, so the settings of an arm with a swapped
+// servo can still be backed up and restored.
+func openConfigArm(a configArm) *robot.Arm {
+	a.cfg.Acceleration, a.cfg.MaxSpeed, a.cfg.TorqueLimit = 0, 0, nil
+	a.cfg.SkipVerify = true
+	arm, err := robot.OpenArm(a.cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to %s arm on %s: %v\n", a.role, a.cfg.Port, err)
+		os.Exit(1)
+	}
+	warnServoMismatch(arm)
+	return arm
+}
+
+// warnServoMismatch warns about the servos of an arm opened with
+// robot.ArmConfig.SkipVerify that other commands would refuse.
+func warnServoMismatch(arm *robot.Arm) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := arm.VerifyServos(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+func (c *MotorsFlashSettingsCommand) Execute(args []string) error {
+	cfg := loadConfig()
+	cfg.ResolvePorts()
+
+	fmt.Println(headerStyle.Render("LeRobot Servo Settings"))
+	fmt.Println(dimStyle.Render("━━━━━━━━━━━━━━━━━━━━━━"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	backup := make(eepromBackup)
+	changes := make(eepromBackup)
+	arms := make(map[string]*robot.Arm)
+	for _, a := range selectArms(cfg, c.Arm) {
+		arm := openConfigArm(a)
+		defer arm.Close()
+		arms[a.role] = arm
+
+		want := robot.RecommendedSettings(a.cfg.Calibration, a.role == "follower")
+		current, err := arm.ReadRegisters(ctx, want)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s arm: %v\n", a.role, err)
+			os.Exit(1)
+		}
+		backup[a.role] = current
+
+		fmt.Println()
+		fmt.Println(subHeaderStyle.Render(fmt.Sprintf("━━━ %s arm (%s) ━━━", a.role, a.cfg.Port)))
+		diff := current.Diff(want)
+		if len(diff) == 0 {
+			fmt.Println(successStyle.Render("Already set"))
+			continue
+		}
+		changes[a.role] = diff
+		printRegisterDiff(arm.Motors(), current, diff)
+	}
+
+	fmt.Println()
+	if len(changes) == 0 {
+		fmt.Println(successStyle.Render("All servos have the recommended settings."))
+		return nil
+	}
+	if !c.Yes && !confirmWrite("Write these settings to the servos' EEPROM?") {
+		fmt.Println("Nothing written.")
+		return nil
+	}
+	// The prompt may have taken longer than the reads' timeout
+	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	path := c.Backup
+	if path == "" {
+		path = fmt.Sprintf("eeprom-backup-%s.json", time.Now().Format("20060102-150405"))
+	}
+	if err := writeBackup(path, backup); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving backup: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(dimStyle.Render("Previous values saved to " + path))
+
+	for _, role := range []string{"leader", "follower"} {
+		diff, ok := changes[role]
+		if !ok {
+			continue
+		}
+		if err := arms[role].WriteRegisters(ctx, diff); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s arm: %v\n", role, err)
+			fmt.Fprintf(os.Stderr, "Previous values are in %s\n", path)
+			os.Exit(1)
+		}
+		fmt.Println(successStyle.Render(fmt.Sprintf("Updated the %s arm", role)))
+	}
+	return nil
+}
+
+// printRegisterDiff prints the registers that change, per motor.
+func printRegisterDiff(motors []robot.MotorName, current, diff robot.RegisterValues) {
+	for _, name := range motors {
+		var regs []string
+		for reg := range diff[name] {
+			regs = append(regs, reg)
+		}
+		slices.Sort(regs)
+		for _, reg := range regs {
+			fmt.Printf("  %-14s %-20s %5d → %d\n", name, reg, current[name][reg], diff[name][reg])
+		}
+	}
+}
+
+// confirmWrite asks whether to write the settings shown. An aborted prompt
+// writes nothing.
+func confirmWrite(title string) bool {
+	var ok bool
+	err := huh.NewConfirm().
+		Title(title).
+		Affirmative("Write").
+		Negative("Cancel").
+		Value(&ok).
+		Run()
+	if err != nil {
+		fmt.Println()
+		return false
+	}
+	return ok
+}
+
+type MotorsBackupCommand struct {
+	Arm    string `long:"arm" default:"both" choice:"leader" choice:"follower" choice:"both" description:"Arm to back up"`
+	Output string `long:"output" short:"o" description:"File to save to (default: eeprom-backup-TIME.json)"`
+}
+
+func (c *MotorsBackupCommand) Execute(args []string) error {
+	cfg := loadConfig()
+	cfg.ResolvePorts()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	backup := make(eepromBackup)
+	for _, a := range selectArms(cfg, c.Arm) {
+		arm := openConfigArm(a)
+		defer arm.Close()
+		values, err := arm.ReadRegisters(ctx, robot.EEPROMSettings(arm.Motors()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s arm: %v\n", a.role, err)
+			os.Exit(1)
+		}
+		backup[a.role] = values
+	}
+
+	path := c.Output
+	if path == "" {
+		path = fmt.Sprintf("eeprom-backup-%s.json", time.Now().Format("20060102-150405"))
+	}
+	if err := writeBackup(path, backup); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving backup: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(successStyle.Render("Saved the servo settings to " + path))
+	return nil
+}
+
+type MotorsRestoreCommand struct {
+	Arm  string `long:"arm" default:"both" choice:"leader" choice:"follower" choice:"both" description:"Arm to restore"`
+	Yes  bool   `long:"yes" short:"y" description:"Write without asking"`
+	Args struct {
+		File string `positional-arg-name:"FILE" required:"yes" description:"Backup made by 'motors backup' or 'motors flash-settings'"`
+	} `positional-args:"yes"`
+}
+
+func (c *MotorsRestoreCommand) Execute(args []string) error {
+	backup, err := readBackup(c.Args.File)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading backup: %v\n", err)
+		os.Exit(1)
+	}
+	cfg := loadConfig()
+	cfg.ResolvePorts()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	changes := make(eepromBackup)
+	arms := make(map[string]*robot.Arm)
+	for _, a := range selectArms(cfg, c.Arm) {
+		want, ok := backup[a.role]
+		if !ok {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("No %s arm in %s", a.role, c.Args.File)))
+			continue
+		}
+		arm := openConfigArm(a)
+		defer arm.Close()
+		arms[a.role] = arm
+
+		// IDs and baud rates address the servos and are left alone
+		want = restorable(want, robot.EEPROMSettings(arm.Motors()))
+		current, err := arm.ReadRegisters(ctx, want)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s arm: %v\n", a.role, err)
+			os.Exit(1)
+		}
+		fmt.Println()
+		fmt.Println(subHeaderStyle.Render(fmt.Sprintf("━━━ %s arm (%s) ━━━", a.role, a.cfg.Port)))
+		diff := current.Diff(want)
+		if len(diff) == 0 {
+			fmt.Println(successStyle.Render("Matches the backup"))
+			continue
+		}
+		changes[a.role] = diff
+		printRegisterDiff(arm.Motors(), current, diff)
+	}
+
+	fmt.Println()
+	if len(changes) == 0 {
+		fmt.Println(successStyle.Render("Nothing to restore."))
+		return nil
+	}
+	if !c.Yes && !confirmWrite("Restore these settings to the servos' EEPROM?") {
+		fmt.Println("Nothing written.")
+		return nil
+	}
+	// The prompt may have taken longer than the reads' timeout
+	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, role := range []string{"leader", "follower"} {
+		diff, ok := changes[role]
+		if !ok {
+			continue
+		}
+		if err := arms[role].WriteRegisters(ctx, diff); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s arm: %v\n", role, err)
+			os.Exit(1)
+		}
+		fmt.Println(successStyle.Render(fmt.Sprintf("Restored the %s arm", role)))
+	}
+	return nil
+}
+
+// restorable returns the values of backup for the registers in settings.
+func restorable(backup, settings robot.RegisterValues) robot.RegisterValues {
+	values := make(robot.RegisterValues)
+	for name, regs := range backup {
+		for reg, v := range regs {
+			if _, ok := settings[name][reg]; !ok {
+				continue
+			}
+			if values[name] == nil {
+				values[name] = make(map[string]int)
+			}
+			values[name][reg] = v
+		}
+	}
+	return values
+}
+
+func readBackup(path string) (eepromBackup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var backup eepromBackup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return backup, nil
+}
+
+func writeBackup(path string, backup eepromBackup) error {
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/camera"
+	"github.com/gwillem/lerobot/pkg/dataset"
+	"github.com/gwillem/lerobot/pkg/teleop"
+	"github.com/gwillem/lerobot/pkg/timesync"
+)
+
+// recordSource is what the teleoperate TUI records from, a
+// teleop.Controller.
+type recordSource interface {
+	States() <-chan teleop.State
+	EpisodeStarted(index int) error
+	EpisodeEnded(index int, saved bool)
+}
+
+// episodeRecorder records episodes from controller states in the
+// teleoperate TUI. It alternates between recording an episode and a reset
+// phase; the operator moves on with the arrow keys, or the phases end
+// after episodeTime and resetTime if those are set. It takes every state
+// on its own goroutine, see run, so drawing the TUI doesn't drop frames.
+type episodeRecorder struct {
+	ctrl        recordSource // switched to ModeRecord during episodes
+	ds          *dataset.Dataset
+	cams        []*camera.Grabber
+	episodeTime time.Duration // 0: until the operator ends the episode
+	resetTime   time.Duration // 0: until the operator starts the next one
+
+	mu         sync.Mutex
+	task       string                 // stored with every episode, see setTask
+	ep         *dataset.EpisodeWriter // nil while resetting
+	phaseStart time.Time
+	clock      *timesync.Clock // started at the episode's first state, with ModeRecord
+	align      timesync.Alignment
+	err        error // recording failure that stopped the TUI
+	closed     bool  // by close, nothing more is recorded
+}
+
+// run records the states of the controller until ctx is done or recording
+// fails, passing the messages of episodes that start or end on their own
+// and the failure to report.
+func (r *episodeRecorder) run(ctx context.Context, report func(string, error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case state := <-r.ctrl.States():
+			msg, err := r.add(state)
+			if err != nil {
+				r.fail(err)
+			}
+			if msg != "" || err != nil {
+				report(msg, err)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// fail records the failure that stops recording, unless there already was
+// one.
+func (r *episodeRecorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// failure returns the failure that stopped recording, if any.
+func (r *episodeRecorder) failure() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// start starts recording the first episode.
+func (r *episodeRecorder) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.begin()
+}
+
+// begin starts recording the next episode.
+func (r *episodeRecorder) begin() {
+	r.ep = r.ds.NewEpisode()
+	r.ep.SetTask(r.task)
+	r.phaseStart = time.Now()
+	r.clock = nil
+	r.align = timesync.Alignment{}
+}
+
+// currentTask returns the task of the current episode.
+func (r *episodeRecorder) currentTask() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.task
+}
+
+// setTask changes the task of the current episode and the ones after it.
+func (r *episodeRecorder) setTask(task string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.task = task
+	if r.recording() {
+		r.ep.SetTask(task)
+	}
+	if task == "" {
+		return "Task cleared"
+	}
+	return fmt.Sprintf("Task: %s", task)
+}
+
+// recording reports whether an episode is being recorded, as opposed to
+// the scene being reset.
+func (r *episodeRecorder) recording() bool { return r.ep != nil }
+
+// add records a state in the current episode and ends or starts episodes
+// whose time is up. It returns a message to log when that happens.
+func (r *episodeRecorder) add(state teleop.State) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return "", nil
+	}
+	if !r.recording() {
+		if r.resetTime > 0 && time.Since(r.phaseStart) >= r.resetTime {
+			r.begin()
+			return fmt.Sprintf("Recording episode %d", r.ep.Index()), nil
+		}
+		return "", nil
+	}
+	if r.episodeTime > 0 && time.Since(r.phaseStart) >= r.episodeTime {
+		return r.end()
+	}
+	if state.Positions == nil || state.FollowerPositions == nil {
+		return "", nil
+	}
+	if r.clock == nil {
+		if err := r.ctrl.EpisodeStarted(r.ep.Index()); err != nil {
+			return "", err
+		}
+		r.clock = timesync.NewClock(state.Timestamp)
+	}
+	images, err := latestImages(r.cams)
+	if err != nil {
+		return "", err
+	}
+	return "", addFrame(r.ep, r.cams, images, r.clock.Offset(state.Timestamp), state, false, &r.align)
+}
+
+// next ends the current episode, saving it, or ends the reset phase.
+func (r *episodeRecorder) next() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return "", nil
+	}
+	if r.recording() {
+		return r.end()
+	}
+	r.begin()
+	return fmt.Sprintf("Recording episode %d", r.ep.Index()), nil
+}
+
+// rerecord discards the current episode and resets the scene to record it
+// again.
+func (r *episodeRecorder) rerecord() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.recording() {
+		return ""
+	}
+	index := r.ep.Index()
+	r.ep.Discard()
+	if r.clock != nil {
+		r.ctrl.EpisodeEnded(index, false)
+	}
+	r.ep = nil
+	r.phaseStart = time.Now()
+	return fmt.Sprintf("Discarded episode %d, reset the environment to record it again", index)
+}
+
+// end saves the current episode, unless it is empty, and starts the reset
+// phase.
+func (r *episodeRecorder) end() (string, error) {
+	ep := r.ep
+	r.ep = nil
+	r.phaseStart = time.Now()
+	if ep.Len() == 0 {
+		ep.Discard()
+		if r.clock != nil {
+			r.ctrl.EpisodeEnded(ep.Index(), false)
+		}
+		return "", nil
+	}
+	ep.SetAlignment(r.align.Max())
+	q := ep.Check(dataset.DefaultQualityLimits())
+	err := ep.Save()
+	r.ctrl.EpisodeEnded(ep.Index(), err == nil)
+	if err != nil {
+		return "", fmt.Errorf("save episode %d: %w", ep.Index(), err)
+	}
+	msg := fmt.Sprintf("Saved episode %d (%d frames, aligned within %v), reset the environment", ep.Index(), ep.Len(), r.align.Bound().Round(time.Millisecond))
+	if len(q.Issues) > 0 {
+		msg += fmt.Sprintf(". Check it: %s", strings.Join(q.Issues, ", "))
+	}
+	return msg, nil
+}
+
+// close saves the episode being recorded, if any and recording didn't
+// fail, and stops recording. It returns the failure, if any.
+func (r *episodeRecorder) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed && r.err == nil && r.recording() {
+		_, r.err = r.end()
+	}
+	r.closed = true
+	return r.err
+}
+
+// saved returns the number of episodes in the dataset.
+func (r *episodeRecorder) saved() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.ds.Episodes())
+}
+
+// status describes the current phase for the TUI header.
+func (r *episodeRecorder) status() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	elapsed := time.Since(r.phaseStart).Round(time.Second)
+	if r.recording() {
+		return fmt.Sprintf("REC episode %d %s", r.ep.Index(), elapsed)
+	}
+	return fmt.Sprintf("RESET %s, %d saved", elapsed, len(r.ds.Episodes()))
+}
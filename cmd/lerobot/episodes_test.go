@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/dataset"
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/teleop"
+)
+
+// fakeRecordSource is a recordSource that remembers the episodes reported.
+type fakeRecordSource struct {
+	states chan teleop.State
+
+	mu    sync.Mutex
+	ended []bool // saved, per ended episode
+}
+
+func (f *fakeRecordSource) States() <-chan teleop.State    { return f.states }
+func (f *fakeRecordSource) EpisodeStarted(index int) error { return nil }
+func (f *fakeRecordSource) EpisodeEnded(index int, saved bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ended = append(f.ended, saved)
+}
+
+func TestEpisodeRecorder(t *testing.T) {
+	ds, err := dataset.Create(filepath.Join(t.TempDir(), "ds"), 30, []robot.MotorName{robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &fakeRecordSource{states: make(chan teleop.State)}
+	rec := &episodeRecorder{ctrl: src, ds: ds}
+	rec.start()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reports := make(chan error, 1)
+	go rec.run(ctx, func(msg string, err error) { reports <- err })
+
+	// Every state is recorded, however slowly the TUI takes them
+	start := time.Now()
+	for i := range 50 {
+		positions := map[robot.MotorName]float64{robot.Gripper: float64(i)}
+		src.states <- teleop.State{Timestamp: start.Add(time.Duration(i) * time.Millisecond), Positions: positions, FollowerPositions: positions}
+	}
+	for deadline := time.Now().Add(time.Second); ; {
+		rec.mu.Lock()
+		n := rec.ep.Len()
+		rec.mu.Unlock()
+		if n == 50 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("recorded %d frames, want 50", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := rec.next(); err != nil {
+		t.Fatal(err)
+	}
+	if rec.saved() != 1 || len(src.ended) != 1 || !src.ended[0] {
+		t.Errorf("saved %d episodes, reported %v", rec.saved(), src.ended)
+	}
+
+	// Nothing is recorded once closed, not even after the reset phase
+	if err := rec.close(); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := rec.next(); msg != "" || rec.recording() {
+		t.Errorf("next() after close = %q", msg)
+	}
+}
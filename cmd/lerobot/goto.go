@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type GotoCommand struct {
+	Speed   float64 `long:"speed" default:"30" description:"Maximum joint speed in normalized units per second (full range is 200)"`
+	Save    bool    `long:"save" description:"Save the follower's current position as this pose instead of moving"`
+	Release bool    `long:"release" description:"Disable torque after reaching the pose (default: hold it)"`
+	Args    struct {
+		Pose string `positional-arg-name:"pose" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+func (c *GotoCommand) Execute(args []string) error {
+	cfg := loadConfig()
+	if cfg.Follower.Port == "" || !cfg.Follower.IsCalibrated() {
+		fmt.Fprintln(os.Stderr, "Follower not configured. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+
+	name := c.Args.Pose
+	pose, ok := cfg.Pose(name)
+	if !ok && !c.Save {
+		fmt.Fprintf(os.Stderr, "Unknown pose %q. Saved poses: %s\n", name, poseNames(cfg))
+		os.Exit(1)
+	}
+
+	cfg.Follower.ResolvePort()
+	arm, err := robot.OpenArm(cfg.Follower)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to follower: %v\n", err)
+		os.Exit(1)
+	}
+	defer arm.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	if c.Save {
+		return savePose(ctx, cfg, arm, name)
+	}
+
+	if err := arm.Hold(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := arm.Enable(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error enabling torque: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Moving follower to %s...\n", name)
+	if err := robot.MoveTo(ctx, arm, pose, c.Speed); err != nil {
+		// Interrupted or failed: let go rather than hold a half-finished move
+		arm.Disable(context.Background())
+		fmt.Fprintf(os.Stderr, "Error moving to %s: %v\n", name, err)
+		os.Exit(1)
+	}
+
+	if c.Release {
+		if err := arm.Disable(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error disabling torque: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Println(successStyle.Render(fmt.Sprintf("Reached %s", name)))
+	return nil
+}
+
+// savePose stores the follower's current position under name.
+func savePose(ctx context.Context, cfg *robot.Config, arm *robot.Arm, name string) error {
+	pose, err := arm.ReadPositions(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading positions: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Poses == nil {
+		cfg.Poses = make(map[string]map[robot.MotorName]float64)
+	}
+	cfg.Poses[name] = pose
+	if err := cfg.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(successStyle.Render(fmt.Sprintf("Saved pose %s to %s", name, cfg.Path())))
+	return nil
+}
+
+func poseNames(cfg *robot.Config) string {
+	names := slices.Sorted(maps.Keys(cfg.Poses))
+	if len(cfg.RestPose) > 0 && cfg.Poses["rest"] == nil {
+		names = append(names, "rest")
+	}
+	if len(names) == 0 {
+		return "none (save one with --save)"
+	}
+	return strings.Join(names, ", ")
+}
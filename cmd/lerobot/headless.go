@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/teleop"
+)
+
+// stateLine is one controller state as written by --no-tui, one JSON
+// object per line.
+type stateLine struct {
+	Time        time.Time                   `json:"time"`
+	Leader      map[robot.MotorName]float64 `json:"leader,omitempty"`       // normalized
+	Targets     map[robot.MotorName]float64 `json:"targets,omitempty"`      // follower targets, absent while paused
+	Follower    map[robot.MotorName]float64 `json:"follower,omitempty"`     // observed, if read
+	FollowerVel map[robot.MotorName]float64 `json:"follower_vel,omitempty"` // observed, normalized units/s
+	Paused      bool                        `json:"paused"`
+	Triggered   bool                        `json:"triggered,omitempty"` // see teleop.trigger in the configuration
+	ReadUs      int64                       `json:"read_us"`
+	WriteUs     int64                       `json:"write_us"`
+	MissedTicks int                         `json:"missed_ticks"`
+	Error       string                      `json:"error,omitempty"`
+}
+
+// openOutput opens the destination for headless state lines: "-" for
+// stdout, or unix:PATH or tcp:HOST:PORT to connect to a listening socket.
+func openOutput(dest string) (io.WriteCloser, error) {
+	if dest == "" || dest == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	network, addr, ok := strings.Cut(dest, ":")
+	if !ok || (network != "unix" && network != "tcp") {
+		return nil, fmt.Errorf("invalid output %q, want -, unix:PATH or tcp:HOST:PORT", dest)
+	}
+	return net.Dial(network, addr)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// runHeadless writes every controller state to out as JSONL and controller
+// logs to stderr until ctx is cancelled. A failing writer (e.g. a closed
+// pipe) is returned as an error so the caller can stop.
+func runHeadless(ctx context.Context, ctrl *teleop.Controller, out io.Writer) error {
+	enc := json.NewEncoder(out)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-ctrl.Logs():
+			fmt.Fprintln(os.Stderr, msg)
+		case st := <-ctrl.States():
+			line := stateLine{
+				Time:        st.Timestamp,
+				Leader:      st.Positions,
+				Targets:     st.Targets,
+				Follower:    st.FollowerPositions,
+				FollowerVel: st.FollowerVelocities,
+				Paused:      st.Paused,
+				Triggered:   st.Triggered,
+				ReadUs:      st.ReadLatency.Microseconds(),
+				WriteUs:     st.WriteLatency.Microseconds(),
+				MissedTicks: st.MissedTicks,
+				Error:       errString(st.Error),
+			}
+			if err := enc.Encode(line); err != nil {
+				return fmt.Errorf("write state: %w", err)
+			}
+		}
+	}
+}
+
+// drainLogs prints the controller logs still buffered, e.g. from shutdown.
+func drainLogs(ctrl *teleop.Controller) {
+	for {
+		select {
+		case msg := <-ctrl.Logs():
+			fmt.Fprintln(os.Stderr, msg)
+		default:
+			return
+		}
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/policy"
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/teleop"
+)
+
+type InferCommand struct {
+	Server         string        `long:"server" required:"true" description:"Policy server websocket URL, e.g. ws://gpu-box:8765"`
+	Task           string        `long:"task" description:"Task instruction for language-conditioned policies"`
+	Camera         []string      `long:"camera" description:"Camera to observe, name=device[@WIDTHxHEIGHT][+LATENCY] (repeatable, default: cameras from the configuration)"`
+	FPS            int           `long:"fps" default:"30" description:"Control rate, matching the policy's training data"`
+	ChunkThreshold float64       `long:"chunk-threshold" default:"0.5" description:"Request the next action chunk when this fraction of the last one is left"`
+	Timeout        time.Duration `long:"timeout" default:"1s" description:"How long to run without actions before the fallback"`
+	Fallback       string        `long:"fallback" default:"hold" choice:"hold" choice:"disable" description:"When the server falls behind: hold the last pose, or disable torque and stop"`
+	ReleaseAfter   time.Duration `long:"release-after" default:"5s" description:"With --fallback hold: ramp torque down and stop after this long without actions (0 holds indefinitely)"`
+	SoftStart      time.Duration `long:"soft-start" default:"2s" description:"Move the follower from its own pose to the first actions over this long (0 to snap)"`
+	Ensemble       bool          `long:"ensemble" description:"Average overlapping chunks (temporal ensembling) instead of switching to the newest"`
+	EnsembleDecay  float64       `long:"ensemble-decay" default:"0.01" description:"Weight older predictions by exp(-decay*i) when ensembling"`
+	Duration       time.Duration `long:"duration" description:"Stop after this long (default: until Ctrl+C)"`
+}
+
+func (c *InferCommand) Execute(args []string) error {
+	cfg := loadConfig()
+	if cfg.Follower.Port == "" || !cfg.Follower.IsCalibrated() {
+		fmt.Fprintln(os.Stderr, "Follower not configured. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+	if cfg.Follower.ResolvePort() {
+		fmt.Printf("Serial port moved: follower on %s\n", cfg.Follower.Port)
+	}
+
+	specs := c.Camera
+	if len(specs) == 0 {
+		specs = cfg.Cameras
+	}
+	cams, err := openCameras(specs, c.FPS, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening cameras: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		for _, g := range cams {
+			g.Close()
+		}
+	}()
+
+	arm, err := robot.OpenArm(cfg.Follower)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to follower: %v\n", err)
+		os.Exit(1)
+	}
+	defer arm.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+	if c.Duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.Duration)
+		defer cancel()
+	}
+
+	runner, err := policy.Dial(ctx, arm, cfg.Follower.Calibration.Motors(), cams, policy.Config{
+		URL:            c.Server,
+		FPS:            c.FPS,
+		Task:           c.Task,
+		ChunkThreshold: c.ChunkThreshold,
+		Timeout:        c.Timeout,
+		Fallback:       policy.Fallback(c.Fallback),
+		Release:        c.ReleaseAfter,
+		SoftStart:      c.SoftStart,
+		Guard:          robot.NewMotionGuard(cfg.Follower, cfg.KinematicModel(), cfg.Workspace),
+		Chunks:         teleop.ChunkConfig{Ensemble: c.Ensemble, Decay: c.EnsembleDecay},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer runner.Close()
+
+	if err := arm.Enable(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error enabling torque: %v\n", err)
+		os.Exit(1)
+	}
+	defer arm.Disable(context.Background())
+
+	fmt.Printf("Running policy from %s at %d fps with %d camera(s)\n", c.Server, c.FPS, len(cams))
+	fmt.Println("Press Ctrl+C to stop.")
+
+	done := make(chan error, 1)
+	go func() { done <- runner.Run(ctx) }()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			fmt.Println()
+			s := runner.Stats()
+			fmt.Println(dimStyle.Render(fmt.Sprintf("%d actions from %d chunks, %d steps without an action", s.Executed, s.Chunks, s.Starved)))
+			if errors.Is(err, policy.ErrTimeout) {
+				fmt.Fprintln(os.Stderr, warnStyle.Render(fmt.Sprintf("No actions for %v, torque disabled", c.Timeout)))
+				os.Exit(1)
+			}
+			if errors.Is(err, policy.ErrReleased) {
+				fmt.Fprintln(os.Stderr, warnStyle.Render(fmt.Sprintf("No actions for %v, torque ramped down", c.ReleaseAfter)))
+				os.Exit(1)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return nil
+		case <-ticker.C:
+			s := runner.Stats()
+			fmt.Printf("\r  step %d  queued %d  chunks %d  round trip %v  starved %d   ",
+				s.Timestep, s.Queued, s.Chunks, s.RoundTrip.Round(time.Millisecond), s.Starved)
+		}
+	}
+}
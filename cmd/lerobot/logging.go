@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/gwillem/lerobot/pkg/logging"
+)
+
+// openLogger returns the logger for --log-file and the --log-level. The
+// logger is nil without --log-file. Call the returned function to close the
+// file.
+func openLogger() (*slog.Logger, slog.Level, func()) {
+	level, err := logging.ParseLevel(opts.LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.LogFile == "" {
+		return nil, level, func() {}
+	}
+
+	handler, f, err := logging.OpenFile(opts.LogFile, level)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening log file: %v\n", err)
+		os.Exit(1)
+	}
+	return slog.New(handler), level, func() { f.Close() }
+}
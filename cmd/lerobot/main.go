@@ -1,21 +1,53 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/jessevdk/go-flags"
+
+	"github.com/gwillem/lerobot/pkg/robot"
 )
 
 type Options struct {
+	LogFile  string `long:"log-file" description:"Append structured JSON logs to this file"`
+	LogLevel string `long:"log-level" default:"info" choice:"debug" choice:"info" choice:"warn" choice:"error" description:"Minimum level of log events"`
+	Profile  string `long:"profile" env:"LEROBOT_PROFILE" description:"Use a named configuration profile, e.g. for one of several robots"`
+
 	Setup       SetupCommand       `command:"setup" description:"Scan for arms and calibrate them"`
 	Teleoperate TeleoperateCommand `command:"teleoperate" alias:"teleop" description:"Start teleoperation (leader-follower control)"`
+	Check       CheckCommand       `command:"check" description:"Validate calibration against the connected arms"`
+	Status      StatusCommand      `command:"status" description:"Show a live dashboard of all servos"`
+	Monitor     MonitorCommand     `command:"monitor" description:"Plot one arm's joint positions without touching its torque"`
+	Scan        ScanCommand        `command:"scan" description:"Probe serial ports for Feetech servos at any ID and baud rate"`
+	Motors      MotorsCommand      `command:"motors" description:"Servo configuration tools"`
+	Goto        GotoCommand        `command:"goto" description:"Move the follower to a named pose, or save one"`
+	Run         RunCommand         `command:"run" description:"Run a waypoint motion script on the follower"`
+	Benchmark   BenchmarkCommand   `command:"benchmark" description:"Measure bus throughput and recommend a control loop frequency"`
+	Record      RecordCommand      `command:"record" description:"Record teleoperation episodes to a dataset"`
+	Replay      ReplayCommand      `command:"replay" description:"Play a dataset episode's actions on the follower"`
+	Dataset     DatasetCommand     `command:"dataset" description:"Inspect and manage recorded datasets"`
+	Cameras     CamerasCommand     `command:"cameras" description:"List cameras, or preview them in the terminal"`
+	Serve       ServeCommand       `command:"serve" description:"Serve a REST and gRPC API for robot control"`
+	Web         WebCommand         `command:"web" description:"Serve a browser dashboard with live cameras and robot controls"`
+	Infer       InferCommand       `command:"infer" description:"Drive the follower with a policy on a remote inference server"`
+	Ros2Bridge  Ros2BridgeCommand  `command:"ros2-bridge" description:"Bridge the follower to ROS 2 topics via rosbridge"`
+	Config      ConfigCommand      `command:"config" description:"Show, edit and list configuration profiles"`
+	Configure   ConfigureCommand   `command:"configure" description:"Change ports, teleoperation, safety and camera settings in a form"`
 }
 
 var opts Options
-var parser = flags.NewParser(&opts, flags.Default)
+var parser = flags.NewParser(&opts, flags.Default|flags.AllowBoolValues)
 
 func main() {
 	parser.LongDescription = "LeRobot - Robot arm control CLI for SO-101 arms"
+	parser.CommandHandler = func(cmd flags.Commander, args []string) error {
+		if err := robot.CheckProfile(opts.Profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return cmd.Execute(args)
+	}
 
 	_, err := parser.Parse()
 	if err != nil {
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/streamlinechart"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type MonitorCommand struct {
+	Arm    string `long:"arm" default:"follower" choice:"leader" choice:"follower" description:"Arm to watch"`
+	Hz     int    `long:"hz" default:"30" description:"Readings per second"`
+	NoTUI  bool   `long:"no-tui" description:"Write every reading as a JSON line to --output instead of plotting it"`
+	Output string `long:"output" default:"-" description:"With --no-tui: - for stdout, or unix:PATH or tcp:HOST:PORT to send readings to a socket"`
+}
+
+// monitorLine is one reading as written by --no-tui.
+type monitorLine struct {
+	Time      time.Time                   `json:"time"`
+	Positions map[robot.MotorName]float64 `json:"positions,omitempty"` // normalized
+	Error     string                      `json:"error,omitempty"`
+}
+
+func (c *MonitorCommand) Execute(args []string) error {
+	cfg := loadConfig()
+	cfg.ResolvePorts()
+	armCfg := cfg.Follower
+	if c.Arm == "leader" {
+		armCfg = cfg.Leader
+	}
+	if armCfg.Port == "" || !armCfg.IsCalibrated() {
+		fmt.Fprintf(os.Stderr, "The %s arm is not set up. Run 'lerobot setup' first.\n", c.Arm)
+		os.Exit(1)
+	}
+	if c.Hz <= 0 {
+		fmt.Fprintln(os.Stderr, "--hz must be positive")
+		os.Exit(1)
+	}
+
+	// Only read: leave torque and the servo limits as they are
+	armCfg.Acceleration, armCfg.MaxSpeed, armCfg.TorqueLimit = 0, 0, nil
+	arm, err := robot.OpenArm(armCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to %s arm on %s: %v\n", c.Arm, armCfg.Port, err)
+		os.Exit(1)
+	}
+	defer arm.Close()
+	period := time.Second / time.Duration(c.Hz)
+
+	if c.NoTUI {
+		return c.runHeadless(arm, period)
+	}
+
+	motors := armCfg.Calibration.Motors()
+	m := monitorModel{
+		arm:    arm,
+		name:   c.Arm,
+		motors: motors,
+		period: period,
+		chart:  newMotorChart(motors),
+		hidden: make(map[robot.MotorName]bool),
+	}
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running monitor: %v\n", err)
+		os.Exit(1)
+	}
+	return nil
+}
+
+// runHeadless writes a JSON line per reading until interrupted.
+func (c *MonitorCommand) runHeadless(arm *robot.Arm, period time.Duration) error {
+	out, err := openOutput(c.Output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer out.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	enc := json.NewEncoder(out)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			positions, err := arm.ReadPositions(ctx)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := enc.Encode(monitorLine{Time: now, Positions: positions, Error: errString(err)}); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: write reading: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+type monitorModel struct {
+	arm      *robot.Arm
+	name     string
+	motors   []robot.MotorName
+	period   time.Duration
+	chart    *streamlinechart.Model
+	hidden   map[robot.MotorName]bool
+	last     map[robot.MotorName]float64
+	err      error
+	frozen   bool
+	width    int
+	height   int
+	quitting bool
+}
+
+type monitorTickMsg time.Time
+
+func (m monitorModel) tick() tea.Cmd {
+	return tea.Tick(m.period, func(t time.Time) tea.Msg { return monitorTickMsg(t) })
+}
+
+func (m monitorModel) Init() tea.Cmd {
+	return m.tick()
+}
+
+func (m monitorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		w := max(m.width-borderSize-2, 40)
+		h := max(m.height-headerHeight-legendHeight-4-borderSize, 10)
+		m.chart.Resize(w, h)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		case " ":
+			m.frozen = !m.frozen
+		case "a":
+			clear(m.hidden)
+			m.draw()
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if i := int(msg.String()[0] - '1'); i < len(m.motors) {
+				name := m.motors[i]
+				m.hidden[name] = !m.hidden[name]
+				m.draw()
+			}
+		}
+		return m, nil
+
+	case monitorTickMsg:
+		ctx, cancel := context.WithTimeout(context.Background(), m.period)
+		defer cancel()
+		positions, err := m.arm.ReadPositions(ctx)
+		m.err = err
+		if err == nil && !m.frozen {
+			m.last = positions
+			for _, name := range m.motors {
+				if pos, ok := positions[name]; ok {
+					m.chart.PushDataSet(string(name), pos)
+				}
+			}
+			m.draw()
+		}
+		return m, m.tick()
+	}
+	return m, nil
+}
+
+// draw redraws the traces of the motors that aren't hidden.
+func (m monitorModel) draw() {
+	var names []string
+	for _, name := range m.motors {
+		if !m.hidden[name] {
+			names = append(names, string(name))
+		}
+	}
+	if len(names) == 0 {
+		m.chart.Clear()
+		m.chart.DrawXYAxisAndLabel()
+		return
+	}
+	m.chart.DrawDataSets(names)
+}
+
+func (m monitorModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render(fmt.Sprintf("LeRobot Monitor - %s (%s), torque untouched", m.name, m.arm.Port())))
+	if m.frozen {
+		sb.WriteString(pausedStyle.Render("  FROZEN"))
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(chartStyle.Render(m.chart.View()))
+	sb.WriteString("\n")
+	sb.WriteString(renderLegend(m.motors, m.hidden))
+	sb.WriteString("\n\n")
+
+	var values []string
+	for _, name := range m.motors {
+		if pos, ok := m.last[name]; ok {
+			values = append(values, fmt.Sprintf("%s %6.1f", name, pos))
+		}
+	}
+	sb.WriteString(strings.Join(values, "  "))
+	sb.WriteString("\n")
+	if m.err != nil {
+		sb.WriteString(warnStyle.Render(fmt.Sprintf("Read failed: %v", m.err)))
+	} else {
+		sb.WriteString(statusStyle.Render("Press 1-9/'a' to toggle traces, space to freeze, 'q' to quit"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+type MotorsCommand struct {
+	Setup         MotorsSetupCommand         `command:"setup" description:"Assign servo IDs to factory servos, one motor at a time"`
+	FlashSettings MotorsFlashSettingsCommand `command:"flash-settings" description:"Write the recommended EEPROM settings to the arms' servos"`
+	Backup        MotorsBackupCommand        `command:"backup" description:"Save the EEPROM settings of the arms' servos to a JSON file"`
+	Restore       MotorsRestoreCommand       `command:"restore" description:"Write EEPROM settings saved by backup or flash-settings back to the servos"`
+}
+
+type MotorsSetupCommand struct {
+	Port     string `long:"port" description:"Serial port of the controller board (default: ask)"`
+	IDOffset int    `long:"id-offset" default:"0" description:"Add this to the IDs, e.g. 6 for a follower daisy-chained after the leader"`
+}
+
+func (c *MotorsSetupCommand) Execute(args []string) error {
+	fmt.Println(headerStyle.Render("LeRobot Motor Setup"))
+	fmt.Println(dimStyle.Render("━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Println()
+	fmt.Println("Factory servos all ship with ID 1. This assigns each motor its ID")
+	fmt.Printf("and sets the baud rate to %d, one motor at a time.\n\n", robot.DefaultBaudRate)
+
+	port := c.Port
+	if port == "" {
+		port = selectPort()
+	}
+
+	motors := configuredMotors()
+	// Go in reverse like the Python tool: gripper first, then down the arm
+	for i := len(motors) - 1; i >= 0; i-- {
+		name := motors[i].Name
+		id := motors[i].ID + c.IDOffset
+
+		fmt.Println(subHeaderStyle.Render(fmt.Sprintf("━━━ %s (ID %d) ━━━", name, id)))
+		waitForUser(fmt.Sprintf("Connect ONLY the %s motor to the controller board.", name))
+
+		if err := assignMotorID(port, id); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting up %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		fmt.Println(successStyle.Render(fmt.Sprintf("%s motor set to ID %d", name, id)))
+		fmt.Println()
+	}
+
+	fmt.Println(successStyle.Render("All motors configured."))
+	fmt.Println("Assemble the arm and run: " + headerStyle.Render("lerobot setup"))
+	return nil
+}
+
+// selectPort asks the user to pick one of the available serial ports.
+func selectPort() string {
+	ports, err := robot.ListPorts()
+	if err != nil || len(ports) == 0 {
+		fmt.Fprintln(os.Stderr, "No serial ports found. Is the controller board connected?")
+		os.Exit(1)
+	}
+	if len(ports) == 1 {
+		return ports[0]
+	}
+
+	var options []huh.Option[string]
+	for _, p := range ports {
+		options = append(options, huh.NewOption(p, p))
+	}
+
+	var port string
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Which port is the controller board on?").
+				Options(options...).
+				Value(&port),
+		),
+	)
+	if err := form.Run(); err != nil {
+		fmt.Println()
+		os.Exit(0)
+	}
+	return port
+}
+
+// findSingleServo looks for exactly one servo on port, trying every
+// supported baud rate. It returns the servo and the baud rate it answered on.
+func findSingleServo(port string) (feetech.FoundServo, int, error) {
+	for _, baud := range feetech.DefaultBaudRates {
+		bus, err := feetech.NewBus(feetech.BusConfig{
+			Port:     port,
+			BaudRate: baud,
+			Protocol: feetech.ProtocolSTS,
+			Timeout:  100 * time.Millisecond,
+		})
+		if err != nil {
+			return feetech.FoundServo{}, 0, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		servos, _ := bus.Discover(ctx)
+		cancel()
+		bus.Close()
+
+		switch len(servos) {
+		case 0:
+			continue
+		case 1:
+			return servos[0], baud, nil
+		default:
+			return feetech.FoundServo{}, 0, fmt.Errorf("found %d servos at %d baud, connect only one", len(servos), baud)
+		}
+	}
+	return feetech.FoundServo{}, 0, fmt.Errorf("no servo found on %s", port)
+}
+
+// assignMotorID gives the single connected servo the target ID and sets it
+// to robot.DefaultBaudRate, then verifies it responds.
+func assignMotorID(port string, id int) error {
+	found, baud, err := findSingleServo(port)
+	if err != nil {
+		return err
+	}
+	fmt.Println(dimStyle.Render(fmt.Sprintf("  found servo ID %d at %d baud", found.ID, baud)))
+
+	bus, err := feetech.NewBus(feetech.BusConfig{
+		Port:     port,
+		BaudRate: baud,
+		Protocol: feetech.ProtocolSTS,
+		Timeout:  100 * time.Millisecond,
+	})
+	if err != nil {
+		return err
+	}
+	defer bus.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	servo := feetech.NewServo(bus, found.ID, found.Model)
+
+	if found.ID != id {
+		if err := writeEEPROM(ctx, servo, func() error { return servo.SetID(ctx, id) }); err != nil {
+			return fmt.Errorf("set ID: %w", err)
+		}
+	}
+
+	if baud != robot.DefaultBaudRate {
+		// The servo switches baud rate immediately, so it can't be locked
+		// again on this connection
+		if err := servo.WriteRegister(ctx, "lock", []byte{0}); err != nil {
+			return fmt.Errorf("unlock EEPROM: %w", err)
+		}
+		if err := servo.SetBaudRate(ctx, robot.DefaultBaudRate); err != nil {
+			return fmt.Errorf("set baud rate: %w", err)
+		}
+	}
+
+	return verifyMotorID(port, id)
+}
+
+// verifyMotorID checks that a servo with the given ID responds at robot.DefaultBaudRate
+// and makes sure its EEPROM is locked.
+func verifyMotorID(port string, id int) error {
+	bus, err := feetech.NewBus(feetech.BusConfig{
+		Port:     port,
+		BaudRate: robot.DefaultBaudRate,
+		Protocol: feetech.ProtocolSTS,
+		Timeout:  100 * time.Millisecond,
+	})
+	if err != nil {
+		return err
+	}
+	defer bus.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := bus.Ping(ctx, id); err != nil {
+		return fmt.Errorf("servo does not respond as ID %d at %d baud: %w", id, robot.DefaultBaudRate, err)
+	}
+	return feetech.NewServo(bus, id, nil).WriteRegister(ctx, "lock", []byte{1})
+}
+
+// writeEEPROM unlocks the servo's EEPROM, runs write and locks it again.
+// The servo must be addressed by its ID after write, so SetID is allowed.
+func writeEEPROM(ctx context.Context, servo *feetech.Servo, write func() error) error {
+	if err := servo.Disable(ctx); err != nil {
+		return fmt.Errorf("disable torque: %w", err)
+	}
+	if err := servo.WriteRegister(ctx, "lock", []byte{0}); err != nil {
+		return fmt.Errorf("unlock EEPROM: %w", err)
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	if err := servo.WriteRegister(ctx, "lock", []byte{1}); err != nil {
+		return fmt.Errorf("lock EEPROM: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,694 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/gwillem/lerobot/pkg/audio"
+	"github.com/gwillem/lerobot/pkg/camera"
+	"github.com/gwillem/lerobot/pkg/dataset"
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/teleop"
+	"github.com/gwillem/lerobot/pkg/timesync"
+)
+
+type RecordCommand struct {
+	Output       string        `long:"output" short:"o" required:"true" description:"Dataset directory"`
+	FPS          int           `long:"fps" default:"30" description:"Frames per second"`
+	Episodes     int           `long:"episodes" default:"10" description:"Number of episodes to record"`
+	EpisodeTime  time.Duration `long:"episode-time" default:"30s" description:"Duration of each episode"`
+	ResetTime    time.Duration `long:"reset-time" default:"10s" description:"Time to reset the scene between episodes"`
+	Mirror       *bool         `long:"mirror" description:"Mirror mode: invert shoulder_pan and wrist_roll positions, --mirror=false to turn it off (default: teleop.mirror from the configuration)"`
+	Smoothing    *float64      `long:"smoothing" description:"Low-pass filter the follower targets with this cutoff in Hz, 0 disables (default: teleop.smoothing from the configuration)"`
+	Cameras      []string      `long:"camera" description:"Camera to record as name=device[@WIDTHxHEIGHT][+LATENCY] (repeatable, requires ffmpeg; default: cameras from the configuration)"`
+	Sim          string        `long:"sim" description:"Record with a simulated follower at this address (e.g. localhost:5555)"`
+	Park         bool          `long:"park" description:"When done, slowly move both arms to the configured rest_pose before disabling torque"`
+	SoftStart    time.Duration `long:"soft-start" default:"2s" description:"Move the follower to the leader pose over this long at start (0 to snap)"`
+	MaxMismatch  float64       `long:"max-mismatch" default:"30" description:"Largest leader/follower difference on any joint at start before warning (or refusing with --soft-start 0), 0 disables"`
+	ReleaseAfter time.Duration `long:"release-after" default:"5s" description:"Ramp follower torque down after this long without leader readings, e.g. when the leader is unplugged (0 holds indefinitely)"`
+	Overrun      string        `long:"overrun" default:"skip" choice:"skip" choice:"degrade" choice:"error" description:"When cycles take longer than 1/fps: skip ticks, lower the rate, or stop"`
+	ErrorBudget  float64       `long:"error-budget" default:"0.25" description:"Stop when more than this fraction of bus transactions failed over 10s (0 disables)"`
+	Pipeline     bool          `long:"pipeline" description:"Read the leader and write the follower concurrently, so a slow follower write does not delay the next leader read"`
+	Effort       bool          `long:"effort" description:"Also record the follower load per motor as observation.effort"`
+	Compress     bool          `long:"compress" description:"Store frames zstd compressed, for long sessions (new datasets only)"`
+	Task         string        `long:"task" description:"Natural-language description of the task, e.g. \"pick up the red cube\", stored with every episode"`
+	AskTask      bool          `long:"ask-task" description:"Ask for the task before each episode, defaulting to the previous one"`
+	Resume       bool          `long:"resume" description:"Append --episodes more episodes to an existing dataset in --output instead of failing"`
+	Audio        []string      `long:"audio" description:"Microphone to record as name=device, e.g. mic=default (repeatable, requires ffmpeg)"`
+	Sensors      []string      `long:"sensor" description:"Extra sensor to record as name=kind:arg, e.g. ft=serial:/dev/ttyACM0@115200 (repeatable)"`
+	OnMotion     bool          `long:"on-motion" description:"Start each episode when the leader starts moving, and end it after --still-time without motion (or --episode-time)"`
+	StillTime    time.Duration `long:"still-time" default:"3s" description:"With --on-motion: end the episode after the leader was still for this long"`
+	Puppet       bool          `long:"puppet" description:"Record without a leader: move the follower by hand with torque off, its positions are both action and observation"`
+	KeepAll      bool          `long:"keep-all" description:"Keep episodes that fail the quality checks without asking"`
+}
+
+// stateSource is what records states: a teleop.Controller, or a
+// teleop.Puppet with --puppet.
+type stateSource interface {
+	Start(ctx context.Context) error
+	States() <-chan teleop.State
+	Logs() <-chan string
+	Close(ctx context.Context) error
+}
+
+// episodeReporter is a stateSource that reports episodes to its hooks, as
+// a teleop.Controller does and a teleop.Puppet doesn't.
+type episodeReporter interface {
+	Modes() <-chan teleop.ModeChange
+	EpisodeStarted(index int) error
+	EpisodeEnded(index int, saved bool)
+}
+
+func (c *RecordCommand) Execute(args []string) error {
+	if c.Puppet && c.Sim != "" {
+		fmt.Fprintln(os.Stderr, "--puppet needs a real follower, not --sim")
+		os.Exit(1)
+	}
+	var cfg *robot.Config
+	if c.Puppet {
+		cfg = loadFollowerConfig()
+	} else {
+		cfg = loadTeleopConfig(c.Sim != "")
+	}
+	logger, logLevel, closeLog := openLogger()
+	defer closeLog()
+
+	motors := cfg.Leader.Calibration.Motors()
+	if c.Puppet {
+		motors = cfg.Follower.Calibration.Motors()
+	}
+	ds, err := dataset.Create(c.Output, c.FPS, motors)
+	if c.Resume && errors.Is(err, dataset.ErrExists) {
+		ds, err = dataset.Resume(c.Output, c.FPS, motors)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating dataset: %v\n", err)
+		os.Exit(1)
+	}
+	resumed := len(ds.Episodes()) > 0
+	if resumed && ds.HasEffort() != c.Effort {
+		fmt.Fprintf(os.Stderr, "Error: %s was recorded with effort %v; pass the same --effort to resume\n", c.Output, ds.HasEffort())
+		os.Exit(1)
+	}
+
+	if c.Effort && !ds.HasEffort() {
+		if err := ds.AddEffort(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating dataset: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if c.Compress && !ds.Compressed() {
+		if err := ds.Compress(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating dataset: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	specs := c.Cameras
+	if len(specs) == 0 {
+		specs = cfg.Cameras
+	}
+	cams, err := openCameras(specs, c.FPS, ds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening cameras: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		for _, g := range cams {
+			g.Close()
+		}
+	}()
+	if keys := ds.VideoKeys(); len(keys) != len(cams) {
+		fmt.Fprintf(os.Stderr, "Error: %s was recorded with cameras %v; pass the same cameras to resume\n", c.Output, keys)
+		os.Exit(1)
+	}
+
+	mics, err := openMicrophones(c.Audio, ds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening microphones: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		for _, m := range mics {
+			m.Close()
+		}
+	}()
+	if keys := ds.AudioKeys(); len(keys) != len(mics) {
+		fmt.Fprintf(os.Stderr, "Error: %s was recorded with microphones %v; pass the same --audio to resume\n", c.Output, keys)
+		os.Exit(1)
+	}
+
+	sensors, err := openSensors(c.Sensors)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening sensors: %v\n", err)
+		os.Exit(1)
+	}
+	if names := ds.Sensors(); len(ds.Episodes()) > 0 && !slices.Equal(names, sensorNames(sensors)) {
+		fmt.Fprintf(os.Stderr, "Error: %s was recorded with sensors %v; pass the same --sensor to resume\n", c.Output, names)
+		os.Exit(1)
+	}
+
+	// The leader trigger ends the episode or the reset like the right arrow
+	// in the TUI, or pauses; the controller runs the tool action itself
+	var trigger *robot.Trigger
+	var onTrigger func(bool)
+	var presses <-chan struct{}
+	if !c.Puppet {
+		trigger = cfg.Teleop.Trigger
+	}
+	if trigger != nil && (trigger.Action == robot.TriggerRecord || trigger.Action == robot.TriggerPause) {
+		onTrigger, presses = triggerPresses()
+	}
+
+	tcfg := teleop.Config{
+		Leader:       cfg.Leader,
+		Follower:     cfg.Follower,
+		Hz:           c.FPS,
+		Mirror:       flagOr(c.Mirror, cfg.Teleop.Mirror),
+		Smoothing:    flagOr(c.Smoothing, cfg.Teleop.Smoothing),
+		Mapping:      cfg.Mapping,
+		Deadband:     cfg.Deadband,
+		ReadFollower: true,
+		ReadLoads:    c.Effort,
+		SimAddr:      c.Sim,
+		Logger:       logger,
+		LogLevel:     logLevel,
+		Overrun:      teleop.OverrunPolicy(c.Overrun),
+		ErrorBudget:  c.ErrorBudget,
+		Pipeline:     c.Pipeline,
+		RestPose:     parkPose(cfg, c.Park),
+		SoftStart:    c.SoftStart,
+		MaxMismatch:  c.MaxMismatch,
+		Watchdog:     teleop.WatchdogConfig{Release: c.ReleaseAfter},
+		Workspace:    cfg.Workspace,
+		Kinematics:   cfg.Kinematics,
+		Observers:    sensors,
+		Trigger:      trigger,
+		Hooks:        teleop.Hooks{OnTrigger: onTrigger},
+	}
+	var ctrl stateSource
+	if c.Puppet {
+		ctrl, err = teleop.NewPuppet(tcfg)
+	} else {
+		ctrl, err = teleop.NewController(tcfg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create controller: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeController(ctrl)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	go func() {
+		for msg := range ctrl.Logs() {
+			fmt.Println(dimStyle.Render(msg))
+		}
+	}()
+
+	var next <-chan struct{} // presses of the trigger with the record action
+	if presses != nil {
+		if trigger.Action == robot.TriggerPause {
+			go pauseOnPress(ctx, ctrl.(pauser), presses)
+		} else {
+			next = presses
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := ctrl.Start(ctx); err != nil && err != context.Canceled {
+			fmt.Fprintf(os.Stderr, "Controller error: %v\n", err)
+			cancel()
+		}
+	}()
+
+	// Episodes can only start once the controller is in teleop mode
+	reporter, _ := ctrl.(episodeReporter)
+	if reporter != nil {
+		select {
+		case <-reporter.Modes():
+		case <-done:
+		}
+	}
+	ended := func(ep *dataset.EpisodeWriter, saved bool) {
+		if reporter != nil {
+			reporter.EpisodeEnded(ep.Index(), saved)
+		}
+	}
+
+	if resumed {
+		fmt.Printf("Resuming %s after episode %d\n", c.Output, len(ds.Episodes())-1)
+	}
+	fmt.Printf("Recording %d episode(s) of %s to %s\n", c.Episodes, c.EpisodeTime, c.Output)
+	before := len(ds.Episodes())
+
+	task := c.Task
+	for i := 0; i < c.Episodes && ctx.Err() == nil; i++ {
+		ep := ds.NewEpisode()
+		if c.AskTask {
+			task = askTask(ep.Index(), task)
+		}
+		ep.SetTask(task)
+		var still time.Duration
+		if c.OnMotion {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("Move the leader to start episode %d", ep.Index())))
+			if !waitForLeaderMotion(ctx, ctrl) {
+				ep.Discard()
+				break
+			}
+			still = c.StillTime
+		}
+		if reporter != nil {
+			if err := reporter.EpisodeStarted(ep.Index()); err != nil {
+				ep.Discard()
+				if ctx.Err() != nil {
+					break // the controller stopped
+				}
+				fmt.Fprintf(os.Stderr, "Error starting episode: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Println(subHeaderStyle.Render(fmt.Sprintf("Recording episode %d", ep.Index())))
+		var align timesync.Alignment
+		err := recordEpisode(ctx, ctrl, cams, mics, ep, c.EpisodeTime, still, next, c.Effort, len(sensors), &align)
+		if err == nil && ep.Len() > 0 {
+			err = addAudio(ep, mics)
+		}
+		if err != nil {
+			ep.Discard()
+			ended(ep, false)
+			fmt.Fprintf(os.Stderr, "Error recording episode: %v\n", err)
+			os.Exit(1)
+		}
+
+		if ep.Len() == 0 {
+			ep.Discard()
+			ended(ep, false)
+			break
+		}
+		ep.SetAlignment(align.Max())
+		if q := ep.Check(dataset.DefaultQualityLimits()); len(q.Issues) > 0 {
+			for _, issue := range q.Issues {
+				fmt.Println(warnStyle.Render(fmt.Sprintf("Episode %d: %s", ep.Index(), issue)))
+			}
+			if !c.KeepAll && !askKeep(ep.Index()) {
+				ep.Discard()
+				ended(ep, false)
+				fmt.Printf("Discarded episode %d\n", ep.Index())
+				i-- // record it again
+				c.reset(ctx, next)
+				continue
+			}
+		}
+		if err := ep.Save(); err != nil {
+			ended(ep, false)
+			fmt.Fprintf(os.Stderr, "Error saving episode: %v\n", err)
+			os.Exit(1)
+		}
+		ended(ep, true)
+		fmt.Printf("Saved episode %d (%d frames)\n", ep.Index(), ep.Len())
+		fmt.Println(dimStyle.Render(fmt.Sprintf("Aligned within %v: %s", align.Bound().Round(100*time.Microsecond), align.String())))
+		for _, g := range cams {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("Camera %s: %s%s", g.Camera().Name(), g.Timing(), networkStatus(g.Camera()))))
+		}
+
+		if i < c.Episodes-1 {
+			c.reset(ctx, next)
+		}
+	}
+
+	cancel()
+	<-done
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("Recorded %d episode(s) to %s, %d in total", len(ds.Episodes())-before, c.Output, len(ds.Episodes()))))
+	return nil
+}
+
+// reset waits --reset-time for the environment to be reset, or until the
+// trigger is pressed on next.
+func (c *RecordCommand) reset(ctx context.Context, next <-chan struct{}) {
+	if ctx.Err() != nil {
+		return
+	}
+	fmt.Println(subHeaderStyle.Render("Reset the environment"))
+	select {
+	case <-ctx.Done():
+	case <-time.After(c.ResetTime):
+	case <-next:
+	}
+}
+
+// askKeep asks whether to keep an episode that failed the quality checks.
+// An aborted prompt keeps it.
+func askKeep(episode int) bool {
+	keep := true
+	err := huh.NewConfirm().
+		Title(fmt.Sprintf("Keep episode %d?", episode)).
+		Affirmative("Keep").
+		Negative("Discard").
+		Value(&keep).
+		Run()
+	if err != nil {
+		fmt.Println()
+		return true
+	}
+	return keep
+}
+
+// askTask prompts for the task of an episode, prefilled with task. An
+// aborted prompt keeps task.
+func askTask(episode int, task string) string {
+	err := huh.NewInput().
+		Title(fmt.Sprintf("Task for episode %d", episode)).
+		Placeholder("e.g. pick up the red cube").
+		Value(&task).
+		Run()
+	if err != nil {
+		fmt.Println()
+	}
+	return strings.TrimSpace(task)
+}
+
+// recordEpisode adds a frame for every controller state until the duration
+// has passed, the leader was still for still if it is positive, the
+// trigger is pressed on next, or ctx is cancelled. The latest image of every camera is added
+// with each frame so videos stay aligned with the joint data. With still,
+// frames are held back, images included, until the leader moves again, so
+// an episode that ends for lack of motion ends at its last motion. States
+// without positions are skipped, as are states without a reading of each
+// of the sensors; with effort, a state whose follower loads couldn't be
+// read is recorded without them (zero on export). Microphones keep audio
+// from the first frame on, see addAudio. The skew of every frame's
+// observations against its action is added to align.
+func recordEpisode(ctx context.Context, ctrl stateSource, cams []*camera.Grabber, mics []*audio.Capture, ep *dataset.EpisodeWriter, duration, still time.Duration, next <-chan struct{}, effort bool, sensors int, align *timesync.Alignment) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	var clock *timesync.Clock // started at the first frame
+	var motion motionTracker
+	var pending []pendingFrame // since the last motion
+	flush := func() error {
+		for _, f := range pending {
+			if err := addFrame(ep, cams, f.images, f.t, f.state, effort, align); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return flush()
+		case <-timer.C:
+			return flush()
+		case <-next:
+			return flush()
+		case state := <-ctrl.States():
+			if state.Positions == nil || state.FollowerPositions == nil || len(state.Observations) < sensors {
+				continue
+			}
+			// Frames without every joint would leave holes in the dataset
+			if len(state.LeaderMissing) > 0 || len(state.FollowerMissing) > 0 {
+				continue
+			}
+			if clock == nil {
+				clock = timesync.NewClock(state.Timestamp)
+				for _, m := range mics {
+					m.Start(clock.Start())
+				}
+			}
+			images, err := latestImages(cams)
+			if err != nil {
+				return err
+			}
+			pending = append(pending, pendingFrame{clock.Offset(state.Timestamp), state, images})
+			// The first frame counts as motion, it starts the episode
+			motion.update(state.Positions, state.Timestamp)
+			if still <= 0 || motion.at.Equal(state.Timestamp) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			if still > 0 && state.Timestamp.Sub(motion.at) >= still {
+				return nil // without the still frames
+			}
+		}
+	}
+}
+
+// pendingFrame is a state to add at offset t, with the camera images of
+// its time, see recordEpisode.
+type pendingFrame struct {
+	t      time.Duration
+	state  teleop.State
+	images []camera.Frame
+}
+
+// stillThreshold is how far, in normalized units, a joint of the leader
+// must move for --on-motion, so sensor noise doesn't count as motion.
+const stillThreshold = 2.0
+
+// motionTracker tracks when the leader last moved.
+type motionTracker struct {
+	ref map[robot.MotorName]float64 // pose at the last motion
+	at  time.Time                   // of the last motion
+}
+
+// update reports whether any joint moved more than stillThreshold since
+// the last motion. The first pose only sets the reference.
+func (m *motionTracker) update(positions map[robot.MotorName]float64, now time.Time) bool {
+	if m.ref == nil {
+		m.ref, m.at = positions, now
+		return false
+	}
+	for name, pos := range positions {
+		if ref, ok := m.ref[name]; !ok || math.Abs(pos-ref) > stillThreshold {
+			m.ref, m.at = positions, now
+			return true
+		}
+	}
+	return false
+}
+
+// waitForLeaderMotion waits until the leader moves. It returns false if ctx is
+// cancelled first.
+func waitForLeaderMotion(ctx context.Context, ctrl stateSource) bool {
+	var motion motionTracker
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case state := <-ctrl.States():
+			if state.Positions != nil && motion.update(state.Positions, state.Timestamp) {
+				return true
+			}
+		}
+	}
+}
+
+// latestImages returns the latest frame of every camera, without Data if
+// it has none yet, or the error that stopped one.
+func latestImages(cams []*camera.Grabber) ([]camera.Frame, error) {
+	images := make([]camera.Frame, len(cams))
+	for i, g := range cams {
+		if err := g.Err(); err != nil {
+			return nil, err
+		}
+		images[i], _ = g.Latest()
+	}
+	return images, nil
+}
+
+// addFrame adds a state to the episode at offset t from its start, with its
+// sensor readings and the images and depth of every camera, see
+// latestImages, and adds how far the follower read and the images are from
+// the leader read to align.
+func addFrame(ep *dataset.EpisodeWriter, cams []*camera.Grabber, images []camera.Frame, t time.Duration, state teleop.State, effort bool, align *timesync.Alignment) error {
+	ep.Add(t.Seconds(), state.Positions, state.FollowerPositions)
+	if effort && state.Loads != nil {
+		ep.AddEffort(state.Loads)
+	}
+	for name, obs := range state.Observations {
+		if err := ep.AddSensor(name, obs); err != nil {
+			return err
+		}
+	}
+	if !state.Follower.IsZero() {
+		align.Add("follower", state.Leader, state.Follower)
+	}
+
+	for i, g := range cams {
+		cam := g.Camera()
+		frame := images[i]
+		_, depth := cam.(camera.DepthCamera)
+		if frame.Data == nil {
+			// No image yet, keep the video in step with a black frame
+			// and unknown depth
+			frame.Data = make([]byte, cam.Width()*cam.Height()*3)
+			if depth {
+				frame.Depth = make([]uint16, cam.Width()*cam.Height())
+			}
+		} else {
+			align.Add(cam.Name(), state.Leader, frame.Stamp())
+		}
+		if err := ep.AddImage(cam.Name(), frame.Data); err != nil {
+			return err
+		}
+		if depth {
+			if err := ep.AddDepth(cam.Name(), frame.Depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// networkStatus describes the latency and reconnects of a network camera,
+// empty for other cameras.
+func networkStatus(cam camera.Camera) string {
+	nc, ok := cam.(*camera.NetworkCamera)
+	if !ok {
+		return ""
+	}
+	status := fmt.Sprintf(", %d fps, latency %v", nc.FPS(), nc.Latency().Round(time.Millisecond))
+	if n, err := nc.Reconnects(); n > 0 {
+		status += fmt.Sprintf(", reconnected %d time(s), last after: %v", n, err)
+	}
+	return status
+}
+
+// addAudio stores the audio captured by every microphone since the
+// episode's first frame.
+func addAudio(ep *dataset.EpisodeWriter, mics []*audio.Capture) error {
+	for _, m := range mics {
+		if err := m.Err(); err != nil {
+			return err
+		}
+		if err := ep.AddAudio(m.Name(), m.Stop()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openSensors opens every sensor spec. On error, sensors opened so far are
+// closed.
+func openSensors(specs []string) ([]teleop.Observer, error) {
+	var sensors []teleop.Observer
+	for _, spec := range specs {
+		o, err := teleop.OpenObserver(spec)
+		if err == nil && slices.Contains(sensorNames(sensors), o.Name()) {
+			err = fmt.Errorf("sensor %s given twice", o.Name())
+			closeSensor(o)
+		}
+		if err != nil {
+			for _, o := range sensors {
+				closeSensor(o)
+			}
+			return nil, err
+		}
+		sensors = append(sensors, o)
+	}
+	return sensors, nil
+}
+
+func closeSensor(o teleop.Observer) {
+	if c, ok := o.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// sensorNames returns the names of sensors, sorted.
+func sensorNames(sensors []teleop.Observer) []string {
+	names := make([]string, len(sensors))
+	for i, o := range sensors {
+		names[i] = o.Name()
+	}
+	slices.Sort(names)
+	return names
+}
+
+// openMicrophones starts capturing from every audio spec and registers the
+// microphones with the dataset. On error, microphones opened so far are
+// closed.
+func openMicrophones(specs []string, ds *dataset.Dataset) ([]*audio.Capture, error) {
+	var mics []*audio.Capture
+	fail := func(err error) ([]*audio.Capture, error) {
+		for _, m := range mics {
+			m.Close()
+		}
+		return nil, err
+	}
+
+	for _, spec := range specs {
+		cfg, err := audio.ParseSpec(spec)
+		if err != nil {
+			return fail(err)
+		}
+		mic, err := audio.Open(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		mics = append(mics, mic)
+
+		if err := ds.AddAudio(cfg.Name, mic.SampleRate(), mic.Channels()); err != nil {
+			return fail(err)
+		}
+	}
+	return mics, nil
+}
+
+// openCameras starts capturing from every camera spec and registers the
+// cameras with the dataset, if any. On error, cameras opened so far are
+// closed.
+func openCameras(specs []string, fps int, ds *dataset.Dataset) ([]*camera.Grabber, error) {
+	var grabbers []*camera.Grabber
+	fail := func(err error) ([]*camera.Grabber, error) {
+		for _, g := range grabbers {
+			g.Close()
+		}
+		return nil, err
+	}
+
+	for _, spec := range specs {
+		cfg, err := camera.ParseSpec(spec)
+		if err != nil {
+			return fail(err)
+		}
+		cfg.FPS = fps
+
+		cam, err := camera.Open(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		grabbers = append(grabbers, camera.NewGrabber(cam))
+
+		if ds == nil {
+			continue
+		}
+		if err := ds.AddCamera(cfg.Name, cfg.Width, cfg.Height); err != nil {
+			return fail(err)
+		}
+		if _, ok := cam.(camera.DepthCamera); ok {
+			if err := ds.AddDepth(cfg.Name, cfg.Width, cfg.Height); err != nil {
+				return fail(err)
+			}
+		}
+	}
+	return grabbers, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/dataset"
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/teleop"
+	"github.com/gwillem/lerobot/pkg/timesync"
+)
+
+func TestMotionTracker(t *testing.T) {
+	var m motionTracker
+	start := time.Now()
+	pose := func(pos float64) map[robot.MotorName]float64 {
+		return map[robot.MotorName]float64{robot.ShoulderPan: 0, robot.Gripper: pos}
+	}
+
+	if m.update(pose(0), start) {
+		t.Error("first pose counts as motion")
+	}
+	if m.update(pose(stillThreshold), start.Add(time.Second)) {
+		t.Error("motion within the threshold counts")
+	}
+	// Small steps add up against the pose of the last motion
+	if !m.update(pose(stillThreshold+1), start.Add(2*time.Second)) {
+		t.Error("motion beyond the threshold doesn't count")
+	}
+	if !m.at.Equal(start.Add(2 * time.Second)) {
+		t.Errorf("last motion at %v, want 2s", m.at.Sub(start))
+	}
+	if !m.update(map[robot.MotorName]float64{robot.WristRoll: 0}, start.Add(3*time.Second)) {
+		t.Error("a joint appearing doesn't count as motion")
+	}
+}
+
+// fakeStates is a stateSource replaying states.
+type fakeStates chan teleop.State
+
+func (f fakeStates) Start(ctx context.Context) error { return nil }
+func (f fakeStates) States() <-chan teleop.State     { return f }
+func (f fakeStates) Logs() <-chan string             { return nil }
+func (f fakeStates) Close(ctx context.Context) error { return nil }
+
+func TestRecordEpisode_TrimsStill(t *testing.T) {
+	ds, err := dataset.Create(filepath.Join(t.TempDir(), "ds"), 10, []robot.MotorName{robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	states := make(fakeStates, 20)
+	for i, pos := range []float64{0, 10, 20, 20, 20, 20, 20, 20, 20, 20} {
+		positions := map[robot.MotorName]float64{robot.Gripper: pos}
+		states <- teleop.State{Timestamp: start.Add(time.Duration(i) * 100 * time.Millisecond), Positions: positions, FollowerPositions: positions}
+	}
+
+	ep := ds.NewEpisode()
+	var align timesync.Alignment
+	if err := recordEpisode(context.Background(), states, nil, nil, ep, time.Minute, 500*time.Millisecond, nil, false, 0, &align); err != nil {
+		t.Fatal(err)
+	}
+	// Frames 3 to 7 were still for 500ms, and are left out
+	if ep.Len() != 3 {
+		t.Errorf("recorded %d frames, want 3 up to the last motion", ep.Len())
+	}
+	if len(states) != 2 {
+		t.Errorf("%d states left, want the episode to end after 500ms still", len(states))
+	}
+	ep.Discard()
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/dataset"
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type ReplayCommand struct {
+	Episode       int     `short:"e" long:"episode" description:"Episode to replay"`
+	Speed         float64 `long:"speed" default:"1" description:"Playback rate (0.5 is half speed)"`
+	ApproachSpeed float64 `long:"approach-speed" default:"30" description:"Joint speed for moving to the first frame, in normalized units per second"`
+	Release       bool    `long:"release" description:"Disable torque after the episode (default: hold the last frame)"`
+	Args          struct {
+		Dataset string `positional-arg-name:"dataset" required:"yes" description:"Dataset directory, or a Hugging Face repo id like lerobot/koch_pick_place"`
+	} `positional-args:"yes"`
+}
+
+func (c *ReplayCommand) Execute(args []string) error {
+	if c.Speed <= 0 {
+		fmt.Fprintln(os.Stderr, "--speed must be positive")
+		os.Exit(1)
+	}
+	ds := openDataset(datasetPath(c.Args.Dataset))
+	var episode *dataset.Episode
+	for _, e := range ds.Episodes() {
+		if e.Index == c.Episode {
+			episode = &e
+			break
+		}
+	}
+	if episode == nil {
+		fmt.Fprintf(os.Stderr, "Episode %d not found, the dataset has %d episodes\n", c.Episode, len(ds.Episodes()))
+		os.Exit(1)
+	}
+	frames, err := ds.ReadFrames(c.Episode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading episode %d: %v\n", c.Episode, err)
+		os.Exit(1)
+	}
+	if len(frames) == 0 {
+		fmt.Fprintf(os.Stderr, "Episode %d has no frames\n", c.Episode)
+		os.Exit(1)
+	}
+
+	cfg := loadConfig()
+	if cfg.Follower.Port == "" || !cfg.Follower.IsCalibrated() {
+		fmt.Fprintln(os.Stderr, "Follower not configured. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+	var missing []string
+	for _, m := range ds.Motors() {
+		if _, ok := cfg.Follower.Calibration[m]; !ok {
+			missing = append(missing, string(m))
+		}
+	}
+	if len(missing) == len(ds.Motors()) {
+		fmt.Fprintf(os.Stderr, "None of the dataset's motors (%v) are on the follower\n", ds.Motors())
+		os.Exit(1)
+	}
+	if len(missing) > 0 {
+		fmt.Println(warnStyle.Render("Not on the follower, skipped: " + strings.Join(missing, ", ")))
+	}
+
+	// Datasets recorded by the Python implementation before v0.3 store degrees
+	targets := make([]map[robot.MotorName]float64, len(frames))
+	for i, f := range frames {
+		targets[i] = make(map[robot.MotorName]float64, len(ds.Motors()))
+		for j, m := range ds.Motors() {
+			if _, ok := cfg.Follower.Calibration[m]; ok && j < len(f.Action) {
+				targets[i][m] = f.Action[j]
+			}
+		}
+		if ds.Units() == robot.UnitsDegrees {
+			targets[i] = cfg.Follower.Positions(targets[i])
+		}
+	}
+
+	cfg.Follower.ResolvePort()
+	arm, err := robot.OpenArm(cfg.Follower)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to follower: %v\n", err)
+		os.Exit(1)
+	}
+	defer arm.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	if err := arm.Hold(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := arm.Enable(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error enabling torque: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Moving follower to the first frame...")
+	if err := robot.MoveTo(ctx, arm, targets[0], c.ApproachSpeed); err != nil {
+		arm.Disable(context.Background())
+		fmt.Fprintf(os.Stderr, "Error moving to the first frame: %v\n", err)
+		os.Exit(1)
+	}
+
+	duration := ds.Duration(*episode) / c.Speed
+	fmt.Printf("Replaying episode %d (%s, %d frames)...\n", c.Episode, formatSeconds(duration), len(frames))
+	start := time.Now()
+	t0 := frames[0].Timestamp
+	for i, f := range frames {
+		due := start.Add(time.Duration((f.Timestamp - t0) / c.Speed * float64(time.Second)))
+		select {
+		case <-ctx.Done():
+			arm.Disable(context.Background())
+			fmt.Fprintf(os.Stderr, "\nInterrupted at frame %d\n", f.Index)
+			os.Exit(1)
+		case <-time.After(time.Until(due)):
+		}
+		if err := arm.WritePositions(ctx, targets[i]); err != nil {
+			arm.Disable(context.Background())
+			fmt.Fprintf(os.Stderr, "\nError at frame %d: %v\n", f.Index, err)
+			os.Exit(1)
+		}
+		fmt.Printf("\r%s", dimStyle.Render(fmt.Sprintf("%s / %s", formatSeconds(time.Since(start).Seconds()), formatSeconds(duration))))
+	}
+	fmt.Println()
+
+	if c.Release {
+		if err := arm.Disable(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error disabling torque: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Println(successStyle.Render(fmt.Sprintf("Replayed episode %d", c.Episode)))
+	return nil
+}
+
+// datasetPath returns path, or if it doesn't exist and looks like a Hugging
+// Face repo id, where the Python implementation downloads that dataset.
+func datasetPath(path string) string {
+	if _, err := os.Stat(path); err == nil || strings.Count(path, "/") != 1 || filepath.IsAbs(path) {
+		return path
+	}
+	home := os.Getenv("HF_LEROBOT_HOME")
+	if home == "" {
+		cache, err := os.UserHomeDir()
+		if err != nil {
+			return path
+		}
+		home = filepath.Join(cache, ".cache", "huggingface", "lerobot")
+	}
+	hub := filepath.Join(home, path)
+	if _, err := os.Stat(filepath.Join(hub, "meta", "info.json")); err != nil {
+		return path
+	}
+	return hub
+}
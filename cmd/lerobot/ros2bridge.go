@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/ros2"
+)
+
+type Ros2BridgeCommand struct {
+	URL       string `long:"url" default:"ws://localhost:9090" description:"rosbridge websocket URL"`
+	Namespace string `long:"namespace" description:"Topic namespace, e.g. /so101"`
+	Hz        int    `long:"hz" default:"30" description:"Joint state publish rate"`
+}
+
+func (c *Ros2BridgeCommand) Execute(args []string) error {
+	cfg := loadConfig()
+	if cfg.Follower.Port == "" || !cfg.Follower.IsCalibrated() {
+		fmt.Fprintln(os.Stderr, "Follower not configured. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+	if cfg.Follower.ResolvePort() {
+		fmt.Printf("Serial port moved: follower on %s\n", cfg.Follower.Port)
+	}
+
+	arm, err := robot.OpenArm(cfg.Follower)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to follower: %v\n", err)
+		os.Exit(1)
+	}
+	defer arm.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	bridge, err := ros2.NewBridge(arm, ros2.Config{URL: c.URL, Namespace: c.Namespace, Hz: c.Hz})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer bridge.Close()
+
+	if err := arm.Enable(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error enabling torque: %v\n", err)
+		os.Exit(1)
+	}
+	defer arm.Disable(context.Background())
+
+	fmt.Printf("Bridging follower to ROS 2 via %s\n", c.URL)
+	fmt.Printf("  publishing  %s\n", bridge.Topic(ros2.JointStatesTopic))
+	fmt.Printf("  subscribed  %s, %s\n", bridge.Topic(ros2.CommandTopic), bridge.Topic(ros2.TrajectoryTopic))
+	fmt.Println("Press Ctrl+C to stop.")
+
+	return bridge.Run(ctx)
+}
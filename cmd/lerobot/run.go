@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gwillem/lerobot/pkg/motion"
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type RunCommand struct {
+	MaxSpeed float64 `long:"max-speed" default:"100" description:"Cap on joint speed for every move, in normalized units per second"`
+	Check    bool    `long:"check" description:"Validate the script without moving the arm"`
+	Release  bool    `long:"release" description:"Disable torque when the script is done (default: hold the last pose)"`
+	Args     struct {
+		Script string `positional-arg-name:"script" required:"yes" description:"YAML or JSON motion script"`
+	} `positional-args:"yes"`
+}
+
+func (c *RunCommand) Execute(args []string) error {
+	cfg := loadConfig()
+	if cfg.Follower.Port == "" || !cfg.Follower.IsCalibrated() {
+		fmt.Fprintln(os.Stderr, "Follower not configured. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+
+	script, err := motion.Load(c.Args.Script)
+	if err == nil {
+		err = script.Check(cfg.Pose, cfg.Follower.Calibration.Motors())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid script %s: %v\n", c.Args.Script, err)
+		os.Exit(1)
+	}
+	if c.Check {
+		fmt.Println(successStyle.Render("Script OK"))
+		return nil
+	}
+
+	cfg.Follower.ResolvePort()
+	arm, err := robot.OpenArm(cfg.Follower)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to follower: %v\n", err)
+		os.Exit(1)
+	}
+	defer arm.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	if err := arm.Hold(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := arm.Enable(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error enabling torque: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = script.Run(ctx, arm, motion.Options{
+		MaxSpeed: c.MaxSpeed,
+		Progress: func(step string) { fmt.Println(dimStyle.Render("  " + step)) },
+	})
+	if err != nil {
+		// Interrupted or failed: let go rather than hold a half-finished move
+		arm.Disable(context.Background())
+		fmt.Fprintf(os.Stderr, "Script stopped: %v\n", err)
+		os.Exit(1)
+	}
+
+	if c.Release {
+		if err := arm.Disable(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error disabling torque: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Println(successStyle.Render("Script done"))
+	return nil
+}
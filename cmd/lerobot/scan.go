@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+type ScanCommand struct {
+	Ports   []string `long:"port" description:"Serial port to scan (repeatable, default: all ports)"`
+	Bauds   []int    `long:"baud" description:"Baud rate to probe (repeatable, default: all supported rates)"`
+	MinID   int      `long:"min-id" default:"0" description:"First servo ID to probe"`
+	MaxID   int      `long:"max-id" default:"20" description:"Last servo ID to probe (up to 253)"`
+	Timeout int      `long:"timeout" default:"20" description:"Per-ID response timeout in milliseconds"`
+}
+
+// scanResult is a servo found during a bus scan.
+type scanResult struct {
+	port     string
+	baud     int
+	id       int
+	model    string
+	firmware string
+}
+
+// maxServoID is the highest ID a servo can have, 254 being broadcast.
+const maxServoID = 253
+
+func (c *ScanCommand) Execute(args []string) error {
+	if c.MinID < 0 || c.MinID > c.MaxID || c.MaxID > maxServoID {
+		return fmt.Errorf("--min-id %d and --max-id %d must satisfy 0 <= min-id <= max-id <= %d", c.MinID, c.MaxID, maxServoID)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	ports := c.Ports
+	if len(ports) == 0 {
+		var err error
+		ports, err = robot.ListPorts()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing ports: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if len(ports) == 0 {
+		fmt.Println("No serial ports found.")
+		return nil
+	}
+
+	bauds := c.Bauds
+	if len(bauds) == 0 {
+		bauds = feetech.DefaultBaudRates
+	}
+
+	fmt.Println(headerStyle.Render("LeRobot Scan"))
+	fmt.Printf("Probing IDs %d-%d on %d port(s) at %d baud rate(s)\n\n", c.MinID, c.MaxID, len(ports), len(bauds))
+
+	total := 0
+	for _, port := range ports {
+		fmt.Println(subHeaderStyle.Render(port))
+		found := 0
+		for _, baud := range bauds {
+			results, err := scanPort(port, baud, c.MinID, c.MaxID, time.Duration(c.Timeout)*time.Millisecond)
+			if err != nil {
+				fmt.Println(dimStyle.Render(fmt.Sprintf("  %d baud: %v", baud, err)))
+				continue
+			}
+			for _, r := range results {
+				fmt.Printf("  %7d baud  ID %3d  %-12s firmware %s\n", r.baud, r.id, r.model, r.firmware)
+			}
+			found += len(results)
+		}
+		if found == 0 {
+			fmt.Println(dimStyle.Render("  no servos found"))
+		}
+		total += found
+	}
+
+	fmt.Println()
+	fmt.Printf("Found %d servo(s).\n", total)
+	return nil
+}
+
+// scanPort pings every ID in [minID, maxID] on port at the given baud rate.
+func scanPort(port string, baud, minID, maxID int, timeout time.Duration) ([]scanResult, error) {
+	bus, err := feetech.NewBus(feetech.BusConfig{
+		Port:     port,
+		BaudRate: baud,
+		Protocol: feetech.ProtocolSTS,
+		Timeout:  timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer bus.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(maxID-minID+1)*timeout*4)
+	defer cancel()
+
+	servos, err := bus.Scan(ctx, minID, maxID)
+	if err != nil && len(servos) == 0 {
+		return nil, err
+	}
+
+	results := make([]scanResult, 0, len(servos))
+	for _, s := range servos {
+		r := scanResult{
+			port:     port,
+			baud:     baud,
+			id:       s.ID,
+			model:    fmt.Sprintf("unknown(%d)", s.ModelNumber),
+			firmware: "?",
+		}
+		if s.Model != nil {
+			r.model = s.Model.Name
+		}
+		if fw, err := readFirmwareVersion(ctx, bus, s.ID); err == nil {
+			r.firmware = fw
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// readFirmwareVersion reads the firmware major and minor version registers.
+func readFirmwareVersion(ctx context.Context, bus *feetech.Bus, id int) (string, error) {
+	data, err := bus.ReadRegister(ctx, id, feetech.RegFirmwareVersion.Address, 2)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.%d", data[0], data[1]), nil
+}
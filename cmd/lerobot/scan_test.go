@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestScanCommand_IDRange(t *testing.T) {
+	for _, c := range []ScanCommand{
+		{MinID: 5, MaxID: 4, Timeout: 20},
+		{MinID: -1, MaxID: 4, Timeout: 20},
+		{MinID: 0, MaxID: 254, Timeout: 20},
+		{MinID: 0, MaxID: 20, Timeout: 0},
+	} {
+		if err := c.Execute(nil); err == nil {
+			t.Errorf("Execute() with IDs %d-%d and timeout %d succeeded", c.MinID, c.MaxID, c.Timeout)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/gwillem/lerobot/pkg/server"
+	"github.com/gwillem/lerobot/pkg/teleop"
+)
+
+type ServeCommand struct {
+	Addr         string        `long:"addr" default:"127.0.0.1:8080" description:"HTTP listen address; other addresses than loopback require a token"`
+	GRPCAddr     string        `long:"grpc-addr" description:"gRPC listen address, e.g. 127.0.0.1:50051 (disabled if empty)"`
+	Token        string        `long:"token" env:"LEROBOT_TOKEN" description:"Require this token from clients (default: a random one when listening on other addresses than loopback)"`
+	DataDir      string        `long:"data-dir" default:"data" description:"Directory that datasets recorded through the API are created in"`
+	Hz           int           `long:"hz" default:"30" description:"Control loop frequency during teleoperation"`
+	Mirror       *bool         `long:"mirror" description:"Mirror mode: invert shoulder_pan and wrist_roll positions, --mirror=false to turn it off (default: teleop.mirror from the configuration)"`
+	Smoothing    *float64      `long:"smoothing" description:"Low-pass filter the follower targets with this cutoff in Hz, 0 disables (default: teleop.smoothing from the configuration)"`
+	SoftStart    time.Duration `long:"soft-start" default:"2s" description:"Move the follower to the leader pose over this long when teleoperation starts (0 to snap)"`
+	MaxMismatch  float64       `long:"max-mismatch" default:"30" description:"Largest leader/follower difference on any joint at start before warning (or refusing with --soft-start 0), 0 disables"`
+	ReleaseAfter time.Duration `long:"release-after" default:"5s" description:"Ramp follower torque down after this long without leader readings, e.g. when the leader is unplugged (0 holds indefinitely)"`
+	ErrorBudget  float64       `long:"error-budget" default:"0.25" description:"Stop when more than this fraction of bus transactions failed over 10s (0 disables)"`
+	Relative     bool          `long:"relative" description:"Clutch mode: after /teleop/resume, the follower follows the leader's motion from where it was held"`
+}
+
+func (c *ServeCommand) Execute(args []string) error {
+	logger, logLevel, closeLog := openLogger()
+	defer closeLog()
+	srv := c.newServer(logger, logLevel)
+	defer srv.Close()
+
+	c.serve(srv, srv.Handler(), "robot API")
+	return nil
+}
+
+// newServer connects to the arms.
+func (c *ServeCommand) newServer(logger *slog.Logger, logLevel slog.Level) *server.Server {
+	cfg := loadTeleopConfig(false)
+	srv, err := server.New(teleop.Config{
+		Leader:      cfg.Leader,
+		Follower:    cfg.Follower,
+		Hz:          c.Hz,
+		Mirror:      flagOr(c.Mirror, cfg.Teleop.Mirror),
+		Smoothing:   flagOr(c.Smoothing, cfg.Teleop.Smoothing),
+		Relative:    c.Relative,
+		SoftStart:   c.SoftStart,
+		MaxMismatch: c.MaxMismatch,
+		Watchdog:    teleop.WatchdogConfig{Release: c.ReleaseAfter},
+		ErrorBudget: c.ErrorBudget,
+		Workspace:   cfg.Workspace,
+		Kinematics:  cfg.Kinematics,
+		Mapping:     cfg.Mapping,
+		Deadband:    cfg.Deadband,
+		Logger:      logger,
+		LogLevel:    logLevel,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to arms: %v\n", err)
+		os.Exit(1)
+	}
+	srv.SetDataDir(c.DataDir)
+	return srv
+}
+
+// serve serves handler, and the gRPC API if enabled, until interrupted.
+// Anyone on the network can move the arms through an API that isn't bound
+// to loopback, so then it requires a token.
+func (c *ServeCommand) serve(srv *server.Server, handler http.Handler, what string) {
+	token := c.Token
+	if token == "" && (!server.IsLoopback(c.Addr) || c.GRPCAddr != "" && !server.IsLoopback(c.GRPCAddr)) {
+		token = server.NewToken()
+	}
+	if token != "" {
+		handler = server.RequireToken(token, handler)
+	}
+	httpSrv := &http.Server{Addr: c.Addr, Handler: handler}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		httpSrv.Shutdown(context.Background())
+	}()
+
+	if c.GRPCAddr != "" {
+		lis, err := net.Listen("tcp", c.GRPCAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listening on %s: %v\n", c.GRPCAddr, err)
+			os.Exit(1)
+		}
+		var opts []grpc.ServerOption
+		if token != "" {
+			opts = server.TokenOptions(token)
+		}
+		grpcSrv := grpc.NewServer(opts...)
+		srv.RegisterGRPC(grpcSrv)
+		go grpcSrv.Serve(lis)
+		defer grpcSrv.Stop()
+		fmt.Printf("Serving gRPC API on %s\n", c.GRPCAddr)
+	}
+
+	if token != "" {
+		fmt.Printf("Serving %s on http://%s/?token=%s\n", what, c.Addr, token)
+		fmt.Println(dimStyle.Render("Clients send the token as ?token=, or as \"Authorization: Bearer\" (also gRPC metadata)"))
+	} else {
+		fmt.Printf("Serving %s on http://%s\n", what, c.Addr)
+	}
+	if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error serving: %v\n", err)
+		os.Exit(1)
+	}
+}
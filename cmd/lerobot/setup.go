@@ -4,15 +4,17 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 	"github.com/hipsterbrown/feetech-servo/feetech"
-	"go.bug.st/serial"
 
 	"github.com/gwillem/lerobot/pkg/robot"
 )
@@ -24,24 +26,41 @@ var (
 	dimStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 )
 
-type SetupCommand struct{}
+type SetupCommand struct {
+	Watch      bool `long:"watch" description:"Wait for arms to be plugged in instead of exiting when none are found"`
+	Reidentify bool `long:"reidentify" description:"Wiggle every arm, even those recognized by their USB adapter"`
+	ByHand     bool `long:"by-hand" description:"Identify arms by moving them by hand instead of wiggling them"`
+}
 
 func (c *SetupCommand) Execute(args []string) error {
 	fmt.Println(headerStyle.Render("LeRobot Setup"))
 	fmt.Println(dimStyle.Render("━━━━━━━━━━━━━━"))
 	fmt.Println()
 
+	// Custom builds list their motors in an existing config
+	var custom []robot.Motor
+	// An invalid configuration is still read, setup is how it gets fixed
+	prev, _ := robot.LoadConfig(opts.Profile)
+	if prev != nil {
+		custom = prev.Motors
+	}
+	if prev == nil || c.Reidentify {
+		prev = &robot.Config{}
+	}
+	motors := (&robot.Config{Motors: custom}).MotorList()
+
 	// Step 1: Scan for arms
-	config := scanForArms()
+	config := c.scanForArms(motors, prev)
+	config.Motors = custom
 
 	// Step 2: Calibrate leader
 	fmt.Println()
 	fmt.Println(subHeaderStyle.Render("━━━ Calibrating Leader Arm ━━━"))
 	fmt.Println()
-	calibrateArm(&config.Leader, "leader")
+	calibrateArm(&config.Leader, "leader", motors)
 
 	// Save after leader calibration
-	if err := config.Save(); err != nil {
+	if err := config.SaveTo(configPath()); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
 		os.Exit(1)
 	}
@@ -50,10 +69,10 @@ func (c *SetupCommand) Execute(args []string) error {
 	fmt.Println()
 	fmt.Println(subHeaderStyle.Render("━━━ Calibrating Follower Arm ━━━"))
 	fmt.Println()
-	calibrateArm(&config.Follower, "follower")
+	calibrateArm(&config.Follower, "follower", motors)
 
 	// Save final config
-	if err := config.Save(); err != nil {
+	if err := config.SaveTo(configPath()); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
 		os.Exit(1)
 	}
@@ -61,44 +80,97 @@ func (c *SetupCommand) Execute(args []string) error {
 	fmt.Println()
 	fmt.Println(dimStyle.Render("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 	fmt.Println(successStyle.Render("Setup complete!"))
-	fmt.Printf("Configuration saved to %s\n", robot.DefaultConfigFile)
+	fmt.Printf("Configuration saved to %s\n", configPath())
 	fmt.Println()
 	fmt.Println("Start teleoperation with: " + headerStyle.Render("lerobot teleoperate"))
 
 	return nil
 }
 
-func scanForArms() *robot.Config {
-	fmt.Println("Scanning for robot arms...")
-	fmt.Println()
-
-	// Find all ports with SO-101 arms
-	arms := findArms()
-
-	if len(arms) == 0 {
-		fmt.Println("No SO-101 arms found.")
-		fmt.Println("Make sure your arms are connected and powered on.")
-		os.Exit(1)
+// configuredMotors returns the motors of the arms in the config file, or
+// the SO-101 motors if there is none.
+func configuredMotors() []robot.Motor {
+	cfg, _ := robot.LoadConfig(opts.Profile)
+	if cfg == nil {
+		return robot.SO101Motors()
 	}
+	return cfg.MotorList()
+}
 
-	fmt.Printf("Found %d arm(s). Let's identify them...\n\n", len(arms))
-
-	// Identify each arm by wiggling it
+// scanForArms finds the leader and follower. Arms whose USB adapter matches
+// one stored in prev get their old role; the others are identified by
+// wiggling them, or with --by-hand by the user moving them.
+func (c *SetupCommand) scanForArms(motors []robot.Motor, prev *robot.Config) *robot.Config {
 	var leaderPort, followerPort string
+	var leaderBaud, followerBaud int
 
-	for _, arm := range arms {
-		role := identifyArmWithWiggle(arm, leaderPort == "", followerPort == "")
+	assign := func(arm armInfo, role string) {
 		switch role {
 		case "leader":
-			leaderPort = arm.port
+			leaderPort, leaderBaud = arm.port, arm.baud
 		case "follower":
-			followerPort = arm.port
+			followerPort, followerBaud = arm.port, arm.baud
 		}
+	}
+	identified := func() bool { return leaderPort != "" && followerPort != "" }
 
-		// If we have both, we can stop
-		if leaderPort != "" && followerPort != "" {
-			break
+	// identify assigns roles to arms and closes their buses
+	identify := func(arms []armInfo) {
+		ids := make([]*robot.USBIdentity, len(arms))
+		for i, arm := range arms {
+			ids[i] = robot.LookupUSBIdentity(arm.port)
+		}
+		var unknown []armInfo
+		for i, arm := range arms {
+			role := ""
+			if sharedIdentity(ids, i) {
+				fmt.Printf("  The USB adapter on %s is identical to another one, identify it by hand\n", arm.port)
+			} else {
+				role = knownRole(ids[i], prev, leaderPort == "", followerPort == "")
+			}
+			if role == "" {
+				unknown = append(unknown, arm)
+				continue
+			}
+			arm.bus.Close()
+			fmt.Printf("  Recognized the %s on %s by its USB adapter\n", role, arm.port)
+			assign(arm, role)
+		}
+
+		if c.ByHand {
+			for role, arm := range identifyArmsByMotion(unknown, leaderPort == "", followerPort == "") {
+				assign(arm, role)
+			}
+			return
+		}
+		for _, arm := range unknown {
+			// If we have both, we can stop
+			if identified() {
+				arm.bus.Close()
+				continue
+			}
+			assign(arm, identifyArmWithWiggle(arm, motors[0].ID, leaderPort == "", followerPort == ""))
+		}
+	}
+
+	if c.Watch {
+		watchForArms(motors, func(arm armInfo) { identify([]armInfo{arm}) }, identified)
+	} else {
+		fmt.Println("Scanning for robot arms...")
+		fmt.Println()
+
+		// Find all ports with arms
+		arms := findArms(motors)
+
+		if len(arms) == 0 {
+			fmt.Println("No arms found.")
+			fmt.Println("Make sure your arms are connected and powered on,")
+			fmt.Println("or run " + headerStyle.Render("lerobot setup --watch") + " to wait for them.")
+			os.Exit(1)
 		}
+
+		fmt.Printf("Found %d arm(s). Let's identify them...\n\n", len(arms))
+		identify(arms)
 	}
 
 	fmt.Println()
@@ -123,22 +195,68 @@ func scanForArms() *robot.Config {
 	fmt.Printf("  Leader:   %s\n", leaderPort)
 	fmt.Printf("  Follower: %s\n", followerPort)
 
+	// Identical adapters can't tell the arms apart, so their ports are used
+	leaderUSB, followerUSB := robot.LookupUSBIdentity(leaderPort), robot.LookupUSBIdentity(followerPort)
+	if leaderUSB.Equal(followerUSB) {
+		fmt.Println(dimStyle.Render("  Both USB adapters are identical, the arms are found by port"))
+		leaderUSB, followerUSB = nil, nil
+	}
+
 	return &robot.Config{
 		Leader: robot.ArmConfig{
 			Port: leaderPort,
+			USB:  leaderUSB,
+			Baud: configBaud(leaderBaud),
 		},
 		Follower: robot.ArmConfig{
 			Port: followerPort,
+			USB:  followerUSB,
+			Baud: configBaud(followerBaud),
 		},
 	}
 }
 
-func calibrateArm(armConfig *robot.ArmConfig, armName string) {
+// knownRole returns the role of the arm whose USB adapter is usb in prev,
+// if that role is still needed. If prev stored the same adapter for both
+// arms, it can't tell them apart and returns "".
+func knownRole(usb *robot.USBIdentity, prev *robot.Config, needLeader, needFollower bool) string {
+	switch {
+	case prev.Leader.USB.Equal(prev.Follower.USB):
+		return ""
+	case needLeader && usb.Equal(prev.Leader.USB):
+		return "leader"
+	case needFollower && usb.Equal(prev.Follower.USB):
+		return "follower"
+	}
+	return ""
+}
+
+// sharedIdentity reports whether the USB adapter ids[i] has the same
+// identity as another one in ids, so it can't be recognized.
+func sharedIdentity(ids []*robot.USBIdentity, i int) bool {
+	for j, id := range ids {
+		if j != i && id.Equal(ids[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// configBaud returns the baud rate to store for a detected rate, leaving
+// the default out of the file.
+func configBaud(baud int) int {
+	if baud == robot.DefaultBaudRate {
+		return 0
+	}
+	return baud
+}
+
+func calibrateArm(armConfig *robot.ArmConfig, armName string, motors []robot.Motor) {
 	fmt.Printf("Calibrating %s arm on %s\n", armName, armConfig.Port)
 	fmt.Println()
 
 	// Connect to arm
-	bus, servos, err := connectToArm(armConfig.Port)
+	bus, servos, err := connectToArm(armConfig.Port, armConfig.BaudRate(), motors)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error connecting to arm: %v\n", err)
 		os.Exit(1)
@@ -157,7 +275,6 @@ func calibrateArm(armConfig *robot.ArmConfig, armName string) {
 		servo.Disable(ctx)
 	}
 
-	motors := robot.AllMotors()
 	calibration := make(robot.Calibration)
 
 	// Record min/max by tracking while user moves arm
@@ -170,13 +287,11 @@ func calibrateArm(armConfig *robot.ArmConfig, armName string) {
 	curPositions := make(map[robot.MotorName]int)
 	minPositions := make(map[robot.MotorName]int)
 	maxPositions := make(map[robot.MotorName]int)
-	for i, motorName := range motors {
-		servoID := i + 1
-		servo := servoMap[servoID]
-		pos, _ := servo.Position(ctx)
-		curPositions[motorName] = pos
-		minPositions[motorName] = pos
-		maxPositions[motorName] = pos
+	for _, motor := range motors {
+		pos, _ := servoMap[motor.ID].Position(ctx)
+		curPositions[motor.Name] = pos
+		minPositions[motor.Name] = pos
+		maxPositions[motor.Name] = pos
 	}
 
 	// Run calibration TUI
@@ -190,36 +305,36 @@ func calibrateArm(armConfig *robot.ArmConfig, armName string) {
 
 	// Get final positions from model
 	cm := finalModel.(calibrationModel)
-	for _, name := range motors {
-		minPositions[name] = cm.minPositions[name]
-		maxPositions[name] = cm.maxPositions[name]
+	for _, motor := range motors {
+		minPositions[motor.Name] = cm.minPositions[motor.Name]
+		maxPositions[motor.Name] = cm.maxPositions[motor.Name]
 	}
 
 	fmt.Println()
 
 	// Build calibration
-	for i, motorName := range motors {
-		servoID := i + 1
-		calibration[motorName] = robot.MotorCalibration{
-			ID:       servoID,
-			RangeMin: minPositions[motorName],
-			RangeMax: maxPositions[motorName],
+	for _, motor := range motors {
+		calibration[motor.Name] = robot.MotorCalibration{
+			ID:       motor.ID,
+			RangeMin: minPositions[motor.Name],
+			RangeMax: maxPositions[motor.Name],
 		}
 	}
 
 	armConfig.Calibration = calibration
 	fmt.Println()
-	fmt.Printf("%s arm calibrated.\n", strings.Title(armName))
+	fmt.Printf("%s arm calibrated.\n", capitalize(armName))
 }
 
 type armInfo struct {
 	port   string
+	baud   int
 	servos []feetech.FoundServo
 	bus    *feetech.Bus
 }
 
-func findArms() []armInfo {
-	ports, err := serial.GetPortsList()
+func findArms(motors []robot.Motor) []armInfo {
+	ports, err := robot.ListPorts()
 	if err != nil {
 		fmt.Printf("Error listing ports: %v\n", err)
 		return nil
@@ -228,51 +343,105 @@ func findArms() []armInfo {
 	var arms []armInfo
 
 	for _, port := range ports {
-		// Skip Bluetooth ports on macOS
-		if strings.Contains(port, "Bluetooth") {
-			continue
+		if arm, ok := findArm(port, motors); ok {
+			fmt.Printf("  Found arm on %s (%d baud)\n", port, arm.baud)
+			arms = append(arms, arm)
 		}
+	}
+
+	return arms
+}
+
+// findArm opens port and checks that exactly the motors' servos answer.
+// The returned arm's bus is left open.
+func findArm(port string, motors []robot.Motor) (armInfo, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	bus, baud, err := probeBaud(ctx, port, motors[0].ID)
+	if err != nil {
+		return armInfo{}, false
+	}
+
+	servos, err := scanMotors(ctx, bus, motors)
+	if err != nil || !isArm(servos, motors) {
+		bus.Close()
+		return armInfo{}, false
+	}
+
+	return armInfo{port: port, baud: baud, servos: servos, bus: bus}, true
+}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+// watchForArms polls the serial ports until done reports true, passing each
+// arm to found as soon as it answers. A port is probed again on every poll
+// until an arm is found on it, so arms may also be powered on late.
+// Unplugging an arm forgets its port, so it is picked up again when
+// plugged back in.
+func watchForArms(motors []robot.Motor, found func(armInfo), done func() bool) {
+	fmt.Println("Waiting for arms. Plug in and power on each arm, press Ctrl+C to abort.")
+	fmt.Println()
+
+	handled := make(map[string]bool)
+	for !done() {
+		ports, err := robot.ListPorts()
+		if err != nil {
+			fmt.Printf("Error listing ports: %v\n", err)
+			os.Exit(1)
+		}
+		for port := range handled {
+			if !slices.Contains(ports, port) {
+				delete(handled, port)
+			}
+		}
+		for _, port := range ports {
+			if handled[port] || done() {
+				continue
+			}
+			if arm, ok := findArm(port, motors); ok {
+				fmt.Printf("  Found arm on %s (%d baud)\n", port, arm.baud)
+				handled[port] = true
+				found(arm)
+			}
+		}
+		if !done() {
+			time.Sleep(time.Second)
+		}
+	}
+}
 
+// probeBaud opens port at the first of robot.ProbeBaudRates the servo with
+// the given ID answers at.
+func probeBaud(ctx context.Context, port string, id int) (*feetech.Bus, int, error) {
+	for _, baud := range robot.ProbeBaudRates {
 		bus, err := feetech.NewBus(feetech.BusConfig{
 			Port:     port,
-			BaudRate: 1_000_000,
+			BaudRate: baud,
 			Protocol: feetech.ProtocolSTS,
 			Timeout:  100 * time.Millisecond,
 		})
 		if err != nil {
-			cancel()
-			continue
-		}
-
-		// Scan for servos with IDs 1-6 (SO-101 arm configuration)
-		servos, err := bus.Scan(ctx, 1, 6)
-		cancel()
-
-		if err != nil {
-			bus.Close()
-			continue
+			return nil, 0, err
 		}
-
-		// Check if it's an SO-101 (6 servos with IDs 1-6)
-		if isSOArm(servos) {
-			fmt.Printf("  Found SO-101 arm on %s\n", port)
-			arms = append(arms, armInfo{
-				port:   port,
-				servos: servos,
-				bus:    bus,
-			})
-		} else {
-			bus.Close()
+		if _, err := bus.Ping(ctx, id); err == nil {
+			return bus, baud, nil
 		}
+		bus.Close()
 	}
+	return nil, 0, fmt.Errorf("no servo %d answering on %s", id, port)
+}
 
-	return arms
+// scanMotors pings the ID range spanned by the motors.
+func scanMotors(ctx context.Context, bus *feetech.Bus, motors []robot.Motor) ([]feetech.FoundServo, error) {
+	minID, maxID := motors[0].ID, motors[0].ID
+	for _, m := range motors {
+		minID, maxID = min(minID, m.ID), max(maxID, m.ID)
+	}
+	return bus.Scan(ctx, minID, maxID)
 }
 
-func isSOArm(servos []feetech.FoundServo) bool {
-	if len(servos) != 6 {
+// isArm reports whether exactly the motors' servos were found.
+func isArm(servos []feetech.FoundServo, motors []robot.Motor) bool {
+	if len(servos) != len(motors) {
 		return false
 	}
 
@@ -281,8 +450,8 @@ func isSOArm(servos []feetech.FoundServo) bool {
 		ids[s.ID] = true
 	}
 
-	for i := 1; i <= 6; i++ {
-		if !ids[i] {
+	for _, m := range motors {
+		if !ids[m.ID] {
 			return false
 		}
 	}
@@ -290,15 +459,15 @@ func isSOArm(servos []feetech.FoundServo) bool {
 	return true
 }
 
-func identifyArmWithWiggle(arm armInfo, needLeader, needFollower bool) string {
+func identifyArmWithWiggle(arm armInfo, wiggleID int, needLeader, needFollower bool) string {
 	defer arm.bus.Close()
 
 	ctx := context.Background()
 
-	// Find servo ID 1 (shoulder_pan) for wiggling
+	// Wiggle the first motor (shoulder_pan on an SO-101)
 	var servo *feetech.Servo
 	for _, s := range arm.servos {
-		if s.ID == 1 {
+		if s.ID == wiggleID {
 			servo = feetech.NewServo(arm.bus, s.ID, s.Model)
 			break
 		}
@@ -372,13 +541,94 @@ func identifyArmWithWiggle(arm armInfo, needLeader, needFollower bool) string {
 	return role
 }
 
-func connectToArm(port string) (*feetech.Bus, []feetech.FoundServo, error) {
+// motionThreshold is how far in raw steps a joint must move by hand to
+// count as motion, well above encoder noise.
+const motionThreshold = 50
+
+// identifyArmsByMotion asks the user to move the leader and then the
+// follower by hand, and gives each role to the arm whose joints moved.
+// Torque is disabled and never enabled, so a mis-assembled arm is not
+// driven. It returns the arms by role and closes all buses.
+func identifyArmsByMotion(arms []armInfo, needLeader, needFollower bool) map[string]armInfo {
+	defer func() {
+		for _, arm := range arms {
+			arm.bus.Close()
+		}
+	}()
+
+	ctx := context.Background()
+	for _, arm := range arms {
+		for _, s := range arm.servos {
+			if err := feetech.NewServo(arm.bus, s.ID, s.Model).Disable(ctx); err != nil {
+				fmt.Printf("  Error disabling servo %d on %s: %v\n", s.ID, arm.port, err)
+			}
+		}
+	}
+
+	roles := make(map[string]armInfo)
+	remaining := slices.Clone(arms)
+	for _, role := range []string{"leader", "follower"} {
+		if len(remaining) == 0 || (role == "leader" && !needLeader) || (role == "follower" && !needFollower) {
+			continue
+		}
+		fmt.Printf("\n  Move a joint of the %s arm by hand...\n", role)
+		i, ok := waitForMotion(ctx, remaining, 15*time.Second)
+		if !ok {
+			fmt.Printf("  No motion detected, %s not identified\n", role)
+			continue
+		}
+		fmt.Printf("  %s is on %s\n", capitalize(role), remaining[i].port)
+		roles[role] = remaining[i]
+		remaining = slices.Delete(remaining, i, i+1)
+	}
+	return roles
+}
+
+// capitalize returns s with its first letter in upper case.
+func capitalize(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	if n == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// waitForMotion polls the positions of all arms until a joint of one of
+// them moves by motionThreshold, returning its index.
+func waitForMotion(ctx context.Context, arms []armInfo, timeout time.Duration) (int, bool) {
+	read := func(arm armInfo) map[int]int {
+		positions := make(map[int]int, len(arm.servos))
+		for _, s := range arm.servos {
+			if pos, err := feetech.NewServo(arm.bus, s.ID, s.Model).Position(ctx); err == nil {
+				positions[s.ID] = pos
+			}
+		}
+		return positions
+	}
+
+	start := make([]map[int]int, len(arms))
+	for i, arm := range arms {
+		start[i] = read(arm)
+	}
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		for i, arm := range arms {
+			for id, pos := range read(arm) {
+				if from, ok := start[i][id]; ok && max(pos-from, from-pos) >= motionThreshold {
+					return i, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func connectToArm(port string, baud int, motors []robot.Motor) (*feetech.Bus, []feetech.FoundServo, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
 	bus, err := feetech.NewBus(feetech.BusConfig{
 		Port:     port,
-		BaudRate: 1_000_000,
+		BaudRate: baud,
 		Protocol: feetech.ProtocolSTS,
 		Timeout:  100 * time.Millisecond,
 	})
@@ -386,15 +636,15 @@ func connectToArm(port string) (*feetech.Bus, []feetech.FoundServo, error) {
 		return nil, nil, err
 	}
 
-	servos, err := bus.Scan(ctx, 1, 6)
+	servos, err := scanMotors(ctx, bus, motors)
 	if err != nil {
 		bus.Close()
 		return nil, nil, err
 	}
 
-	if !isSOArm(servos) {
+	if !isArm(servos, motors) {
 		bus.Close()
-		return nil, nil, fmt.Errorf("not an SO-101 arm (expected 6 servos with IDs 1-6)")
+		return nil, nil, fmt.Errorf("expected %d servos with IDs %v, found %d", len(motors), robot.MotorIDs(motors), len(servos))
 	}
 
 	return bus, servos, nil
@@ -420,7 +670,7 @@ func waitForUser(prompt string) {
 
 // Calibration TUI model
 type calibrationModel struct {
-	motors       []robot.MotorName
+	motors       []robot.Motor
 	servoMap     map[int]*feetech.Servo
 	curPositions map[robot.MotorName]int
 	minPositions map[robot.MotorName]int
@@ -431,7 +681,7 @@ type calibrationModel struct {
 type tickMsg time.Time
 
 func newCalibrationModel(
-	motors []robot.MotorName,
+	motors []robot.Motor,
 	servoMap map[int]*feetech.Servo,
 	curPositions, minPositions, maxPositions map[robot.MotorName]int,
 ) calibrationModel {
@@ -462,10 +712,9 @@ func (m calibrationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tickMsg:
 		// Read positions from servos
 		ctx := context.Background()
-		for i, motorName := range m.motors {
-			servoID := i + 1
-			servo := m.servoMap[servoID]
-			pos, err := servo.Position(ctx)
+		for _, motor := range m.motors {
+			motorName := motor.Name
+			pos, err := m.servoMap[motor.ID].Position(ctx)
 			if err != nil {
 				continue
 			}
@@ -502,7 +751,8 @@ func (m calibrationModel) View() string {
 
 	rows := make([][]string, 0, len(m.motors))
 	ranges := make([]int, 0, len(m.motors))
-	for _, motorName := range m.motors {
+	for _, motor := range m.motors {
+		motorName := motor.Name
 		rangeSize := m.maxPositions[motorName] - m.minPositions[motorName]
 		ranges = append(ranges, rangeSize)
 		rows = append(rows, []string{
@@ -529,7 +779,7 @@ func (m calibrationModel) View() string {
 			case 1:
 				return tableCurrentStyle
 			case 4:
-				if row >= 0 && row < len(ranges) && ranges[row] > 500 {
+				if row >= 0 && row < len(ranges) && ranges[row] >= robot.MinCalibratedRange {
 					return tableRangeGoodStyle
 				}
 				return tableRangeLowStyle
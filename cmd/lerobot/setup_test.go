@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+func TestKnownRole(t *testing.T) {
+	leader := &robot.USBIdentity{VID: "1A86", PID: "55D3", SerialNumber: "58FA083324"}
+	follower := &robot.USBIdentity{VID: "1A86", PID: "55D3", SerialNumber: "58FA083325"}
+	prev := &robot.Config{Leader: robot.ArmConfig{USB: leader}, Follower: robot.ArmConfig{USB: follower}}
+
+	if role := knownRole(follower, prev, true, true); role != "follower" {
+		t.Errorf("knownRole(follower) = %q", role)
+	}
+	if role := knownRole(leader, prev, false, true); role != "" {
+		t.Errorf("knownRole(leader) = %q once the leader is found", role)
+	}
+
+	// Identical adapters could be either arm
+	prev.Follower.USB = leader
+	if role := knownRole(leader, prev, true, true); role != "" {
+		t.Errorf("knownRole() = %q for identical adapters, want them identified by hand", role)
+	}
+}
+
+func TestSharedIdentity(t *testing.T) {
+	a := &robot.USBIdentity{VID: "1A86", PID: "55D3", SerialNumber: "0001"}
+	b := &robot.USBIdentity{VID: "1A86", PID: "55D3", SerialNumber: "0002"}
+	ids := []*robot.USBIdentity{a, b, nil, nil}
+	for i, want := range []bool{false, false, false, false} {
+		if got := sharedIdentity(ids, i); got != want {
+			t.Errorf("sharedIdentity(%d) = %v, want %v", i, got, want)
+		}
+	}
+	ids = append(ids, &robot.USBIdentity{VID: "1A86", PID: "55D3", SerialNumber: "0001"})
+	if !sharedIdentity(ids, 0) || !sharedIdentity(ids, 4) || sharedIdentity(ids, 1) {
+		t.Error("identical adapters are not detected")
+	}
+}
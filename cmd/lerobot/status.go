@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type StatusCommand struct {
+	Interval time.Duration `long:"interval" default:"500ms" description:"Refresh interval"`
+}
+
+func (c *StatusCommand) Execute(args []string) error {
+	cfg := loadConfig()
+	cfg.ResolvePorts()
+
+	var arms []statusArm
+	for _, a := range []struct {
+		name string
+		cfg  robot.ArmConfig
+	}{
+		{"Leader", cfg.Leader},
+		{"Follower", cfg.Follower},
+	} {
+		if a.cfg.Port == "" || !a.cfg.IsCalibrated() {
+			continue
+		}
+		// Show an arm with a swapped servo too, the table tells which one
+		a.cfg.SkipVerify = true
+		arm, err := robot.OpenArm(a.cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error connecting to %s arm on %s: %v\n", strings.ToLower(a.name), a.cfg.Port, err)
+			continue
+		}
+		defer arm.Close()
+		warnServoMismatch(arm)
+		arms = append(arms, statusArm{name: a.name, cfg: a.cfg, arm: arm})
+	}
+
+	if len(arms) == 0 {
+		fmt.Fprintln(os.Stderr, "No arms available. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+
+	p := tea.NewProgram(statusModel{arms: arms, interval: c.Interval, kinematics: cfg.KinematicModel(), workspace: cfg.Workspace})
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running status: %v\n", err)
+		os.Exit(1)
+	}
+	return nil
+}
+
+type statusArm struct {
+	name     string
+	cfg      robot.ArmConfig
+	arm      *robot.Arm
+	statuses []robot.ServoStatus
+}
+
+type statusModel struct {
+	arms       []statusArm
+	interval   time.Duration
+	kinematics robot.Kinematics
+	workspace  robot.Workspace
+	quitting   bool
+}
+
+type statusTickMsg time.Time
+
+func (m statusModel) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg {
+		return statusTickMsg(t)
+	})
+}
+
+func (m statusModel) Init() tea.Cmd {
+	return func() tea.Msg { return statusTickMsg(time.Now()) }
+}
+
+func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		}
+
+	case statusTickMsg:
+		ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+		defer cancel()
+		for i := range m.arms {
+			m.arms[i].statuses = m.arms[i].arm.ReadStatus(ctx)
+		}
+		return m, m.tick()
+	}
+
+	return m, nil
+}
+
+func (m statusModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("LeRobot Status"))
+	sb.WriteString("\n\n")
+
+	for _, a := range m.arms {
+		sb.WriteString(subHeaderStyle.Render(fmt.Sprintf("%s (%s)", a.name, a.arm.Port())))
+		sb.WriteString("\n")
+		sb.WriteString(renderStatusTable(a.arm, a.statuses))
+		sb.WriteString("\n")
+		if a.name == "Follower" {
+			sb.WriteString(m.renderTip(a))
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(dimStyle.Render("Press 'q' to quit"))
+	return sb.String()
+}
+
+func renderStatusTable(arm *robot.Arm, statuses []robot.ServoStatus) string {
+	cellStyle := lipgloss.NewStyle().Padding(0, 1)
+	headerCellStyle := cellStyle.Bold(true).Foreground(lipgloss.Color("12"))
+	motorCellStyle := cellStyle.Foreground(lipgloss.Color("14"))
+	errorCellStyle := cellStyle.Foreground(lipgloss.Color("9"))
+	okCellStyle := cellStyle.Foreground(lipgloss.Color("10"))
+
+	rows := make([][]string, 0, len(statuses))
+	for _, st := range statuses {
+		torque := "off"
+		if st.TorqueEnabled {
+			torque = "on"
+		}
+		errText := st.Faults.String()
+		if st.Err != nil {
+			errText = st.Err.Error()
+		}
+		rows = append(rows, []string{
+			string(st.Motor),
+			fmt.Sprintf("%d", st.ID),
+			st.Model,
+			fmt.Sprintf("%d", st.Position),
+			formatJoint(arm, st),
+			fmt.Sprintf("%d°C", st.Temperature),
+			fmt.Sprintf("%.1fV", st.Voltage),
+			fmt.Sprintf("%.1f%%", float64(st.Load)/10),
+			torque,
+			errText,
+		})
+	}
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(dimStyle).
+		Headers("Motor", "ID", "Model", "Position", "Joint", "Temp", "Voltage", "Load", "Torque", "Status").
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerCellStyle
+			}
+			switch col {
+			case 0:
+				return motorCellStyle
+			case 9:
+				if row >= 0 && row < len(statuses) && (statuses[row].Err != nil || statuses[row].Faults != 0) {
+					return errorCellStyle
+				}
+				return okCellStyle
+			default:
+				return cellStyle
+			}
+		})
+
+	return t.Render()
+}
+
+// renderTip shows where the kinematic model puts the gripper tip, to check
+// the model against the real arm, whether the arm is in its workspace and,
+// with collision_check, whether it is clear of itself.
+func (m statusModel) renderTip(a statusArm) string {
+	if len(a.statuses) == 0 {
+		return dimStyle.Render("Gripper tip: -")
+	}
+	degrees := make(map[robot.MotorName]float64, len(a.statuses))
+	for _, st := range a.statuses {
+		if st.Err != nil {
+			return dimStyle.Render("Gripper tip: -")
+		}
+		degrees[st.Motor] = a.cfg.Angles.Joint(st.Motor).Degrees(a.cfg.Calibration[st.Motor], st.Position)
+	}
+	pose := m.kinematics.Forward(degrees)
+	line := fmt.Sprintf("Gripper tip: %s m", pose.Tip)
+	if a.cfg.CollisionCheck {
+		if err := m.kinematics.SelfCollision(pose); err != nil {
+			return warnStyle.Render(fmt.Sprintf("%s, %v", line, err))
+		}
+		line += ", no self-collision"
+	}
+	if m.workspace.IsZero() {
+		return dimStyle.Render(line)
+	}
+	if err := m.workspace.Check(pose); err != nil {
+		return warnStyle.Render(fmt.Sprintf("%s, %v", line, err))
+	}
+	return dimStyle.Render(line + ", inside workspace")
+}
+
+// formatJoint shows a servo's position in the arm's configured units.
+func formatJoint(arm *robot.Arm, st robot.ServoStatus) string {
+	if st.Err != nil {
+		return "-"
+	}
+	v := arm.FromRaw(st.Motor, st.Position)
+	switch arm.Units() {
+	case robot.UnitsDegrees:
+		return fmt.Sprintf("%.1f°", v)
+	case robot.UnitsRadians:
+		return fmt.Sprintf("%.3f rad", v)
+	default:
+		return fmt.Sprintf("%.1f", v)
+	}
+}
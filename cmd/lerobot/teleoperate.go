@@ -1,11 +1,19 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"net"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -13,13 +21,43 @@ import (
 	"github.com/NimbleMarkets/ntcharts/canvas/runes"
 	"github.com/NimbleMarkets/ntcharts/linechart/streamlinechart"
 
+	"github.com/gwillem/lerobot/pkg/dataset"
 	"github.com/gwillem/lerobot/pkg/robot"
 	"github.com/gwillem/lerobot/pkg/teleop"
 )
 
 type TeleoperateCommand struct {
-	Hz     int  `long:"hz" default:"60" description:"Control loop frequency"`
-	Mirror bool `long:"mirror" description:"Mirror mode: invert shoulder_pan and wrist_roll positions"`
+	Hz           int           `long:"hz" description:"Control loop frequency (default: teleop.hz from the configuration, or 60)"`
+	Mirror       *bool         `long:"mirror" description:"Mirror mode: invert shoulder_pan and wrist_roll positions, --mirror=false to turn it off (default: teleop.mirror from the configuration)"`
+	Smoothing    *float64      `long:"smoothing" description:"Low-pass filter the follower targets with this cutoff in Hz, 0 disables (default: teleop.smoothing from the configuration)"`
+	Deadband     float64       `long:"deadband" default:"0" description:"Skip follower writes for motors that moved less than this (normalized units)"`
+	GripForce    int           `long:"grip-force" default:"0" description:"Stop closing the follower gripper at this load (0-1000, 0 disables)"`
+	Sim          string        `long:"sim" description:"Drive a simulated follower at this address (e.g. localhost:5555) instead of the real one"`
+	Trace        string        `long:"trace" description:"Write every control cycle (raw reads, targets, timing) to this JSONL file"`
+	Park         bool          `long:"park" description:"On exit, slowly move both arms to the configured rest_pose before disabling torque"`
+	SoftStart    time.Duration `long:"soft-start" default:"2s" description:"Move the follower to the leader pose over this long at start (0 to snap)"`
+	MaxMismatch  float64       `long:"max-mismatch" default:"30" description:"Largest leader/follower difference on any joint at start before warning (or refusing with --soft-start 0), 0 disables"`
+	ReleaseAfter time.Duration `long:"release-after" default:"5s" description:"Ramp follower torque down after this long without leader readings, e.g. when the leader is unplugged (0 holds indefinitely)"`
+	Relative     bool          `long:"relative" description:"Clutch mode: after resuming a pause, the follower follows the leader's motion from where it was held"`
+	Telemetry    bool          `long:"telemetry" description:"Read the follower servo temperatures once a second, shown in the bars view"`
+	Duration     time.Duration `long:"duration" description:"Stop after this long (e.g. 10m), parking if --park is set (default: run until stopped)"`
+	NoTUI        bool          `long:"no-tui" description:"Run without the terminal UI and write every state as a JSON line to --output"`
+	Output       string        `long:"output" default:"-" description:"With --no-tui: - for stdout, or unix:PATH or tcp:HOST:PORT to send states to a socket"`
+	Overrun      string        `long:"overrun" default:"skip" choice:"skip" choice:"degrade" choice:"error" description:"When cycles take longer than 1/hz: skip ticks, lower the rate, or stop"`
+	ErrorBudget  float64       `long:"error-budget" default:"0.25" description:"Stop when more than this fraction of bus transactions failed over 10s (0 disables)"`
+	Pipeline     bool          `long:"pipeline" description:"Read the leader and write the follower concurrently, so a slow follower write does not delay the next leader read"`
+	Record       string        `long:"record" description:"Record episodes to this dataset directory: right arrow ends an episode or starts the next, left arrow discards and re-records, Esc stops"`
+	EpisodeTime  time.Duration `long:"episode-time" description:"With --record: end episodes after this long (default: only with the right arrow)"`
+	ResetTime    time.Duration `long:"reset-time" description:"With --record: start the next episode after this long (default: only with the right arrow)"`
+	Task         string        `long:"task" description:"With --record: natural-language description of the task, stored with every episode ('t' changes it)"`
+	Input        string        `long:"input" default:"leader" description:"What drives the follower: the leader arm, the keyboard (1-9 selects a joint, +/- nudges it), a hand tracker, a VR controller, a phone, - for JSON lines of positions on stdin, or a named pipe to read them from"`
+	Step         float64       `long:"step" default:"2" description:"With --input keyboard: how far one key press moves a joint (normalized units)"`
+	HandListen   string        `long:"hand-listen" default:"localhost:7070" description:"With --input hand or vr: TCP address to accept the tracker bridge on"`
+	HandScale    float64       `long:"hand-scale" default:"1" description:"With --input hand or vr: how far the gripper moves per unit of hand motion"`
+	PhoneListen  string        `long:"phone-listen" default:":8443" description:"With --input phone: address to serve the phone page on over HTTPS"`
+	PhoneGain    float64       `long:"phone-gain" default:"1" description:"With --input phone: degrees a joint turns per degree the phone turns (negative reverses)"`
+	TrimStep     float64       `long:"trim-step" default:"0.5" description:"How far +/- trims the joint selected in the bars view (normalized units)"`
+	SaveTrims    bool          `long:"save-trims" description:"On exit, fold the trims into the follower calibration offsets and save the configuration"`
 }
 
 const (
@@ -40,23 +78,53 @@ var motorColors = map[robot.MotorName]string{
 	robot.Gripper:      "201", // magenta
 }
 
+// extraColors are used in order for motors of custom builds.
+var extraColors = []string{"33", "141", "214", "118", "219", "250"}
+
+// motorColor returns the chart color of the i-th motor.
+func motorColor(name robot.MotorName, i int) string {
+	if color, ok := motorColors[name]; ok {
+		return color
+	}
+	return extraColors[i%len(extraColors)]
+}
+
 var (
 	titleStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
 	chartStyle  = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240"))
 	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	pausedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
+	recStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
 )
 
 type teleopModel struct {
 	ctrl          *teleop.Controller
-	chart         *streamlinechart.Model
-	width         int                          // terminal width
-	height        int                          // terminal height
-	logs          []string                     // last N log messages
+	motors        []robot.MotorName
+	chart         *streamlinechart.Model // leader positions
+	loadChart     *streamlinechart.Model // follower loads, % of max torque
+	plotLoads     bool                   // show loadChart instead of chart
+	width         int                    // terminal width
+	height        int                    // terminal height
+	logs          []string               // last N log messages
 	quitting      bool
 	lastPositions map[robot.MotorName]float64 // track previous positions to detect movement
+	bars          bool                        // per-joint bar view instead of the chart
+	hidden        map[robot.MotorName]bool    // motors toggled off on the chart
+	deadline      time.Time                   // zero unless --duration is set
+	last          teleop.State                // latest state, for the bar view
+	rec           *episodeRecorder            // nil unless --record is set
+	keyboard      *teleop.Keyboard            // nil unless --input keyboard
+	taskInput     *string                     // task being typed after 't', nil otherwise
+	trimJoint     robot.MotorName             // joint +/- trims in the bars view, none if empty
+	trimStep      float64
+	trigger       string          // robot.Trigger.Action
+	triggers      <-chan struct{} // trigger presses, nil without an action
 }
 
 func (m *teleopModel) addLog(msg string) {
+	if msg == "" {
+		return
+	}
 	m.logs = append(m.logs, msg)
 	if len(m.logs) > maxLogs {
 		m.logs = m.logs[len(m.logs)-maxLogs:]
@@ -78,7 +146,18 @@ func (m *teleopModel) hasMovement(positions map[robot.MotorName]float64) bool {
 
 // Messages from the controller
 type stateMsg teleop.State
-type logMsg string
+type eventMsg teleop.Event
+type triggerMsg struct{}
+
+// recordedMsg is what the episodeRecorder reports from its goroutine.
+type recordedMsg struct {
+	msg string
+	err error
+}
+
+// snapshotInterval is how often the TUI shows the latest state while an
+// episodeRecorder takes the states.
+const snapshotInterval = time.Second / 30
 
 func waitForState(ctrl *teleop.Controller) tea.Cmd {
 	return func() tea.Msg {
@@ -86,9 +165,28 @@ func waitForState(ctrl *teleop.Controller) tea.Cmd {
 	}
 }
 
-func waitForLog(ctrl *teleop.Controller) tea.Cmd {
+// nextState waits for the next state to show: from the controller, or the
+// latest one every snapshotInterval while recording.
+func (m teleopModel) nextState() tea.Cmd {
+	if m.rec == nil {
+		return waitForState(m.ctrl)
+	}
+	ctrl := m.ctrl
+	return tea.Tick(snapshotInterval, func(time.Time) tea.Msg {
+		return stateMsg(ctrl.Snapshot())
+	})
+}
+
+func waitForEvent(ctrl *teleop.Controller) tea.Cmd {
 	return func() tea.Msg {
-		return logMsg(<-ctrl.Logs())
+		return eventMsg(<-ctrl.Events())
+	}
+}
+
+func waitForTrigger(triggers <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		<-triggers
+		return triggerMsg{}
 	}
 }
 
@@ -111,32 +209,66 @@ func (m *teleopModel) chartSize() (width, height int) {
 func (m *teleopModel) resizeChart() {
 	w, h := m.chartSize()
 	m.chart.Resize(w, h)
+	m.loadChart.Resize(w, h)
 }
 
-func initialTeleopModel(ctrl *teleop.Controller) teleopModel {
+// newMotorChart returns a chart from -100 to 100 with a trace per motor.
+func newMotorChart(motors []robot.MotorName) *streamlinechart.Model {
 	chart := streamlinechart.New(80, 20,
 		streamlinechart.WithYRange(-100, 100),
 	)
 
 	// Set up data set styles for each motor
-	for _, name := range robot.AllMotors() {
-		color := motorColors[name]
-		style := lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+	for i, name := range motors {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(motorColor(name, i)))
 		chart.SetDataSetStyles(string(name), runes.ThinLineStyle, style)
 	}
+	return &chart
+}
 
+func initialTeleopModel(ctrl *teleop.Controller, motors []robot.MotorName) teleopModel {
 	return teleopModel{
-		ctrl:  ctrl,
-		chart: &chart,
+		ctrl:      ctrl,
+		motors:    motors,
+		chart:     newMotorChart(motors),
+		loadChart: newMotorChart(motors),
+		hidden:    make(map[robot.MotorName]bool),
 	}
 }
 
+// activeChart returns the chart currently shown.
+func (m *teleopModel) activeChart() *streamlinechart.Model {
+	if m.plotLoads {
+		return m.loadChart
+	}
+	return m.chart
+}
+
+// drawChart redraws the traces of the motors that aren't hidden.
+func (m *teleopModel) drawChart() {
+	chart := m.activeChart()
+	var names []string
+	for _, name := range m.motors {
+		if !m.hidden[name] {
+			names = append(names, string(name))
+		}
+	}
+	if len(names) == 0 {
+		// DrawDataSets leaves the canvas alone when given nothing
+		chart.Clear()
+		chart.DrawXYAxisAndLabel()
+		return
+	}
+	chart.DrawDataSets(names)
+}
+
 func (m teleopModel) Init() tea.Cmd {
 	// Start listening for state and log updates
-	return tea.Batch(
-		waitForState(m.ctrl),
-		waitForLog(m.ctrl),
-	)
+	cmds := []tea.Cmd{m.nextState(), waitForEvent(m.ctrl)}
+	if m.triggers != nil {
+		cmds = append(cmds, waitForTrigger(m.triggers))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m teleopModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -148,34 +280,170 @@ func (m teleopModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.taskInput != nil {
+			return m.editTask(msg)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
+		case "esc":
+			// Stops the session like in Python lerobot record; the current
+			// episode is saved on exit
+			if m.rec != nil {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+		case "right":
+			var cmd tea.Cmd
+			if m.rec != nil {
+				cmd = m.recorded(m.rec.next())
+			}
+			return m, cmd
+		case "left":
+			if m.rec != nil {
+				m.addLog(m.rec.rerecord())
+			}
+			return m, nil
+		case "t":
+			if m.rec != nil {
+				task := m.rec.currentTask()
+				m.taskInput = &task
+			}
+			return m, nil
+		case "p":
+			togglePause(m.ctrl)
+			return m, nil
+		case "tab":
+			m.bars = !m.bars
+			m.ctrl.WatchLoads(m.bars || m.plotLoads)
+			return m, nil
+		case "l":
+			m.plotLoads = !m.plotLoads
+			m.ctrl.WatchLoads(m.bars || m.plotLoads)
+			m.drawChart()
+			return m, nil
+		case "a":
+			clear(m.hidden)
+			m.drawChart()
+			return m, nil
+		case "+", "=", "up":
+			m.nudge(1)
+			return m, nil
+		case "-", "down":
+			m.nudge(-1)
+			return m, nil
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if m.keyboard != nil {
+				m.keyboard.Select(int(msg.String()[0] - '1'))
+			} else if i := int(msg.String()[0] - '1'); i < len(m.motors) && m.bars {
+				m.trimJoint = m.motors[i]
+			} else if i < len(m.motors) {
+				name := m.motors[i]
+				m.hidden[name] = !m.hidden[name]
+				m.drawChart()
+			}
+			return m, nil
 		}
 
 	case stateMsg:
 		state := teleop.State(msg)
 		if state.Positions != nil {
+			m.last = state
 			// Only update chart if there's movement (freeze when idle)
 			if m.hasMovement(state.Positions) {
 				for name, pos := range state.Positions {
 					m.chart.PushDataSet(string(name), pos)
 				}
-				m.chart.DrawAll()
+				if !m.plotLoads {
+					m.drawChart()
+				}
 				m.lastPositions = state.Positions
 			}
+			if m.plotLoads && state.Loads != nil {
+				for name, load := range state.Loads {
+					m.loadChart.PushDataSet(string(name), float64(load)/10)
+				}
+				m.drawChart()
+			}
+		}
+		return m, m.nextState()
+
+	case recordedMsg:
+		return m, m.recorded(msg.msg, msg.err)
+
+	case triggerMsg:
+		// The leader trigger acts like the right arrow or 'p'
+		var cmd tea.Cmd
+		switch {
+		case m.trigger == robot.TriggerRecord && m.rec != nil:
+			cmd = m.recorded(m.rec.next())
+		case m.trigger == robot.TriggerPause:
+			togglePause(m.ctrl)
+		}
+		if m.quitting {
+			return m, cmd
 		}
-		return m, waitForState(m.ctrl)
+		return m, tea.Batch(cmd, waitForTrigger(m.triggers))
 
-	case logMsg:
-		m.addLog(string(msg))
-		return m, waitForLog(m.ctrl)
+	case eventMsg:
+		line := teleop.Event(msg).String()
+		if msg.Level >= slog.LevelWarn {
+			line = warnStyle.Render(line)
+		}
+		m.addLog(line)
+		return m, waitForEvent(m.ctrl)
 	}
 
 	return m, nil
 }
 
+// nudge moves the joint selected with --input keyboard, or else trims the
+// joint selected in the bars view, by one step in direction dir.
+func (m *teleopModel) nudge(dir int) {
+	switch {
+	case m.keyboard != nil:
+		m.keyboard.Nudge(dir)
+	case m.bars && m.trimJoint != "":
+		m.ctrl.Trim(m.trimJoint, float64(dir)*m.trimStep)
+	}
+}
+
+// editTask handles keys while the task is typed: Enter applies it, Esc
+// cancels.
+func (m teleopModel) editTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.addLog(m.rec.setTask(strings.TrimSpace(*m.taskInput)))
+		m.taskInput = nil
+	case tea.KeyEsc:
+		m.taskInput = nil
+	case tea.KeyBackspace:
+		if r := []rune(*m.taskInput); len(r) > 0 {
+			*m.taskInput = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		*m.taskInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// recorded logs the outcome of an episodeRecorder call. A failure to
+// record quits, as the episode can't be completed.
+func (m *teleopModel) recorded(msg string, err error) tea.Cmd {
+	if err != nil {
+		m.rec.fail(err)
+		m.quitting = true
+		return tea.Quit
+	}
+	m.addLog(msg)
+	return nil
+}
+
 func (m teleopModel) View() string {
 	if m.quitting {
 		return "Teleoperation stopped.\n"
@@ -185,30 +453,71 @@ func (m teleopModel) View() string {
 
 	// Header
 	sb.WriteString(titleStyle.Render("LeRobot Teleoperate"))
-	sb.WriteString(fmt.Sprintf(" - %d Hz", m.ctrl.Hz()))
+	metrics := m.ctrl.Metrics()
+	sb.WriteString(fmt.Sprintf(" - %d Hz", metrics.Hz))
+	if m.ctrl.Paused() {
+		sb.WriteString(pausedStyle.Render("  PAUSED"))
+	}
+	if m.rec != nil {
+		sb.WriteString(recStyle.Render("  " + m.rec.status()))
+	}
+	if m.keyboard != nil {
+		sb.WriteString(pausedStyle.Render("  KEYBOARD " + string(m.keyboard.Selected())))
+	}
+	if !m.deadline.IsZero() {
+		left := max(time.Until(m.deadline), 0).Round(time.Second)
+		sb.WriteString(statusStyle.Render(fmt.Sprintf("  %s left", left)))
+	}
+	if metrics.Cycles > 0 {
+		sb.WriteString(statusStyle.Render(fmt.Sprintf(" (%.1f effective)  read %s  write %s  missed %d (p50/p95/max)",
+			metrics.EffectiveHz, formatLatency(metrics.Read), formatLatency(metrics.Write), metrics.MissedTicks)))
+	}
+	if metrics.Dropped > 0 {
+		sb.WriteString(statusStyle.Render(fmt.Sprintf("  dropped %d", metrics.Dropped)))
+	}
 	if m.width > 0 {
 		sb.WriteString(statusStyle.Render(fmt.Sprintf("  [%dx%d]", m.width, m.height)))
 	}
 	sb.WriteString("\n\n")
 
-	// Chart
-	sb.WriteString(chartStyle.Render(m.chart.View()))
-	sb.WriteString("\n")
+	if m.bars {
+		w, h := m.chartSize()
+		sb.WriteString(chartStyle.Render(renderBars(m.motors, m.last, m.ctrl.Trims(), m.trimJoint, w, h)))
+		sb.WriteString("\n\n")
+	} else {
+		// Chart
+		sb.WriteString(chartStyle.Render(m.activeChart().View()))
+		sb.WriteString("\n")
 
-	// Legend
-	sb.WriteString(renderLegend())
-	sb.WriteString("\n")
+		// Legend
+		sb.WriteString(renderLegend(m.motors, m.hidden))
+		if m.plotLoads {
+			sb.WriteString(statusStyle.Render("  follower load, % of max torque"))
+		}
+		sb.WriteString("\n")
+	}
 
 	// Log box
 	logStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("240")).
-		Width(m.width - 4).
-		Foreground(lipgloss.Color("9")) // bright red
+		Width(m.width - 4)
 
 	var logLines string
-	if len(m.logs) == 0 {
-		logLines = statusStyle.Render("Press 'q' to quit")
+	if m.taskInput != nil {
+		logLines = "Task: " + *m.taskInput + "█\n" + statusStyle.Render("Enter to apply to this and the next episodes, Esc to cancel")
+	} else if len(m.logs) == 0 {
+		help := "Press 'p' to pause/resume the follower, 1-9/'a' to toggle traces, 'l' to plot loads, Tab to switch chart/bars, 'q' to quit"
+		if m.bars {
+			help = "Press 1-9 to select a joint and +/- to trim its follower target, Tab to go back to the chart, 'p' to pause/resume, 'q' to quit"
+		}
+		if m.keyboard != nil {
+			help = "Press 1-9 to select a joint, +/- to move it, 'p' to pause/resume the follower, Tab to switch chart/bars, 'q' to quit"
+		}
+		if m.rec != nil {
+			help = "Press → to end the episode or start the next, ← to discard and re-record, 't' to set the task, Esc to stop; 'p' pauses, 'q' quits"
+		}
+		logLines = statusStyle.Render(help)
 	} else {
 		logLines = strings.Join(m.logs, "\n")
 	}
@@ -218,68 +527,520 @@ func (m teleopModel) View() string {
 	return sb.String()
 }
 
-func renderLegend() string {
+func formatLatency(l teleop.Latency) string {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return fmt.Sprintf("%.1f/%.1f/%.1fms", ms(l.P50), ms(l.P95), ms(l.Max))
+}
+
+func renderLegend(motors []robot.MotorName, hidden map[robot.MotorName]bool) string {
 	var items []string
-	for _, name := range robot.AllMotors() {
-		color := motorColors[name]
-		colorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Bold(true)
+	for i, name := range motors {
+		colorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(motorColor(name, i))).Bold(true)
 		item := colorStyle.Render("━━") + " " + string(name)
+		if hidden[name] {
+			item = statusStyle.Render("── " + string(name))
+		}
+		if i < 9 {
+			item = statusStyle.Render(fmt.Sprintf("%d ", i+1)) + item
+		}
 		items = append(items, item)
 	}
 	return strings.Join(items, "  ")
 }
 
+// renderBars shows one row per motor: the leader position as a bar from
+// the center, the follower target as a marker on it, raw counts, load, trim
+// and any servo fault. The joint selected for trimming is marked.
+func renderBars(motors []robot.MotorName, st teleop.State, trims map[robot.MotorName]float64, selected robot.MotorName, width, height int) string {
+	const labelWidth, valuesWidth = 17, 44
+	barWidth := max(width-labelWidth-valuesWidth, 20)
+	center := barWidth / 2
+	col := func(v float64) int {
+		return max(0, min(barWidth-1, int((v+100)/200*float64(barWidth-1)+0.5)))
+	}
+
+	var rows []string
+	for i, name := range motors {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(motorColor(name, i)))
+		label := "  " + string(name)
+		if name == selected {
+			label = "▸ " + string(name)
+		}
+		pos, ok := st.Positions[name]
+		if slices.Contains(st.LeaderMissing, name) {
+			rows = append(rows, fmt.Sprintf("%-*s%s", labelWidth, label, warnStyle.Render("leader servo not responding, retrying")))
+			continue
+		}
+		if !ok {
+			rows = append(rows, fmt.Sprintf("%-*s%s", labelWidth, label, statusStyle.Render("no data")))
+			continue
+		}
+
+		bar := []rune(strings.Repeat("·", barWidth))
+		lo, hi := min(center, col(pos)), max(center, col(pos))
+		for c := lo; c <= hi; c++ {
+			bar[c] = '█'
+		}
+		bar[center] = '│'
+		targetText := "     -"
+		if target, ok := st.Targets[name]; ok {
+			bar[col(target)] = '◆'
+			targetText = fmt.Sprintf("%6.1f", target)
+		}
+		loadText := "    -"
+		if load, ok := st.Loads[name]; ok {
+			loadText = fmt.Sprintf("%5d", load)
+		}
+
+		row := fmt.Sprintf("%-*s%s %6.1f  raw %4d  target %s  load %s",
+			labelWidth, label, style.Render(string(bar)), pos, st.Raw[name], targetText, loadText)
+		if temp, ok := st.Temperatures[name]; ok {
+			row += fmt.Sprintf("  %3d°C", temp)
+		}
+		if trim, ok := trims[name]; ok {
+			row += fmt.Sprintf("  trim %+.1f", trim)
+		}
+		if slices.Contains(st.FollowerMissing, name) {
+			row += "  " + warnStyle.Render("follower servo not responding, retrying")
+		} else if fault, ok := st.Errors[name]; ok {
+			row += "  " + warnStyle.Render(fault.String())
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, "", statusStyle.Render("█ leader  ◆ follower target  load in 0.1% of max torque"))
+	for len(rows) < height {
+		rows = append(rows, "")
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(rows, "\n"))
+}
+
 func (c *TeleoperateCommand) Execute(args []string) error {
-	// Load config
-	cfg, err := robot.LoadConfig()
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "No configuration found. Run 'lerobot setup' first.")
+	useKeyboard := c.Input == "keyboard"
+	if useKeyboard && c.NoTUI {
+		fmt.Fprintln(os.Stderr, "--input keyboard needs the TUI to read keys")
 		os.Exit(1)
 	}
-
-	// Check ports are configured
-	if cfg.Leader.Port == "" || cfg.Follower.Port == "" {
-		fmt.Fprintln(os.Stderr, "Arms not configured. Run 'lerobot setup' first.")
+	if c.Input == "-" && !c.NoTUI {
+		fmt.Fprintln(os.Stderr, "--input - needs --no-tui, the TUI reads keys from stdin")
 		os.Exit(1)
 	}
-
-	// Check calibration
-	if !cfg.Leader.IsCalibrated() || !cfg.Follower.IsCalibrated() {
-		fmt.Fprintln(os.Stderr, "Arms not calibrated. Run 'lerobot setup' first.")
+	if c.TrimStep <= 0 {
+		fmt.Fprintln(os.Stderr, "--trim-step must be positive")
 		os.Exit(1)
 	}
+	var cfg *robot.Config
+	if c.Input != "leader" {
+		cfg = loadFollowerConfig()
+	} else {
+		cfg = loadTeleopConfig(c.Sim != "")
+	}
+	logger, logLevel, closeLog := openLogger()
+	defer closeLog()
+
+	restPose := parkPose(cfg, c.Park)
+	motors := cfg.Leader.Calibration.Motors()
+	var keyboard *teleop.Keyboard
+	var input teleop.Input
+	switch c.Input {
+	case "leader":
+	case "keyboard":
+		motors = cfg.Follower.Calibration.Motors()
+		keyboard = teleop.NewKeyboard(motors, c.Step)
+		input = keyboard
+	case "hand", "vr":
+		motors = cfg.Follower.Calibration.Motors()
+		listen, what := teleop.ListenHand, "hand tracker"
+		if c.Input == "vr" {
+			listen, what = teleop.ListenVR, "VR controller"
+		}
+		hand, err := listen(c.HandListen, teleop.HandConfig{Follower: cfg.Follower, Kinematics: cfg.Kinematics, Scale: c.HandScale})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Waiting for a %s on %s\n", what, hand.Addr())
+		input = hand
+	case "phone":
+		motors = cfg.Follower.Calibration.Motors()
+		phone, err := teleop.ListenPhone(c.PhoneListen, teleop.PhoneConfig{Follower: cfg.Follower, Gain: c.PhoneGain})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Open on your phone, and accept the self-signed certificate:")
+		for _, host := range lanHosts() {
+			fmt.Println("  " + phone.URL(host))
+		}
+		input = phone
+	default:
+		motors = cfg.Follower.Calibration.Motors()
+		input = openStream(c.Input)
+	}
+	// Per-motor deadbands from config override the command line default
+	deadband := make(map[robot.MotorName]float64)
+	for _, name := range motors {
+		deadband[name] = c.Deadband
+	}
+	for name, db := range cfg.Deadband {
+		deadband[name] = db
+	}
 
-	fmt.Printf("Loaded configuration from %s\n", robot.DefaultConfigFile)
+	var trace io.Writer
+	if c.Trace != "" {
+		f, err := os.Create(c.Trace)
+		if err != nil {
+			log.Fatalf("Failed to create trace file: %v", err)
+		}
+		defer f.Close()
+		trace = f
+	}
+
+	hz := cmp.Or(c.Hz, cfg.Teleop.Hz, 60)
+	var rec *episodeRecorder
+	if c.Record != "" {
+		if c.NoTUI {
+			fmt.Fprintln(os.Stderr, "--record needs the TUI for its hotkeys; use 'lerobot record' instead")
+			os.Exit(1)
+		}
+		ds, err := dataset.Create(c.Record, hz, motors)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating dataset: %v\n", err)
+			os.Exit(1)
+		}
+		cams, err := openCameras(cfg.Cameras, hz, ds)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening cameras: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			for _, g := range cams {
+				g.Close()
+			}
+		}()
+		rec = &episodeRecorder{ds: ds, cams: cams, episodeTime: c.EpisodeTime, resetTime: c.ResetTime, task: c.Task}
+	}
+
+	var telemetryHz float64
+	if c.Telemetry {
+		telemetryHz = 1
+	}
+
+	// The episode being recorded is saved once the loop stopped
+	var flush func(context.Context) error
+	if rec != nil {
+		flush = func(context.Context) error { return rec.close() }
+	}
+
+	// A press of the leader trigger is handled by the TUI like a key, except
+	// for the tool action, which the controller runs
+	trigger := cfg.Teleop.Trigger
+	var triggers <-chan struct{}
+	var onTrigger func(bool)
+	if trigger != nil && (trigger.Action == robot.TriggerRecord || trigger.Action == robot.TriggerPause) && !c.NoTUI {
+		if trigger.Action == robot.TriggerRecord && rec == nil {
+			fmt.Fprintln(os.Stderr, "The trigger's record action needs --record")
+			os.Exit(1)
+		}
+		onTrigger, triggers = triggerPresses()
+	}
 
 	// Create controller
 	ctrl, err := teleop.NewController(teleop.Config{
-		LeaderPort:          cfg.Leader.Port,
-		LeaderCalibration:   cfg.Leader.Calibration,
-		FollowerPort:        cfg.Follower.Port,
-		FollowerCalibration: cfg.Follower.Calibration,
-		Hz:                  c.Hz,
-		Mirror:              c.Mirror,
+		Leader:       cfg.Leader,
+		Input:        input,
+		Follower:     cfg.Follower,
+		Hz:           hz,
+		ReadFollower: rec != nil,
+		Mirror:       flagOr(c.Mirror, cfg.Teleop.Mirror),
+		Smoothing:    flagOr(c.Smoothing, cfg.Teleop.Smoothing),
+		Mapping:      cfg.Mapping,
+		Deadband:     deadband,
+		GripForce:    c.GripForce,
+		SimAddr:      c.Sim,
+		Logger:       logger,
+		LogLevel:     logLevel,
+		Trace:        trace,
+		Overrun:      teleop.OverrunPolicy(c.Overrun),
+		ErrorBudget:  c.ErrorBudget,
+		Pipeline:     c.Pipeline,
+		RestPose:     restPose,
+		Relative:     c.Relative,
+		TelemetryHz:  telemetryHz,
+		SoftStart:    c.SoftStart,
+		MaxMismatch:  c.MaxMismatch,
+		Watchdog:     teleop.WatchdogConfig{Release: c.ReleaseAfter},
+		Workspace:    cfg.Workspace,
+		Kinematics:   cfg.Kinematics,
+		Trigger:      trigger,
+		Hooks:        teleop.Hooks{OnTrigger: onTrigger},
+		Flush:        flush,
 	})
 	if err != nil {
 		log.Fatalf("Failed to create controller: %v", err)
 	}
-	defer ctrl.Close()
+	defer closeController(ctrl)
+
+	if c.NoTUI {
+		return c.runHeadless(ctrl, restPose != nil)
+	}
 
 	// Start controller in background
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// SIGINT and SIGTERM quit the TUI like 'q', so the follower is parked
+	// and its torque disabled below. So does SIGHUP, when the terminal is
+	// closed.
+	model := initialTeleopModel(ctrl, motors)
+	model.keyboard = keyboard
+	model.trimStep = c.TrimStep
+	if triggers != nil {
+		model.trigger, model.triggers = trigger.Action, triggers
+	}
+	if rec != nil {
+		rec.ctrl = ctrl
+		rec.start()
+		model.rec = rec
+	}
+	if c.Duration > 0 {
+		model.deadline = time.Now().Add(c.Duration)
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if c.Duration > 0 {
+		time.AfterFunc(c.Duration, p.Quit)
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 	go func() {
+		<-hup
+		p.Quit()
+	}()
+
+	if rec != nil {
+		go rec.run(ctx, func(msg string, err error) { p.Send(recordedMsg{msg, err}) })
+	}
+
+	ctrlErr := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
 		if err := ctrl.Start(ctx); err != nil && err != context.Canceled {
-			log.Printf("Controller error: %v", err)
+			ctrlErr <- err
+			p.Quit()
 		}
 	}()
 
-	// Run TUI
-	p := tea.NewProgram(initialTeleopModel(ctrl), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		log.Fatalf("Error running program: %v", err)
+	// Run TUI. If it fails or panics, still stop the controller below, so
+	// the follower doesn't stay energized.
+	_, runErr := p.Run()
+
+	// Let the controller park and disable torque, then save the episode
+	// being recorded, before closing the arms
+	if restPose != nil {
+		fmt.Println("Moving to rest pose...")
+	}
+	closeController(ctrl)
+	<-done
+	if runErr != nil {
+		log.Fatalf("Error running program: %v", runErr)
+	}
+	saveTrims(cfg, ctrl.Trims(), c.SaveTrims)
+
+	if rec != nil {
+		if err := rec.failure(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error recording: %v\n", err)
+			fmt.Fprintf(os.Stderr, "%d episode(s) were saved to %s before\n", rec.saved(), c.Record)
+			os.Exit(1)
+		}
+		fmt.Println(successStyle.Render(fmt.Sprintf("Recorded %d episode(s) to %s", rec.saved(), c.Record)))
+	}
+
+	select {
+	case err := <-ctrlErr:
+		log.Fatalf("Controller error: %v", err)
+	default:
+	}
+	return nil
+}
+
+// runHeadless runs the controller without the TUI until interrupted,
+// streaming states as JSONL.
+func (c *TeleoperateCommand) runHeadless(ctrl *teleop.Controller, parking bool) error {
+	out, err := openOutput(c.Output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer out.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+	if c.Duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.Duration)
+		defer cancel()
 	}
 
+	ctrlErr := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Start returns ctx.Err() when interrupted or out of time
+		if err := ctrl.Start(ctx); err != nil && ctx.Err() == nil {
+			ctrlErr <- err
+			cancel()
+		}
+	}()
+
+	if err := runHeadless(ctx, ctrl, out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+
+	// Let the controller park and disable torque before closing the arms
+	if parking {
+		fmt.Fprintln(os.Stderr, "Moving to rest pose...")
+	}
+	cancel()
+	<-done
+	drainLogs(ctrl)
+
+	select {
+	case err := <-ctrlErr:
+		fmt.Fprintf(os.Stderr, "Controller error: %v\n", err)
+		os.Exit(1)
+	default:
+	}
 	return nil
 }
+
+// saveTrims folds the trims made in the TUI into the follower calibration
+// offsets and saves the configuration if save is set, or else lists them.
+func saveTrims(cfg *robot.Config, trims map[robot.MotorName]float64, save bool) {
+	if len(trims) == 0 {
+		return
+	}
+	if !save {
+		var list []string
+		for _, name := range cfg.Follower.Calibration.Motors() {
+			if trim, ok := trims[name]; ok {
+				list = append(list, fmt.Sprintf("%s %+.1f", name, trim))
+			}
+		}
+		fmt.Println(dimStyle.Render("Trims not saved (see --save-trims): " + strings.Join(list, ", ")))
+		return
+	}
+	// Reload the file, so the settings changed for this session by flags
+	// and the environment stay out of it
+	saved, err := robot.LoadConfigFrom(cfg.Path())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reloading config: %v\n", err)
+		os.Exit(1)
+	}
+	for name, trim := range trims {
+		if cal, ok := saved.Follower.Calibration[name]; ok {
+			saved.Follower.Calibration[name] = cal.Trimmed(trim)
+		}
+	}
+	if err := saved.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(successStyle.Render("Saved the trims to the follower calibration in " + cfg.Path()))
+}
+
+// closeTimeout bounds closing a controller, parking the follower included.
+const closeTimeout = 20 * time.Second
+
+// closeController stops ctrl and closes its arms, see
+// teleop.Controller.Close.
+func closeController(ctrl interface{ Close(context.Context) error }) error {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	return ctrl.Close(ctx)
+}
+
+// parkPose returns the rest pose to park in if park is set, exiting if none
+// is configured.
+func parkPose(cfg *robot.Config, park bool) map[robot.MotorName]float64 {
+	if !park {
+		return nil
+	}
+	if len(cfg.RestPose) == 0 {
+		fmt.Fprintln(os.Stderr, "No rest_pose in the configuration; see the README to add one.")
+		os.Exit(1)
+	}
+	return cfg.RestPose
+}
+
+// loadTeleopConfig loads the configuration and exits if the arms are not set
+// up. With sim set, the follower is simulated and only the leader is needed.
+func loadTeleopConfig(sim bool) *robot.Config {
+	cfg := loadConfig()
+
+	// Check ports are configured
+	if cfg.Leader.Port == "" || (!sim && cfg.Follower.Port == "") {
+		fmt.Fprintln(os.Stderr, "Arms not configured. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+
+	// Check calibration
+	if !cfg.Leader.IsCalibrated() || (!sim && !cfg.Follower.IsCalibrated()) {
+		fmt.Fprintln(os.Stderr, "Arms not calibrated. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Loaded configuration from %s\n", cfg.Path())
+
+	if cfg.ResolvePorts() {
+		fmt.Printf("Serial ports moved: leader on %s, follower on %s\n", cfg.Leader.Port, cfg.Follower.Port)
+	}
+	return cfg
+}
+
+// lanHosts returns every IPv4 address of this computer, to open a server
+// listening on all of them from another device.
+func lanHosts() []string {
+	var hosts []string
+	if ifaces, err := net.InterfaceAddrs(); err == nil {
+		for _, a := range ifaces {
+			if ip, ok := a.(*net.IPNet); ok && !ip.IP.IsLoopback() && ip.IP.To4() != nil {
+				hosts = append(hosts, ip.IP.String())
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = append(hosts, "localhost")
+	}
+	return hosts
+}
+
+// openStream opens a stream of positions from stdin for "-", or else from
+// the named pipe or file at path.
+func openStream(path string) *teleop.Stream {
+	if path == "-" {
+		return teleop.NewStream(os.Stdin)
+	}
+	// Opening a named pipe blocks until its writer opens it too
+	fmt.Printf("Waiting for positions on %s\n", path)
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening input: %v\n", err)
+		os.Exit(1)
+	}
+	return teleop.NewStream(f)
+}
+
+// loadFollowerConfig is loadTeleopConfig for when only the follower is
+// used, such as with --input other than the leader or record --puppet.
+func loadFollowerConfig() *robot.Config {
+	cfg := loadConfig()
+	if cfg.Follower.Port == "" || !cfg.Follower.IsCalibrated() {
+		fmt.Fprintln(os.Stderr, "Follower not set up. Run 'lerobot setup' first.")
+		os.Exit(1)
+	}
+	fmt.Printf("Loaded configuration from %s\n", cfg.Path())
+	if cfg.Follower.ResolvePort() {
+		fmt.Printf("Serial port moved: follower on %s\n", cfg.Follower.Port)
+	}
+	return cfg
+}
@@ -0,0 +1,47 @@
+package main
+
+import "context"
+
+// triggerPresses returns a teleop.Hooks.OnTrigger that sends every press
+// of the trigger on the returned channel. Presses while the last one is
+// still waiting to be handled are dropped.
+func triggerPresses() (func(pressed bool), <-chan struct{}) {
+	presses := make(chan struct{}, 1)
+	return func(pressed bool) {
+		if !pressed {
+			return
+		}
+		select {
+		case presses <- struct{}{}:
+		default: // the last press is still being handled
+		}
+	}, presses
+}
+
+// pauser is a teleop.Controller, for the pause action of the trigger.
+type pauser interface {
+	Paused() bool
+	Pause()
+	Resume()
+}
+
+// togglePause pauses or resumes the follower.
+func togglePause(ctrl pauser) {
+	if ctrl.Paused() {
+		ctrl.Resume()
+	} else {
+		ctrl.Pause()
+	}
+}
+
+// pauseOnPress toggles pausing on every press until ctx is done.
+func pauseOnPress(ctx context.Context, ctrl pauser, presses <-chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-presses:
+			togglePause(ctrl)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/canvas"
+	"github.com/NimbleMarkets/ntcharts/linechart"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gwillem/lerobot/pkg/camera"
+	"github.com/gwillem/lerobot/pkg/dataset"
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+type DatasetVizCommand struct {
+	Episode int    `long:"episode" short:"e" description:"Episode to show first"`
+	HTML    string `long:"html" description:"Write a self-contained HTML report with plots, statistics and videos to this file instead of opening the viewer"`
+	NoVideo bool   `long:"no-video" description:"With --html: leave the videos out to keep the report small"`
+	Args    struct {
+		Dataset string `positional-arg-name:"dataset" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+func (c *DatasetVizCommand) Execute(args []string) error {
+	ds := openDataset(c.Args.Dataset)
+	if len(ds.Episodes()) == 0 {
+		fmt.Fprintf(os.Stderr, "Dataset %s has no episodes\n", c.Args.Dataset)
+		os.Exit(1)
+	}
+	if c.Episode < 0 || c.Episode >= len(ds.Episodes()) {
+		fmt.Fprintf(os.Stderr, "Episode %d does not exist, the dataset has %d\n", c.Episode, len(ds.Episodes()))
+		os.Exit(1)
+	}
+
+	if c.HTML != "" {
+		if err := writeVizReport(c.HTML, ds, !c.NoVideo); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %s\n", c.HTML)
+		return nil
+	}
+
+	m := vizModel{
+		ds:       ds,
+		features: []string{dataset.FeatureAction, dataset.FeatureState},
+		hidden:   make(map[robot.MotorName]bool),
+	}
+	if ds.HasEffort() {
+		m.features = append(m.features, dataset.FeatureEffort)
+	}
+	m.load(c.Episode)
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running viewer: %v\n", err)
+		os.Exit(1)
+	}
+	return nil
+}
+
+// featureValues returns the values of feature in f, in dataset motor order.
+func featureValues(f dataset.Frame, feature string) []float64 {
+	switch feature {
+	case dataset.FeatureState:
+		return f.State
+	case dataset.FeatureEffort:
+		return f.Effort
+	}
+	return f.Action
+}
+
+// vizModel shows one episode at a time: the trajectory of every joint with
+// a cursor at the current frame, the values there, the camera image and the
+// episode's statistics.
+type vizModel struct {
+	ds       *dataset.Dataset
+	episode  int
+	frames   []dataset.Frame
+	err      error // loading the episode
+	cursor   int   // frame index
+	features []string
+	feature  int // index into features
+	hidden   map[robot.MotorName]bool
+	camera   int // index into the dataset's video keys
+
+	preview    []byte
+	previewKey vizFrameKey // of preview
+	previewErr error
+
+	width, height int
+}
+
+// vizFrameKey identifies a camera image.
+type vizFrameKey struct {
+	episode, camera, frame int
+}
+
+type vizPreviewMsg struct {
+	key vizFrameKey
+	rgb []byte
+	err error
+}
+
+// Camera images are decoded at this resolution, enough for the terminal.
+const vizPreviewWidth, vizPreviewHeight = 96, 72
+
+func (m *vizModel) load(episode int) {
+	m.episode = episode
+	m.frames, m.err = m.ds.ReadFrames(episode)
+	if m.err == nil && len(m.frames) == 0 {
+		m.err = fmt.Errorf("no frames")
+	}
+	m.cursor = 0
+}
+
+func (m vizModel) Init() tea.Cmd {
+	return m.loadPreview()
+}
+
+// loadPreview decodes the camera image at the cursor, unless it is shown
+// already or the dataset has no videos.
+func (m vizModel) loadPreview() tea.Cmd {
+	keys := m.ds.VideoKeys()
+	if len(keys) == 0 || len(m.frames) == 0 {
+		return nil
+	}
+	key := vizFrameKey{m.episode, m.camera, m.cursor}
+	if key == m.previewKey && (m.preview != nil || m.previewErr != nil) {
+		return nil
+	}
+	path := m.ds.VideoPath(keys[m.camera], m.episode)
+	at := m.frames[m.cursor].Timestamp
+	return func() tea.Msg {
+		rgb, err := camera.ReadVideoFrame(path, at, vizPreviewWidth, vizPreviewHeight)
+		return vizPreviewMsg{key: key, rgb: rgb, err: err}
+	}
+}
+
+func (m vizModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case vizPreviewMsg:
+		// Only the latest request is shown, older ones are stale
+		if msg.key == (vizFrameKey{m.episode, m.camera, m.cursor}) {
+			m.preview, m.previewKey, m.previewErr = msg.rgb, msg.key, msg.err
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		second := m.ds.Info().FPS
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "right", "l":
+			m.seek(m.cursor + 1)
+		case "left", "h":
+			m.seek(m.cursor - 1)
+		case "shift+right", "L", "pgdown":
+			m.seek(m.cursor + second)
+		case "shift+left", "H", "pgup":
+			m.seek(m.cursor - second)
+		case "home":
+			m.seek(0)
+		case "end":
+			m.seek(len(m.frames) - 1)
+		case "down", "n":
+			if m.episode < len(m.ds.Episodes())-1 {
+				m.load(m.episode + 1)
+			}
+		case "up", "p":
+			if m.episode > 0 {
+				m.load(m.episode - 1)
+			}
+		case "f":
+			m.feature = (m.feature + 1) % len(m.features)
+		case "tab":
+			if n := len(m.ds.VideoKeys()); n > 0 {
+				m.camera = (m.camera + 1) % n
+			}
+		case "a":
+			clear(m.hidden)
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if i := int(msg.String()[0] - '1'); i < len(m.ds.Motors()) {
+				name := m.ds.Motors()[i]
+				m.hidden[name] = !m.hidden[name]
+			}
+		}
+		return m, m.loadPreview()
+	}
+	return m, nil
+}
+
+func (m *vizModel) seek(frame int) {
+	m.cursor = max(0, min(len(m.frames)-1, frame))
+}
+
+func (m vizModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+	ep := m.ds.Episodes()[m.episode]
+	feature := m.features[m.feature]
+
+	var sb strings.Builder
+	title := fmt.Sprintf("%s - episode %d of %d", m.ds.Root(), m.episode, len(m.ds.Episodes()))
+	if len(ep.Tasks) > 0 {
+		title += ": " + strings.Join(ep.Tasks, ", ")
+	}
+	sb.WriteString(titleStyle.Render(title))
+	sb.WriteString("\n\n")
+	if m.err != nil {
+		sb.WriteString(warnStyle.Render(fmt.Sprintf("Error reading episode: %v", m.err)))
+		sb.WriteString("\n\n")
+		sb.WriteString(statusStyle.Render("up/down: episode, q: quit"))
+		return sb.String()
+	}
+
+	details := m.renderDetails(feature)
+	if len(m.ds.VideoKeys()) > 0 {
+		details = lipgloss.JoinHorizontal(lipgloss.Top, details, "  ", m.renderCamera(lipgloss.Height(details)))
+	}
+	w := max(m.width-borderSize-2, 40)
+	h := max(m.height-headerHeight-legendHeight-lipgloss.Height(details)-2-borderSize, 8)
+	sb.WriteString(chartStyle.Render(m.renderChart(feature, w, h)))
+	sb.WriteString("\n")
+	sb.WriteString(renderLegend(m.ds.Motors(), m.hidden))
+	sb.WriteString("\n\n")
+	sb.WriteString(details)
+	sb.WriteString("\n")
+	sb.WriteString(statusStyle.Render("left/right: frame (shift: 1s), up/down: episode, f: feature, 1-9/'a': traces, tab: camera, q: quit"))
+	return sb.String()
+}
+
+// renderChart plots feature over the whole episode with a cursor line at
+// the current frame.
+func (m vizModel) renderChart(feature string, w, h int) string {
+	end := m.frames[len(m.frames)-1].Timestamp
+	chart := linechart.New(w, h, 0, max(end, 1e-3), -100, 100, linechart.WithXYSteps(4, 2))
+	chart.DrawXYAxisAndLabel()
+	for i, name := range m.ds.Motors() {
+		if m.hidden[name] {
+			continue
+		}
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(motorColor(name, i)))
+		var prev canvas.Float64Point
+		for j, f := range m.frames {
+			v := featureValues(f, feature)
+			if i >= len(v) {
+				break
+			}
+			p := canvas.Float64Point{X: f.Timestamp, Y: max(-100, min(100, v[i]))}
+			if j > 0 {
+				chart.DrawBrailleLineWithStyle(prev, p, style)
+			}
+			prev = p
+		}
+	}
+	at := m.frames[m.cursor].Timestamp
+	chart.DrawRuneLineWithStyle(canvas.Float64Point{X: at, Y: -100}, canvas.Float64Point{X: at, Y: 100}, '│', pausedStyle)
+	return chart.View()
+}
+
+// renderDetails shows the frame at the cursor, per motor its value there
+// and its range over the episode, and the episode's quality checks.
+func (m vizModel) renderDetails(feature string) string {
+	ep := m.ds.Episodes()[m.episode]
+	f := m.frames[m.cursor]
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s  frame %d/%d  %.2fs of %s",
+		subHeaderStyle.Render(feature), m.cursor, len(m.frames)-1,
+		f.Timestamp, formatSeconds(m.ds.Duration(ep)))
+	sb.WriteString("\n")
+	sb.WriteString(dimStyle.Render(fmt.Sprintf("%-15s %7s %7s %7s %7s", "", "value", "min", "max", "mean")))
+	for i, name := range m.ds.Motors() {
+		lo, hi, sum := math.Inf(1), math.Inf(-1), 0.0
+		for _, fr := range m.frames {
+			v := featureValues(fr, feature)
+			if i < len(v) {
+				lo, hi, sum = min(lo, v[i]), max(hi, v[i]), sum+v[i]
+			}
+		}
+		var value float64
+		if v := featureValues(f, feature); i < len(v) {
+			value = v[i]
+		}
+		fmt.Fprintf(&sb, "\n%-15s %7.1f %7.1f %7.1f %7.1f", name, value, lo, hi, sum/float64(len(m.frames)))
+	}
+	sb.WriteString("\n")
+	switch issues := episodeIssues(ep); issues {
+	case "ok", "-":
+		sb.WriteString(dimStyle.Render("Quality: " + issues))
+	default:
+		sb.WriteString(warnStyle.Render("Quality: " + issues))
+	}
+	return sb.String()
+}
+
+// renderCamera shows the image of the current camera at the cursor in rows
+// terminal rows.
+func (m vizModel) renderCamera(rows int) string {
+	keys := m.ds.VideoKeys()
+	name := strings.TrimPrefix(keys[m.camera], dataset.FeatureImagePrefix)
+	if len(keys) > 1 {
+		name = fmt.Sprintf("%s (%d of %d)", name, m.camera+1, len(keys))
+	}
+	switch {
+	case m.previewErr != nil:
+		return dimStyle.Render(name) + "\n" + warnStyle.Render(m.previewErr.Error())
+	case m.preview == nil:
+		return dimStyle.Render(name) + "\n" + dimStyle.Render("Decoding...")
+	}
+	cols := (rows - 1) * 2 * vizPreviewWidth / vizPreviewHeight
+	return dimStyle.Render(name) + "\n" + renderPreview(m.preview, vizPreviewWidth, vizPreviewHeight, cols, rows-1)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gwillem/lerobot/pkg/dataset"
+)
+
+//go:embed viz.html
+var vizHTML string
+
+var vizTemplate = template.Must(template.New("viz").Parse(vizHTML))
+
+type vizReport struct {
+	Root     string
+	Info     dataset.Info
+	Episodes []vizReportEpisode
+}
+
+type vizReportEpisode struct {
+	Index    int
+	Task     string
+	Frames   int
+	Duration string
+	Quality  string
+	Plots    []vizReportPlot
+	Videos   []vizReportVideo
+}
+
+// vizReportPlot is a feature over an episode, drawn as an SVG of 1000 by
+// 200 units with a polyline per motor.
+type vizReportPlot struct {
+	Feature string
+	Lines   []vizReportLine
+	Stats   [][]string // per motor: name, min, max, mean, std
+}
+
+type vizReportLine struct {
+	Motor  string
+	Color  string
+	Points string
+}
+
+type vizReportVideo struct {
+	Name string
+	Src  template.URL
+}
+
+// writeVizReport writes an HTML page with every episode of ds to path. With
+// videos, the camera videos are embedded, so the page can be shared as one
+// file.
+func writeVizReport(path string, ds *dataset.Dataset, videos bool) error {
+	report := vizReport{Root: ds.Root(), Info: ds.Info()}
+	features := []string{dataset.FeatureAction, dataset.FeatureState}
+	if ds.HasEffort() {
+		features = append(features, dataset.FeatureEffort)
+	}
+	stats := ds.EpisodeStats()
+
+	for i, e := range ds.Episodes() {
+		frames, err := ds.ReadFrames(e.Index)
+		if err != nil {
+			return fmt.Errorf("read episode %d: %w", e.Index, err)
+		}
+		ep := vizReportEpisode{
+			Index:    e.Index,
+			Task:     strings.Join(e.Tasks, ", "),
+			Frames:   e.Length,
+			Duration: formatSeconds(ds.Duration(e)),
+			Quality:  episodeIssues(e),
+		}
+		for _, feature := range features {
+			plot := vizReportPlot{Feature: feature, Lines: plotLines(ds, frames, feature)}
+			if i < len(stats) {
+				if st, ok := stats[i].Stats[feature]; ok {
+					for j, m := range ds.Motors() {
+						plot.Stats = append(plot.Stats, []string{
+							string(m),
+							fmt.Sprintf("%.1f", st.Min[j]),
+							fmt.Sprintf("%.1f", st.Max[j]),
+							fmt.Sprintf("%.1f", st.Mean[j]),
+							fmt.Sprintf("%.1f", st.Std[j]),
+						})
+					}
+				}
+			}
+			ep.Plots = append(ep.Plots, plot)
+		}
+		if videos {
+			for _, key := range ds.VideoKeys() {
+				data, err := os.ReadFile(ds.VideoPath(key, e.Index))
+				if err != nil {
+					return err
+				}
+				ep.Videos = append(ep.Videos, vizReportVideo{
+					Name: strings.TrimPrefix(key, dataset.FeatureImagePrefix),
+					Src:  template.URL("data:video/mp4;base64," + base64.StdEncoding.EncodeToString(data)),
+				})
+			}
+		}
+		report.Episodes = append(report.Episodes, ep)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := vizTemplate.Execute(f, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// plotLines returns a polyline per motor of feature over frames, with time
+// from 0 to 1000 and positions from 100 at the top to -100 at the bottom.
+func plotLines(ds *dataset.Dataset, frames []dataset.Frame, feature string) []vizReportLine {
+	if len(frames) == 0 {
+		return nil
+	}
+	end := max(frames[len(frames)-1].Timestamp, 1e-3)
+	var lines []vizReportLine
+	for i, m := range ds.Motors() {
+		var sb strings.Builder
+		for _, f := range frames {
+			v := featureValues(f, feature)
+			if i >= len(v) {
+				break
+			}
+			x := f.Timestamp / end * 1000
+			y := 100 - max(-100, min(100, v[i]))
+			fmt.Fprintf(&sb, "%.1f,%.1f ", x, y)
+		}
+		if sb.Len() == 0 {
+			continue
+		}
+		lines = append(lines, vizReportLine{
+			Motor:  string(m),
+			Color:  xtermColor(motorColor(m, i)),
+			Points: sb.String(),
+		})
+	}
+	return lines
+}
+
+// xtermColor converts a color of the terminal's 256 color palette, as used
+// for the charts, to CSS.
+func xtermColor(code string) string {
+	n, _ := strconv.Atoi(code)
+	switch {
+	case n >= 232:
+		v := 8 + 10*(n-232)
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	case n >= 16:
+		level := func(c int) int {
+			if c == 0 {
+				return 0
+			}
+			return 55 + 40*c
+		}
+		n -= 16
+		return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+	}
+	return "#888888"
+}
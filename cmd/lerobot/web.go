@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type WebCommand struct {
+	ServeCommand
+	Camera []string `long:"camera" description:"Camera to stream and record, name=device[@WIDTHxHEIGHT][+LATENCY] (repeatable, default: cameras from the configuration)"`
+}
+
+func (c *WebCommand) Execute(args []string) error {
+	logger, logLevel, closeLog := openLogger()
+	defer closeLog()
+	srv := c.newServer(logger, logLevel)
+	defer srv.Close()
+
+	specs := c.Camera
+	if len(specs) == 0 {
+		specs = loadConfig().Cameras
+	}
+	cams, err := openCameras(specs, c.Hz, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening cameras: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		for _, g := range cams {
+			g.Close()
+		}
+	}()
+	srv.SetCameras(cams)
+
+	c.serve(srv, srv.Dashboard(), "dashboard")
+	return nil
+}
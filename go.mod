@@ -9,7 +9,12 @@ require (
 	github.com/charmbracelet/lipgloss v1.0.0
 	github.com/hipsterbrown/feetech-servo v0.4.2
 	github.com/jessevdk/go-flags v1.6.1
+	github.com/klauspost/compress v1.18.0
 	go.bug.st/serial v1.6.4
+	golang.org/x/net v0.42.0
+	google.golang.org/grpc v1.76.0
+	google.golang.org/protobuf v1.36.12
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -36,7 +41,8 @@ require (
 	github.com/muesli/cancelreader v0.2.2 // indirect
 	github.com/muesli/termenv v0.16.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	golang.org/x/sync v0.12.0 // indirect
+	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.37.0 // indirect
-	golang.org/x/text v0.23.0 // indirect
+	golang.org/x/text v0.27.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250804133106-a7a43d27e69b // indirect
 )
@@ -0,0 +1,198 @@
+// Package audio captures microphone audio for recording alongside episodes.
+//
+// Like camera capture, it runs an external ffmpeg process, which must be
+// installed and on PATH, and reads raw 16-bit PCM from its stdout.
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults suit speech-band sounds such as clicks and pours at a small size.
+const (
+	DefaultSampleRate = 16000
+	DefaultChannels   = 1
+)
+
+// chunkDuration is how much audio is read at a time, and so the resolution
+// of the capture timestamps.
+const chunkDuration = 10 * time.Millisecond
+
+// Config describes a microphone to open.
+type Config struct {
+	Name       string // feature name, e.g. "mic" for observation.audio.mic
+	Device     string // OS device, e.g. default or hw:1 (Linux), :0 (macOS)
+	SampleRate int
+	Channels   int
+}
+
+// ParseSpec parses an audio spec of the form name=device.
+func ParseSpec(spec string) (Config, error) {
+	name, device, ok := strings.Cut(spec, "=")
+	if !ok || name == "" || device == "" {
+		return Config{}, fmt.Errorf("invalid audio device %q, want name=device", spec)
+	}
+	return Config{Name: name, Device: device, SampleRate: DefaultSampleRate, Channels: DefaultChannels}, nil
+}
+
+// Capture reads audio continuously from a microphone. Samples are only kept
+// between Start and Stop, aligned so that the first sample returned by Stop
+// was captured at the time passed to Start.
+type Capture struct {
+	cfg    Config
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	done   chan struct{}
+
+	mu        sync.Mutex
+	recording bool
+	from      time.Time
+	samples   []int16 // interleaved, since from
+	err       error
+}
+
+// Open starts capturing from the configured device.
+func Open(cfg Config) (*Capture, error) {
+	if cfg.SampleRate <= 0 {
+		cfg.SampleRate = DefaultSampleRate
+	}
+	if cfg.Channels <= 0 {
+		cfg.Channels = DefaultChannels
+	}
+
+	args := []string{"-hide_banner", "-loglevel", "error"}
+	args = append(args, inputArgs(cfg.Device)...)
+	args = append(args,
+		"-ac", strconv.Itoa(cfg.Channels),
+		"-ar", strconv.Itoa(cfg.SampleRate),
+		"-f", "s16le",
+		"-",
+	)
+	cmd := exec.Command("ffmpeg", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start ffmpeg: %w", err)
+	}
+
+	c := &Capture{cfg: cfg, cmd: cmd, stdout: stdout, done: make(chan struct{})}
+	go c.run()
+	return c, nil
+}
+
+// inputArgs returns the ffmpeg input options for the device on this OS.
+func inputArgs(device string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"-f", "avfoundation", "-i", device}
+	case "windows":
+		return []string{"-f", "dshow", "-i", "audio=" + device}
+	default:
+		return []string{"-f", "alsa", "-i", device}
+	}
+}
+
+func (c *Capture) run() {
+	defer close(c.done)
+	frames := int(time.Duration(c.cfg.SampleRate) * chunkDuration / time.Second)
+	buf := make([]byte, frames*c.cfg.Channels*2)
+	for {
+		if _, err := io.ReadFull(c.stdout, buf); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				err = fmt.Errorf("audio %s: stream ended", c.cfg.Name)
+			}
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+			return
+		}
+		end := time.Now()
+
+		c.mu.Lock()
+		if c.recording {
+			chunk := decodePCM(buf)
+			if len(c.samples) == 0 {
+				chunk = alignStart(c.from, end, chunk, c.cfg.SampleRate, c.cfg.Channels)
+			}
+			c.samples = append(c.samples, chunk...)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// Name returns the feature name of the microphone.
+func (c *Capture) Name() string { return c.cfg.Name }
+
+// SampleRate returns the sample rate in Hz.
+func (c *Capture) SampleRate() int { return c.cfg.SampleRate }
+
+// Channels returns the number of channels.
+func (c *Capture) Channels() int { return c.cfg.Channels }
+
+// Start keeps the audio captured from t on, e.g. the timestamp of an
+// episode's first frame. Audio already discarded before Start is padded
+// with silence.
+func (c *Capture) Start(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recording, c.from, c.samples = true, t, nil
+}
+
+// Stop returns the interleaved samples captured since Start.
+func (c *Capture) Stop() []int16 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	samples := c.samples
+	c.recording, c.samples = false, nil
+	return samples
+}
+
+// Err returns the error that stopped capturing, if any.
+func (c *Capture) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close stops ffmpeg.
+func (c *Capture) Close() error {
+	c.cmd.Process.Kill()
+	c.stdout.Close()
+	c.cmd.Wait()
+	<-c.done
+	return nil
+}
+
+// alignStart trims or pads the first chunk, which ended at end, so that it
+// starts at from.
+func alignStart(from, end time.Time, chunk []int16, rate, channels int) []int16 {
+	frames := len(chunk) / channels
+	start := end.Add(-time.Duration(frames) * time.Second / time.Duration(rate))
+	offset := int(start.Sub(from) * time.Duration(rate) / time.Second)
+	switch {
+	case offset < 0:
+		skip := min(-offset, frames)
+		return chunk[skip*channels:]
+	case offset > 0:
+		return append(make([]int16, offset*channels), chunk...)
+	}
+	return chunk
+}
+
+func decodePCM(b []byte) []int16 {
+	samples := make([]int16, len(b)/2)
+	for i := range samples {
+		samples[i] = int16(uint16(b[2*i]) | uint16(b[2*i+1])<<8)
+	}
+	return samples
+}
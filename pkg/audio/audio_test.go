@@ -0,0 +1,42 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAlignStart(t *testing.T) {
+	from := time.Unix(100, 0)
+	chunk := make([]int16, 160) // 10ms at 16 kHz mono
+	for i := range chunk {
+		chunk[i] = int16(i + 1)
+	}
+
+	// Chunk started 5ms before the episode: drop its first 80 samples
+	got := alignStart(from, from.Add(5*time.Millisecond), chunk, 16000, 1)
+	if len(got) != 80 || got[0] != 81 {
+		t.Errorf("early chunk: %d samples starting with %d, want 80 starting with 81", len(got), got[0])
+	}
+
+	// Chunk started 5ms after the episode: pad 80 samples of silence
+	got = alignStart(from, from.Add(15*time.Millisecond), chunk, 16000, 1)
+	if len(got) != 240 || got[79] != 0 || got[80] != 1 {
+		t.Errorf("late chunk: %d samples, want 240 with chunk from 80", len(got))
+	}
+}
+
+func TestWriteWAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a", "episode.wav")
+	if err := WriteWAV(path, 16000, 2, []int16{1, -1, 2, -2}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 44+8 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" || data[22] != 2 {
+		t.Errorf("unexpected WAV header % x", data[:44])
+	}
+}
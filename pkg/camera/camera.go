@@ -0,0 +1,181 @@
+// Package camera captures frames from cameras and encodes them to video.
+//
+// Capture and encoding are done by an external ffmpeg process, which must be
+// installed and on PATH.
+package camera
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/timesync"
+)
+
+// Frame is a single RGB24 image.
+type Frame struct {
+	Data        []byte        // width*height*3 bytes, RGB24
+	Depth       []uint16      // width*height, aligned to Data, nil unless from a DepthCamera
+	Timestamp   time.Time     // arrival, less the camera's Config.Latency
+	Uncertainty time.Duration // of Timestamp either way, see Grabber
+}
+
+// Stamp returns when the frame arrived, less the configured latency of its
+// camera, on the timeline fitted by Grabber.
+func (f Frame) Stamp() timesync.Stamp {
+	return timesync.Stamp{At: f.Timestamp, Uncertainty: f.Uncertainty}
+}
+
+// Camera is a source of frames.
+type Camera interface {
+	Name() string
+	Width() int
+	Height() int
+	// FPS returns the rate frames are delivered at, 0 while unknown.
+	FPS() int
+
+	// Read blocks until the next frame is available.
+	Read(ctx context.Context) (Frame, error)
+	Close() error
+}
+
+// Config describes a camera to open.
+type Config struct {
+	Name   string // feature name, e.g. "front" for observation.images.front
+	Device string // OS device, e.g. /dev/video0, a URL, or realsense[:SERIAL]
+	Width  int
+	Height int
+	FPS    int
+
+	// Latency is how long a frame takes from exposure until ffmpeg hands
+	// it over, taken off its arrival time. It depends on the camera and
+	// its driver, typically 30 to 100ms for USB cameras, and is 0 unless
+	// measured, e.g. by filming a clock on screen.
+	Latency time.Duration
+}
+
+// ParseSpec parses a camera spec of the form
+// name=device[@WIDTHxHEIGHT][+LATENCY], e.g. front=/dev/video0@640x480+60ms.
+func ParseSpec(spec string) (Config, error) {
+	name, device, ok := strings.Cut(spec, "=")
+	if !ok || name == "" || device == "" {
+		return Config{}, fmt.Errorf("invalid camera %q, want name=device[@WIDTHxHEIGHT][+LATENCY]", spec)
+	}
+	cfg := Config{Name: name, Device: device, Width: 640, Height: 480}
+
+	if i := strings.LastIndex(device, "+"); i > 0 {
+		if latency, err := time.ParseDuration(device[i+1:]); err == nil {
+			if latency < 0 {
+				return Config{}, fmt.Errorf("invalid camera %q: negative latency", spec)
+			}
+			device = device[:i]
+			cfg.Device = device
+			cfg.Latency = latency
+		}
+	}
+
+	if i := strings.LastIndex(device, "@"); i > 0 {
+		w, h, ok := strings.Cut(device[i+1:], "x")
+		width, errW := strconv.Atoi(w)
+		height, errH := strconv.Atoi(h)
+		if ok && errW == nil && errH == nil {
+			cfg.Device = device[:i]
+			cfg.Width = width
+			cfg.Height = height
+		}
+	}
+	return cfg, nil
+}
+
+// Open opens the camera described by cfg: a RealSense for realsense devices,
+// a reconnecting stream for URLs, otherwise ffmpeg.
+func Open(cfg Config) (Camera, error) {
+	if _, ok := isRealSense(cfg.Device); ok {
+		return OpenRealSense(cfg)
+	}
+	if isNetwork(cfg.Device) {
+		return OpenNetwork(cfg)
+	}
+	return OpenFFmpeg(cfg)
+}
+
+// Grabber continuously reads frames from a camera in the background and keeps
+// the most recent one, so consumers running at a different rate always get
+// a fresh frame without blocking.
+//
+// Frames arrive with a delay that varies from frame to frame, and the
+// camera's clock drifts from ours, so the grabber fits a timeline through
+// their arrival times and stamps each frame on it, see timesync.Source.
+type Grabber struct {
+	cam    Camera
+	timing *timesync.Source
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	latest Frame
+	err    error
+}
+
+// NewGrabber starts reading from cam.
+func NewGrabber(cam Camera) *Grabber {
+	ctx, cancel := context.WithCancel(context.Background())
+	var period time.Duration
+	if fps := cam.FPS(); fps > 0 {
+		period = time.Second / time.Duration(fps)
+	}
+	g := &Grabber{cam: cam, timing: timesync.NewSource(period), cancel: cancel, done: make(chan struct{})}
+	go g.run(ctx)
+	return g
+}
+
+func (g *Grabber) run(ctx context.Context) {
+	defer close(g.done)
+	for ctx.Err() == nil {
+		frame, err := g.cam.Read(ctx)
+		if err == nil {
+			stamp := g.timing.Add(frame.Timestamp)
+			frame.Timestamp, frame.Uncertainty = stamp.At, stamp.Uncertainty
+		}
+		g.mu.Lock()
+		if err != nil {
+			g.err = err
+			g.mu.Unlock()
+			return
+		}
+		g.latest = frame
+		g.mu.Unlock()
+	}
+}
+
+// Camera returns the underlying camera.
+func (g *Grabber) Camera() Camera { return g.cam }
+
+// Timing returns the estimated frame timing of the camera.
+func (g *Grabber) Timing() timesync.Estimate { return g.timing.Estimate() }
+
+// Latest returns the most recent frame. It returns false if no frame has
+// been captured yet.
+func (g *Grabber) Latest() (Frame, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.latest, g.latest.Data != nil
+}
+
+// Err returns the error that stopped capturing, if any.
+func (g *Grabber) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// Close stops capturing and closes the camera.
+func (g *Grabber) Close() error {
+	g.cancel()
+	err := g.cam.Close()
+	<-g.done
+	return err
+}
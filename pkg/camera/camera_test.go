@@ -0,0 +1,26 @@
+package camera
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSpec(t *testing.T) {
+	for spec, want := range map[string]Config{
+		"front=/dev/video0":                  {Name: "front", Device: "/dev/video0", Width: 640, Height: 480},
+		"wrist=/dev/video2@320x240":          {Name: "wrist", Device: "/dev/video2", Width: 320, Height: 240},
+		"wrist=/dev/video2@320x240+60ms":     {Name: "wrist", Device: "/dev/video2", Width: 320, Height: 240, Latency: 60 * time.Millisecond},
+		"top=rtsp://cam/live+0.1s":           {Name: "top", Device: "rtsp://cam/live", Width: 640, Height: 480, Latency: 100 * time.Millisecond},
+		"top=http://cam/video?a=b+c@640x360": {Name: "top", Device: "http://cam/video?a=b+c", Width: 640, Height: 360},
+	} {
+		got, err := ParseSpec(spec)
+		if err != nil || got != want {
+			t.Errorf("ParseSpec(%q) = %+v, %v, want %+v", spec, got, err, want)
+		}
+	}
+	for _, spec := range []string{"front", "=/dev/video0", "front=/dev/video0+-5ms"} {
+		if _, err := ParseSpec(spec); err == nil {
+			t.Errorf("ParseSpec(%q) succeeded", spec)
+		}
+	}
+}
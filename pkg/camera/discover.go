@@ -0,0 +1,257 @@
+package camera
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// discoverTimeout bounds each ffmpeg query while discovering cameras.
+const discoverTimeout = 5 * time.Second
+
+// Device is a camera found by Discover.
+type Device struct {
+	Index  int
+	Name   string // as reported by the OS, e.g. "HD USB Camera"
+	Device string // for a camera spec, e.g. /dev/video0 (Linux), 0 (macOS)
+	Modes  []Mode
+}
+
+// Mode is a resolution a camera supports.
+type Mode struct {
+	Width, Height int
+	FPS           float64 // highest frame rate, 0 if unknown
+}
+
+func (m Mode) String() string {
+	if m.FPS == 0 {
+		return fmt.Sprintf("%dx%d", m.Width, m.Height)
+	}
+	return fmt.Sprintf("%dx%d %gfps", m.Width, m.Height, m.FPS)
+}
+
+// Discover lists the cameras connected to this computer and their modes,
+// by asking ffmpeg. Network cameras and RealSense depth are not listed.
+func Discover() ([]Device, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return discoverAVFoundation()
+	case "windows":
+		return discoverDShow()
+	default:
+		return discoverV4L2()
+	}
+}
+
+// ffmpegOutput runs ffmpeg and returns what it logged. Listing devices and
+// modes makes ffmpeg fail, so its exit status is ignored.
+func ffmpegOutput(args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), discoverTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ffmpeg", append([]string{"-hide_banner"}, args...)...).CombinedOutput()
+	if _, ok := err.(*exec.ExitError); ok || err == nil {
+		return string(out), nil
+	}
+	return "", fmt.Errorf("run ffmpeg: %w", err)
+}
+
+// discoverV4L2 lists /dev/video* devices with capture formats. UVC cameras
+// also have a metadata node without any, which is skipped.
+func discoverV4L2() ([]Device, error) {
+	paths, err := filepath.Glob("/dev/video*")
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(paths, func(a, b string) int { return videoIndex(a) - videoIndex(b) })
+
+	var devices []Device
+	for _, path := range paths {
+		out, err := ffmpegOutput("-f", "v4l2", "-list_formats", "all", "-i", path)
+		if err != nil {
+			return nil, err
+		}
+		modes := parseV4L2Formats(out)
+		if len(modes) == 0 {
+			continue
+		}
+		name := filepath.Base(path)
+		if b, err := os.ReadFile(filepath.Join("/sys/class/video4linux", name, "name")); err == nil {
+			name = strings.TrimSpace(string(b))
+		}
+		devices = append(devices, Device{Index: videoIndex(path), Name: name, Device: path, Modes: modes})
+	}
+	return devices, nil
+}
+
+func videoIndex(path string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(path, "/dev/video"))
+	return n
+}
+
+// sizePattern matches a resolution such as 1280x720.
+var sizePattern = regexp.MustCompile(`\b(\d+)x(\d+)\b`)
+
+// parseV4L2Formats parses the output of ffmpeg -list_formats all, e.g.
+// "[video4linux2,v4l2 @ 0x1] Compressed: mjpeg : Motion-JPEG : 640x480 1280x720".
+// The v4l2 input does not report frame rates.
+func parseV4L2Formats(out string) []Mode {
+	var modes []Mode
+	for line := range strings.Lines(out) {
+		if !strings.Contains(line, "Raw") && !strings.Contains(line, "Compressed") {
+			continue
+		}
+		_, sizes, ok := cutLast(line, ":")
+		if !ok {
+			continue
+		}
+		for _, m := range sizePattern.FindAllStringSubmatch(sizes, -1) {
+			modes = addMode(modes, atoi(m[1]), atoi(m[2]), 0)
+		}
+	}
+	return sortModes(modes)
+}
+
+func discoverAVFoundation() ([]Device, error) {
+	out, err := ffmpegOutput("-f", "avfoundation", "-list_devices", "true", "-i", "")
+	if err != nil {
+		return nil, err
+	}
+	devices := parseAVFoundationDevices(out)
+	for i, d := range devices {
+		// An impossible size makes avfoundation list the supported modes
+		out, err := ffmpegOutput("-f", "avfoundation", "-video_size", "1x1", "-i", d.Device)
+		if err != nil {
+			return nil, err
+		}
+		devices[i].Modes = parseAVFoundationModes(out)
+	}
+	return devices, nil
+}
+
+// avfDevicePattern matches a device line such as "[0] FaceTime HD Camera".
+var avfDevicePattern = regexp.MustCompile(`\] \[(\d+)\] (.+)$`)
+
+// parseAVFoundationDevices parses the video devices listed by
+// ffmpeg -f avfoundation -list_devices true, skipping screen capture.
+func parseAVFoundationDevices(out string) []Device {
+	var devices []Device
+	video := false
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.Contains(line, "video devices:"):
+			video = true
+			continue
+		case strings.Contains(line, "audio devices:"):
+			video = false
+			continue
+		}
+		m := avfDevicePattern.FindStringSubmatch(line)
+		if !video || m == nil || strings.HasPrefix(m[2], "Capture screen") {
+			continue
+		}
+		devices = append(devices, Device{Index: atoi(m[1]), Name: m[2], Device: m[1]})
+	}
+	return devices
+}
+
+// avfModePattern matches a supported mode such as "640x480@[1.000000 30.000000]fps".
+var avfModePattern = regexp.MustCompile(`(\d+)x(\d+)@\[([\d.]+) ([\d.]+)\]fps`)
+
+// parseAVFoundationModes parses the supported modes avfoundation lists when
+// opened with an unsupported size.
+func parseAVFoundationModes(out string) []Mode {
+	var modes []Mode
+	for _, m := range avfModePattern.FindAllStringSubmatch(out, -1) {
+		fps, _ := strconv.ParseFloat(m[4], 64)
+		modes = addMode(modes, atoi(m[1]), atoi(m[2]), fps)
+	}
+	return sortModes(modes)
+}
+
+func discoverDShow() ([]Device, error) {
+	out, err := ffmpegOutput("-f", "dshow", "-list_devices", "true", "-i", "dummy")
+	if err != nil {
+		return nil, err
+	}
+	devices := parseDShowDevices(out)
+	for i, d := range devices {
+		out, err := ffmpegOutput("-f", "dshow", "-list_options", "true", "-i", "video="+d.Device)
+		if err != nil {
+			return nil, err
+		}
+		devices[i].Modes = parseDShowOptions(out)
+	}
+	return devices, nil
+}
+
+// dshowDevicePattern matches a device line such as `"Integrated Camera" (video)`.
+var dshowDevicePattern = regexp.MustCompile(`"([^"]+)" \(video\)`)
+
+// parseDShowDevices parses the video devices listed by
+// ffmpeg -f dshow -list_devices true.
+func parseDShowDevices(out string) []Device {
+	var devices []Device
+	for line := range strings.Lines(out) {
+		if m := dshowDevicePattern.FindStringSubmatch(line); m != nil {
+			devices = append(devices, Device{Index: len(devices), Name: m[1], Device: m[1]})
+		}
+	}
+	return devices
+}
+
+// dshowOptionPattern matches the largest mode of an option line such as
+// "vcodec=mjpeg  min s=1280x720 fps=5 max s=1280x720 fps=30".
+var dshowOptionPattern = regexp.MustCompile(`max s=(\d+)x(\d+) fps=([\d.]+)`)
+
+// parseDShowOptions parses the options listed by ffmpeg -f dshow
+// -list_options true.
+func parseDShowOptions(out string) []Mode {
+	var modes []Mode
+	for _, m := range dshowOptionPattern.FindAllStringSubmatch(out, -1) {
+		fps, _ := strconv.ParseFloat(m[3], 64)
+		modes = addMode(modes, atoi(m[1]), atoi(m[2]), fps)
+	}
+	return sortModes(modes)
+}
+
+// addMode adds a resolution, or raises the frame rate of one already listed
+// in another pixel format.
+func addMode(modes []Mode, width, height int, fps float64) []Mode {
+	for i, m := range modes {
+		if m.Width == width && m.Height == height {
+			modes[i].FPS = max(m.FPS, fps)
+			return modes
+		}
+	}
+	return append(modes, Mode{Width: width, Height: height, FPS: fps})
+}
+
+// sortModes sorts modes from the largest resolution down.
+func sortModes(modes []Mode) []Mode {
+	slices.SortFunc(modes, func(a, b Mode) int {
+		return b.Width*b.Height - a.Width*a.Height
+	})
+	return modes
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
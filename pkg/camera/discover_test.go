@@ -0,0 +1,58 @@
+package camera
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseV4L2Formats(t *testing.T) {
+	out := `[video4linux2,v4l2 @ 0x5581] Compressed:       mjpeg :          Motion-JPEG : 1280x720 640x480 320x240
+[video4linux2,v4l2 @ 0x5581] Raw       :     yuyv422 :           YUYV 4:2:2 : 640x480 320x240
+/dev/video0: Immediate exit requested
+`
+	want := []Mode{{Width: 1280, Height: 720}, {Width: 640, Height: 480}, {Width: 320, Height: 240}}
+	if got := parseV4L2Formats(out); !slices.Equal(got, want) {
+		t.Errorf("parseV4L2Formats() = %v, want %v", got, want)
+	}
+}
+
+func TestParseAVFoundation(t *testing.T) {
+	devices := parseAVFoundationDevices(`[AVFoundation indev @ 0x7f8] AVFoundation video devices:
+[AVFoundation indev @ 0x7f8] [0] FaceTime HD Camera
+[AVFoundation indev @ 0x7f8] [1] Capture screen 0
+[AVFoundation indev @ 0x7f8] AVFoundation audio devices:
+[AVFoundation indev @ 0x7f8] [0] MacBook Pro Microphone
+`)
+	if len(devices) != 1 || devices[0].Device != "0" || devices[0].Name != "FaceTime HD Camera" {
+		t.Errorf("parseAVFoundationDevices() = %+v", devices)
+	}
+
+	modes := parseAVFoundationModes(`[avfoundation @ 0x7f8] Selected video size (1x1) is not supported by the device.
+[avfoundation @ 0x7f8] Supported modes:
+[avfoundation @ 0x7f8]   640x480@[1.000000 30.000000]fps
+[avfoundation @ 0x7f8]   1280x720@[1.000000 30.000000]fps
+[avfoundation @ 0x7f8]   1280x720@[1.000000 60.000000]fps
+`)
+	want := []Mode{{Width: 1280, Height: 720, FPS: 60}, {Width: 640, Height: 480, FPS: 30}}
+	if !slices.Equal(modes, want) {
+		t.Errorf("parseAVFoundationModes() = %v, want %v", modes, want)
+	}
+}
+
+func TestParseDShow(t *testing.T) {
+	devices := parseDShowDevices(`[dshow @ 000001] "Integrated Camera" (video)
+[dshow @ 000001]   Alternative name "@device_pnp_\\?\usb#vid_04f2"
+[dshow @ 000001] "Microphone Array" (audio)
+`)
+	if len(devices) != 1 || devices[0].Device != "Integrated Camera" {
+		t.Errorf("parseDShowDevices() = %+v", devices)
+	}
+
+	modes := parseDShowOptions(`[dshow @ 000001]   vcodec=mjpeg  min s=1280x720 fps=30 max s=1280x720 fps=30
+[dshow @ 000001]   pixel_format=yuyv422  min s=640x480 fps=30 max s=640x480 fps=30
+`)
+	want := []Mode{{Width: 1280, Height: 720, FPS: 30}, {Width: 640, Height: 480, FPS: 30}}
+	if !slices.Equal(modes, want) {
+		t.Errorf("parseDShowOptions() = %v, want %v", modes, want)
+	}
+}
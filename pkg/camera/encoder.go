@@ -0,0 +1,114 @@
+package camera
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+// VideoWriter encodes RGB24 frames to a video file using ffmpeg.
+type VideoWriter struct {
+	path  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	size  int
+}
+
+// Codec settings matching the LeRobot v2 defaults.
+const (
+	DefaultCodec  = "libx264"
+	DefaultPixFmt = "yuv420p"
+)
+
+// NewVideoWriter starts an ffmpeg process that encodes frames of the given
+// size and rate to path.
+func NewVideoWriter(path string, width, height, fps int, codec string) (*VideoWriter, error) {
+	if codec == "" {
+		codec = DefaultCodec
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("ffmpeg",
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "rawvideo",
+		"-pix_fmt", "rgb24",
+		"-s", fmt.Sprintf("%dx%d", width, height),
+		"-r", strconv.Itoa(fps),
+		"-i", "-",
+		"-c:v", codec,
+		"-pix_fmt", DefaultPixFmt,
+		"-g", "2",
+		path,
+	)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start ffmpeg: %w", err)
+	}
+
+	return &VideoWriter{
+		path:  path,
+		cmd:   cmd,
+		stdin: stdin,
+		size:  width * height * 3,
+	}, nil
+}
+
+// Path returns the output file path.
+func (w *VideoWriter) Path() string { return w.path }
+
+// WriteFrame encodes one RGB24 frame.
+func (w *VideoWriter) WriteFrame(data []byte) error {
+	if len(data) != w.size {
+		return fmt.Errorf("frame is %d bytes, want %d", len(data), w.size)
+	}
+	_, err := w.stdin.Write(data)
+	return err
+}
+
+// Close finishes encoding and waits for ffmpeg to exit.
+func (w *VideoWriter) Close() error {
+	w.stdin.Close()
+	if err := w.cmd.Wait(); err != nil {
+		return fmt.Errorf("encode %s: %w", w.path, err)
+	}
+	return nil
+}
+
+// Abort stops encoding and removes the partial file.
+func (w *VideoWriter) Abort() {
+	w.stdin.Close()
+	w.cmd.Process.Kill()
+	w.cmd.Wait()
+	os.Remove(w.path)
+}
+
+// ReadVideoFrame decodes the frame shown at seconds into the video at path,
+// scaled to width x height, as RGB24 using ffmpeg.
+func ReadVideoFrame(path string, seconds float64, width, height int) ([]byte, error) {
+	cmd := exec.Command("ffmpeg",
+		"-hide_banner", "-loglevel", "error",
+		"-ss", strconv.FormatFloat(seconds, 'f', 3, 64),
+		"-i", path,
+		"-frames:v", "1",
+		"-f", "rawvideo",
+		"-pix_fmt", "rgb24",
+		"-s", fmt.Sprintf("%dx%d", width, height),
+		"-",
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if len(out) != width*height*3 {
+		return nil, fmt.Errorf("decode %s: no frame at %.3fs", path, seconds)
+	}
+	return out, nil
+}
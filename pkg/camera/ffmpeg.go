@@ -0,0 +1,181 @@
+package camera
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FFmpegCamera captures frames from a local camera device or a network
+// stream by running ffmpeg and reading raw RGB24 frames from its stdout.
+type FFmpegCamera struct {
+	cfg    Config
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	reader *bufio.Reader
+	pts    chan showinfo // stream time of every frame, network streams only
+	frames int           // read so far
+
+	mu      sync.Mutex
+	closed  bool
+	lastLog string // last ffmpeg message other than showinfo, network streams only
+}
+
+// OpenFFmpeg starts capturing from the configured device.
+func OpenFFmpeg(cfg Config) (*FFmpegCamera, error) {
+	if cfg.FPS <= 0 {
+		cfg.FPS = 30
+	}
+
+	// Network streams are passed through at their own rate, with the
+	// stream time of every frame logged by showinfo, see NetworkCamera
+	network := isNetwork(cfg.Device)
+	loglevel, filters := "error", fmt.Sprintf("scale=%d:%d", cfg.Width, cfg.Height)
+	rate := []string{"-r", strconv.Itoa(cfg.FPS)}
+	if network {
+		loglevel, filters = "info", filters+",showinfo"
+		rate = []string{"-fps_mode", "passthrough"}
+	}
+
+	args := []string{"-hide_banner", "-loglevel", loglevel}
+	args = append(args, inputArgs(cfg)...)
+	args = append(args, "-vf", filters)
+	args = append(args, rate...)
+	args = append(args,
+		"-f", "rawvideo",
+		"-pix_fmt", "rgb24",
+		"-",
+	)
+
+	cmd := exec.Command("ffmpeg", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	var stderr io.ReadCloser
+	if network {
+		if stderr, err = cmd.StderrPipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start ffmpeg: %w", err)
+	}
+
+	c := &FFmpegCamera{
+		cfg:    cfg,
+		cmd:    cmd,
+		stdout: stdout,
+		reader: bufio.NewReaderSize(stdout, cfg.Width*cfg.Height*3),
+	}
+	if network {
+		c.pts = make(chan showinfo, 64)
+		go c.readShowinfo(stderr)
+	}
+	return c, nil
+}
+
+// inputArgs returns the ffmpeg input options for the device on this OS.
+func inputArgs(cfg Config) []string {
+	if isNetwork(cfg.Device) {
+		return networkInputArgs(cfg.Device)
+	}
+
+	size := fmt.Sprintf("%dx%d", cfg.Width, cfg.Height)
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"-f", "avfoundation", "-framerate", strconv.Itoa(cfg.FPS), "-video_size", size, "-i", cfg.Device}
+	case "windows":
+		return []string{"-f", "dshow", "-framerate", strconv.Itoa(cfg.FPS), "-video_size", size, "-i", "video=" + cfg.Device}
+	default:
+		return []string{"-f", "v4l2", "-framerate", strconv.Itoa(cfg.FPS), "-video_size", size, "-i", cfg.Device}
+	}
+}
+
+func (c *FFmpegCamera) Name() string { return c.cfg.Name }
+func (c *FFmpegCamera) Width() int   { return c.cfg.Width }
+func (c *FFmpegCamera) Height() int  { return c.cfg.Height }
+
+// FPS returns the configured rate, or 0 for a network stream, which is
+// passed through at its own rate, see NetworkCamera.FPS.
+func (c *FFmpegCamera) FPS() int {
+	if c.pts != nil {
+		return 0
+	}
+	return c.cfg.FPS
+}
+
+// Read returns the next frame from ffmpeg.
+func (c *FFmpegCamera) Read(ctx context.Context) (Frame, error) {
+	frame, _, _, err := c.read(ctx)
+	return frame, err
+}
+
+// read returns the next frame and, for network streams, its number and
+// stream time if ffmpeg reported them.
+func (c *FFmpegCamera) read(ctx context.Context) (Frame, showinfo, bool, error) {
+	buf := make([]byte, c.cfg.Width*c.cfg.Height*3)
+	if _, err := io.ReadFull(c.reader, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = fmt.Errorf("camera %s: stream ended", c.cfg.Name)
+			c.mu.Lock()
+			if c.lastLog != "" {
+				err = fmt.Errorf("%w: %s", err, c.lastLog)
+			}
+			c.mu.Unlock()
+		}
+		return Frame{}, showinfo{}, false, err
+	}
+	frame := Frame{Data: buf, Timestamp: time.Now().Add(-c.cfg.Latency)}
+	n := c.frames
+	c.frames++
+	if c.pts == nil {
+		return frame, showinfo{}, false, ctx.Err()
+	}
+
+	// showinfo logs a frame before ffmpeg writes it, but the pipes are
+	// read independently, and lines are dropped if Read falls behind
+	timeout := time.After(showinfoWait)
+	for {
+		select {
+		case info := <-c.pts:
+			if info.n < n {
+				continue
+			}
+			if info.n > n {
+				// This frame's line was dropped; put the line back for
+				// the next frame if there is room
+				select {
+				case c.pts <- info:
+				default:
+				}
+				return frame, showinfo{}, false, ctx.Err()
+			}
+			return frame, info, true, ctx.Err()
+		case <-timeout:
+			return frame, showinfo{}, false, ctx.Err()
+		}
+	}
+}
+
+// Close stops ffmpeg.
+func (c *FFmpegCamera) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	c.cmd.Process.Kill()
+	c.stdout.Close()
+	c.cmd.Wait()
+	return nil
+}
@@ -0,0 +1,21 @@
+package camera
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+)
+
+// EncodeJPEG compresses an RGB24 image, e.g. to send it over the network.
+func EncodeJPEG(rgb []byte, width, height, quality int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range width * height {
+		copy(img.Pix[4*i:4*i+3], rgb[3*i:3*i+3])
+		img.Pix[4*i+3] = 0xff
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
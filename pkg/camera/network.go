@@ -0,0 +1,276 @@
+package camera
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// networkTimeout is how long a stream may stall before ffmpeg gives up
+	// on it and the camera reconnects.
+	networkTimeout = 5 * time.Second
+
+	reconnectMinBackoff = 250 * time.Millisecond
+	reconnectMaxBackoff = 5 * time.Second
+
+	// showinfoWait is how long to wait for the stream time of a frame
+	// that has already arrived.
+	showinfoWait = 50 * time.Millisecond
+
+	// smoothing is the weight of a new sample in the latency and frame
+	// period averages.
+	smoothing = 0.05
+)
+
+// isNetwork reports whether device is a stream URL, such as rtsp:// or an
+// http:// MJPEG stream from a phone or IP camera.
+func isNetwork(device string) bool { return strings.Contains(device, "://") }
+
+// networkInputArgs returns the ffmpeg input options for a stream URL:
+// without input buffering, so frames are delivered as soon as they are
+// decoded, and with a timeout so a stalled stream ends.
+func networkInputArgs(url string) []string {
+	timeout := strconv.FormatInt(networkTimeout.Microseconds(), 10)
+	args := []string{"-fflags", "nobuffer", "-flags", "low_delay"}
+	if strings.HasPrefix(url, "rtsp://") || strings.HasPrefix(url, "rtsps://") {
+		// TCP avoids the smeared frames of lost UDP packets
+		args = append(args, "-rtsp_transport", "tcp", "-timeout", timeout)
+	} else {
+		args = append(args, "-rw_timeout", timeout)
+	}
+	return append(args, "-i", url)
+}
+
+// readShowinfo sends the stream time of every frame logged by ffmpeg's
+// showinfo filter, and keeps the last other message for errors.
+func (c *FFmpegCamera) readShowinfo(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if info, ok := parseShowinfo(line); ok {
+			select {
+			case c.pts <- info:
+			default: // Read is behind, it falls back to arrival times
+			}
+			continue
+		}
+		if !strings.Contains(line, "showinfo") && strings.TrimSpace(line) != "" {
+			c.mu.Lock()
+			c.lastLog = strings.TrimSpace(line)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// showinfo is a frame logged by ffmpeg's showinfo filter.
+type showinfo struct {
+	n   int           // frame number from 0
+	pts time.Duration // stream time
+}
+
+// parseShowinfo parses a showinfo frame line, e.g.
+// "[Parsed_showinfo_1 @ 0x1] n:  12 pts: 36000 pts_time:0.4 duration: ...".
+func parseShowinfo(line string) (showinfo, bool) {
+	if !strings.Contains(line, "showinfo") {
+		return showinfo{}, false
+	}
+	n, okN := showinfoField(line, "n:")
+	pts, okPTS := showinfoField(line, "pts_time:")
+	if !okN || !okPTS {
+		return showinfo{}, false
+	}
+	return showinfo{n: int(n), pts: time.Duration(pts * float64(time.Second))}, true
+}
+
+// showinfoField returns the number following key, e.g. "n:" in "n:  12".
+func showinfoField(line, key string) (float64, bool) {
+	i := strings.Index(line, " "+key)
+	if i < 0 {
+		return 0, false
+	}
+	field, _, _ := strings.Cut(strings.TrimSpace(line[i+1+len(key):]), " ")
+	v, err := strconv.ParseFloat(field, 64)
+	return v, err == nil
+}
+
+// NetworkCamera captures from an RTSP or MJPEG stream, such as a phone or
+// IP camera, through ffmpeg. When the stream ends or stalls it reconnects
+// with backoff, so a flaky network does not end a recording; the Grabber
+// keeps the last frame meanwhile.
+//
+// Network cameras deliver frames late, by a varying amount. Every frame is
+// stamped with when it was captured according to the stream time, relative
+// to the fastest frame so far, which takes network jitter out of the
+// timestamps. Latency reports how much later than that frames arrive; the
+// constant delay of encoding and the fastest transfer cannot be observed
+// without a reference clock in view of the camera.
+type NetworkCamera struct {
+	cfg Config
+
+	mu         sync.Mutex
+	cam        *FFmpegCamera // nil while reconnecting
+	closed     bool
+	reconnects int
+	lastErr    error
+
+	// Stream time to local time, reset on reconnect
+	origin  time.Time // local time of stream time 0, for the fastest frame
+	latency time.Duration
+	last    showinfo // previous stamped frame, n -1 if none
+	period  time.Duration
+}
+
+// OpenNetwork connects to the stream at cfg.Device.
+func OpenNetwork(cfg Config) (*NetworkCamera, error) {
+	cam, err := OpenFFmpeg(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkCamera{cfg: cam.cfg, cam: cam, last: showinfo{n: -1}}, nil
+}
+
+func (c *NetworkCamera) Name() string { return c.cfg.Name }
+func (c *NetworkCamera) Width() int   { return c.cfg.Width }
+func (c *NetworkCamera) Height() int  { return c.cfg.Height }
+
+// FPS returns the rate the stream delivers frames at, measured on the
+// stream clock, or 0 until two frames have arrived. Streams are passed
+// through at their own rate, whatever Config.FPS says.
+func (c *NetworkCamera) FPS() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.period <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(time.Second) / float64(c.period)))
+}
+
+// Read returns the next frame, reconnecting until one arrives or ctx is
+// done.
+func (c *NetworkCamera) Read(ctx context.Context) (Frame, error) {
+	backoff := reconnectMinBackoff
+	for {
+		cam, err := c.connection()
+		if err != nil {
+			return Frame{}, err
+		}
+		if cam != nil {
+			frame, info, ok, err := cam.read(ctx)
+			if err == nil {
+				if ok {
+					c.stamp(&frame, info)
+				}
+				return frame, nil
+			}
+			if ctx.Err() != nil {
+				return Frame{}, ctx.Err()
+			}
+			c.disconnect(cam, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return Frame{}, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, reconnectMaxBackoff)
+		c.reconnect()
+	}
+}
+
+// connection returns the current ffmpeg camera, nil while reconnecting.
+func (c *NetworkCamera) connection() (*FFmpegCamera, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, io.ErrClosedPipe
+	}
+	return c.cam, nil
+}
+
+func (c *NetworkCamera) disconnect(cam *FFmpegCamera, err error) {
+	cam.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cam == cam {
+		c.cam = nil
+		c.lastErr = err
+	}
+}
+
+func (c *NetworkCamera) reconnect() {
+	cam, err := OpenFFmpeg(c.cfg)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.lastErr = err
+		return
+	}
+	if c.closed {
+		cam.Close()
+		return
+	}
+	c.cam = cam
+	c.reconnects++
+	c.origin = time.Time{}
+	c.last = showinfo{n: -1}
+}
+
+// stamp replaces the arrival time of frame by its capture time on the
+// stream clock and updates the latency and frame period.
+func (c *NetworkCamera) stamp(frame *Frame, info showinfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pts := info.pts
+	if c.last.n >= 0 && info.n > c.last.n && pts > c.last.pts {
+		// Frames whose stream time was not reported are in between
+		period := (pts - c.last.pts) / time.Duration(info.n-c.last.n)
+		if c.period == 0 {
+			c.period = period
+		}
+		c.period += time.Duration(smoothing * float64(period-c.period))
+	}
+	c.last = info
+
+	if origin := frame.Timestamp.Add(-pts); c.origin.IsZero() || origin.Before(c.origin) {
+		c.origin = origin
+	}
+	captured := c.origin.Add(pts)
+	late := frame.Timestamp.Sub(captured)
+	c.latency += time.Duration(smoothing * float64(late-c.latency))
+	frame.Timestamp = captured
+}
+
+// Latency returns how much later than the fastest frame frames arrive, on
+// average.
+func (c *NetworkCamera) Latency() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latency
+}
+
+// Reconnects returns how often the stream was reconnected, and the error
+// that caused the last reconnect.
+func (c *NetworkCamera) Reconnects() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reconnects, c.lastErr
+}
+
+// Close disconnects and stops reconnecting.
+func (c *NetworkCamera) Close() error {
+	c.mu.Lock()
+	cam := c.cam
+	c.closed, c.cam = true, nil
+	c.mu.Unlock()
+	if cam != nil {
+		return cam.Close()
+	}
+	return nil
+}
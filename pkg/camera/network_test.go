@@ -0,0 +1,52 @@
+package camera
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseShowinfo(t *testing.T) {
+	line := "[Parsed_showinfo_1 @ 0x600003c2c000] n:  12 pts:  36000 pts_time:0.4     duration:   3000 duration_time:0.0333333 fmt:yuvj420p"
+	info, ok := parseShowinfo(line)
+	if !ok || info.n != 12 || info.pts != 400*time.Millisecond {
+		t.Errorf("parseShowinfo() = %+v, %v", info, ok)
+	}
+	if _, ok := parseShowinfo("[rtsp @ 0x1] method DESCRIBE failed: 404 Not Found"); ok {
+		t.Error("parsed a non-showinfo line")
+	}
+}
+
+func TestNetworkCamera_Stamp(t *testing.T) {
+	c := &NetworkCamera{last: showinfo{n: -1}}
+	start := time.Unix(100, 0)
+
+	// Frame 1 arrives 30ms sooner after frame 0 than the stream time
+	// between them: it becomes the reference for the timeline
+	f0 := Frame{Timestamp: start.Add(80 * time.Millisecond)}
+	c.stamp(&f0, showinfo{n: 0, pts: 0})
+	f1 := Frame{Timestamp: start.Add(150 * time.Millisecond)}
+	c.stamp(&f1, showinfo{n: 1, pts: 100 * time.Millisecond})
+	if !f1.Timestamp.Equal(start.Add(150 * time.Millisecond)) {
+		t.Errorf("frame 1 stamped at %v, want its arrival", f1.Timestamp.Sub(start))
+	}
+
+	// Frame 2 arrives 30ms later than the fastest frame would have
+	f2 := Frame{Timestamp: start.Add(280 * time.Millisecond)}
+	c.stamp(&f2, showinfo{n: 2, pts: 200 * time.Millisecond})
+	if want := start.Add(250 * time.Millisecond); !f2.Timestamp.Equal(want) {
+		t.Errorf("frame 2 stamped at %v, want %v", f2.Timestamp.Sub(start), want.Sub(start))
+	}
+	if c.Latency() <= 0 {
+		t.Errorf("latency = %v, want > 0", c.Latency())
+	}
+	if fps := c.FPS(); fps != 10 {
+		t.Errorf("FPS() = %d, want the stream's 10", fps)
+	}
+
+	// Frames without a reported stream time don't halve the rate
+	f5 := Frame{Timestamp: start.Add(550 * time.Millisecond)}
+	c.stamp(&f5, showinfo{n: 5, pts: 500 * time.Millisecond})
+	if fps := c.FPS(); fps != 10 {
+		t.Errorf("FPS() after skipped frames = %d, want 10", fps)
+	}
+}
@@ -0,0 +1,133 @@
+package camera
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed realsense.py
+var realsenseScript string
+
+// realsensePrefix selects the RealSense backend in a camera device, as in
+// realsense or realsense:SERIAL.
+const realsensePrefix = "realsense"
+
+// DepthCamera is a camera whose frames also carry depth, see Frame.Depth.
+type DepthCamera interface {
+	Camera
+	// DepthUnit returns the size of one depth count in metres.
+	DepthUnit() float64
+}
+
+// RealSenseCamera captures color and aligned depth from an Intel RealSense.
+// There is no Go SDK, so like the MuJoCo simulator it runs a Python helper
+// (realsense.py, embedded), which needs pyrealsense2 and numpy.
+type RealSenseCamera struct {
+	cfg    Config
+	serial string
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	reader *bufio.Reader
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// isRealSense reports whether device selects a RealSense, and its serial
+// number if given.
+func isRealSense(device string) (serial string, ok bool) {
+	rest, ok := strings.CutPrefix(device, realsensePrefix)
+	if !ok || (rest != "" && rest[0] != ':') {
+		return "", false
+	}
+	return strings.TrimPrefix(rest, ":"), true
+}
+
+// OpenRealSense starts capturing from the RealSense selected by cfg.Device,
+// the first one connected unless a serial number is given.
+func OpenRealSense(cfg Config) (*RealSenseCamera, error) {
+	serial, ok := isRealSense(cfg.Device)
+	if !ok {
+		return nil, fmt.Errorf("camera %s: %q is not a RealSense device", cfg.Name, cfg.Device)
+	}
+	if cfg.FPS <= 0 {
+		cfg.FPS = 30
+	}
+
+	python := "python3"
+	if runtime.GOOS == "windows" {
+		python = "python"
+	}
+	cmd := exec.Command(python, "-c", realsenseScript,
+		"--serial", serial,
+		"--width", strconv.Itoa(cfg.Width),
+		"--height", strconv.Itoa(cfg.Height),
+		"--fps", strconv.Itoa(cfg.FPS),
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start RealSense helper: %w", err)
+	}
+
+	return &RealSenseCamera{
+		cfg:    cfg,
+		serial: serial,
+		cmd:    cmd,
+		stdout: stdout,
+		reader: bufio.NewReaderSize(stdout, cfg.Width*cfg.Height*5),
+	}, nil
+}
+
+func (c *RealSenseCamera) Name() string       { return c.cfg.Name }
+func (c *RealSenseCamera) Width() int         { return c.cfg.Width }
+func (c *RealSenseCamera) Height() int        { return c.cfg.Height }
+func (c *RealSenseCamera) FPS() int           { return c.cfg.FPS }
+func (c *RealSenseCamera) DepthUnit() float64 { return 0.001 }
+
+// Read returns the next color image with its aligned depth.
+func (c *RealSenseCamera) Read(ctx context.Context) (Frame, error) {
+	pixels := c.cfg.Width * c.cfg.Height
+	buf := make([]byte, pixels*5)
+	if _, err := io.ReadFull(c.reader, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return Frame{}, fmt.Errorf("camera %s: RealSense stream ended (is pyrealsense2 installed?)", c.cfg.Name)
+		}
+		return Frame{}, err
+	}
+	now := time.Now().Add(-c.cfg.Latency)
+
+	raw := buf[pixels*3:]
+	depth := make([]uint16, pixels)
+	for i := range depth {
+		depth[i] = uint16(raw[2*i]) | uint16(raw[2*i+1])<<8
+	}
+	return Frame{Data: buf[:pixels*3], Depth: depth, Timestamp: now}, ctx.Err()
+}
+
+// Close stops the helper.
+func (c *RealSenseCamera) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	c.cmd.Process.Kill()
+	c.stdout.Close()
+	c.cmd.Wait()
+	return nil
+}
@@ -0,0 +1,49 @@
+"""Stream color and aligned depth from an Intel RealSense for pkg/camera.
+
+Run by RealSenseCamera, not by hand. Writes one record per frameset to
+stdout: width*height*3 bytes of RGB24, then width*height little-endian
+uint16 depth values in millimetres, aligned to the color image (0 where
+depth is unknown).
+
+    pip install pyrealsense2 numpy
+"""
+
+import argparse
+import sys
+
+import numpy as np
+import pyrealsense2 as rs
+
+parser = argparse.ArgumentParser()
+parser.add_argument("--serial", default="")
+parser.add_argument("--width", type=int, default=640)
+parser.add_argument("--height", type=int, default=480)
+parser.add_argument("--fps", type=int, default=30)
+args = parser.parse_args()
+
+config = rs.config()
+if args.serial:
+    config.enable_device(args.serial)
+config.enable_stream(rs.stream.color, args.width, args.height, rs.format.rgb8, args.fps)
+config.enable_stream(rs.stream.depth, args.width, args.height, rs.format.z16, args.fps)
+
+pipeline = rs.pipeline()
+profile = pipeline.start(config)
+to_mm = profile.get_device().first_depth_sensor().get_depth_scale() * 1000
+align = rs.align(rs.stream.color)
+out = sys.stdout.buffer
+
+try:
+    while True:
+        frames = align.process(pipeline.wait_for_frames())
+        color, depth = frames.get_color_frame(), frames.get_depth_frame()
+        if not color or not depth:
+            continue
+        mm = np.asanyarray(depth.get_data()).astype(np.float32) * to_mm
+        out.write(np.asanyarray(color.get_data()).tobytes())
+        out.write(np.clip(mm, 0, 65535).astype("<u2").tobytes())
+        out.flush()
+except (BrokenPipeError, KeyboardInterrupt):
+    pass
+finally:
+    pipeline.stop()
@@ -0,0 +1,1202 @@
+// Package dataset reads and writes recorded teleoperation episodes.
+//
+// The on-disk layout follows the LeRobot v2 dataset structure, with JSON
+// Lines instead of Parquet for the frame data:
+//
+//	<root>/meta/info.json          dataset-wide metadata and feature schema
+//	<root>/meta/episodes.jsonl     one line per episode
+//	<root>/meta/tasks.jsonl        task descriptions, "" for episodes without one
+//	<root>/meta/episodes_stats.jsonl  feature statistics per episode
+//	<root>/meta/stats.json         feature statistics over all episodes
+//	<root>/data/episode_000000.jsonl  one line per frame, .jsonl.zst if compressed
+//	<root>/videos/observation.images.<camera>/episode_000000.mp4
+//	<root>/images/observation.images.<camera>_depth/episode_000000/frame_000000.png
+//	<root>/audio/observation.audio.<microphone>/episode_000000.wav
+package dataset
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"image"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/audio"
+	"github.com/gwillem/lerobot/pkg/camera"
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// Feature names used in frames.
+const (
+	FeatureAction = "action"
+	FeatureState  = "observation.state"
+
+	// FeatureEffort is the follower load per motor in % of max torque,
+	// present if the dataset was created with AddEffort.
+	FeatureEffort = "observation.effort"
+
+	// FeatureImagePrefix prefixes camera names to form video feature keys.
+	FeatureImagePrefix = "observation.images."
+
+	// FeatureDepthSuffix follows a camera's feature key to form the key of
+	// its depth images.
+	FeatureDepthSuffix = "_depth"
+
+	// FeatureAudioPrefix prefixes microphone names to form audio feature
+	// keys.
+	FeatureAudioPrefix = "observation.audio."
+
+	// FeatureSensorPrefix prefixes sensor names to form the keys of their
+	// features, see Dataset.AddSensor.
+	FeatureSensorPrefix = "observation.sensors."
+)
+
+// videoPathTemplate is stored in info.json and documents where videos live.
+const videoPathTemplate = "videos/{video_key}/episode_{episode_index:06d}.mp4"
+
+// imagePathTemplate is stored in info.json and documents where depth images
+// live. Like LeRobot's image features, they are stored as PNG per frame.
+const imagePathTemplate = "images/{image_key}/episode_{episode_index:06d}/frame_{frame_index:06d}.png"
+
+// audioPathTemplate is stored in info.json and documents where audio lives.
+const audioPathTemplate = "audio/{audio_key}/episode_{episode_index:06d}.wav"
+
+// Info is the dataset-wide metadata stored in meta/info.json.
+type Info struct {
+	RobotType     string             `json:"robot_type"`
+	FPS           int                `json:"fps"`
+	TotalEpisodes int                `json:"total_episodes"`
+	TotalFrames   int                `json:"total_frames"`
+	TotalTasks    int                `json:"total_tasks"`
+	VideoPath     string             `json:"video_path,omitempty"`
+	ImagePath     string             `json:"image_path,omitempty"`
+	AudioPath     string             `json:"audio_path,omitempty"`
+	Features      map[string]Feature `json:"features"`
+
+	// Set by the Python implementation, whose v2 datasets store frames in
+	// Parquet files grouped in chunks of episodes. These are read only.
+	// DataPath is also set for compressed datasets, see Compress.
+	CodebaseVersion string `json:"codebase_version,omitempty"`
+	DataPath        string `json:"data_path,omitempty"`
+	ChunksSize      int    `json:"chunks_size,omitempty"`
+}
+
+// Feature describes one per-frame value in the dataset.
+type Feature struct {
+	DType      string   `json:"dtype"`
+	Shape      []int    `json:"shape"`
+	Names      []string `json:"names,omitempty"`
+	SampleRate int      `json:"sample_rate,omitempty"` // audio only
+}
+
+// Episode is the metadata for one recorded episode, stored in meta/episodes.jsonl.
+type Episode struct {
+	Index  int      `json:"episode_index"`
+	Tasks  []string `json:"tasks,omitempty"`
+	Length int      `json:"length"`
+
+	// Alignment is the worst skew of each source (the follower, cameras)
+	// against the action in any frame, in seconds. See EpisodeWriter.SetAlignment.
+	Alignment map[string]float64 `json:"alignment,omitempty"`
+
+	// Quality is the outcome of the checks at save time, if any were run.
+	Quality *Quality `json:"quality,omitempty"`
+}
+
+// Task is a natural-language description of what was done in an episode,
+// for language-conditioned policies. Tasks are stored once in
+// meta/tasks.jsonl and referenced from frames by index. Frames of episodes
+// without a task refer to the empty task, so no index stands for two tasks.
+type Task struct {
+	Index int    `json:"task_index"`
+	Task  string `json:"task"`
+}
+
+// Frame is a single recorded sample.
+type Frame struct {
+	Index     int       `json:"frame_index"`
+	Episode   int       `json:"episode_index"`
+	Timestamp float64   `json:"timestamp"` // seconds since episode start
+	Action    []float64 `json:"action"`
+	State     []float64 `json:"observation.state"`
+	Effort    []float64 `json:"observation.effort,omitempty"`
+	TaskIndex int       `json:"task_index"` // see Episode.Tasks
+
+	// Sensors holds the values of each sensor read in this frame, by
+	// name, in the order of its feature's names.
+	Sensors map[string][]float64 `json:"observation.sensors,omitempty"`
+}
+
+// Dataset is a recorded dataset on disk.
+type Dataset struct {
+	root     string
+	info     Info
+	motors   []robot.MotorName
+	episodes []Episode
+	tasks    []Task
+
+	// episodeStats is parallel to episodes, or empty for datasets
+	// recorded before statistics were stored
+	episodeStats []EpisodeStats
+}
+
+// ErrExists is returned by Create if root already contains a dataset.
+var ErrExists = errors.New("dataset already exists")
+
+// Create initializes a new, empty dataset at root. It fails with ErrExists
+// if root already contains a dataset.
+func Create(root string, fps int, motors []robot.MotorName) (*Dataset, error) {
+	if _, err := os.Stat(infoPath(root)); err == nil {
+		return nil, fmt.Errorf("%w at %s", ErrExists, root)
+	}
+	for _, dir := range []string{"meta", "data"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, len(motors))
+	for i, m := range motors {
+		names[i] = string(m) + ".pos"
+	}
+	feature := Feature{DType: "float32", Shape: []int{len(motors)}, Names: names}
+
+	d := &Dataset{
+		root:   root,
+		motors: motors,
+		info: Info{
+			RobotType: "so101_follower",
+			FPS:       fps,
+			Features: map[string]Feature{
+				FeatureAction: feature,
+				FeatureState:  feature,
+			},
+		},
+	}
+	if err := d.writeInfo(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Open loads an existing dataset from root.
+func Open(root string) (*Dataset, error) {
+	data, err := os.ReadFile(infoPath(root))
+	if err != nil {
+		return nil, err
+	}
+	d := &Dataset{root: root}
+	if err := json.Unmarshal(data, &d.info); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", infoPath(root), err)
+	}
+	for _, name := range d.info.Features[FeatureAction].Names {
+		name = strings.TrimPrefix(strings.TrimSuffix(name, ".pos"), pythonMotorPrefix)
+		d.motors = append(d.motors, robot.MotorName(name))
+	}
+
+	episodes, err := readJSONL[Episode](filepath.Join(root, "meta", "episodes.jsonl"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	d.episodes = episodes
+
+	tasks, err := readJSONL[Task](tasksPath(root))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	d.tasks = tasks
+
+	if d.Python() {
+		// Its statistics include images, computed again from the frames
+		// when needed
+		return d, nil
+	}
+	stats, err := readJSONL[EpisodeStats](episodeStatsPath(root))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(stats) == len(episodes) {
+		d.episodeStats = stats
+	}
+	return d, nil
+}
+
+// pythonMotorPrefix starts the motor names of datasets recorded by the
+// Python implementation before v0.3, whose positions are in degrees.
+const pythonMotorPrefix = "main_"
+
+// Python reports whether the dataset was recorded by the Python
+// implementation, in its v2 format with Parquet files. It can be read,
+// replayed and merged into a new dataset, but not changed.
+func (d *Dataset) Python() bool {
+	return strings.HasSuffix(d.info.DataPath, ".parquet")
+}
+
+// errPython is returned when changing a dataset for which Python is true.
+var errPython = errors.New("datasets recorded by the Python implementation are read-only, merge them into a new dataset first")
+
+// Units returns the units of the positions in the dataset: normalized,
+// except for datasets recorded by the Python implementation before v0.3.
+func (d *Dataset) Units() robot.Units {
+	for _, name := range d.info.Features[FeatureAction].Names {
+		if strings.HasPrefix(name, pythonMotorPrefix) {
+			return robot.UnitsDegrees
+		}
+	}
+	return robot.UnitsNormalized
+}
+
+// templatePath fills in a path template of info.json, such as
+// data/chunk-{episode_chunk:03d}/episode_{episode_index:06d}.parquet.
+func (d *Dataset) templatePath(template, key string, episode int) string {
+	chunk := episode / cmp.Or(d.info.ChunksSize, 1000)
+	path := strings.NewReplacer(
+		"{episode_chunk:03d}", fmt.Sprintf("%03d", chunk),
+		"{episode_index:06d}", fmt.Sprintf("%06d", episode),
+		"{video_key}", key,
+	).Replace(template)
+	return filepath.Join(d.root, filepath.FromSlash(path))
+}
+
+// Resume opens the dataset at root to record more episodes, numbered after
+// the existing ones. It fails if the dataset was recorded at another FPS or
+// with other motors.
+func Resume(root string, fps int, motors []robot.MotorName) (*Dataset, error) {
+	d, err := Open(root)
+	if err != nil {
+		return nil, err
+	}
+	if d.Python() {
+		return nil, errPython
+	}
+	if d.info.FPS != fps {
+		return nil, fmt.Errorf("%s was recorded at %d fps, not %d", root, d.info.FPS, fps)
+	}
+	if !slices.Equal(d.motors, motors) {
+		return nil, fmt.Errorf("%s was recorded with motors %v, not %v", root, d.motors, motors)
+	}
+	return d, nil
+}
+
+// AddCamera registers a camera whose frames are stored as video, one file
+// per episode. It must be called before recording the first episode, unless
+// the camera is already registered with the same size.
+func (d *Dataset) AddCamera(name string, width, height int) error {
+	feature := Feature{
+		DType: "video",
+		Shape: []int{height, width, 3},
+		Names: []string{"height", "width", "channels"},
+	}
+	if f, ok := d.info.Features[FeatureImagePrefix+name]; ok && slices.Equal(f.Shape, feature.Shape) {
+		return nil
+	}
+	if len(d.episodes) > 0 {
+		return fmt.Errorf("cannot add camera %s (%dx%d) to a dataset with episodes", name, width, height)
+	}
+	d.info.VideoPath = videoPathTemplate
+	d.info.Features[FeatureImagePrefix+name] = feature
+	return d.writeInfo()
+}
+
+// AddDepth registers the depth images of a camera, stored as 16-bit
+// grayscale PNG per frame in millimetres, see EpisodeWriter.AddDepth. Like
+// AddCamera, it must be called before recording the first episode unless
+// the depth images are registered already with the same size.
+func (d *Dataset) AddDepth(name string, width, height int) error {
+	key := FeatureImagePrefix + name + FeatureDepthSuffix
+	feature := Feature{
+		DType: "image",
+		Shape: []int{height, width, 1},
+		Names: []string{"height", "width", "channels"},
+	}
+	if f, ok := d.info.Features[key]; ok && slices.Equal(f.Shape, feature.Shape) {
+		return nil
+	}
+	if len(d.episodes) > 0 {
+		return fmt.Errorf("cannot add depth of camera %s to a dataset with episodes", name)
+	}
+	d.info.ImagePath = imagePathTemplate
+	d.info.Features[key] = feature
+	return d.writeInfo()
+}
+
+// AddEffort adds the follower load of every motor to the recorded
+// observations (see EpisodeWriter.AddEffort). It must be called before
+// recording the first episode.
+func (d *Dataset) AddEffort() error {
+	if len(d.episodes) > 0 {
+		return errors.New("cannot add effort to a dataset with episodes")
+	}
+	names := make([]string, len(d.motors))
+	for i, m := range d.motors {
+		names[i] = string(m) + ".effort"
+	}
+	d.info.Features[FeatureEffort] = Feature{DType: "float32", Shape: []int{len(d.motors)}, Names: names}
+	return d.writeInfo()
+}
+
+// compressedDataPath is the data_path in info.json of datasets created with
+// Compress.
+const compressedDataPath = "data/episode_{episode_index:06d}.jsonl.zst"
+
+// Compress stores the frames of episodes zstd compressed, for long
+// recordings. It must be called before recording the first episode.
+func (d *Dataset) Compress() error {
+	if len(d.episodes) > 0 {
+		return errors.New("cannot compress a dataset with episodes")
+	}
+	d.info.DataPath = compressedDataPath
+	return d.writeInfo()
+}
+
+// Compressed reports whether frames are stored zstd compressed.
+func (d *Dataset) Compressed() bool {
+	return strings.HasSuffix(d.info.DataPath, ".zst")
+}
+
+// HasEffort reports whether frames carry FeatureEffort.
+func (d *Dataset) HasEffort() bool {
+	_, ok := d.info.Features[FeatureEffort]
+	return ok
+}
+
+// AddAudio registers a microphone whose samples are stored as a 16-bit WAV
+// file per episode, see EpisodeWriter.AddAudio. Like AddCamera, it must be
+// called before recording the first episode unless the microphone is
+// registered already with the same format.
+func (d *Dataset) AddAudio(name string, sampleRate, channels int) error {
+	feature := Feature{DType: "audio", Shape: []int{channels}, Names: []string{"channels"}, SampleRate: sampleRate}
+	if f, ok := d.info.Features[FeatureAudioPrefix+name]; ok && slices.Equal(f.Shape, feature.Shape) && f.SampleRate == sampleRate {
+		return nil
+	}
+	if len(d.episodes) > 0 {
+		return fmt.Errorf("cannot add microphone %s to a dataset with episodes", name)
+	}
+	d.info.AudioPath = audioPathTemplate
+	d.info.Features[FeatureAudioPrefix+name] = feature
+	return d.writeInfo()
+}
+
+// AddSensor registers a sensor, such as a force-torque sensor or a scale,
+// whose named values are recorded with every frame, see
+// EpisodeWriter.AddSensor. Like AddCamera, it must be called before
+// recording the first episode unless the sensor is registered already with
+// the same values.
+func (d *Dataset) AddSensor(name string, values []string) error {
+	feature := Feature{DType: "float32", Shape: []int{len(values)}, Names: values}
+	if f, ok := d.info.Features[FeatureSensorPrefix+name]; ok && slices.Equal(f.Names, values) {
+		return nil
+	}
+	if len(d.episodes) > 0 {
+		return fmt.Errorf("cannot add sensor %s with values %v to a dataset with episodes", name, values)
+	}
+	d.info.Features[FeatureSensorPrefix+name] = feature
+	return d.writeInfo()
+}
+
+// Sensors returns the names of all sensors, sorted.
+func (d *Dataset) Sensors() []string {
+	var names []string
+	for key := range d.info.Features {
+		if name, ok := strings.CutPrefix(key, FeatureSensorPrefix); ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// VideoKeys returns the feature keys of all cameras, sorted.
+func (d *Dataset) VideoKeys() []string { return d.keys("video") }
+
+// DepthKeys returns the feature keys of all depth images, sorted.
+func (d *Dataset) DepthKeys() []string { return d.keys("image") }
+
+// AudioKeys returns the feature keys of all microphones, sorted.
+func (d *Dataset) AudioKeys() []string { return d.keys("audio") }
+
+func (d *Dataset) keys(dtype string) []string {
+	var keys []string
+	for key, f := range d.info.Features {
+		if f.DType == dtype {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// VideoPath returns the video file of a camera for an episode.
+func (d *Dataset) VideoPath(key string, episode int) string {
+	if strings.Contains(d.info.VideoPath, "{episode_chunk") {
+		return d.templatePath(d.info.VideoPath, key, episode)
+	}
+	return filepath.Join(d.root, "videos", key, fmt.Sprintf("episode_%06d.mp4", episode))
+}
+
+// ImageDir returns the directory of an episode's depth images.
+func (d *Dataset) ImageDir(key string, episode int) string {
+	return filepath.Join(d.root, "images", key, fmt.Sprintf("episode_%06d", episode))
+}
+
+// ImagePath returns the depth image of a frame.
+func (d *Dataset) ImagePath(key string, episode, frame int) string {
+	return filepath.Join(d.ImageDir(key, episode), fmt.Sprintf("frame_%06d.png", frame))
+}
+
+// AudioPath returns the WAV file of a microphone for an episode.
+func (d *Dataset) AudioPath(key string, episode int) string {
+	return filepath.Join(d.root, "audio", key, fmt.Sprintf("episode_%06d.wav", episode))
+}
+
+// mediaFiles returns the video and audio files of an episode, and the
+// directories of its depth images.
+func (d *Dataset) mediaFiles(episode int) []string {
+	var files []string
+	for _, key := range d.VideoKeys() {
+		files = append(files, d.VideoPath(key, episode))
+	}
+	for _, key := range d.DepthKeys() {
+		files = append(files, d.ImageDir(key, episode))
+	}
+	for _, key := range d.AudioKeys() {
+		files = append(files, d.AudioPath(key, episode))
+	}
+	return files
+}
+
+// Root returns the dataset directory.
+func (d *Dataset) Root() string { return d.root }
+
+// Info returns the dataset metadata.
+func (d *Dataset) Info() Info { return d.info }
+
+// Motors returns the motor order used for action and state vectors.
+func (d *Dataset) Motors() []robot.MotorName { return d.motors }
+
+// Episodes returns the metadata of all saved episodes.
+func (d *Dataset) Episodes() []Episode { return d.episodes }
+
+// Tasks returns all task descriptions, in index order.
+func (d *Dataset) Tasks() []Task { return d.tasks }
+
+// taskIndex returns the index of a task, adding it if new.
+func (d *Dataset) taskIndex(task string) int {
+	index := d.findTask(task)
+	if index == len(d.tasks) {
+		d.tasks = append(d.tasks, Task{Index: index, Task: task})
+	}
+	return index
+}
+
+// findTask returns the index of a task, or the one it gets when added.
+func (d *Dataset) findTask(task string) int {
+	for _, t := range d.tasks {
+		if t.Task == task {
+			return t.Index
+		}
+	}
+	return len(d.tasks)
+}
+
+// ReadFrames loads all frames of an episode. Positions are in the units of
+// Units, with the gripper of datasets recorded by the Python implementation
+// mapped onto -100 to 100 like here.
+func (d *Dataset) ReadFrames(episode int) ([]Frame, error) {
+	if d.Python() {
+		return d.readParquetFrames(episode)
+	}
+	return readJSONL[Frame](d.episodePath(episode))
+}
+
+// NewEpisode starts recording a new episode. Frames are written to disk as
+// they are added, so memory use doesn't grow with the episode's length, but
+// the episode is only part of the dataset once Save is called.
+func (d *Dataset) NewEpisode() *EpisodeWriter {
+	return &EpisodeWriter{dataset: d, index: len(d.episodes), stats: d.newFrameStats()}
+}
+
+// EpisodeWriter records frames for one episode.
+type EpisodeWriter struct {
+	dataset *Dataset
+	index   int
+	task    string
+	align   map[string]float64
+	videos  map[string]*camera.VideoWriter
+	audio   []string     // WAV files written by AddAudio
+	images  []string     // depth image directories written by AddDepth
+	depth   *depthWriter // nil until the first depth image
+
+	out       *jsonlWriter // nil until the first frame is written
+	last      *Frame       // not written yet, AddEffort may still change it
+	frames    int
+	first     float64 // timestamp of the first frame
+	taskIndex int     // of the frames written so far
+	stats     *frameStats
+	err       error // first failure to write a frame, returned by Save
+
+	frozen  map[string]*frozenRun // per camera, see Check
+	seed    maphash.Seed
+	maxJump float64
+	quality *Quality
+}
+
+// Index returns the episode index this writer will save to.
+func (w *EpisodeWriter) Index() int { return w.index }
+
+// SetTask describes what is done in the episode, e.g. "pick up the red
+// cube". Empty means no task.
+func (w *EpisodeWriter) SetTask(task string) { w.task = task }
+
+// SetAlignment records the worst skew of each source against the action,
+// the bound within which the episode's observations are aligned with it.
+func (w *EpisodeWriter) SetAlignment(skew map[string]time.Duration) {
+	w.align = make(map[string]float64, len(skew))
+	for name, d := range skew {
+		w.align[name] = d.Seconds()
+	}
+}
+
+// Len returns the number of frames recorded so far.
+func (w *EpisodeWriter) Len() int { return w.frames }
+
+// Add appends a frame with the given action (leader) and observed state
+// (follower) positions. timestamp is in seconds since the episode started.
+func (w *EpisodeWriter) Add(timestamp float64, action, state map[robot.MotorName]float64) {
+	f := &Frame{
+		Index:     w.frames,
+		Episode:   w.index,
+		Timestamp: timestamp,
+		Action:    w.dataset.vector(action),
+		State:     w.dataset.vector(state),
+	}
+	if w.last == nil {
+		w.first = timestamp
+	} else {
+		w.maxJump = max(w.maxJump, maxDiff(w.last.Action, f.Action), maxDiff(w.last.State, f.State))
+		w.write(*w.last)
+	}
+	w.last = f
+	w.frames++
+}
+
+// write appends a finished frame to the episode file, creating it for the
+// first frame.
+func (w *EpisodeWriter) write(f Frame) {
+	w.stats.add(f)
+	if w.open(); w.err != nil {
+		return
+	}
+	f.TaskIndex = w.taskIndex
+	w.err = w.out.write(f)
+}
+
+func (w *EpisodeWriter) open() {
+	if w.out != nil || w.err != nil {
+		return
+	}
+	w.taskIndex = w.dataset.findTask(w.task)
+	w.out, w.err = createJSONL(w.dataset.episodePath(w.index))
+}
+
+// AddEffort sets the follower loads of the last added frame, in 0.1% of
+// max torque as reported by the servos. They are stored in %.
+func (w *EpisodeWriter) AddEffort(loads map[robot.MotorName]int) {
+	if w.last == nil {
+		return
+	}
+	effort := make(map[robot.MotorName]float64, len(loads))
+	for name, load := range loads {
+		effort[name] = float64(load) / 10
+	}
+	w.last.Effort = w.dataset.vector(effort)
+}
+
+// AddImage encodes a camera frame for the current episode. Images are
+// encoded immediately, so they should be added once per Add call to keep
+// video and frame data in sync.
+func (w *EpisodeWriter) AddImage(name string, rgb []byte) error {
+	key := FeatureImagePrefix + name
+	vw, ok := w.videos[key]
+	if !ok {
+		f, ok := w.dataset.info.Features[key]
+		if !ok {
+			return fmt.Errorf("unknown camera %s", name)
+		}
+		var err error
+		vw, err = camera.NewVideoWriter(w.dataset.VideoPath(key, w.index), f.Shape[1], f.Shape[0], w.dataset.info.FPS, "")
+		if err != nil {
+			return err
+		}
+		if w.videos == nil {
+			w.videos = make(map[string]*camera.VideoWriter)
+		}
+		w.videos[key] = vw
+	}
+	w.trackImage(key, rgb)
+	return vw.WriteFrame(rgb)
+}
+
+// AddDepth writes the depth image of a camera for the last added frame, in
+// millimetres.
+func (w *EpisodeWriter) AddDepth(name string, depth []uint16) error {
+	key := FeatureImagePrefix + name + FeatureDepthSuffix
+	f, ok := w.dataset.info.Features[key]
+	if !ok {
+		return fmt.Errorf("unknown depth camera %s", name)
+	}
+	if w.frames == 0 {
+		return errors.New("no frame to add depth to")
+	}
+	height, width := f.Shape[0], f.Shape[1]
+	if len(depth) != width*height {
+		return fmt.Errorf("depth of %s has %d values, want %d", name, len(depth), width*height)
+	}
+
+	img := image.NewGray16(image.Rect(0, 0, width, height))
+	for i, v := range depth {
+		img.Pix[2*i], img.Pix[2*i+1] = byte(v>>8), byte(v)
+	}
+	dir := w.dataset.ImageDir(key, w.index)
+	if !slices.Contains(w.images, dir) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		w.images = append(w.images, dir)
+	}
+	if w.depth == nil {
+		w.depth = newDepthWriter()
+	}
+	w.depth.write(w.dataset.ImagePath(key, w.index, w.frames-1), img)
+	return nil
+}
+
+// AddSensor sets the values of a sensor in the last added frame. Values
+// missing from the reading are recorded as 0. A sensor not registered with
+// Dataset.AddSensor is registered with the names in its first reading, in
+// sorted order, which fails if the dataset already has episodes.
+func (w *EpisodeWriter) AddSensor(name string, values map[string]float64) error {
+	if w.last == nil {
+		return errors.New("no frame to add sensor values to")
+	}
+	f, ok := w.dataset.info.Features[FeatureSensorPrefix+name]
+	if !ok {
+		if err := w.dataset.AddSensor(name, slices.Sorted(maps.Keys(values))); err != nil {
+			return err
+		}
+		f = w.dataset.info.Features[FeatureSensorPrefix+name]
+		w.stats.addSensor(name, len(f.Names))
+	}
+	v := make([]float64, len(f.Names))
+	for i, n := range f.Names {
+		v[i] = values[n]
+	}
+	if w.last.Sensors == nil {
+		w.last.Sensors = make(map[string][]float64)
+	}
+	w.last.Sensors[name] = v
+	return nil
+}
+
+// AddAudio writes the audio of a microphone for the episode, as
+// interleaved 16-bit samples starting at the first frame's timestamp.
+func (w *EpisodeWriter) AddAudio(name string, samples []int16) error {
+	key := FeatureAudioPrefix + name
+	f, ok := w.dataset.info.Features[key]
+	if !ok {
+		return fmt.Errorf("unknown microphone %s", name)
+	}
+	path := w.dataset.AudioPath(key, w.index)
+	if err := audio.WriteWAV(path, f.SampleRate, f.Shape[0], samples); err != nil {
+		return err
+	}
+	w.audio = append(w.audio, path)
+	return nil
+}
+
+// Discard drops the episode, including the frames written so far, any
+// partially encoded video and written audio and depth images.
+func (w *EpisodeWriter) Discard() {
+	if w.out != nil {
+		w.out.abort()
+	}
+	for _, vw := range w.videos {
+		vw.Abort()
+	}
+	for _, path := range w.audio {
+		os.Remove(path)
+	}
+	if w.depth != nil {
+		w.depth.close()
+		w.depth = nil
+	}
+	for _, dir := range w.images {
+		os.RemoveAll(dir)
+	}
+	w.videos = nil
+	w.audio = nil
+	w.images = nil
+	w.out = nil
+	w.last = nil
+	w.frames = 0
+	w.stats = w.dataset.newFrameStats()
+	w.err = nil
+	w.frozen = nil
+	w.maxJump = 0
+	w.quality = nil
+}
+
+// Save writes the episode's frames and updates the dataset metadata.
+func (w *EpisodeWriter) Save() error {
+	d := w.dataset
+	for key, vw := range w.videos {
+		if err := vw.Close(); err != nil {
+			return fmt.Errorf("encode %s: %w", key, err)
+		}
+	}
+	if w.depth != nil {
+		err := w.depth.close()
+		w.depth = nil
+		if err != nil {
+			return fmt.Errorf("write depth images: %w", err)
+		}
+	}
+	if w.last != nil {
+		w.write(*w.last)
+		w.last = nil
+	}
+	if w.open(); w.err == nil {
+		w.err = w.out.close()
+	}
+	if w.err != nil {
+		return fmt.Errorf("write episode %d: %w", w.index, w.err)
+	}
+
+	e := Episode{Index: w.index, Length: w.frames, Alignment: w.align, Quality: w.quality}
+	if w.task != "" {
+		e.Tasks = []string{w.task}
+	}
+	if index := d.taskIndex(w.task); index != w.taskIndex {
+		// The task changed after the first frames were written
+		if err := d.setTaskIndex(w.index, index); err != nil {
+			return fmt.Errorf("write episode %d: %w", w.index, err)
+		}
+	}
+
+	d.appendEpisodeStats(EpisodeStats{Index: w.index, Stats: w.stats.stats()})
+	d.episodes = append(d.episodes, e)
+	d.info.TotalFrames += w.frames
+	return d.writeMeta()
+}
+
+// vector converts a motor map into a slice in dataset motor order.
+func (d *Dataset) vector(positions map[robot.MotorName]float64) []float64 {
+	v := make([]float64, len(d.motors))
+	for i, m := range d.motors {
+		v[i] = positions[m]
+	}
+	return v
+}
+
+func (d *Dataset) episodePath(index int) string {
+	if d.info.DataPath != "" {
+		return d.templatePath(d.info.DataPath, "", index)
+	}
+	return filepath.Join(d.root, "data", fmt.Sprintf("episode_%06d.jsonl", index))
+}
+
+// setTaskIndex sets the task of every frame of an episode, rewriting it
+// one frame at a time.
+func (d *Dataset) setTaskIndex(episode, task int) error {
+	path := d.episodePath(episode)
+	// Keep the extension, which decides the compression
+	tmp := filepath.Join(filepath.Dir(path), ".tmp-"+filepath.Base(path))
+	out, err := createJSONL(tmp)
+	if err != nil {
+		return err
+	}
+	err = eachJSONL(path, func(f Frame) error {
+		f.TaskIndex = task
+		return out.write(f)
+	})
+	if err != nil {
+		out.abort()
+		return err
+	}
+	if err := out.close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (d *Dataset) writeInfo() error {
+	data, err := json.MarshalIndent(d.info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(infoPath(d.root), data, 0644)
+}
+
+func infoPath(root string) string {
+	return filepath.Join(root, "meta", "info.json")
+}
+
+func episodeStatsPath(root string) string {
+	return filepath.Join(root, "meta", "episodes_stats.jsonl")
+}
+
+func tasksPath(root string) string {
+	return filepath.Join(root, "meta", "tasks.jsonl")
+}
+
+func readJSONL[T any](path string) ([]T, error) {
+	var items []T
+	err := eachJSONL(path, func(item T) error {
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// eachJSONL calls fn with each line of a JSON Lines file, which is zstd
+// compressed if its name ends in .zst.
+func eachJSONL[T any](path string, fn func(T) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".zst") {
+		z, err := newZstdReader(f)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		defer z.Close()
+		r = z
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var item T
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeJSONL[T any](path string, items []T) error {
+	w, err := createJSONL(path)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := w.write(item); err != nil {
+			w.f.Close()
+			return err
+		}
+	}
+	return w.close()
+}
+
+// jsonlWriter writes a JSON Lines file as it goes, zstd compressed if its
+// name ends in .zst.
+type jsonlWriter struct {
+	f   *os.File
+	out interface {
+		io.Writer
+		Flush() error
+	}
+	enc *json.Encoder
+}
+
+func createJSONL(path string) (*jsonlWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &jsonlWriter{f: f, out: bufio.NewWriter(f)}
+	if strings.HasSuffix(path, ".zst") {
+		w.out = newZstdWriter(f)
+	}
+	w.enc = json.NewEncoder(w.out)
+	return w, nil
+}
+
+func (w *jsonlWriter) write(v any) error { return w.enc.Encode(v) }
+
+func (w *jsonlWriter) close() error {
+	if err := w.out.Flush(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// abort closes and removes the file.
+func (w *jsonlWriter) abort() {
+	w.f.Close()
+	os.Remove(w.f.Name())
+}
+
+// Duration returns the length of the episode in seconds at the dataset's FPS.
+func (d *Dataset) Duration(e Episode) float64 {
+	if d.info.FPS == 0 {
+		return 0
+	}
+	return float64(e.Length) / float64(d.info.FPS)
+}
+
+// DeleteEpisodes removes the given episodes and renumbers the remaining ones
+// so episode indices stay contiguous.
+func (d *Dataset) DeleteEpisodes(indices ...int) error {
+	if d.Python() {
+		return errPython
+	}
+	drop := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		if i < 0 || i >= len(d.episodes) {
+			return fmt.Errorf("episode %d does not exist", i)
+		}
+		drop[i] = true
+	}
+
+	var keep []Episode
+	for _, e := range d.episodes {
+		if !drop[e.Index] {
+			keep = append(keep, e)
+		}
+	}
+
+	// Remove the media of the deleted episodes first, so the later ones
+	// move into free places; depth image directories can't be renamed
+	// onto others
+	for i := range drop {
+		for _, path := range d.mediaFiles(i) {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Rewrite remaining episodes in order; each new index is <= its old
+	// index, so no episode is overwritten before it has been read. The
+	// metadata is renumbered in a copy, and only replaced and written once
+	// all files are in place.
+	oldCount := len(d.episodes)
+	next := *d
+	next.episodes = nil
+	next.episodeStats = nil
+	next.info.TotalFrames = 0
+	for newIndex, e := range keep {
+		frames, err := d.ReadFrames(e.Index)
+		if err != nil {
+			return err
+		}
+		if err := next.appendEpisode(newIndex, e, frames); err != nil {
+			return err
+		}
+		if newIndex == e.Index {
+			continue
+		}
+		for i, path := range d.mediaFiles(e.Index) {
+			if err := os.Rename(path, d.mediaFiles(newIndex)[i]); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	for i := len(keep); i < oldCount; i++ {
+		if err := os.Remove(d.episodePath(i)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		for _, path := range d.mediaFiles(i) {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+	}
+	*d = next
+	return d.writeMeta()
+}
+
+// Merge creates a new dataset at root containing all episodes of srcs, in
+// order. All sources must have the same FPS and motors.
+func Merge(root string, srcs ...*Dataset) (*Dataset, error) {
+	if len(srcs) == 0 {
+		return nil, errors.New("nothing to merge")
+	}
+	first := srcs[0]
+	for _, src := range srcs[1:] {
+		if src.info.FPS != first.info.FPS {
+			return nil, fmt.Errorf("%s has %d fps, %s has %d fps", src.root, src.info.FPS, first.root, first.info.FPS)
+		}
+		if !slices.Equal(src.motors, first.motors) {
+			return nil, fmt.Errorf("%s and %s have different motors", src.root, first.root)
+		}
+		if !slices.Equal(src.VideoKeys(), first.VideoKeys()) {
+			return nil, fmt.Errorf("%s and %s have different cameras", src.root, first.root)
+		}
+		if !slices.Equal(src.DepthKeys(), first.DepthKeys()) {
+			return nil, fmt.Errorf("%s and %s have different depth cameras", src.root, first.root)
+		}
+		if !slices.Equal(src.AudioKeys(), first.AudioKeys()) {
+			return nil, fmt.Errorf("%s and %s have different microphones", src.root, first.root)
+		}
+		for _, name := range append(src.Sensors(), first.Sensors()...) {
+			key := FeatureSensorPrefix + name
+			if !slices.Equal(src.info.Features[key].Names, first.info.Features[key].Names) {
+				return nil, fmt.Errorf("%s and %s have different values of sensor %s", src.root, first.root, name)
+			}
+		}
+		if src.HasEffort() != first.HasEffort() {
+			return nil, fmt.Errorf("only one of %s and %s has effort", src.root, first.root)
+		}
+	}
+
+	dst, err := Create(root, first.info.FPS, first.motors)
+	if err != nil {
+		return nil, err
+	}
+	dst.info.RobotType = first.info.RobotType
+	dst.info.VideoPath = first.info.VideoPath
+	dst.info.ImagePath = first.info.ImagePath
+	dst.info.AudioPath = first.info.AudioPath
+	if first.Compressed() {
+		dst.info.DataPath = first.info.DataPath
+	}
+	dst.info.Features = maps.Clone(first.info.Features)
+
+	for _, src := range srcs {
+		for _, e := range src.episodes {
+			frames, err := src.ReadFrames(e.Index)
+			if err != nil {
+				return nil, err
+			}
+			// Task indices are per dataset
+			for i := range frames {
+				frames[i].TaskIndex = dst.taskIndex(src.frameTask(e, frames[i]))
+			}
+			index := len(dst.episodes)
+			if err := dst.appendEpisode(index, e, frames); err != nil {
+				return nil, err
+			}
+			for i, path := range src.mediaFiles(e.Index) {
+				if err := copyMedia(path, dst.mediaFiles(index)[i]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return dst, dst.writeMeta()
+}
+
+// appendEpisode writes frames as episode index and adds e (renumbered) to
+// the in-memory episode list. Call writeMeta afterwards.
+func (d *Dataset) appendEpisode(index int, e Episode, frames []Frame) error {
+	for i := range frames {
+		frames[i].Episode = index
+	}
+	if err := writeJSONL(d.episodePath(index), frames); err != nil {
+		return fmt.Errorf("write episode %d: %w", index, err)
+	}
+	e.Index = index
+	d.appendEpisodeStats(d.computeEpisodeStats(index, frames))
+	d.episodes = append(d.episodes, e)
+	d.info.TotalFrames += len(frames)
+	return nil
+}
+
+// appendEpisodeStats records the statistics of the episode about to be
+// appended, unless older episodes have none.
+func (d *Dataset) appendEpisodeStats(s EpisodeStats) {
+	if len(d.episodeStats) == len(d.episodes) {
+		d.episodeStats = append(d.episodeStats, s)
+	}
+}
+
+// writeMeta writes the episode and task lists and info.
+func (d *Dataset) writeMeta() error {
+	d.info.TotalEpisodes = len(d.episodes)
+	d.info.TotalTasks = len(d.tasks)
+	if err := writeJSONL(filepath.Join(d.root, "meta", "episodes.jsonl"), d.episodes); err != nil {
+		return err
+	}
+	if len(d.tasks) > 0 {
+		if err := writeJSONL(tasksPath(d.root), d.tasks); err != nil {
+			return err
+		}
+	}
+	if len(d.episodeStats) == len(d.episodes) {
+		if err := writeJSONL(episodeStatsPath(d.root), d.episodeStats); err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(aggregateStats(d.episodeStats), "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(d.root, "meta", "stats.json"), data, 0644); err != nil {
+			return err
+		}
+	}
+	return d.writeInfo()
+}
+
+// copyMedia copies a media file, or a directory of depth images.
+func copyMedia(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil || !info.IsDir() {
+		return copyFile(src, dst)
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := copyFile(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,1097 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+func TestDataset_RoundTrip(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ds")
+	motors := []robot.MotorName{robot.ShoulderPan, robot.Gripper}
+
+	ds, err := Create(root, 30, motors)
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	ep := ds.NewEpisode()
+	ep.Add(0, map[robot.MotorName]float64{robot.ShoulderPan: 10, robot.Gripper: -5},
+		map[robot.MotorName]float64{robot.ShoulderPan: 9, robot.Gripper: -4})
+	ep.Add(1.0/30, map[robot.MotorName]float64{robot.ShoulderPan: 11, robot.Gripper: -6},
+		map[robot.MotorName]float64{robot.ShoulderPan: 10, robot.Gripper: -5})
+	if err := ep.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	if _, err := Create(root, 30, motors); err == nil {
+		t.Error("Create() on existing dataset should fail")
+	}
+
+	opened, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	if info := opened.Info(); info.FPS != 30 || info.TotalEpisodes != 1 || info.TotalFrames != 2 {
+		t.Errorf("Info() = %+v, want fps 30, 1 episode, 2 frames", info)
+	}
+	if got := opened.Motors(); len(got) != 2 || got[0] != robot.ShoulderPan || got[1] != robot.Gripper {
+		t.Errorf("Motors() = %v, want %v", got, motors)
+	}
+
+	frames, err := opened.ReadFrames(0)
+	if err != nil {
+		t.Fatalf("ReadFrames() error: %v", err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("ReadFrames() returned %d frames, want 2", len(frames))
+	}
+	if frames[1].Action[0] != 11 || frames[1].State[1] != -5 || frames[1].Index != 1 {
+		t.Errorf("frame 1 = %+v", frames[1])
+	}
+}
+
+func TestDataset_DeleteAndMerge(t *testing.T) {
+	dir := t.TempDir()
+	motors := []robot.MotorName{robot.Gripper}
+
+	ds, err := Create(filepath.Join(dir, "a"), 30, motors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		ep := ds.NewEpisode()
+		for range i + 1 {
+			ep.Add(0, map[robot.MotorName]float64{robot.Gripper: float64(i)}, nil)
+		}
+		if err := ep.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ds.DeleteEpisodes(1); err != nil {
+		t.Fatalf("DeleteEpisodes() error: %v", err)
+	}
+	if n := len(ds.Episodes()); n != 2 {
+		t.Fatalf("after delete: %d episodes, want 2", n)
+	}
+	frames, err := ds.ReadFrames(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 3 || frames[0].Episode != 1 || frames[0].Action[0] != 2 {
+		t.Errorf("episode 1 after delete = %+v, want former episode 2", frames)
+	}
+
+	merged, err := Merge(filepath.Join(dir, "merged"), ds, ds)
+	if err != nil {
+		t.Fatalf("Merge() error: %v", err)
+	}
+	if info := merged.Info(); info.TotalEpisodes != 4 || info.TotalFrames != 8 {
+		t.Errorf("merged info = %+v, want 4 episodes and 8 frames", info)
+	}
+
+	stats, err := merged.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := stats[FeatureAction]; st.Min[0] != 0 || st.Max[0] != 2 {
+		t.Errorf("action stats = %+v, want min 0 max 2", st)
+	}
+}
+
+func TestDataset_Effort(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ds")
+	ds, err := Create(root, 30, []robot.MotorName{robot.ShoulderPan, robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.AddEffort(); err != nil {
+		t.Fatal(err)
+	}
+
+	ep := ds.NewEpisode()
+	ep.Add(0, map[robot.MotorName]float64{}, map[robot.MotorName]float64{})
+	ep.AddEffort(map[robot.MotorName]int{robot.Gripper: -250})
+	if err := ep.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.AddEffort(); err == nil {
+		t.Error("AddEffort() after recording should fail")
+	}
+
+	opened, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opened.HasEffort() {
+		t.Fatal("effort feature not saved")
+	}
+	frames, err := opened.ReadFrames(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := frames[0].Effort; len(got) != 2 || got[0] != 0 || got[1] != -25 {
+		t.Errorf("effort = %v, want [0 -25]", got)
+	}
+	stats, err := opened.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st, ok := stats[FeatureEffort]; !ok || st.Min[1] != -25 {
+		t.Errorf("effort stats = %+v", st)
+	}
+}
+
+func TestDataset_Sensors(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ds")
+	ds, err := Create(root, 30, []robot.MotorName{robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ep := ds.NewEpisode()
+	ep.Add(0, nil, nil) // before the first reading
+	ep.Add(1.0/30, nil, nil)
+	if err := ep.AddSensor("scale", map[string]float64{"weight": 120, "tare": 1}); err != nil {
+		t.Fatal(err)
+	}
+	ep.Add(2.0/30, nil, nil)
+	if err := ep.AddSensor("scale", map[string]float64{"weight": 80}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.AddSensor("scale", []string{"weight"}); err == nil {
+		t.Error("AddSensor() with other values after recording should fail")
+	}
+
+	opened, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := opened.Sensors(); !slices.Equal(got, []string{"scale"}) {
+		t.Errorf("Sensors() = %v", got)
+	}
+	frames, err := opened.ReadFrames(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string][]float64{nil, {"scale": {1, 120}}, {"scale": {0, 80}}}
+	for i, f := range frames {
+		if !reflect.DeepEqual(f.Sensors, want[i]) {
+			t.Errorf("frame %d sensors = %v, want %v", i, f.Sensors, want[i])
+		}
+	}
+	st := opened.EpisodeStats()[0].Stats[FeatureSensorPrefix+"scale"]
+	if st.Count[0] != 2 || st.Mean[1] != 100 {
+		t.Errorf("scale stats = %+v", st)
+	}
+}
+
+func TestDataset_Tasks(t *testing.T) {
+	dir := t.TempDir()
+	motors := []robot.MotorName{robot.Gripper}
+	record := func(name string, tasks ...string) *Dataset {
+		ds, err := Create(filepath.Join(dir, name), 30, motors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, task := range tasks {
+			ep := ds.NewEpisode()
+			ep.SetTask(task)
+			ep.Add(0, map[robot.MotorName]float64{robot.Gripper: 1}, nil)
+			if err := ep.Save(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ds
+	}
+
+	record("a", "pick cube", "stack cubes", "pick cube")
+	a, err := Open(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks := a.Tasks(); len(tasks) != 2 || tasks[1].Task != "stack cubes" || a.Info().TotalTasks != 2 {
+		t.Fatalf("Tasks() = %+v, total %d", tasks, a.Info().TotalTasks)
+	}
+	if frames, _ := a.ReadFrames(2); frames[0].TaskIndex != 0 {
+		t.Errorf("episode 2 task index = %d, want 0", frames[0].TaskIndex)
+	}
+
+	b := record("b", "open drawer", "stack cubes")
+	merged, err := Merge(filepath.Join(dir, "merged"), a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(merged.Tasks()); n != 3 {
+		t.Errorf("merged has %d tasks, want 3", n)
+	}
+	frames, _ := merged.ReadFrames(4)
+	if task := merged.Tasks()[frames[0].TaskIndex].Task; task != "stack cubes" {
+		t.Errorf("merged episode 4 task = %q, want stack cubes", task)
+	}
+	if e := merged.Episodes()[3]; len(e.Tasks) != 1 || e.Tasks[0] != "open drawer" {
+		t.Errorf("merged episode 3 tasks = %v", e.Tasks)
+	}
+
+	// An episode without a task doesn't take the index of another
+	c := record("c", "", "pick cube")
+	if tasks := c.Tasks(); len(tasks) != 2 || tasks[0].Task != "" {
+		t.Fatalf("Tasks() = %+v, want the empty task first", tasks)
+	}
+	if frames, _ := c.ReadFrames(1); c.Tasks()[frames[0].TaskIndex].Task != "pick cube" {
+		t.Errorf("episode 1 task index = %d", frames[0].TaskIndex)
+	}
+	merged, err = Merge(filepath.Join(dir, "merged-c"), a, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frames, _ := merged.ReadFrames(3); merged.Tasks()[frames[0].TaskIndex].Task != "" {
+		t.Errorf("merged episode 3 task = %q, want none", merged.Tasks()[frames[0].TaskIndex].Task)
+	}
+}
+
+func TestDataset_Compress(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ds")
+	ds, err := Create(root, 30, []robot.MotorName{robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Compress(); err != nil {
+		t.Fatal(err)
+	}
+
+	// More than a chunk, with the task changed halfway
+	const n = 5000
+	ep := ds.NewEpisode()
+	ep.SetTask("grasp")
+	for i := range n {
+		ep.Add(float64(i)/30, map[robot.MotorName]float64{robot.Gripper: float64(i % 100)}, nil)
+		if i == n/2 {
+			ep.SetTask("release")
+		}
+	}
+	if err := ep.Save(); err != nil {
+		t.Fatal(err)
+	}
+	discarded := ds.NewEpisode()
+	discarded.Add(0, nil, nil)
+	discarded.Add(1.0/30, nil, nil)
+	discarded.Discard()
+
+	path := filepath.Join(root, "data", "episode_000000.jsonl.zst")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binary.LittleEndian.Uint32(data) != zstdMagic {
+		t.Errorf("%s is not zstd compressed", path)
+	}
+	if _, err := os.Stat(filepath.Join(root, "data", "episode_000001.jsonl.zst")); !os.IsNotExist(err) {
+		t.Errorf("discarded episode was left on disk: %v", err)
+	}
+
+	opened, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opened.Compressed() {
+		t.Error("Compressed() = false after reopening")
+	}
+	frames, err := opened.ReadFrames(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != n {
+		t.Fatalf("ReadFrames() returned %d frames, want %d", len(frames), n)
+	}
+	for i, f := range frames {
+		if f.Index != i || f.Action[0] != float64(i%100) || opened.Tasks()[f.TaskIndex].Task != "release" {
+			t.Fatalf("frame %d = %+v", i, f)
+		}
+	}
+	if got := opened.EpisodeStats()[0].Stats[FeatureAction].Max[0]; got != 99 {
+		t.Errorf("action max = %v, want 99", got)
+	}
+	if err := ds.Compress(); err == nil {
+		t.Error("Compress() should fail once episodes are recorded")
+	}
+}
+
+func TestZstd(t *testing.T) {
+	var src bytes.Buffer
+	for i := range 20000 {
+		fmt.Fprintf(&src, `{"frame_index":%d,"action":[%g,%d]}`+"\n", i, float64(i%100)/7, i%13)
+	}
+	var compressed bytes.Buffer
+	w := newZstdWriter(&compressed)
+	if _, err := w.Write(src.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if compressed.Len() > src.Len()/5 {
+		t.Errorf("compressed %d bytes to %d", src.Len(), compressed.Len())
+	}
+	r, err := newZstdReader(bytes.NewReader(compressed.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, src.Bytes()) {
+		t.Error("zstd round trip changed the data")
+	}
+
+	// Every chunk is a frame of its own, so a recording cut short keeps
+	// the chunks before the last
+	cut := compressed.Bytes()[:compressed.Len()-10]
+	r, err = newZstdReader(bytes.NewReader(cut))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err = io.ReadAll(r)
+	if err == nil || len(got) < zstdChunkSize || !bytes.Equal(got, src.Bytes()[:len(got)]) {
+		t.Errorf("read %d bytes of a cut stream, %v; want the first chunks and an error", len(got), err)
+	}
+}
+
+func TestDataset_StatsFile(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ds")
+	ds, err := Create(root, 30, []robot.MotorName{robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, positions := range [][]float64{{1, 2, 3}, {10, -4}} {
+		ep := ds.NewEpisode()
+		for _, p := range positions {
+			pos := map[robot.MotorName]float64{robot.Gripper: p}
+			ep.Add(0, pos, pos)
+		}
+		if err := ep.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "meta", "stats.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]FeatureStats
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	want, err := ds.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, exp := saved[FeatureAction], want[FeatureAction]
+	if got.Count[0] != 5 || got.Min[0] != -4 || got.Max[0] != 10 ||
+		math.Abs(got.Mean[0]-exp.Mean[0]) > 1e-9 || math.Abs(got.Std[0]-exp.Std[0]) > 1e-9 {
+		t.Errorf("stats.json action = %+v, want %+v", got, exp)
+	}
+
+	opened, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es := opened.EpisodeStats(); len(es) != 2 || es[1].Stats[FeatureState].Count[0] != 2 {
+		t.Errorf("EpisodeStats() = %+v", es)
+	}
+}
+
+func TestResume(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ds")
+	motors := []robot.MotorName{robot.Gripper}
+	ds, err := Create(root, 30, motors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.AddCamera("front", 640, 480); err != nil {
+		t.Fatal(err)
+	}
+	ep := ds.NewEpisode()
+	ep.Add(0, map[robot.MotorName]float64{robot.Gripper: 1}, nil)
+	if err := ep.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Create(root, 30, motors); !errors.Is(err, ErrExists) {
+		t.Errorf("Create() of an existing dataset = %v, want ErrExists", err)
+	}
+	if _, err := Resume(root, 60, motors); err == nil {
+		t.Error("Resume() at another fps should fail")
+	}
+	if _, err := Resume(root, 30, []robot.MotorName{robot.ShoulderPan}); err == nil {
+		t.Error("Resume() with other motors should fail")
+	}
+
+	resumed, err := Resume(root, 30, motors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.AddCamera("front", 640, 480); err != nil {
+		t.Errorf("AddCamera() of the recorded camera: %v", err)
+	}
+	if err := resumed.AddCamera("front", 320, 240); err == nil {
+		t.Error("AddCamera() with another size should fail")
+	}
+	if i := resumed.NewEpisode().Index(); i != 1 {
+		t.Errorf("next episode = %d, want 1", i)
+	}
+}
+
+func TestDataset_Audio(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ds")
+	ds, err := Create(root, 30, []robot.MotorName{robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.AddAudio("mic", 16000, 1); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		ep := ds.NewEpisode()
+		ep.Add(0, map[robot.MotorName]float64{robot.Gripper: 1}, nil)
+		if err := ep.AddAudio("mic", []int16{1, 2, 3}); err != nil {
+			t.Fatal(err)
+		}
+		if err := ep.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key := FeatureAudioPrefix + "mic"
+	if keys := ds.AudioKeys(); len(keys) != 1 || keys[0] != key {
+		t.Fatalf("AudioKeys() = %v", keys)
+	}
+
+	if err := ds.DeleteEpisodes(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ds.AudioPath(key, 0)); err != nil {
+		t.Errorf("episode 1 audio not renamed to 0: %v", err)
+	}
+	if _, err := os.Stat(ds.AudioPath(key, 1)); !os.IsNotExist(err) {
+		t.Errorf("stale episode 1 audio: %v", err)
+	}
+}
+
+func TestDataset_Depth(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ds")
+	ds, err := Create(root, 30, []robot.MotorName{robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.AddDepth("front", 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		ep := ds.NewEpisode()
+		ep.Add(0, map[robot.MotorName]float64{robot.Gripper: 1}, nil)
+		if err := ep.AddDepth("front", []uint16{uint16(i), 1234}); err != nil {
+			t.Fatal(err)
+		}
+		if err := ep.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Depth image directories move onto those of deleted episodes
+	key := FeatureImagePrefix + "front" + FeatureDepthSuffix
+	if err := ds.DeleteEpisodes(0); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(ds.Episodes()); n != 2 {
+		t.Fatalf("%d episodes after deleting one of 3", n)
+	}
+	if _, err := os.Stat(ds.ImageDir(key, 2)); !os.IsNotExist(err) {
+		t.Errorf("stale episode 2 depth images: %v", err)
+	}
+
+	f, err := os.Open(ds.ImagePath(key, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gray, ok := img.(*image.Gray16)
+	if !ok || gray.Gray16At(0, 0).Y != 1 || gray.Gray16At(1, 0).Y != 1234 {
+		t.Errorf("depth image %T, want Gray16 of former episode 1 with 1234 at (1, 0)", img)
+	}
+
+	merged, err := Merge(filepath.Join(t.TempDir(), "merged"), ds, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(merged.ImagePath(key, 1, 0)); err != nil {
+		t.Errorf("depth image not merged: %v", err)
+	}
+}
+
+func TestEpisodeWriter_Check(t *testing.T) {
+	ds, err := Create(filepath.Join(t.TempDir(), "ds"), 10, []robot.MotorName{robot.ShoulderPan})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep := ds.NewEpisode()
+	// 2s at 10 fps with frames 5-9 dropped and a jump of 30 at frame 15
+	for i := range 20 {
+		if i >= 5 && i < 10 {
+			continue
+		}
+		pos := float64(i)
+		if i >= 15 {
+			pos += 30
+		}
+		ep.Add(float64(i)/10, map[robot.MotorName]float64{robot.ShoulderPan: pos}, map[robot.MotorName]float64{robot.ShoulderPan: pos})
+		img := []byte{byte(i)}
+		if i >= 10 {
+			img = []byte{0} // stalls for 1s
+		}
+		ep.trackImage("observation.images.front", img)
+	}
+
+	q := ep.Check(DefaultQualityLimits())
+	if q.Duration != 2 || q.Dropped != 0.25 || q.MaxJump != 31 || q.Frozen["observation.images.front"] != 1 {
+		t.Errorf("Check() = %+v", q)
+	}
+	if len(q.Issues) != 2 {
+		t.Errorf("Issues = %q, want the jump and dropped frames", q.Issues)
+	}
+	if err := ep.Save(); err != nil {
+		t.Fatal(err)
+	}
+	opened, err := Open(ds.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := opened.Episodes()[0].Quality; got == nil || got.MaxJump != 31 {
+		t.Errorf("saved quality = %+v", got)
+	}
+}
+
+func TestDataset_Export(t *testing.T) {
+	dir := t.TempDir()
+	ds, err := Create(filepath.Join(dir, "ds"), 30, []robot.MotorName{robot.ShoulderPan, robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep := ds.NewEpisode()
+	ep.SetTask("wave")
+	ep.Add(0, map[robot.MotorName]float64{robot.ShoulderPan: 10, robot.Gripper: 50},
+		map[robot.MotorName]float64{robot.ShoulderPan: 9.5, robot.Gripper: 49})
+	ep.Add(1.0/30, map[robot.MotorName]float64{robot.ShoulderPan: 11, robot.Gripper: 50},
+		map[robot.MotorName]float64{robot.ShoulderPan: 10.5, robot.Gripper: 49})
+	if err := ep.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	var csv strings.Builder
+	if err := ds.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	want := "episode_index,frame_index,timestamp,task,action.shoulder_pan,action.gripper,observation.state.shoulder_pan,observation.state.gripper\n" +
+		"0,0,0,wave,10,50,9.5,49\n"
+	if !strings.HasPrefix(csv.String(), want) {
+		t.Errorf("WriteCSV() =\n%s\nwant it to start with\n%s", csv.String(), want)
+	}
+
+	var parquet bytes.Buffer
+	if err := ds.WriteParquet(&parquet); err != nil {
+		t.Fatal(err)
+	}
+	if b := parquet.Bytes(); !bytes.HasPrefix(b, parquetMagic) || !bytes.HasSuffix(b, parquetMagic) {
+		t.Errorf("WriteParquet() is not framed by %q", parquetMagic)
+	}
+
+	bags := filepath.Join(dir, "bags")
+	if err := ds.WriteRosbag(bags, nil); err != nil {
+		t.Fatal(err)
+	}
+	mcap, err := os.ReadFile(filepath.Join(bags, "episode_000000", "episode_000000_0.mcap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(mcap, mcapMagic) || !bytes.HasSuffix(mcap, mcapMagic) {
+		t.Errorf("bag is not framed by the MCAP magic")
+	}
+	meta, err := os.ReadFile(filepath.Join(bags, "episode_000000", "metadata.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(meta), "message_count: 4\n") {
+		t.Errorf("metadata.yaml =\n%s\nwant 4 messages", meta)
+	}
+}
+
+// TestRosbag_ReadBack reads an exported bag back record by record, as the
+// MCAP spec lays them out, and decodes its messages.
+func TestRosbag_ReadBack(t *testing.T) {
+	ds, err := Create(filepath.Join(t.TempDir(), "ds"), 30, []robot.MotorName{robot.ShoulderPan, robot.Gripper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.AddEffort(); err != nil {
+		t.Fatal(err)
+	}
+	ep := ds.NewEpisode()
+	for i := range 3 {
+		pos := map[robot.MotorName]float64{robot.ShoulderPan: float64(i), robot.Gripper: -float64(i)}
+		ep.Add(float64(i)/30, pos, pos)
+		ep.AddEffort(map[robot.MotorName]int{robot.ShoulderPan: 100, robot.Gripper: 200})
+	}
+	if err := ep.Save(); err != nil {
+		t.Fatal(err)
+	}
+	bags := t.TempDir()
+	if err := ds.WriteRosbag(bags, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(bags, "episode_000000", "episode_000000_0.mcap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type message struct {
+		topic string
+		ns    uint64
+		js    jointState
+	}
+	var ops []byte
+	var messages []message
+	topics := make(map[uint16]string)
+	var dataEnd, summaryStart, statsCount uint64
+	if !bytes.HasPrefix(data, mcapMagic) || !bytes.HasSuffix(data, mcapMagic) {
+		t.Fatal("not framed by the MCAP magic")
+	}
+	for pos := uint64(len(mcapMagic)); pos < uint64(len(data)-len(mcapMagic)); {
+		op := data[pos]
+		n := binary.LittleEndian.Uint64(data[pos+1:])
+		body := data[pos+9 : pos+9+n]
+		ops = append(ops, op)
+		switch op {
+		case mcapChannel:
+			id := binary.LittleEndian.Uint16(body)
+			l := binary.LittleEndian.Uint32(body[4:])
+			topics[id] = string(body[8 : 8+l])
+		case mcapMessage:
+			ns := binary.LittleEndian.Uint64(body[6:])
+			js, err := decodeJointState(body[22:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			messages = append(messages, message{topics[binary.LittleEndian.Uint16(body)], ns, js})
+		case mcapDataEnd:
+			dataEnd = pos + 9 + n
+		case mcapStatistics:
+			statsCount = binary.LittleEndian.Uint64(body)
+		case mcapFooter:
+			summaryStart = binary.LittleEndian.Uint64(body)
+		}
+		pos += 9 + n
+	}
+
+	wantOps := []byte{mcapHeader, mcapSchema, mcapChannel, mcapChannel}
+	for range 6 {
+		wantOps = append(wantOps, mcapMessage)
+	}
+	wantOps = append(wantOps, mcapDataEnd, mcapSchema, mcapChannel, mcapChannel, mcapStatistics, mcapFooter)
+	if !slices.Equal(ops, wantOps) {
+		t.Errorf("records % x, want % x", ops, wantOps)
+	}
+	if summaryStart != dataEnd || statsCount != 6 {
+		t.Errorf("summary at %d after data end %d, %d messages counted", summaryStart, dataEnd, statsCount)
+	}
+	for i, m := range messages {
+		frame := i / 2
+		want := jointState{
+			ns:       uint64(math.Round(float64(frame) / 30 * 1e9)),
+			names:    []string{"shoulder_pan", "gripper"},
+			position: []float64{float64(frame), -float64(frame)},
+		}
+		wantTopic := RosbagActionTopic
+		if i%2 == 0 {
+			wantTopic, want.effort = RosbagStateTopic, []float64{10, 20} // percent
+		}
+		if m.topic != wantTopic || m.ns != want.ns || !reflect.DeepEqual(m.js, want) {
+			t.Errorf("message %d on %s at %d = %+v, want %+v on %s", i, m.topic, m.ns, m.js, want, wantTopic)
+		}
+	}
+}
+
+// jointState is a decoded sensor_msgs/msg/JointState.
+type jointState struct {
+	ns                         uint64
+	names                      []string
+	position, velocity, effort []float64
+}
+
+// decodeJointState decodes a little endian CDR JointState, with alignment
+// relative to the end of the encapsulation header.
+func decodeJointState(b []byte) (jointState, error) {
+	if len(b) < 4 || b[1] != 1 {
+		return jointState{}, fmt.Errorf("encapsulation % x", b[:min(4, len(b))])
+	}
+	b = b[4:]
+	pos := 0
+	align := func(n int) { pos = (pos + n - 1) / n * n }
+	u32 := func() uint32 {
+		align(4)
+		pos += 4
+		return binary.LittleEndian.Uint32(b[pos-4:])
+	}
+	str := func() string {
+		n := int(u32())
+		pos += n
+		return string(b[pos-n : pos-1])
+	}
+	doubles := func() []float64 {
+		n := int(u32())
+		if n == 0 {
+			return nil
+		}
+		align(8)
+		vs := make([]float64, n)
+		for i := range vs {
+			vs[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[pos:]))
+			pos += 8
+		}
+		return vs
+	}
+	var js jointState
+	sec := u32()
+	js.ns = uint64(sec)*1e9 + uint64(u32())
+	str() // frame_id
+	for range u32() {
+		js.names = append(js.names, str())
+	}
+	js.position, js.velocity, js.effort = doubles(), doubles(), doubles()
+	if pos != len(b) {
+		return js, fmt.Errorf("%d trailing bytes", len(b)-pos)
+	}
+	return js, nil
+}
+
+// TestThrift checks the compact protocol against bytes worked out from the
+// Thrift spec, and reads them back.
+func TestThrift(t *testing.T) {
+	s := thriftStruct{
+		{1, int32(5)},
+		{2, "ab"},
+		{4, thriftList{thriftTypeI32, []any{int32(1), int32(2)}}},
+		{20, int64(-1)}, // a gap over 15 writes the id in full
+		{21, thriftStruct{{1, true}}},
+	}
+	want := []byte{
+		0x15, 0x0a, // 1: i32 5, zigzag
+		0x18, 2, 'a', 'b', // 2: binary
+		0x29, 0x25, 0x02, 0x04, // 4: list of two i32
+		0x06, 0x28, 0x01, // 20: i64 -1
+		0x1c, 0x11, 0x00, // 21: struct with 1: true
+		0x00,
+	}
+	got := appendThrift(nil, s)
+	if !bytes.Equal(got, want) {
+		t.Errorf("appendThrift() = % x, want % x", got, want)
+	}
+	back, n, err := readThrift(got)
+	if err != nil || n != len(want) {
+		t.Fatalf("readThrift() read %d bytes, %v", n, err)
+	}
+	if back.i32(1) != 5 || back.str(2) != "ab" || !reflect.DeepEqual(back.list(4), []any{int32(1), int32(2)}) ||
+		back.i64(20) != -1 || back.strct(21).field(1) != true {
+		t.Errorf("readThrift() = %+v", back)
+	}
+}
+
+func TestJointStateCDR(t *testing.T) {
+	got := jointStateCDR(1_500_000_000, []string{"a"}, []float64{1}, nil)
+	want := []byte{
+		0, 1, 0, 0, // little endian CDR
+		1, 0, 0, 0, 0x00, 0x65, 0xcd, 0x1d, // stamp 1s 500000000ns
+		1, 0, 0, 0, 0, 0, 0, 0, // empty frame_id and padding
+		1, 0, 0, 0, 2, 0, 0, 0, 'a', 0, 0, 0, // name
+		1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // position, already aligned to 8
+		0, 0, 0, 0, // velocity
+		0, 0, 0, 0, // effort
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("jointStateCDR() =\n% x\nwant\n% x", got, want)
+	}
+}
+
+func TestReadParquet(t *testing.T) {
+	ds, err := Create(filepath.Join(t.TempDir(), "ds"), 30, []robot.MotorName{robot.ShoulderPan})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		ep := ds.NewEpisode()
+		ep.Add(0, map[robot.MotorName]float64{robot.ShoulderPan: 1.5}, nil)
+		ep.Add(0.5, map[robot.MotorName]float64{robot.ShoulderPan: -2}, nil)
+		if err := ep.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "export.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.WriteParquet(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	columns, err := readParquet(path, "timestamp", "action.shoulder_pan", "episode_index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][][]float64{
+		"timestamp":           {{0}, {0.5}, {0}, {0.5}},
+		"action.shoulder_pan": {{1.5}, {-2}, {1.5}, {-2}},
+		"episode_index":       {{0}, {0}, {1}, {1}},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("readParquet() = %v, want %v", columns, want)
+	}
+
+	// The footer has the rows and a row group per episode
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := binary.LittleEndian.Uint32(data[len(data)-8:])
+	footer, _, err := readThrift(data[len(data)-8-int(n) : len(data)-8])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if footer.i64(3) != 4 || len(footer.list(4)) != 2 {
+		t.Errorf("footer has %d rows in %d row groups, want 4 in 2", footer.i64(3), len(footer.list(4)))
+	}
+
+	// A footer with a number for a row group is an error, not a panic
+	for i, f := range footer {
+		if f.id == 4 {
+			footer[i].value = thriftList{thriftTypeI32, []any{int32(1)}}
+		}
+	}
+	corrupt := appendThrift(slices.Clone(data[:len(data)-8-int(n)]), footer)
+	corrupt = binary.LittleEndian.AppendUint32(corrupt, uint32(len(corrupt)-(len(data)-8-int(n))))
+	corrupt = append(corrupt, parquetMagic...)
+	if err := os.WriteFile(path, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readParquet(path, "timestamp"); err == nil || !strings.Contains(err.Error(), "bad row group") {
+		t.Errorf("readParquet() of a corrupt footer = %v, want a bad row group", err)
+	}
+}
+
+func TestSnappyDecode(t *testing.T) {
+	// A literal "a", then a copy of 9 bytes overlapping its own output
+	got, err := snappyDecode([]byte{10, 0x00, 'a', 0x15, 0x01})
+	if err != nil || string(got) != "aaaaaaaaaa" {
+		t.Errorf("snappyDecode() = %q, %v", got, err)
+	}
+	if _, err := snappyDecode([]byte{10, 0x00, 'a'}); err == nil {
+		t.Error("snappyDecode() of a short block should fail")
+	}
+}
+
+// writePythonDataset writes a dataset laid out by the Python
+// implementation, with one episode of two frames whose actions for the
+// motors names are [10, 20] and [20, 10], in an action list column as
+// pyarrow writes it: dictionary encoded, with repetition and definition
+// levels.
+func writePythonDataset(t *testing.T, names ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	quoted, _ := json.Marshal(names)
+	info := `{"codebase_version": "v2.0", "robot_type": "so100", "fps": 30, "chunks_size": 1000,
+		"data_path": "data/chunk-{episode_chunk:03d}/episode_{episode_index:06d}.parquet",
+		"video_path": "videos/chunk-{episode_chunk:03d}/{video_key}/episode_{episode_index:06d}.mp4",
+		"features": {
+			"action": {"dtype": "float32", "shape": [2], "names": ` + string(quoted) + `},
+			"observation.images.laptop": {"dtype": "video", "shape": [480, 640, 3], "names": ["height", "width", "channels"], "info": {"video.fps": 30}}
+		}}`
+	for path, data := range map[string]string{
+		"meta/info.json":      info,
+		"meta/episodes.jsonl": `{"episode_index": 0, "tasks": ["grasp"], "length": 2}` + "\n",
+		"meta/tasks.jsonl":    `{"task_index": 0, "task": "grasp"}` + "\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, path), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// timestamp: required float, plain
+	var file bytes.Buffer
+	file.Write(parquetMagic)
+	float32s := func(vs ...float32) []byte {
+		var b []byte
+		for _, v := range vs {
+			b = binary.LittleEndian.AppendUint32(b, math.Float32bits(v))
+		}
+		return b
+	}
+	page := func(header thriftStruct, body []byte) int64 {
+		offset := int64(file.Len())
+		header = append(thriftStruct{{1, header.i32(1)}, {2, int32(len(body))}, {3, int32(len(body))}}, header[1:]...)
+		file.Write(appendThrift(nil, header))
+		file.Write(body)
+		return offset
+	}
+	tsOffset := page(thriftStruct{{1, int32(0)}, {5, thriftStruct{{1, int32(2)}, {2, int32(0)}, {3, int32(3)}, {4, int32(3)}}}},
+		float32s(0, 1.0/30))
+	// action: two rows of two items, from the dictionary [10, 20]
+	dictOffset := page(thriftStruct{{1, int32(2)}, {7, thriftStruct{{1, int32(2)}, {2, int32(0)}}}}, float32s(10, 20))
+	levels := []byte{
+		2, 0, 0, 0, 0x03, 0x0a, // repetition 0, 1, 0, 1 bit-packed
+		2, 0, 0, 0, 0x08, 0x03, // definition 3 four times
+		1, 0x03, 0x06, // bit width 1, indices 0, 1, 1, 0 bit-packed
+	}
+	page(thriftStruct{{1, int32(0)}, {5, thriftStruct{{1, int32(4)}, {2, int32(8)}, {3, int32(3)}, {4, int32(3)}}}}, levels)
+	chunk := func(typ int32, path []string, values, offset, dict int64) thriftStruct {
+		var names []any
+		for _, p := range path {
+			names = append(names, p)
+		}
+		meta := thriftStruct{{1, typ}, {2, thriftList{thriftTypeI32, []any{int32(0)}}}, {3, thriftList{thriftTypeBinary, names}},
+			{4, int32(0)}, {5, values}, {6, int64(0)}, {7, int64(0)}, {9, offset}}
+		if dict > 0 {
+			meta = append(meta, thriftField{11, dict})
+		}
+		return thriftStruct{{2, offset}, {3, meta}}
+	}
+	footer := appendThrift(nil, thriftStruct{
+		{1, int32(1)},
+		{2, thriftList{thriftTypeStruct, []any{
+			thriftStruct{{4, "schema"}, {5, int32(2)}},
+			thriftStruct{{1, int32(parquetFloat)}, {3, int32(0)}, {4, "timestamp"}},
+			thriftStruct{{3, int32(1)}, {4, "action"}, {5, int32(1)}, {6, int32(3)}},
+			thriftStruct{{3, int32(2)}, {4, "list"}, {5, int32(1)}},
+			thriftStruct{{1, int32(parquetFloat)}, {3, int32(1)}, {4, "element"}},
+		}}},
+		{3, int64(2)},
+		{4, thriftList{thriftTypeStruct, []any{thriftStruct{
+			{1, thriftList{thriftTypeStruct, []any{
+				chunk(parquetFloat, []string{"timestamp"}, 2, tsOffset, 0),
+				chunk(parquetFloat, []string{"action", "list", "element"}, 4, dictOffset+0, dictOffset),
+			}}},
+			{2, int64(0)},
+			{3, int64(2)},
+		}}}},
+	})
+	file.Write(footer)
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	file.Write(parquetMagic)
+	data := filepath.Join(root, "data", "chunk-000", "episode_000000.parquet")
+	os.MkdirAll(filepath.Dir(data), 0755)
+	if err := os.WriteFile(data, file.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestOpen_Python(t *testing.T) {
+	root := writePythonDataset(t, "main_shoulder_pan", "main_gripper")
+	ds, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ds.Python() || ds.Units() != robot.UnitsDegrees {
+		t.Errorf("Python() = %v, Units() = %v", ds.Python(), ds.Units())
+	}
+	if got := ds.Motors(); !slices.Equal(got, []robot.MotorName{robot.ShoulderPan, robot.Gripper}) {
+		t.Errorf("Motors() = %v", got)
+	}
+	if got, want := ds.VideoPath("observation.images.laptop", 0), filepath.Join(root, "videos", "chunk-000", "observation.images.laptop", "episode_000000.mp4"); got != want {
+		t.Errorf("VideoPath() = %s, want %s", got, want)
+	}
+	frames, err := ds.ReadFrames(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || !slices.Equal(frames[0].Action, []float64{10, 20}) || !slices.Equal(frames[1].Action, []float64{20, 10}) ||
+		frames[1].Timestamp != float64(float32(1.0/30)) {
+		t.Errorf("ReadFrames() = %+v", frames)
+	}
+	if err := ds.DeleteEpisodes(0); err == nil {
+		t.Error("DeleteEpisodes() should refuse to change a Python dataset")
+	}
+}
+
+func TestOpen_PythonFile(t *testing.T) {
+	// Written by pyarrow rather than by hand like writePythonDataset
+	root := filepath.Join("testdata", "python")
+	if _, err := os.Stat(root); err != nil {
+		t.Skip("run testdata/make_python_dataset.py to create", root)
+	}
+	ds, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ds.Python() || ds.Units() != robot.UnitsNormalized || len(ds.Motors()) != 6 {
+		t.Errorf("Python() = %v, Units() = %v, Motors() = %v", ds.Python(), ds.Units(), ds.Motors())
+	}
+	frames, err := ds.ReadFrames(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("read %d frames, want 3", len(frames))
+	}
+	for i, f := range frames {
+		want := []float64{10 * float64(i), 10 * float64(i), 10 * float64(i), 10 * float64(i), 10 * float64(i), 50*float64(i) - 100}
+		if f.Index != i || !slices.Equal(f.Action, want) || len(f.State) != 6 || f.Timestamp != float64(float32(float64(i)/30)) {
+			t.Errorf("frame %d = %+v, want action %v", i, f, want)
+		}
+	}
+}
+
+func TestReadFrames_PythonGripper(t *testing.T) {
+	// From v0.3 on, the gripper goes from 0 to 100 and the other joints
+	// from -100 to 100
+	ds, err := Open(writePythonDataset(t, "shoulder_pan.pos", "gripper.pos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Units() != robot.UnitsNormalized {
+		t.Errorf("Units() = %v", ds.Units())
+	}
+	frames, err := ds.ReadFrames(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || !slices.Equal(frames[0].Action, []float64{10, -60}) || !slices.Equal(frames[1].Action, []float64{20, -80}) {
+		t.Errorf("ReadFrames() = %+v, want the gripper mapped to -100 to 100", frames)
+	}
+}
@@ -0,0 +1,68 @@
+package dataset
+
+import (
+	"image"
+	"image/png"
+	"os"
+)
+
+// depthEncoder trades compression for speed, to keep up with recording.
+var depthEncoder = png.Encoder{CompressionLevel: png.BestSpeed}
+
+// depthQueue is how many depth images may wait to be encoded before
+// AddDepth blocks, about a second at 30 fps.
+const depthQueue = 32
+
+// depthImage is a depth image waiting to be written to path.
+type depthImage struct {
+	path string
+	img  *image.Gray16
+}
+
+// depthWriter encodes depth images in the background, so PNG encoding
+// doesn't hold up the recording loop.
+type depthWriter struct {
+	queue chan depthImage
+	done  chan struct{}
+	err   error // first failure, read after done is closed
+}
+
+func newDepthWriter() *depthWriter {
+	w := &depthWriter{queue: make(chan depthImage, depthQueue), done: make(chan struct{})}
+	go w.run()
+	return w
+}
+
+func (w *depthWriter) run() {
+	defer close(w.done)
+	for d := range w.queue {
+		if w.err == nil {
+			w.err = writePNG(d.path, d.img)
+		}
+	}
+}
+
+// write queues img to be written to path.
+func (w *depthWriter) write(path string, img *image.Gray16) {
+	w.queue <- depthImage{path, img}
+}
+
+// close waits for the queued images to be written and returns the first
+// failure.
+func (w *depthWriter) close() error {
+	close(w.queue)
+	<-w.done
+	return w.err
+}
+
+func writePNG(path string, img image.Image) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := depthEncoder.Encode(out, img); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,306 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// Exported tables have a row per frame with these columns, followed by a
+// column per motor of each vector feature, named like action.shoulder_pan.
+var frameColumns = []parquetColumn{
+	{"episode_index", parquetInt64},
+	{"frame_index", parquetInt64},
+	{"timestamp", parquetDouble},
+	{"task", parquetByteArray},
+}
+
+// exportFeatures returns the vector features in the dataset.
+func (d *Dataset) exportFeatures() []string {
+	features := []string{FeatureAction, FeatureState}
+	if d.HasEffort() {
+		features = append(features, FeatureEffort)
+	}
+	return features
+}
+
+func (d *Dataset) exportColumns() []parquetColumn {
+	columns := slices.Clone(frameColumns)
+	for _, feature := range d.exportFeatures() {
+		for _, m := range d.motors {
+			columns = append(columns, parquetColumn{feature + "." + string(m), parquetDouble})
+		}
+	}
+	return columns
+}
+
+// frameTask returns the task of a frame of e.
+func (d *Dataset) frameTask(e Episode, f Frame) string {
+	if len(e.Tasks) == 0 || f.TaskIndex >= len(d.tasks) {
+		return ""
+	}
+	return d.tasks[f.TaskIndex].Task
+}
+
+// frameVector returns the values of feature in f, zero if it wasn't
+// recorded in the frame.
+func (d *Dataset) frameVector(f Frame, feature string) []float64 {
+	v := f.Action
+	switch feature {
+	case FeatureState:
+		v = f.State
+	case FeatureEffort:
+		v = f.Effort
+	}
+	if len(v) < len(d.motors) {
+		v = append(slices.Clone(v), make([]float64, len(d.motors)-len(v))...)
+	}
+	return v
+}
+
+// WriteCSV writes all frames as CSV with a header row. The columns are
+// episode_index, frame_index, timestamp and task, followed by one per motor
+// of action, observation.state and observation.effort, named like
+// action.shoulder_pan.
+func (d *Dataset) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	var header []string
+	for _, c := range d.exportColumns() {
+		header = append(header, c.name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range d.episodes {
+		frames, err := d.ReadFrames(e.Index)
+		if err != nil {
+			return fmt.Errorf("read episode %d: %w", e.Index, err)
+		}
+		for _, f := range frames {
+			row := []string{
+				strconv.Itoa(f.Episode),
+				strconv.Itoa(f.Index),
+				strconv.FormatFloat(f.Timestamp, 'f', -1, 64),
+				d.frameTask(e, f),
+			}
+			for _, feature := range d.exportFeatures() {
+				for _, v := range d.frameVector(f, feature) {
+					row = append(row, strconv.FormatFloat(v, 'f', -1, 64))
+				}
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteParquet writes all frames as a Parquet table with the columns of
+// WriteCSV and a row group per episode.
+func (d *Dataset) WriteParquet(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	columns := d.exportColumns()
+	pw, err := newParquetWriter(bw, columns)
+	if err != nil {
+		return err
+	}
+	for _, e := range d.episodes {
+		frames, err := d.ReadFrames(e.Index)
+		if err != nil {
+			return fmt.Errorf("read episode %d: %w", e.Index, err)
+		}
+		episodes := make([]int64, len(frames))
+		indices := make([]int64, len(frames))
+		timestamps := make([]float64, len(frames))
+		tasks := make([]string, len(frames))
+		motors := make([][]float64, len(columns)-len(frameColumns))
+		for i, f := range frames {
+			episodes[i], indices[i], timestamps[i] = int64(f.Episode), int64(f.Index), f.Timestamp
+			tasks[i] = d.frameTask(e, f)
+			col := 0
+			for _, feature := range d.exportFeatures() {
+				for _, v := range d.frameVector(f, feature) {
+					motors[col] = append(motors[col], v)
+					col++
+				}
+			}
+		}
+		values := []any{episodes, indices, timestamps, tasks}
+		for _, m := range motors {
+			values = append(values, m)
+		}
+		if err := pw.writeRowGroup(values); err != nil {
+			return err
+		}
+	}
+	if err := pw.close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Topics of exported bags.
+const (
+	RosbagStateTopic  = "/joint_states"   // observation.state, and observation.effort as effort
+	RosbagActionTopic = "/joint_commands" // action
+)
+
+// WriteRosbag writes a rosbag2 bag per episode to dir, named like the
+// episode, with sensor_msgs/msg/JointState messages on RosbagStateTopic and
+// RosbagActionTopic. Positions are converted to radians with arm, as the
+// ROS 2 bridge publishes them, or kept normalized if arm is nil. Message
+// times count from the start of each episode.
+func (d *Dataset) WriteRosbag(dir string, arm *robot.ArmConfig) error {
+	for _, e := range d.episodes {
+		frames, err := d.ReadFrames(e.Index)
+		if err != nil {
+			return fmt.Errorf("read episode %d: %w", e.Index, err)
+		}
+		if err := d.writeBag(filepath.Join(dir, fmt.Sprintf("episode_%06d", e.Index)), frames, arm); err != nil {
+			return fmt.Errorf("write episode %d: %w", e.Index, err)
+		}
+	}
+	return nil
+}
+
+func (d *Dataset) writeBag(dir string, frames []Frame, arm *robot.ArmConfig) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := filepath.Base(dir) + "_0.mcap"
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+
+	names := make([]string, len(d.motors))
+	for i, m := range d.motors {
+		names[i] = string(m)
+	}
+	positions := func(v []float64) []float64 {
+		if arm == nil {
+			return v
+		}
+		degrees := arm.Degrees(d.positions(v))
+		rad := make([]float64, len(d.motors))
+		for i, m := range d.motors {
+			rad[i] = degrees[m] * math.Pi / 180
+		}
+		return rad
+	}
+
+	mw := newMCAPWriter(bw, "sensor_msgs/msg/JointState", "ros2msg", jointStateDefinition)
+	state := mw.addChannel(RosbagStateTopic)
+	action := mw.addChannel(RosbagActionTopic)
+	for _, fr := range frames {
+		ns := uint64(math.Round(fr.Timestamp * 1e9))
+		mw.addMessage(state, ns, jointStateCDR(ns, names, positions(d.frameVector(fr, FeatureState)), fr.Effort))
+		mw.addMessage(action, ns, jointStateCDR(ns, names, positions(d.frameVector(fr, FeatureAction)), nil))
+	}
+	if err := mw.close(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(rosbagMetadata(name, mw))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "metadata.yaml"), data, 0644)
+}
+
+// positions converts a vector in dataset motor order to a motor map.
+func (d *Dataset) positions(v []float64) map[robot.MotorName]float64 {
+	m := make(map[robot.MotorName]float64, len(d.motors))
+	for i, name := range d.motors {
+		if i < len(v) {
+			m[name] = v[i]
+		}
+	}
+	return m
+}
+
+// rosbagInfo is metadata.yaml of a bag, as rosbag2 writes it.
+type rosbagInfo struct {
+	Info rosbagBag `yaml:"rosbag2_bagfile_information"`
+}
+
+type rosbagBag struct {
+	Version           int                `yaml:"version"`
+	StorageIdentifier string             `yaml:"storage_identifier"`
+	Duration          rosbagDuration     `yaml:"duration"`
+	StartingTime      rosbagTime         `yaml:"starting_time"`
+	MessageCount      uint64             `yaml:"message_count"`
+	Topics            []rosbagTopicCount `yaml:"topics_with_message_count"`
+	CompressionFormat string             `yaml:"compression_format"`
+	CompressionMode   string             `yaml:"compression_mode"`
+	RelativeFilePaths []string           `yaml:"relative_file_paths"`
+	Files             []rosbagFile       `yaml:"files"`
+}
+
+type rosbagDuration struct {
+	Nanoseconds uint64 `yaml:"nanoseconds"`
+}
+
+type rosbagTime struct {
+	NanosecondsSinceEpoch uint64 `yaml:"nanoseconds_since_epoch"`
+}
+
+type rosbagTopicCount struct {
+	Topic struct {
+		Name                string `yaml:"name"`
+		Type                string `yaml:"type"`
+		SerializationFormat string `yaml:"serialization_format"`
+		OfferedQoSProfiles  string `yaml:"offered_qos_profiles"`
+	} `yaml:"topic_metadata"`
+	MessageCount uint64 `yaml:"message_count"`
+}
+
+type rosbagFile struct {
+	Path         string         `yaml:"path"`
+	StartingTime rosbagTime     `yaml:"starting_time"`
+	Duration     rosbagDuration `yaml:"duration"`
+	MessageCount uint64         `yaml:"message_count"`
+}
+
+func rosbagMetadata(file string, mw *mcapWriter) rosbagInfo {
+	duration := rosbagDuration{mw.end - mw.start}
+	start := rosbagTime{mw.start}
+	bag := rosbagBag{
+		Version:           5,
+		StorageIdentifier: "mcap",
+		Duration:          duration,
+		StartingTime:      start,
+		MessageCount:      mw.messages,
+		RelativeFilePaths: []string{file},
+		Files:             []rosbagFile{{Path: file, StartingTime: start, Duration: duration, MessageCount: mw.messages}},
+	}
+	for i, topic := range []string{RosbagStateTopic, RosbagActionTopic} {
+		var t rosbagTopicCount
+		t.Topic.Name = topic
+		t.Topic.Type = "sensor_msgs/msg/JointState"
+		t.Topic.SerializationFormat = "cdr"
+		t.MessageCount = mw.counts[uint16(i+1)]
+		bag.Topics = append(bag.Topics, t)
+	}
+	return rosbagInfo{bag}
+}
@@ -0,0 +1,604 @@
+package dataset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// Parquet physical types, see parquet.thrift.
+const (
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetFloat     = 4
+	parquetDouble    = 5
+	parquetByteArray = 6
+)
+
+// Parquet encodings and codecs used here.
+const (
+	parquetPlain         = 0
+	parquetRLE           = 3
+	parquetUncompressed  = 0
+	parquetConvertedUTF8 = 0
+	parquetDataPage      = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// parquetColumn is a flat, required column.
+type parquetColumn struct {
+	name string
+	typ  int32 // parquetInt64, parquetDouble or parquetByteArray
+}
+
+// parquetWriter writes a Parquet file of flat, required columns, with a
+// row group per writeRowGroup call and a single uncompressed, plain
+// encoded page per column chunk. That is the simplest valid layout, which
+// pandas, pyarrow and DuckDB read.
+type parquetWriter struct {
+	w         io.Writer
+	offset    int64
+	columns   []parquetColumn
+	rowGroups []any
+	rows      int64
+}
+
+func newParquetWriter(w io.Writer, columns []parquetColumn) (*parquetWriter, error) {
+	p := &parquetWriter{w: w, columns: columns}
+	return p, p.write(parquetMagic)
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return err
+}
+
+// writeRowGroup writes a row group with values per column, a []int64,
+// []float64 or []string by the column's type, all of the same length.
+func (p *parquetWriter) writeRowGroup(values []any) error {
+	var rows int
+	var chunks []any
+	var size int64
+	for i, col := range p.columns {
+		data, n, err := plainEncode(col.typ, values[i])
+		if err != nil {
+			return fmt.Errorf("column %s: %w", col.name, err)
+		}
+		if i == 0 {
+			rows = n
+		} else if n != rows {
+			return fmt.Errorf("column %s has %d rows, want %d", col.name, n, rows)
+		}
+
+		header := appendThrift(nil, thriftStruct{
+			{1, int32(parquetDataPage)},
+			{2, int32(len(data))},
+			{3, int32(len(data))},
+			{5, thriftStruct{
+				{1, int32(n)},
+				{2, int32(parquetPlain)},
+				{3, int32(parquetRLE)},
+				{4, int32(parquetRLE)},
+			}},
+		})
+		start := p.offset
+		if err := p.write(header); err != nil {
+			return err
+		}
+		if err := p.write(data); err != nil {
+			return err
+		}
+		chunkSize := int64(len(header) + len(data))
+		size += chunkSize
+		chunks = append(chunks, thriftStruct{
+			{2, start},
+			{3, thriftStruct{
+				{1, col.typ},
+				{2, thriftList{thriftTypeI32, []any{int32(parquetPlain)}}},
+				{3, thriftList{thriftTypeBinary, []any{col.name}}},
+				{4, int32(parquetUncompressed)},
+				{5, int64(n)},
+				{6, chunkSize},
+				{7, chunkSize},
+				{9, start},
+			}},
+		})
+	}
+	p.rowGroups = append(p.rowGroups, thriftStruct{
+		{1, thriftList{thriftTypeStruct, chunks}},
+		{2, size},
+		{3, int64(rows)},
+	})
+	p.rows += int64(rows)
+	return nil
+}
+
+// close writes the footer. It does not close the underlying writer.
+func (p *parquetWriter) close() error {
+	schema := []any{thriftStruct{
+		{4, "schema"},
+		{5, int32(len(p.columns))},
+	}}
+	for _, col := range p.columns {
+		el := thriftStruct{
+			{1, col.typ},
+			{3, int32(0)}, // required
+			{4, col.name},
+		}
+		if col.typ == parquetByteArray {
+			el = append(el, thriftField{6, int32(parquetConvertedUTF8)})
+		}
+		schema = append(schema, el)
+	}
+	footer := appendThrift(nil, thriftStruct{
+		{1, int32(1)},
+		{2, thriftList{thriftTypeStruct, schema}},
+		{3, p.rows},
+		{4, thriftList{thriftTypeStruct, p.rowGroups}},
+		{6, "github.com/gwillem/lerobot"},
+	})
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	return p.write(append(footer, parquetMagic...))
+}
+
+// plainEncode encodes values of a column of type typ, returning the number
+// of values.
+func plainEncode(typ int32, values any) ([]byte, int, error) {
+	var b []byte
+	switch v := values.(type) {
+	case []int64:
+		if typ == parquetInt64 {
+			for _, x := range v {
+				b = binary.LittleEndian.AppendUint64(b, uint64(x))
+			}
+			return b, len(v), nil
+		}
+	case []float64:
+		if typ == parquetDouble {
+			for _, x := range v {
+				b = binary.LittleEndian.AppendUint64(b, math.Float64bits(x))
+			}
+			return b, len(v), nil
+		}
+	case []string:
+		if typ == parquetByteArray {
+			for _, s := range v {
+				b = binary.LittleEndian.AppendUint32(b, uint32(len(s)))
+				b = append(b, s...)
+			}
+			return b, len(v), nil
+		}
+	}
+	return nil, 0, fmt.Errorf("%T values for type %d", values, typ)
+}
+
+// parquetLeaf is a leaf column of a Parquet schema.
+type parquetLeaf struct {
+	path   []string
+	typ    int32
+	maxDef int
+	maxRep int
+}
+
+// parquetLeaves flattens schema elements, stored depth first with the root
+// first, to their leaf columns.
+func parquetLeaves(elements []any) ([]parquetLeaf, error) {
+	var leaves []parquetLeaf
+	var walk func(i int, path []string, def, rep int) (int, error)
+	walk = func(i int, path []string, def, rep int) (int, error) {
+		if i >= len(elements) {
+			return i, fmt.Errorf("parquet: truncated schema")
+		}
+		el, _ := elements[i].(thriftStruct)
+		if i > 0 {
+			path = append(slices.Clone(path), el.str(4))
+			switch el.i32(3) {
+			case 1: // optional
+				def++
+			case 2: // repeated
+				def++
+				rep++
+			}
+		}
+		children := int(el.i32(5))
+		if children == 0 && i > 0 {
+			leaves = append(leaves, parquetLeaf{path: path, typ: el.i32(1), maxDef: def, maxRep: rep})
+			return i + 1, nil
+		}
+		next := i + 1
+		for range children {
+			var err error
+			if next, err = walk(next, path, def, rep); err != nil {
+				return next, err
+			}
+		}
+		return next, nil
+	}
+	_, err := walk(0, nil, 0, 0)
+	return leaves, err
+}
+
+// readParquet reads the columns with the given top-level names from the
+// Parquet file at path, as a row of values per column: one value for
+// scalars, and the items of lists. Numbers of any type are returned as
+// float64. Missing columns are left out.
+func readParquet(path string, names ...string) (map[string][][]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	n := len(data)
+	if n < 12 || !bytes.Equal(data[:4], parquetMagic) || !bytes.Equal(data[n-4:], parquetMagic) {
+		return nil, fmt.Errorf("%s: not a Parquet file", path)
+	}
+	size := int(binary.LittleEndian.Uint32(data[n-8:]))
+	if size > n-12 {
+		return nil, fmt.Errorf("%s: bad footer", path)
+	}
+	meta, _, err := readThrift(data[n-8-size : n-8])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	leaves, err := parquetLeaves(meta.list(2))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	columns := make(map[string][][]float64)
+	for g, item := range meta.list(4) {
+		rg, ok := item.(thriftStruct)
+		if !ok {
+			return nil, fmt.Errorf("%s: bad row group %d", path, g)
+		}
+		chunks := rg.list(1)
+		for i, leaf := range leaves {
+			if !slices.Contains(names, leaf.path[0]) || i >= len(chunks) {
+				continue
+			}
+			chunk, ok := chunks[i].(thriftStruct)
+			if !ok {
+				return nil, fmt.Errorf("%s: bad row group %d: column %s", path, g, strings.Join(leaf.path, "."))
+			}
+			rows, err := readColumnChunk(data, chunk.strct(3), leaf)
+			if err != nil {
+				return nil, fmt.Errorf("%s: column %s: %w", path, strings.Join(leaf.path, "."), err)
+			}
+			columns[leaf.path[0]] = append(columns[leaf.path[0]], rows...)
+		}
+	}
+	return columns, nil
+}
+
+// Parquet page types, encodings and codecs the reader supports.
+const (
+	parquetDictionaryPage  = 2
+	parquetDataPageV2      = 3
+	parquetPlainDictionary = 2
+	parquetRLEDictionary   = 8
+	parquetSnappy          = 1
+	parquetGzip            = 2
+)
+
+// readColumnChunk reads the rows of one column chunk.
+func readColumnChunk(data []byte, meta thriftStruct, leaf parquetLeaf) ([][]float64, error) {
+	codec := meta.i32(4)
+	total := int(meta.i64(5))
+	pos := int(meta.i64(9))
+	if dict := int(meta.i64(11)); dict > 0 && dict < pos {
+		pos = dict
+	}
+
+	var dictionary []float64
+	var rows [][]float64
+	for read := 0; read < total; {
+		if pos >= len(data) {
+			return nil, fmt.Errorf("truncated")
+		}
+		header, n, err := readThrift(data[pos:])
+		if err != nil {
+			return nil, err
+		}
+		pos += n
+		size := int(header.i32(3))
+		if size < 0 || pos+size > len(data) {
+			return nil, fmt.Errorf("truncated page")
+		}
+		page := data[pos : pos+size]
+		pos += size
+
+		switch header.i32(1) {
+		case parquetDictionaryPage:
+			raw, err := decompress(codec, page)
+			if err != nil {
+				return nil, err
+			}
+			if dictionary, err = plainDecode(leaf.typ, raw, int(header.strct(7).i32(1))); err != nil {
+				return nil, err
+			}
+
+		case parquetDataPage:
+			h := header.strct(5)
+			raw, err := decompress(codec, page)
+			if err != nil {
+				return nil, err
+			}
+			count := int(h.i32(1))
+			var reps, defs []int
+			if leaf.maxRep > 0 {
+				if reps, raw, err = readLevels(raw, leaf.maxRep, count); err != nil {
+					return nil, err
+				}
+			}
+			if leaf.maxDef > 0 {
+				if defs, raw, err = readLevels(raw, leaf.maxDef, count); err != nil {
+					return nil, err
+				}
+			}
+			if rows, err = appendPage(rows, raw, h.i32(2), dictionary, leaf, reps, defs, count); err != nil {
+				return nil, err
+			}
+			read += count
+
+		case parquetDataPageV2:
+			h := header.strct(8)
+			count := int(h.i32(1))
+			defLen, repLen := int(h.i32(5)), int(h.i32(6))
+			if repLen+defLen > len(page) {
+				return nil, fmt.Errorf("truncated page")
+			}
+			var reps, defs []int
+			if leaf.maxRep > 0 {
+				if reps, err = rleDecode(page[:repLen], bitWidth(leaf.maxRep), count); err != nil {
+					return nil, err
+				}
+			}
+			if leaf.maxDef > 0 {
+				if defs, err = rleDecode(page[repLen:repLen+defLen], bitWidth(leaf.maxDef), count); err != nil {
+					return nil, err
+				}
+			}
+			values := page[repLen+defLen:]
+			if compressed, ok := h.field(7).(bool); !ok || compressed {
+				if values, err = decompress(codec, values); err != nil {
+					return nil, err
+				}
+			}
+			if rows, err = appendPage(rows, values, h.i32(4), dictionary, leaf, reps, defs, count); err != nil {
+				return nil, err
+			}
+			read += count
+
+		default: // index pages
+		}
+	}
+	return rows, nil
+}
+
+// appendPage decodes the values of a data page and appends them to rows:
+// a repetition level of 0 starts a row, and values are only present where
+// the definition level is the maximum.
+func appendPage(rows [][]float64, raw []byte, encoding int32, dictionary []float64, leaf parquetLeaf, reps, defs []int, count int) ([][]float64, error) {
+	present := count
+	if defs != nil {
+		present = 0
+		for _, d := range defs {
+			if d == leaf.maxDef {
+				present++
+			}
+		}
+	}
+
+	var values []float64
+	switch encoding {
+	case parquetPlain:
+		var err error
+		if values, err = plainDecode(leaf.typ, raw, present); err != nil {
+			return nil, err
+		}
+	case parquetPlainDictionary, parquetRLEDictionary:
+		if len(raw) == 0 {
+			break
+		}
+		indices, err := rleDecode(raw[1:], int(raw[0]), present)
+		if err != nil {
+			return nil, err
+		}
+		values = make([]float64, len(indices))
+		for i, idx := range indices {
+			if idx >= len(dictionary) {
+				return nil, fmt.Errorf("dictionary index %d out of range", idx)
+			}
+			values[i] = dictionary[idx]
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encoding %d", encoding)
+	}
+	if len(values) < present {
+		return nil, fmt.Errorf("page has %d values, want %d", len(values), present)
+	}
+
+	next := 0
+	for i := range count {
+		if reps == nil || reps[i] == 0 {
+			rows = append(rows, nil)
+		}
+		if defs == nil || defs[i] == leaf.maxDef {
+			rows[len(rows)-1] = append(rows[len(rows)-1], values[next])
+			next++
+		}
+	}
+	return rows, nil
+}
+
+func decompress(codec int32, b []byte) ([]byte, error) {
+	switch codec {
+	case parquetUncompressed:
+		return b, nil
+	case parquetSnappy:
+		return snappyDecode(b)
+	case parquetGzip:
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	}
+	return nil, fmt.Errorf("unsupported compression codec %d", codec)
+}
+
+// plainDecode decodes n plain encoded numbers.
+func plainDecode(typ int32, b []byte, n int) ([]float64, error) {
+	width := 8
+	if typ == parquetInt32 || typ == parquetFloat {
+		width = 4
+	}
+	if typ != parquetInt32 && typ != parquetInt64 && typ != parquetFloat && typ != parquetDouble {
+		return nil, fmt.Errorf("unsupported type %d", typ)
+	}
+	if len(b) < n*width {
+		return nil, fmt.Errorf("%d bytes for %d values", len(b), n)
+	}
+	values := make([]float64, n)
+	for i := range values {
+		v := b[i*width:]
+		switch typ {
+		case parquetInt32:
+			values[i] = float64(int32(binary.LittleEndian.Uint32(v)))
+		case parquetInt64:
+			values[i] = float64(int64(binary.LittleEndian.Uint64(v)))
+		case parquetFloat:
+			values[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(v)))
+		case parquetDouble:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(v))
+		}
+	}
+	return values, nil
+}
+
+// readLevels reads repetition or definition levels of a v1 data page,
+// prefixed by their length, and returns the rest of b.
+func readLevels(b []byte, maxLevel, n int) ([]int, []byte, error) {
+	if len(b) < 4 {
+		return nil, nil, fmt.Errorf("truncated levels")
+	}
+	size := int(binary.LittleEndian.Uint32(b))
+	if 4+size > len(b) {
+		return nil, nil, fmt.Errorf("truncated levels")
+	}
+	levels, err := rleDecode(b[4:4+size], bitWidth(maxLevel), n)
+	return levels, b[4+size:], err
+}
+
+func bitWidth(maxLevel int) int {
+	return bits.Len(uint(maxLevel))
+}
+
+// rleDecode decodes n values of the RLE/bit-packing hybrid encoding.
+func rleDecode(b []byte, width, n int) ([]int, error) {
+	out := make([]int, 0, n)
+	if width == 0 {
+		return out[:n], nil
+	}
+	pos := 0
+	for len(out) < n {
+		header, k := binary.Uvarint(b[pos:])
+		if k <= 0 {
+			return nil, fmt.Errorf("truncated levels")
+		}
+		pos += k
+		if header&1 == 1 { // bit-packed groups of 8
+			count := int(header>>1) * 8
+			end := pos + count*width/8
+			if end > len(b) {
+				return nil, fmt.Errorf("truncated levels")
+			}
+			for i := range count {
+				v := 0
+				for j := range width {
+					bit := i*width + j
+					v |= int(b[pos+bit/8]>>(bit%8)&1) << j
+				}
+				out = append(out, v)
+			}
+			pos = end
+		} else { // a run of one value
+			count := int(header >> 1)
+			size := (width + 7) / 8
+			if pos+size > len(b) {
+				return nil, fmt.Errorf("truncated levels")
+			}
+			v := 0
+			for i := range size {
+				v |= int(b[pos+i]) << (8 * i)
+			}
+			pos += size
+			for range count {
+				out = append(out, v)
+			}
+		}
+	}
+	return out[:n], nil
+}
+
+// readParquetFrames reads an episode of a dataset recorded by the Python
+// implementation.
+func (d *Dataset) readParquetFrames(episode int) ([]Frame, error) {
+	columns, err := readParquet(d.episodePath(episode),
+		"frame_index", "episode_index", "timestamp", "task_index", FeatureAction, FeatureState, FeatureEffort)
+	if err != nil {
+		return nil, err
+	}
+	actions := columns[FeatureAction]
+	if actions == nil {
+		return nil, fmt.Errorf("episode %d has no %s", episode, FeatureAction)
+	}
+	scalar := func(name string, i int) float64 {
+		if rows := columns[name]; i < len(rows) && len(rows[i]) > 0 {
+			return rows[i][0]
+		}
+		return 0
+	}
+	vector := func(name string, i int) []float64 {
+		if rows := columns[name]; i < len(rows) {
+			return rows[i]
+		}
+		return nil
+	}
+	frames := make([]Frame, len(actions))
+	for i := range frames {
+		frames[i] = Frame{
+			Index:     int(scalar("frame_index", i)),
+			Episode:   int(scalar("episode_index", i)),
+			Timestamp: scalar("timestamp", i),
+			Action:    actions[i],
+			State:     vector(FeatureState, i),
+			Effort:    vector(FeatureEffort, i),
+			TaskIndex: int(scalar("task_index", i)),
+		}
+	}
+
+	// From v0.3 on, the Python implementation normalizes the gripper from 0
+	// to 100 rather than -100 to 100 like the other joints
+	if gripper := slices.Index(d.motors, robot.Gripper); gripper >= 0 && d.Units() == robot.UnitsNormalized {
+		for _, f := range frames {
+			for _, values := range [][]float64{f.Action, f.State} {
+				if gripper < len(values) {
+					values[gripper] = 2*values[gripper] - 100
+				}
+			}
+		}
+	}
+	return frames, nil
+}
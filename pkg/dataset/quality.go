@@ -0,0 +1,122 @@
+package dataset
+
+import (
+	"fmt"
+	"hash/maphash"
+	"maps"
+	"math"
+	"slices"
+)
+
+// QualityLimits bound what Check accepts in an episode. Zero fields are
+// not checked.
+type QualityLimits struct {
+	MinDuration float64 // seconds
+	MaxDuration float64 // seconds
+	MaxJump     float64 // largest change of a joint between frames, in normalized units
+	MaxDropped  float64 // fraction of frames missing
+	MaxFrozen   float64 // seconds a camera image may stay exactly the same
+}
+
+// DefaultQualityLimits catches what spoils an episode for training: one
+// that ended right away, a joint jumping faster than it can move, such as
+// from a garbled read, many dropped frames, or a camera that stalled.
+func DefaultQualityLimits() QualityLimits {
+	return QualityLimits{
+		MinDuration: 1,
+		MaxJump:     20,
+		MaxDropped:  0.05,
+		MaxFrozen:   1,
+	}
+}
+
+// Quality is the outcome of the checks of an episode, stored with it in
+// meta/episodes.jsonl. See EpisodeWriter.Check.
+type Quality struct {
+	Duration float64            `json:"duration"`         // seconds, from the timestamps
+	Dropped  float64            `json:"dropped"`          // fraction of frames missing, from gaps in the timestamps
+	MaxJump  float64            `json:"max_jump"`         // largest change of a joint between frames
+	Frozen   map[string]float64 `json:"frozen,omitempty"` // longest time each camera's image stayed the same, in seconds
+	Issues   []string           `json:"issues,omitempty"` // limits exceeded, empty if the episode passed
+}
+
+// frozenRun tracks runs of identical images of a camera.
+type frozenRun struct {
+	last    uint64
+	run     int // frames in the current run
+	longest int
+}
+
+func (r *frozenRun) add(hash uint64) {
+	if r.run > 0 && hash == r.last {
+		r.run++
+	} else {
+		r.last, r.run = hash, 1
+	}
+	r.longest = max(r.longest, r.run)
+}
+
+// trackImage notes a camera image for the frozen image check.
+func (w *EpisodeWriter) trackImage(key string, rgb []byte) {
+	if w.frozen == nil {
+		w.frozen = make(map[string]*frozenRun)
+		w.seed = maphash.MakeSeed()
+	}
+	r, ok := w.frozen[key]
+	if !ok {
+		r = &frozenRun{}
+		w.frozen[key] = r
+	}
+	r.add(maphash.Bytes(w.seed, rgb))
+}
+
+// Check checks the episode recorded so far against limits, and stores the
+// outcome with the episode when it is saved.
+func (w *EpisodeWriter) Check(limits QualityLimits) Quality {
+	fps := float64(w.dataset.info.FPS)
+	var q Quality
+	if n := w.frames; n > 0 {
+		q.Duration = w.last.Timestamp - w.first + 1/fps
+		if expected := math.Round(q.Duration * fps); expected > float64(n) {
+			q.Dropped = 1 - float64(n)/expected
+		}
+	}
+	q.MaxJump = w.maxJump
+	for key, r := range w.frozen {
+		if q.Frozen == nil {
+			q.Frozen = make(map[string]float64, len(w.frozen))
+		}
+		q.Frozen[key] = float64(r.longest) / fps
+	}
+
+	if limits.MinDuration > 0 && q.Duration < limits.MinDuration {
+		q.Issues = append(q.Issues, fmt.Sprintf("only %.1fs long (min %.1fs)", q.Duration, limits.MinDuration))
+	}
+	if limits.MaxDuration > 0 && q.Duration > limits.MaxDuration {
+		q.Issues = append(q.Issues, fmt.Sprintf("%.1fs long (max %.1fs)", q.Duration, limits.MaxDuration))
+	}
+	if limits.MaxJump > 0 && q.MaxJump > limits.MaxJump {
+		q.Issues = append(q.Issues, fmt.Sprintf("a joint jumped %.1f between frames (max %.1f)", q.MaxJump, limits.MaxJump))
+	}
+	if limits.MaxDropped > 0 && q.Dropped > limits.MaxDropped {
+		q.Issues = append(q.Issues, fmt.Sprintf("%.0f%% of frames dropped (max %.0f%%)", q.Dropped*100, limits.MaxDropped*100))
+	}
+	if limits.MaxFrozen > 0 {
+		for _, key := range slices.Sorted(maps.Keys(q.Frozen)) {
+			if s := q.Frozen[key]; s > limits.MaxFrozen {
+				q.Issues = append(q.Issues, fmt.Sprintf("%s frozen for %.1fs (max %.1fs)", key, s, limits.MaxFrozen))
+			}
+		}
+	}
+	w.quality = &q
+	return q
+}
+
+// maxDiff returns the largest absolute difference between a and b.
+func maxDiff(a, b []float64) float64 {
+	var m float64
+	for i := range min(len(a), len(b)) {
+		m = max(m, math.Abs(a[i]-b[i]))
+	}
+	return m
+}
@@ -0,0 +1,222 @@
+package dataset
+
+import (
+	"encoding/binary"
+	"io"
+	"maps"
+	"math"
+	"slices"
+)
+
+// A rosbag2 bag is a directory with metadata.yaml and its messages in MCAP
+// files, the default storage since ROS 2 Iron. Messages are CDR encoded
+// like on the wire.
+
+var mcapMagic = []byte{0x89, 'M', 'C', 'A', 'P', '0', '\r', '\n'}
+
+// MCAP record opcodes.
+const (
+	mcapHeader     = 0x01
+	mcapFooter     = 0x02
+	mcapSchema     = 0x03
+	mcapChannel    = 0x04
+	mcapMessage    = 0x05
+	mcapDataEnd    = 0x0f
+	mcapStatistics = 0x0b
+)
+
+// jointStateDefinition is the ros2msg schema of sensor_msgs/msg/JointState
+// with its dependencies, as rosbag2 stores it.
+const jointStateDefinition = `std_msgs/Header header
+string[] name
+float64[] position
+float64[] velocity
+float64[] effort
+
+================================================================================
+MSG: std_msgs/Header
+builtin_interfaces/Time stamp
+string frame_id
+
+================================================================================
+MSG: builtin_interfaces/Time
+int32 sec
+uint32 nanosec
+`
+
+// mcapWriter writes an MCAP file of messages on channels that share one
+// schema, with a summary section so tools can list topics without reading
+// every message.
+type mcapWriter struct {
+	w        io.Writer
+	offset   uint64
+	schema   []byte // the schema record, repeated in the summary
+	channels [][]byte
+	counts   map[uint16]uint64
+	messages uint64
+	start    uint64 // log time of the first message
+	end      uint64 // log time of the last message
+	err      error
+}
+
+func newMCAPWriter(w io.Writer, schemaName, encoding, definition string) *mcapWriter {
+	m := &mcapWriter{w: w, counts: make(map[uint16]uint64)}
+	m.write(mcapMagic)
+	m.record(mcapHeader, mcapString(mcapString(nil, "ros2"), "github.com/gwillem/lerobot"))
+
+	schema := binary.LittleEndian.AppendUint16(nil, 1)
+	schema = mcapString(schema, schemaName)
+	schema = mcapString(schema, encoding)
+	schema = mcapBytes(schema, []byte(definition))
+	m.schema = schema
+	m.record(mcapSchema, schema)
+	return m
+}
+
+func (m *mcapWriter) write(b []byte) {
+	if m.err != nil {
+		return
+	}
+	n, err := m.w.Write(b)
+	m.offset += uint64(n)
+	m.err = err
+}
+
+func (m *mcapWriter) record(op byte, content []byte) {
+	b := binary.LittleEndian.AppendUint64([]byte{op}, uint64(len(content)))
+	m.write(append(b, content...))
+}
+
+// addChannel adds a topic with CDR messages and returns its id.
+func (m *mcapWriter) addChannel(topic string) uint16 {
+	id := uint16(len(m.channels) + 1)
+	c := binary.LittleEndian.AppendUint16(nil, id)
+	c = binary.LittleEndian.AppendUint16(c, 1) // schema id
+	c = mcapString(c, topic)
+	c = mcapString(c, "cdr")
+	var meta []byte
+	meta = mcapString(meta, "offered_qos_profiles")
+	meta = mcapString(meta, "")
+	c = mcapBytes(c, meta)
+	m.channels = append(m.channels, c)
+	m.record(mcapChannel, c)
+	return id
+}
+
+// addMessage adds a message logged at ns nanoseconds.
+func (m *mcapWriter) addMessage(channel uint16, ns uint64, data []byte) {
+	b := binary.LittleEndian.AppendUint16(nil, channel)
+	b = binary.LittleEndian.AppendUint32(b, uint32(m.counts[channel]))
+	b = binary.LittleEndian.AppendUint64(b, ns)
+	b = binary.LittleEndian.AppendUint64(b, ns)
+	m.record(mcapMessage, append(b, data...))
+
+	if m.messages == 0 || ns < m.start {
+		m.start = ns
+	}
+	m.end = max(m.end, ns)
+	m.counts[channel]++
+	m.messages++
+}
+
+// close writes the summary and footer. It does not close the underlying
+// writer.
+func (m *mcapWriter) close() error {
+	m.record(mcapDataEnd, make([]byte, 4)) // no CRC
+
+	summaryStart := m.offset
+	m.record(mcapSchema, m.schema)
+	for _, c := range m.channels {
+		m.record(mcapChannel, c)
+	}
+	s := binary.LittleEndian.AppendUint64(nil, m.messages)
+	s = binary.LittleEndian.AppendUint16(s, 1)                       // schemas
+	s = binary.LittleEndian.AppendUint32(s, uint32(len(m.channels))) // channels
+	s = append(s, make([]byte, 3*4)...)                              // attachments, metadata, chunks
+	s = binary.LittleEndian.AppendUint64(s, m.start)
+	s = binary.LittleEndian.AppendUint64(s, m.end)
+	var counts []byte
+	for _, id := range slices.Sorted(maps.Keys(m.counts)) {
+		counts = binary.LittleEndian.AppendUint16(counts, id)
+		counts = binary.LittleEndian.AppendUint64(counts, m.counts[id])
+	}
+	s = mcapBytes(s, counts)
+	m.record(mcapStatistics, s)
+
+	footer := binary.LittleEndian.AppendUint64(nil, summaryStart)
+	footer = binary.LittleEndian.AppendUint64(footer, 0) // no summary offsets
+	footer = binary.LittleEndian.AppendUint32(footer, 0) // no CRC
+	m.record(mcapFooter, footer)
+	m.write(mcapMagic)
+	return m.err
+}
+
+func mcapString(b []byte, s string) []byte {
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+func mcapBytes(b, data []byte) []byte {
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+// cdrWriter encodes a message in little endian CDR. Alignment is relative
+// to the end of the 4 byte encapsulation header.
+type cdrWriter struct {
+	b []byte
+}
+
+func newCDRWriter() *cdrWriter {
+	return &cdrWriter{b: []byte{0x00, 0x01, 0x00, 0x00}}
+}
+
+func (c *cdrWriter) align(n int) {
+	for (len(c.b)-4)%n != 0 {
+		c.b = append(c.b, 0)
+	}
+}
+
+func (c *cdrWriter) uint32(v uint32) {
+	c.align(4)
+	c.b = binary.LittleEndian.AppendUint32(c.b, v)
+}
+
+func (c *cdrWriter) string(s string) {
+	c.uint32(uint32(len(s) + 1))
+	c.b = append(c.b, s...)
+	c.b = append(c.b, 0)
+}
+
+func (c *cdrWriter) strings(ss []string) {
+	c.uint32(uint32(len(ss)))
+	for _, s := range ss {
+		c.string(s)
+	}
+}
+
+// float64s writes a sequence of doubles. Like Fast CDR, the values are
+// only aligned if there are any.
+func (c *cdrWriter) float64s(vs []float64) {
+	c.uint32(uint32(len(vs)))
+	if len(vs) > 0 {
+		c.align(8)
+	}
+	for _, v := range vs {
+		c.b = binary.LittleEndian.AppendUint64(c.b, math.Float64bits(v))
+	}
+}
+
+// jointStateCDR encodes a sensor_msgs/msg/JointState stamped at ns
+// nanoseconds.
+func jointStateCDR(ns uint64, names []string, position, effort []float64) []byte {
+	c := newCDRWriter()
+	c.uint32(uint32(ns / 1e9))
+	c.uint32(uint32(ns % 1e9))
+	c.string("") // frame_id
+	c.strings(names)
+	c.float64s(position)
+	c.float64s(nil) // velocity
+	c.float64s(effort)
+	return c.b
+}
@@ -0,0 +1,79 @@
+package dataset
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errSnappy = errors.New("snappy: corrupt input")
+
+// snappyDecode decompresses a Snappy block, the default compression of
+// Parquet files written by pyarrow.
+func snappyDecode(src []byte) ([]byte, error) {
+	n, k := binary.Uvarint(src)
+	if k <= 0 || n > 1<<30 {
+		return nil, errSnappy
+	}
+	src = src[k:]
+	dst := make([]byte, 0, n)
+	for len(src) > 0 {
+		tag := src[0]
+		var length, offset int
+		switch tag & 0x03 {
+		case 0x00: // literal
+			length = int(tag >> 2)
+			src = src[1:]
+			if length >= 60 {
+				extra := length - 59
+				if len(src) < extra {
+					return nil, errSnappy
+				}
+				length = 0
+				for i := range extra {
+					length |= int(src[i]) << (8 * i)
+				}
+				src = src[extra:]
+			}
+			length++
+			if len(src) < length {
+				return nil, errSnappy
+			}
+			dst = append(dst, src[:length]...)
+			src = src[length:]
+			continue
+		case 0x01: // copy with 1 byte offset
+			if len(src) < 2 {
+				return nil, errSnappy
+			}
+			length = 4 + int(tag>>2&0x07)
+			offset = int(tag&0xe0)<<3 | int(src[1])
+			src = src[2:]
+		case 0x02: // copy with 2 byte offset
+			if len(src) < 3 {
+				return nil, errSnappy
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[1:]))
+			src = src[3:]
+		case 0x03: // copy with 4 byte offset
+			if len(src) < 5 {
+				return nil, errSnappy
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[1:]))
+			src = src[5:]
+		}
+		if offset <= 0 || offset > len(dst) {
+			return nil, errSnappy
+		}
+		// Copies may overlap their own output, so go byte by byte
+		start := len(dst) - offset
+		for i := range length {
+			dst = append(dst, dst[start+i])
+		}
+	}
+	if uint64(len(dst)) != n {
+		return nil, errSnappy
+	}
+	return dst, nil
+}
@@ -0,0 +1,231 @@
+package dataset
+
+import (
+	"math"
+	"slices"
+)
+
+// FeatureStats holds per-dimension statistics of a vector feature.
+type FeatureStats struct {
+	Min   []float64 `json:"min"`
+	Max   []float64 `json:"max"`
+	Mean  []float64 `json:"mean"`
+	Std   []float64 `json:"std"`
+	Count []int     `json:"count"` // number of frames, a single value as in LeRobot
+}
+
+// EpisodeStats holds the statistics of one episode, stored in
+// meta/episodes_stats.jsonl.
+type EpisodeStats struct {
+	Index int                     `json:"episode_index"`
+	Stats map[string]FeatureStats `json:"stats"`
+}
+
+// Stats computes statistics of the action and observation.state features,
+// and observation.effort and sensors if recorded, over all frames of all
+// episodes. These are also written to meta/stats.json on every save, for
+// normalization in LeRobot training.
+func (d *Dataset) Stats() (map[string]FeatureStats, error) {
+	acc := d.newFrameStats()
+	for _, e := range d.episodes {
+		frames, err := d.ReadFrames(e.Index)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range frames {
+			acc.add(f)
+		}
+	}
+	return acc.stats(), nil
+}
+
+// EpisodeStats returns the statistics of every episode, as computed when it
+// was saved. Datasets recorded before statistics were stored have none.
+func (d *Dataset) EpisodeStats() []EpisodeStats { return d.episodeStats }
+
+// WriteStats recomputes the statistics of every episode and writes them,
+// e.g. for datasets recorded before statistics were stored.
+func (d *Dataset) WriteStats() error {
+	if d.Python() {
+		return errPython
+	}
+	stats := make([]EpisodeStats, 0, len(d.episodes))
+	for _, e := range d.episodes {
+		frames, err := d.ReadFrames(e.Index)
+		if err != nil {
+			return err
+		}
+		stats = append(stats, d.computeEpisodeStats(e.Index, frames))
+	}
+	d.episodeStats = stats
+	return d.writeMeta()
+}
+
+// frameStats accumulates the statistics of the vector features of frames.
+type frameStats struct {
+	action, state, effort *statsAccumulator
+	hasEffort             bool
+	sensors               map[string]*statsAccumulator
+}
+
+func (d *Dataset) newFrameStats() *frameStats {
+	s := &frameStats{
+		action:    newStatsAccumulator(len(d.motors)),
+		state:     newStatsAccumulator(len(d.motors)),
+		effort:    newStatsAccumulator(len(d.motors)),
+		hasEffort: d.HasEffort(),
+	}
+	for _, name := range d.Sensors() {
+		s.addSensor(name, len(d.info.Features[FeatureSensorPrefix+name].Names))
+	}
+	return s
+}
+
+// addSensor starts the statistics of a sensor with dim values.
+func (s *frameStats) addSensor(name string, dim int) {
+	if s.sensors == nil {
+		s.sensors = make(map[string]*statsAccumulator)
+	}
+	s.sensors[name] = newStatsAccumulator(dim)
+}
+
+func (s *frameStats) add(f Frame) {
+	s.action.add(f.Action)
+	s.state.add(f.State)
+	if f.Effort != nil {
+		s.effort.add(f.Effort)
+	}
+	for name, v := range f.Sensors {
+		if acc, ok := s.sensors[name]; ok {
+			acc.add(v)
+		}
+	}
+}
+
+func (s *frameStats) stats() map[string]FeatureStats {
+	stats := map[string]FeatureStats{
+		FeatureAction: s.action.stats(),
+		FeatureState:  s.state.stats(),
+	}
+	if s.hasEffort {
+		stats[FeatureEffort] = s.effort.stats()
+	}
+	for name, acc := range s.sensors {
+		stats[FeatureSensorPrefix+name] = acc.stats()
+	}
+	return stats
+}
+
+// computeEpisodeStats computes the statistics of an episode's frames.
+func (d *Dataset) computeEpisodeStats(index int, frames []Frame) EpisodeStats {
+	acc := d.newFrameStats()
+	for _, f := range frames {
+		acc.add(f)
+	}
+	return EpisodeStats{Index: index, Stats: acc.stats()}
+}
+
+// aggregateStats combines per-episode statistics into dataset statistics,
+// weighting each episode by its frame count.
+func aggregateStats(episodes []EpisodeStats) map[string]FeatureStats {
+	total := make(map[string]FeatureStats)
+	for _, e := range episodes {
+		for feature, st := range e.Stats {
+			total[feature] = mergeFeatureStats(total[feature], st)
+		}
+	}
+	return total
+}
+
+// mergeFeatureStats combines the statistics of two disjoint sets of frames.
+func mergeFeatureStats(a, b FeatureStats) FeatureStats {
+	na, nb := count(a), count(b)
+	switch {
+	case nb == 0:
+		return a
+	case na == 0:
+		return b
+	}
+	n := na + nb
+	m := FeatureStats{
+		Min:   slices.Clone(a.Min),
+		Max:   slices.Clone(a.Max),
+		Mean:  make([]float64, len(a.Mean)),
+		Std:   make([]float64, len(a.Mean)),
+		Count: []int{n},
+	}
+	wa, wb := float64(na)/float64(n), float64(nb)/float64(n)
+	for i := range m.Mean {
+		m.Min[i] = min(a.Min[i], b.Min[i])
+		m.Max[i] = max(a.Max[i], b.Max[i])
+		m.Mean[i] = wa*a.Mean[i] + wb*b.Mean[i]
+		da, db := a.Mean[i]-m.Mean[i], b.Mean[i]-m.Mean[i]
+		m.Std[i] = math.Sqrt(wa*(a.Std[i]*a.Std[i]+da*da) + wb*(b.Std[i]*b.Std[i]+db*db))
+	}
+	return m
+}
+
+func count(s FeatureStats) int {
+	if len(s.Count) == 0 {
+		return 0
+	}
+	return s.Count[0]
+}
+
+// statsAccumulator computes running statistics using Welford's algorithm.
+type statsAccumulator struct {
+	n    int
+	min  []float64
+	max  []float64
+	mean []float64
+	m2   []float64
+}
+
+func newStatsAccumulator(dim int) *statsAccumulator {
+	a := &statsAccumulator{
+		min:  make([]float64, dim),
+		max:  make([]float64, dim),
+		mean: make([]float64, dim),
+		m2:   make([]float64, dim),
+	}
+	for i := range dim {
+		a.min[i] = math.Inf(1)
+		a.max[i] = math.Inf(-1)
+	}
+	return a
+}
+
+func (a *statsAccumulator) add(v []float64) {
+	a.n++
+	for i := range a.mean {
+		if i >= len(v) {
+			break
+		}
+		x := v[i]
+		a.min[i] = min(a.min[i], x)
+		a.max[i] = max(a.max[i], x)
+		delta := x - a.mean[i]
+		a.mean[i] += delta / float64(a.n)
+		a.m2[i] += delta * (x - a.mean[i])
+	}
+}
+
+func (a *statsAccumulator) stats() FeatureStats {
+	s := FeatureStats{
+		Min:   make([]float64, len(a.mean)),
+		Max:   make([]float64, len(a.mean)),
+		Mean:  make([]float64, len(a.mean)),
+		Std:   make([]float64, len(a.mean)),
+		Count: []int{a.n},
+	}
+	if a.n == 0 {
+		return s
+	}
+	copy(s.Min, a.min)
+	copy(s.Max, a.max)
+	copy(s.Mean, a.mean)
+	for i := range a.m2 {
+		s.Std[i] = math.Sqrt(a.m2[i] / float64(a.n))
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+"""Writes testdata/python, a LeRobot v2.1 dataset as the Python
+implementation records it, for TestOpen_PythonFile. The Parquet file is
+written by pyarrow with its defaults, like LeRobot's datasets library does:
+snappy compression and dictionary encoding.
+
+    pip install pyarrow
+    python make_python_dataset.py
+"""
+
+import json
+import os
+
+import pyarrow as pa
+import pyarrow.parquet as pq
+
+ROOT = os.path.join(os.path.dirname(os.path.abspath(__file__)), "python")
+MOTORS = ["shoulder_pan", "shoulder_lift", "elbow_flex", "wrist_flex", "wrist_roll", "gripper"]
+FRAMES = 3
+
+
+def write_json(path, obj, lines=False):
+    path = os.path.join(ROOT, path)
+    os.makedirs(os.path.dirname(path), exist_ok=True)
+    with open(path, "w") as f:
+        if lines:
+            for o in obj:
+                f.write(json.dumps(o) + "\n")
+        else:
+            json.dump(obj, f, indent=2)
+
+
+names = [m + ".pos" for m in MOTORS]
+vector = {"dtype": "float32", "shape": [len(MOTORS)], "names": names}
+scalar = lambda dtype: {"dtype": dtype, "shape": [1], "names": None}
+write_json("meta/info.json", {
+    "codebase_version": "v2.1",
+    "robot_type": "so101_follower",
+    "total_episodes": 1,
+    "total_frames": FRAMES,
+    "total_tasks": 1,
+    "chunks_size": 1000,
+    "fps": 30,
+    "splits": {"train": "0:1"},
+    "data_path": "data/chunk-{episode_chunk:03d}/episode_{episode_index:06d}.parquet",
+    "video_path": "videos/chunk-{episode_chunk:03d}/{video_key}/episode_{episode_index:06d}.mp4",
+    "features": {
+        "action": vector,
+        "observation.state": vector,
+        "timestamp": scalar("float32"),
+        "frame_index": scalar("int64"),
+        "episode_index": scalar("int64"),
+        "index": scalar("int64"),
+        "task_index": scalar("int64"),
+    },
+})
+write_json("meta/episodes.jsonl", [{"episode_index": 0, "tasks": ["grasp the cube"], "length": FRAMES}], lines=True)
+write_json("meta/tasks.jsonl", [{"task_index": 0, "task": "grasp the cube"}], lines=True)
+
+# Frame i moves every joint to 10*i and the gripper, from 0 to 100, to 25*i
+action = [[10.0 * i] * (len(MOTORS) - 1) + [25.0 * i] for i in range(FRAMES)]
+state = [[v - 1 for v in a] for a in action]
+floats = pa.list_(pa.float32())
+table = pa.table({
+    "action": pa.array(action, type=floats),
+    "observation.state": pa.array(state, type=floats),
+    "timestamp": pa.array([i / 30 for i in range(FRAMES)], type=pa.float32()),
+    "frame_index": pa.array(range(FRAMES), type=pa.int64()),
+    "episode_index": pa.array([0] * FRAMES, type=pa.int64()),
+    "index": pa.array(range(FRAMES), type=pa.int64()),
+    "task_index": pa.array([0] * FRAMES, type=pa.int64()),
+})
+path = os.path.join(ROOT, "data", "chunk-000", "episode_000000.parquet")
+os.makedirs(os.path.dirname(path), exist_ok=True)
+pq.write_table(table, path)
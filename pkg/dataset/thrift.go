@@ -0,0 +1,276 @@
+package dataset
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The Parquet footer and page headers are Thrift structs in the compact
+// protocol. Only what Parquet needs is supported, with structs kept
+// generic: fields by id, values as int32, int64, bool, []byte, thriftList
+// or thriftStruct.
+
+// Compact protocol type ids.
+const (
+	thriftTypeBoolTrue  = 1
+	thriftTypeBoolFalse = 2
+	thriftTypeByte      = 3
+	thriftTypeI16       = 4
+	thriftTypeI32       = 5
+	thriftTypeI64       = 6
+	thriftTypeDouble    = 7
+	thriftTypeBinary    = 8
+	thriftTypeList      = 9
+	thriftTypeSet       = 10
+	thriftTypeMap       = 11
+	thriftTypeStruct    = 12
+)
+
+type thriftField struct {
+	id    int16
+	value any
+}
+
+type thriftStruct []thriftField
+
+type thriftList struct {
+	elem  byte // type id of the items
+	items []any
+}
+
+// field returns the value of field id, or nil.
+func (s thriftStruct) field(id int16) any {
+	for _, f := range s {
+		if f.id == id {
+			return f.value
+		}
+	}
+	return nil
+}
+
+func thriftType(v any) byte {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return thriftTypeBoolTrue
+		}
+		return thriftTypeBoolFalse
+	case int32:
+		return thriftTypeI32
+	case int64:
+		return thriftTypeI64
+	case []byte, string:
+		return thriftTypeBinary
+	case thriftList:
+		return thriftTypeList
+	case thriftStruct:
+		return thriftTypeStruct
+	}
+	panic(fmt.Sprintf("thrift: unsupported %T", v))
+}
+
+// appendThrift encodes s in the compact protocol.
+func appendThrift(b []byte, s thriftStruct) []byte {
+	var last int16
+	for _, f := range s {
+		typ := thriftType(f.value)
+		if delta := f.id - last; delta > 0 && delta <= 15 {
+			b = append(b, byte(delta)<<4|typ)
+		} else {
+			b = append(b, typ)
+			b = binary.AppendVarint(b, int64(f.id))
+		}
+		last = f.id
+		if typ != thriftTypeBoolTrue && typ != thriftTypeBoolFalse {
+			b = appendThriftValue(b, f.value)
+		}
+	}
+	return append(b, 0)
+}
+
+func appendThriftValue(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case int32:
+		return binary.AppendVarint(b, int64(v))
+	case int64:
+		return binary.AppendVarint(b, v)
+	case string:
+		b = binary.AppendUvarint(b, uint64(len(v)))
+		return append(b, v...)
+	case []byte:
+		b = binary.AppendUvarint(b, uint64(len(v)))
+		return append(b, v...)
+	case thriftList:
+		if n := len(v.items); n < 15 {
+			b = append(b, byte(n)<<4|v.elem)
+		} else {
+			b = append(b, 0xf0|v.elem)
+			b = binary.AppendUvarint(b, uint64(n))
+		}
+		for _, item := range v.items {
+			b = appendThriftValue(b, item)
+		}
+		return b
+	case thriftStruct:
+		return appendThrift(b, v)
+	}
+	panic(fmt.Sprintf("thrift: unsupported %T", v))
+}
+
+// readThrift decodes a struct in the compact protocol from the start of b,
+// returning it and the number of bytes it took. Lists of structs, integers
+// and binaries are decoded, other collections are skipped.
+func readThrift(b []byte) (thriftStruct, int, error) {
+	r := thriftReader{b: b}
+	s := r.readStruct()
+	return s, r.pos, r.err
+}
+
+type thriftReader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *thriftReader) fail(format string, args ...any) {
+	if r.err == nil {
+		r.err = fmt.Errorf("thrift: "+format, args...)
+	}
+	r.pos = len(r.b)
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.b) {
+		r.fail("unexpected end")
+		return 0
+	}
+	r.pos++
+	return r.b[r.pos-1]
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	if n <= 0 {
+		r.fail("bad varint")
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.b[r.pos:])
+	if n <= 0 {
+		r.fail("bad varint")
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) readStruct() thriftStruct {
+	var s thriftStruct
+	var last int16
+	for r.err == nil {
+		h := r.byte()
+		if h == 0 {
+			break
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.varint())
+		}
+		last = id
+		switch typ := h & 0x0f; typ {
+		case thriftTypeBoolTrue, thriftTypeBoolFalse:
+			s = append(s, thriftField{id, typ == thriftTypeBoolTrue})
+		default:
+			s = append(s, thriftField{id, r.readValue(typ)})
+		}
+	}
+	return s
+}
+
+func (r *thriftReader) readValue(typ byte) any {
+	switch typ {
+	case thriftTypeBoolTrue, thriftTypeBoolFalse:
+		return r.byte() == 1 // in a list
+	case thriftTypeByte:
+		return int32(int8(r.byte()))
+	case thriftTypeI16, thriftTypeI32:
+		return int32(r.varint())
+	case thriftTypeI64:
+		return r.varint()
+	case thriftTypeDouble:
+		r.pos += 8
+		if r.pos > len(r.b) {
+			r.fail("unexpected end")
+		}
+		return nil
+	case thriftTypeBinary:
+		n := int(r.uvarint())
+		if n < 0 || r.pos+n > len(r.b) {
+			r.fail("unexpected end")
+			return []byte(nil)
+		}
+		r.pos += n
+		return r.b[r.pos-n : r.pos]
+	case thriftTypeList, thriftTypeSet:
+		h := r.byte()
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		l := thriftList{elem: h & 0x0f}
+		for i := 0; i < n && r.err == nil; i++ {
+			l.items = append(l.items, r.readValue(l.elem))
+		}
+		return l
+	case thriftTypeMap:
+		n := int(r.uvarint())
+		if n > 0 {
+			kv := r.byte()
+			for i := 0; i < n && r.err == nil; i++ {
+				r.readValue(kv >> 4)
+				r.readValue(kv & 0x0f)
+			}
+		}
+		return nil
+	case thriftTypeStruct:
+		return r.readStruct()
+	}
+	r.fail("unknown type %d", typ)
+	return nil
+}
+
+// Accessors for decoded fields, zero if absent.
+
+func (s thriftStruct) i32(id int16) int32 {
+	v, _ := s.field(id).(int32)
+	return v
+}
+
+func (s thriftStruct) i64(id int16) int64 {
+	switch v := s.field(id).(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	}
+	return 0
+}
+
+func (s thriftStruct) str(id int16) string {
+	v, _ := s.field(id).([]byte)
+	return string(v)
+}
+
+func (s thriftStruct) strct(id int16) thriftStruct {
+	v, _ := s.field(id).(thriftStruct)
+	return v
+}
+
+func (s thriftStruct) list(id int16) []any {
+	v, _ := s.field(id).(thriftList)
+	return v.items
+}
@@ -0,0 +1,79 @@
+package dataset
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Frame data of long recordings is compressed with zstd as it is written,
+// in independent zstd frames of at most zstdChunkSize bytes each, so memory
+// stays bounded and a recording cut short loses at most the last chunk.
+// Concatenated frames are a valid zstd stream, readable by the zstd tool
+// and Python's zstandard.
+
+// zstdChunkSize is the uncompressed size of each zstd frame.
+const zstdChunkSize = 256 << 10
+
+// zstdMagic starts every zstd frame.
+const zstdMagic = 0xfd2fb528
+
+// zstdWriter compresses what is written to it, a zstd frame per chunk. Call
+// Flush to write a partial chunk.
+type zstdWriter struct {
+	w   io.Writer
+	enc *zstd.Encoder
+	buf []byte
+	err error
+}
+
+func newZstdWriter(w io.Writer) *zstdWriter {
+	// Only EncodeAll is used, which needs no goroutines
+	enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	return &zstdWriter{w: w, enc: enc, buf: make([]byte, 0, zstdChunkSize)}
+}
+
+func (z *zstdWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 && z.err == nil {
+		k := min(len(p), zstdChunkSize-len(z.buf))
+		z.buf = append(z.buf, p[:k]...)
+		p = p[k:]
+		if len(z.buf) == zstdChunkSize {
+			z.Flush()
+		}
+	}
+	if z.err != nil {
+		return 0, z.err
+	}
+	return n, nil
+}
+
+// Flush writes the buffered data as a frame.
+func (z *zstdWriter) Flush() error {
+	if z.err != nil || len(z.buf) == 0 {
+		return z.err
+	}
+	_, z.err = z.w.Write(z.enc.EncodeAll(z.buf, nil))
+	z.buf = z.buf[:0]
+	return z.err
+}
+
+// zstdReader decompresses a stream of zstd frames. Call Close when done.
+type zstdReader struct {
+	*zstd.Decoder
+}
+
+func newZstdReader(r io.Reader) (*zstdReader, error) {
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReader{dec}, nil
+}
+
+// Close releases the decoder. It doesn't close the underlying reader.
+func (z *zstdReader) Close() error {
+	z.Decoder.Close()
+	return nil
+}
@@ -0,0 +1,187 @@
+// Package logging provides slog handlers shared by the commands: a fan-out
+// to several sinks, a compact one-line format for terminal UIs, and a JSON
+// log file.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// OpenFile returns a handler that appends JSON records at or above level to
+// the file at path. Close the returned file when done.
+func OpenFile(path string, level slog.Leveler) (slog.Handler, io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return slog.NewJSONHandler(f, &slog.HandlerOptions{Level: level}), f, nil
+}
+
+// Fanout returns a handler that passes records to all handlers. Nil
+// handlers are skipped.
+func Fanout(handlers ...slog.Handler) slog.Handler {
+	var hs fanout
+	for _, h := range handlers {
+		if h != nil {
+			hs = append(hs, h)
+		}
+	}
+	return hs
+}
+
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// Event is a log record in structured form, for UIs that show events
+// rather than print them, e.g. by severity or per motor. See EventHandler.
+type Event struct {
+	Time      time.Time
+	Level     slog.Level
+	Component string // the "component" attribute, e.g. leader
+	Motor     string // the "motor" attribute, empty unless about one motor
+	Message   string
+	Err       error       // the "error" attribute, nil if none
+	Attrs     []slog.Attr // all attributes but the component, in order
+}
+
+// String formats the event as a single line, e.g.
+//
+//	[15:04:05] leader: Read failed kind=timeout error="no response"
+func (e Event) String() string {
+	var sb strings.Builder
+	sb.WriteString("[" + e.Time.Format(time.TimeOnly) + "] ")
+	if e.Component != "" {
+		sb.WriteString(e.Component + ": ")
+	}
+	sb.WriteString(e.Message)
+	for _, a := range e.Attrs {
+		sb.WriteString(" " + a.Key + "=" + formatValue(a.Value))
+	}
+	return sb.String()
+}
+
+func formatValue(v slog.Value) string {
+	s := v.Resolve().String()
+	if strings.ContainsAny(s, " =\"") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// EventHandler turns records into Events and passes them to a function
+// such as a channel send.
+type EventHandler struct {
+	level slog.Leveler
+	emit  func(Event)
+	attrs []slog.Attr
+	mu    *sync.Mutex
+}
+
+// NewEventHandler returns a handler that calls emit with each record at or
+// above level.
+func NewEventHandler(level slog.Leveler, emit func(Event)) *EventHandler {
+	return &EventHandler{level: level, emit: emit, mu: &sync.Mutex{}}
+}
+
+func (h *EventHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *EventHandler) Handle(_ context.Context, r slog.Record) error {
+	e := Event{Time: r.Time, Level: r.Level, Message: r.Message}
+	collect := func(a slog.Attr) bool {
+		v := a.Value.Resolve()
+		switch a.Key {
+		case "component":
+			e.Component = v.String()
+			return true
+		case "motor":
+			e.Motor = v.String()
+		case "error":
+			if err, ok := v.Any().(error); ok {
+				e.Err = err
+			} else {
+				e.Err = errors.New(v.String())
+			}
+		}
+		e.Attrs = append(e.Attrs, a)
+		return true
+	}
+	for _, a := range h.attrs {
+		collect(a)
+	}
+	r.Attrs(collect)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.emit(e)
+	return nil
+}
+
+func (h *EventHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := *h
+	out.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &out
+}
+
+// WithGroup is not supported by events; group names are dropped.
+func (h *EventHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// NewLineHandler returns a handler that calls emit with each record at or
+// above level, formatted as a single line by Event.String.
+func NewLineHandler(level slog.Leveler, emit func(string)) *EventHandler {
+	return NewEventHandler(level, func(e Event) { emit(e.String()) })
+}
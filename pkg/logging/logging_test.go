@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFanout(t *testing.T) {
+	var lines []string
+	var buf bytes.Buffer
+	logger := slog.New(Fanout(
+		NewLineHandler(slog.LevelWarn, func(s string) { lines = append(lines, s) }),
+		slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		nil,
+	)).With("component", "leader")
+
+	logger.Debug("Read failed", "attempt", 2)
+	logger.Warn("Read failed", "kind", "timeout", "error", "no response")
+
+	if len(lines) != 1 {
+		t.Fatalf("line sink got %d records, want 1: %q", len(lines), lines)
+	}
+	if !strings.HasSuffix(lines[0], `] leader: Read failed kind=timeout error="no response"`) {
+		t.Errorf("line = %q", lines[0])
+	}
+
+	var records []map[string]any
+	for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r map[string]any
+		if err := json.Unmarshal([]byte(l), &r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 2 || records[0]["component"] != "leader" || records[1]["kind"] != "timeout" {
+		t.Errorf("json sink got %v", records)
+	}
+}
+
+func TestEventHandler(t *testing.T) {
+	var events []Event
+	logger := slog.New(NewEventHandler(slog.LevelInfo, func(e Event) { events = append(events, e) }))
+	errTimeout := errors.New("no response")
+	logger.With("component", "follower").Warn("Write failed", "motor", "gripper", "error", errTimeout)
+	logger.Debug("Dropped")
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1: %v", len(events), events)
+	}
+	e := events[0]
+	if e.Level != slog.LevelWarn || e.Component != "follower" || e.Motor != "gripper" || e.Err != errTimeout {
+		t.Errorf("event = %+v", e)
+	}
+	if !strings.HasSuffix(e.String(), `] follower: Write failed motor=gripper error="no response"`) {
+		t.Errorf("String() = %q", e.String())
+	}
+}
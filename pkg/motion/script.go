@@ -0,0 +1,244 @@
+// Package motion runs scripted waypoint sequences on an arm: named or
+// inline poses, speeds, dwell times, gripper actions and loops.
+//
+// Scripts are YAML (or JSON, which is valid YAML):
+//
+//	speed: 30
+//	loops: 2
+//	steps:
+//	  - pose: home
+//	  - joints: {shoulder_pan: 20, elbow_flex: 40}
+//	    speed: 60
+//	  - gripper: close
+//	  - dwell: 1.5s
+//	  - repeat: 3
+//	    steps:
+//	      - joints: {wrist_roll: -50}
+//	      - joints: {wrist_roll: 50}
+package motion
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// DefaultSpeed is the joint speed of steps that don't set one, in
+// normalized units per second.
+const DefaultSpeed = 30
+
+// Script is a sequence of steps, run Loops times.
+type Script struct {
+	Speed float64 `yaml:"speed"` // default joint speed, normalized units per second
+	Loops int     `yaml:"loops"` // times to run the steps, 0 means once
+	Steps []Step  `yaml:"steps"`
+
+	// Limits restricts joints to [min, max] in normalized units. Targets
+	// outside are rejected when the script is checked.
+	Limits map[robot.MotorName][2]float64 `yaml:"limits"`
+}
+
+// Step is one action. Exactly one of Pose, Joints, Gripper, Dwell or
+// Repeat is set.
+type Step struct {
+	Pose    string                      `yaml:"pose"`    // named pose from the config
+	Joints  map[robot.MotorName]float64 `yaml:"joints"`  // inline target, other joints stay put
+	Gripper *Gripper                    `yaml:"gripper"` // open, close or a position
+	Dwell   time.Duration               `yaml:"dwell"`   // wait in place
+	Speed   float64                     `yaml:"speed"`   // overrides the script speed for this move
+
+	Repeat int    `yaml:"repeat"` // run Steps this many times
+	Steps  []Step `yaml:"steps"`
+}
+
+// Gripper is a gripper target: "open" (100), "close" (-100) or a number.
+type Gripper float64
+
+func (g *Gripper) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Value {
+	case "open":
+		*g = 100
+	case "close", "closed":
+		*g = -100
+	default:
+		v, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: gripper must be open, close or a number", node.Line)
+		}
+		*g = Gripper(v)
+	}
+	return nil
+}
+
+// Load reads a script file.
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses a YAML or JSON script.
+func Parse(data []byte) (*Script, error) {
+	var s Script
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if len(s.Steps) == 0 {
+		return nil, errors.New("script has no steps")
+	}
+	return &s, nil
+}
+
+// Check resolves named poses and validates every step against the arm's
+// motors and the script's limits, so errors surface before anything moves.
+func (s *Script) Check(poses func(name string) (map[robot.MotorName]float64, bool), motors []robot.MotorName) error {
+	known := make(map[robot.MotorName]bool, len(motors))
+	for _, name := range motors {
+		known[name] = true
+	}
+	checkTarget := func(where string, target map[robot.MotorName]float64) error {
+		for name, pos := range target {
+			if !known[name] {
+				return fmt.Errorf("%s: unknown motor %s", where, name)
+			}
+			lo, hi := -100.0, 100.0
+			if l, ok := s.Limits[name]; ok {
+				lo, hi = l[0], l[1]
+			}
+			if pos < lo || pos > hi {
+				return fmt.Errorf("%s: %s target %g outside limits [%g, %g]", where, name, pos, lo, hi)
+			}
+		}
+		return nil
+	}
+
+	var check func(prefix string, steps []Step) error
+	check = func(prefix string, steps []Step) error {
+		for i := range steps {
+			st := &steps[i]
+			where := fmt.Sprintf("%sstep %d", prefix, i+1)
+
+			actions := 0
+			for _, set := range []bool{st.Pose != "", st.Joints != nil, st.Gripper != nil, st.Dwell != 0, st.Repeat != 0} {
+				if set {
+					actions++
+				}
+			}
+			if actions != 1 {
+				return fmt.Errorf("%s: need exactly one of pose, joints, gripper, dwell or repeat", where)
+			}
+			if st.Speed < 0 || st.Dwell < 0 || st.Repeat < 0 {
+				return fmt.Errorf("%s: negative value", where)
+			}
+
+			switch {
+			case st.Pose != "":
+				pose, ok := poses(st.Pose)
+				if !ok {
+					return fmt.Errorf("%s: unknown pose %q", where, st.Pose)
+				}
+				st.Joints = pose
+			case st.Gripper != nil:
+				st.Joints = map[robot.MotorName]float64{robot.Gripper: float64(*st.Gripper)}
+			case st.Repeat != 0:
+				if len(st.Steps) == 0 {
+					return fmt.Errorf("%s: repeat without steps", where)
+				}
+				if err := check(where+" > ", st.Steps); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := checkTarget(where, st.Joints); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check("", s.Steps)
+}
+
+// Options control how a script is run.
+type Options struct {
+	// MaxSpeed caps every move, in normalized units per second.
+	MaxSpeed float64
+
+	// Progress, if set, is called before each step with a description.
+	Progress func(step string)
+}
+
+// Run executes a checked script on the arm, which must have torque
+// enabled. It stops at the first error or when ctx is cancelled.
+func (s *Script) Run(ctx context.Context, arm robot.Positioner, opts Options) error {
+	loops := max(s.Loops, 1)
+	for loop := 1; loop <= loops; loop++ {
+		if loops > 1 && opts.Progress != nil {
+			opts.Progress(fmt.Sprintf("loop %d/%d", loop, loops))
+		}
+		if err := s.run(ctx, arm, opts, s.Steps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Script) run(ctx context.Context, arm robot.Positioner, opts Options, steps []Step) error {
+	for _, st := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if opts.Progress != nil {
+			opts.Progress(st.String())
+		}
+
+		switch {
+		case st.Repeat != 0:
+			for range st.Repeat {
+				if err := s.run(ctx, arm, opts, st.Steps); err != nil {
+					return err
+				}
+			}
+		case st.Dwell != 0:
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(st.Dwell):
+			}
+		default:
+			speed := cmp.Or(st.Speed, s.Speed, DefaultSpeed)
+			if opts.MaxSpeed > 0 {
+				speed = min(speed, opts.MaxSpeed)
+			}
+			if err := robot.MoveTo(ctx, arm, st.Joints, speed); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// String describes the step for progress output.
+func (st Step) String() string {
+	switch {
+	case st.Pose != "":
+		return "pose " + st.Pose
+	case st.Gripper != nil:
+		return fmt.Sprintf("gripper %g", float64(*st.Gripper))
+	case st.Dwell != 0:
+		return "dwell " + st.Dwell.String()
+	case st.Repeat != 0:
+		return fmt.Sprintf("repeat %dx", st.Repeat)
+	default:
+		return fmt.Sprintf("joints %v", st.Joints)
+	}
+}
@@ -0,0 +1,112 @@
+package motion
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// fakeArm records every written pose and jumps there.
+type fakeArm struct {
+	pos    map[robot.MotorName]float64
+	writes int
+}
+
+func (a *fakeArm) ReadPositions(ctx context.Context) (map[robot.MotorName]float64, error) {
+	out := make(map[robot.MotorName]float64, len(a.pos))
+	for k, v := range a.pos {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (a *fakeArm) WritePositions(ctx context.Context, positions map[robot.MotorName]float64) error {
+	for k, v := range positions {
+		a.pos[k] = v
+	}
+	a.writes++
+	return nil
+}
+
+func poses(name string) (map[robot.MotorName]float64, bool) {
+	if name == "home" {
+		return map[robot.MotorName]float64{robot.ShoulderPan: 0, robot.Gripper: 0}, true
+	}
+	return nil, false
+}
+
+func TestScript_Run(t *testing.T) {
+	s, err := Parse([]byte(`
+speed: 5000
+loops: 2
+limits:
+  shoulder_pan: [-50, 50]
+steps:
+  - pose: home
+  - joints: {shoulder_pan: 40}
+  - gripper: close
+  - dwell: 1ms
+  - repeat: 2
+    steps:
+      - joints: {shoulder_pan: -40}
+      - gripper: 25
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Check(poses, robot.AllMotors()); err != nil {
+		t.Fatal(err)
+	}
+
+	arm := &fakeArm{pos: map[robot.MotorName]float64{robot.ShoulderPan: 90, robot.Gripper: 90}}
+	var steps []string
+	err = s.Run(context.Background(), arm, Options{Progress: func(step string) { steps = append(steps, step) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arm.pos[robot.ShoulderPan] != -40 || arm.pos[robot.Gripper] != 25 {
+		t.Errorf("final pose = %v", arm.pos)
+	}
+	// 2 loops of: loop header, 5 steps and 2x2 repeated steps
+	if len(steps) != 20 {
+		t.Errorf("ran %d steps, want 20: %v", len(steps), steps)
+	}
+}
+
+func TestScript_Check(t *testing.T) {
+	tests := []struct {
+		script string
+		err    string
+	}{
+		{`{"steps": [{"pose": "nowhere"}]}`, "unknown pose"},
+		{`{"steps": [{"joints": {"tail": 10}}]}`, "unknown motor"},
+		{`{"steps": [{"joints": {"gripper": 120}}]}`, "outside limits"},
+		{`{"limits": {"elbow_flex": [0, 50]}, "steps": [{"joints": {"elbow_flex": -10}}]}`, "outside limits"},
+		{`{"steps": [{"pose": "home", "dwell": "1s"}]}`, "exactly one"},
+		{`{"steps": [{"repeat": 2}]}`, "repeat without steps"},
+	}
+	for _, tt := range tests {
+		s, err := Parse([]byte(tt.script))
+		if err == nil {
+			err = s.Check(poses, robot.AllMotors())
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: err = %v, want %q", tt.script, err, tt.err)
+		}
+	}
+}
+
+func TestScript_RunCancel(t *testing.T) {
+	s, err := Parse([]byte(`steps: [{dwell: 1m}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Run(ctx, &fakeArm{}, Options{}); err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+}
@@ -0,0 +1,361 @@
+// Package policy drives a follower with a policy running on a remote server,
+// like LeRobot's asynchronous inference: observations are streamed to the
+// server, which answers each with a chunk of upcoming actions. The actions
+// are queued and executed at a fixed rate while the next chunk is computed,
+// so the arm keeps moving during inference.
+//
+// LeRobot's own policy server exchanges pickled Python objects over gRPC,
+// which only a Python client can produce. This package speaks JSON over a
+// WebSocket instead; scripts/policy_server.py serves any LeRobot policy with
+// it:
+//
+//	-> {"type": "setup", "fps": 30, "motors": ["shoulder_pan", ...], "cameras": ["front"], "task": "..."}
+//	-> {"type": "observation", "timestep": 120, "state": [...], "images": {"front": "<base64 JPEG>"}, "task": "..."}
+//	<- {"type": "actions", "timestep": 120, "actions": [[...], [...], ...]}
+//	<- {"type": "error", "error": "..."}
+//
+// State and action vectors list positions in the order of the setup motors,
+// in the same normalized [-100, 100] range as robot.Arm and recorded
+// datasets. The first action of a chunk is for the timestep of the
+// observation it answers, the next one for the timestep after, and so on.
+package policy
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/gwillem/lerobot/pkg/camera"
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/teleop"
+)
+
+// jpegQuality is the quality of camera images sent to the server.
+const jpegQuality = 90
+
+// dialTimeout is how long Dial waits for the policy server to accept the
+// connection.
+const dialTimeout = 5 * time.Second
+
+// ErrTimeout is returned by Run when the action queue ran dry for longer
+// than the timeout and the fallback is FallbackDisable.
+var ErrTimeout = errors.New("no actions from policy server")
+
+// ErrReleased is returned by Run when the action queue ran dry for longer
+// than Config.Release with FallbackHold, and torque was ramped down.
+var ErrReleased = errors.New("policy server stalled, follower released")
+
+// Fallback is what the runner does when the policy server falls behind.
+type Fallback string
+
+const (
+	// FallbackHold keeps the arm at its last target and waits for actions,
+	// until Config.Release if set.
+	FallbackHold Fallback = "hold"
+	// FallbackDisable disables torque and stops.
+	FallbackDisable Fallback = "disable"
+)
+
+// Arm is the part of a follower the runner controls.
+type Arm interface {
+	Disable(ctx context.Context) error
+	ReadPositions(ctx context.Context) (map[robot.MotorName]float64, error)
+	WritePositions(ctx context.Context, positions map[robot.MotorName]float64) error
+}
+
+// Config configures a Runner.
+type Config struct {
+	URL  string // policy server, e.g. ws://gpu-box:8765
+	FPS  int    // control rate, which the policy was trained at
+	Task string // language instruction for task-conditioned policies
+
+	// ChunkThreshold sends the next observation once the queue holds at
+	// most this fraction of the last chunk (LeRobot's chunk_size_threshold).
+	// 0 waits for the queue to run dry, 1 sends one every step.
+	ChunkThreshold float64
+	// Timeout is how long the queue may stay empty before Fallback, and how
+	// long an observation may go unanswered before it is sent again.
+	Timeout  time.Duration
+	Fallback Fallback
+	// Release, with FallbackHold, is how long the queue may stay empty
+	// before torque is ramped down and Run stops. 0 holds indefinitely.
+	Release time.Duration
+	// SoftStart is how long the follower takes to move from its own pose
+	// to the first actions. 0 snaps to them.
+	SoftStart time.Duration
+	// Chunks configures how overlapping chunks are combined.
+	Chunks teleop.ChunkConfig
+	// Guard, if set, stops actions at the workspace boundary or before the
+	// arm would hit itself.
+	Guard *robot.MotionGuard
+}
+
+// message is a protocol message in either direction.
+type message struct {
+	Type     string            `json:"type"`
+	FPS      int               `json:"fps,omitempty"`
+	Motors   []robot.MotorName `json:"motors,omitempty"`
+	Cameras  []string          `json:"cameras,omitempty"`
+	Task     string            `json:"task,omitempty"`
+	Timestep int               `json:"timestep"`
+	State    []float64         `json:"state,omitempty"`
+	Images   map[string]string `json:"images,omitempty"`
+	Actions  [][]float64       `json:"actions,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// Stats describes a run so far.
+type Stats struct {
+	Timestep  int           // current control step
+	Queued    int           // actions waiting to be executed
+	Chunks    int           // received from the server
+	Executed  int           // actions written to the arm
+	Starved   int           // steps without an action to execute
+	RoundTrip time.Duration // of the last observation
+}
+
+// Runner executes a remote policy on an arm.
+type Runner struct {
+	arm    Arm
+	motors []robot.MotorName
+	cams   []*camera.Grabber
+	cfg    Config
+	conn   *websocket.Conn
+
+	mu       sync.Mutex // guards the fields below, shared with receive
+	exec     *teleop.ChunkExecutor
+	sent     map[int]time.Time // observations awaiting a chunk
+	lastSent time.Time
+	stats    Stats
+}
+
+// Dial connects to the policy server and describes the robot to it. It
+// gives up after dialTimeout, or when ctx is cancelled.
+func Dial(ctx context.Context, arm Arm, motors []robot.MotorName, cams []*camera.Grabber, cfg Config) (*Runner, error) {
+	if cfg.FPS <= 0 {
+		cfg.FPS = 30
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second
+	}
+	if cfg.Fallback == "" {
+		cfg.Fallback = FallbackHold
+	}
+	if cfg.Fallback != FallbackHold && cfg.Fallback != FallbackDisable {
+		return nil, fmt.Errorf("invalid fallback %q, want hold or disable", cfg.Fallback)
+	}
+
+	wsCfg, err := websocket.NewConfig(cfg.URL, "http://localhost/")
+	if err != nil {
+		return nil, fmt.Errorf("policy server %s: %w", cfg.URL, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	conn, err := wsCfg.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("connect to policy server at %s: %w", cfg.URL, err)
+	}
+	r := &Runner{arm: arm, motors: motors, cams: cams, cfg: cfg, conn: conn, exec: teleop.NewChunkExecutor(cfg.Chunks), sent: make(map[int]time.Time)}
+
+	setup := message{Type: "setup", FPS: cfg.FPS, Motors: motors, Task: cfg.Task}
+	for _, g := range cams {
+		setup.Cameras = append(setup.Cameras, g.Camera().Name())
+	}
+	if err := websocket.JSON.Send(conn, setup); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("send setup: %w", err)
+	}
+	return r, nil
+}
+
+// Close disconnects from the policy server.
+func (r *Runner) Close() error { return r.conn.Close() }
+
+// Stats returns the statistics of the run so far.
+func (r *Runner) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats
+	s.Queued = r.exec.Len()
+	return s
+}
+
+// Run executes actions at the configured rate and streams observations to
+// the server until ctx is cancelled, the connection fails, or the queue
+// runs dry with FallbackDisable. The first actions are ramped in over
+// Config.SoftStart.
+func (r *Runner) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- r.receive() }()
+
+	ticker := time.NewTicker(time.Second / time.Duration(r.cfg.FPS))
+	defer ticker.Stop()
+
+	watchdog := teleop.NewWatchdog(teleop.WatchdogConfig{Hold: r.cfg.Timeout, Release: r.cfg.Release}, r.arm, nil)
+	if r.cfg.Fallback == FallbackHold {
+		watchdogCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		go watchdog.Run(watchdogCtx)
+	}
+
+	// The first actions are blended in from where the follower is
+	var from map[robot.MotorName]float64
+	if r.cfg.SoftStart > 0 {
+		var err error
+		if from, err = r.arm.ReadPositions(ctx); err != nil {
+			return fmt.Errorf("read positions: %w", err)
+		}
+	}
+	var ramp *teleop.Ramp
+
+	lastAction := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			return err
+		case <-ticker.C:
+		}
+		if watchdog.State() == teleop.WatchdogReleased {
+			return ErrReleased
+		}
+
+		action, ok, send := r.step()
+		if ok {
+			watchdog.Feed(ctx)
+			if from != nil {
+				ramp, from = teleop.NewRamp(from, r.cfg.SoftStart), nil
+			}
+			if ramp != nil {
+				var done bool
+				if action, done = ramp.Apply(action, time.Now()); done {
+					ramp = nil
+				}
+			}
+			if r.cfg.Guard != nil {
+				action, _ = r.cfg.Guard.Limit(action)
+			}
+			if err := r.arm.WritePositions(ctx, action); err != nil {
+				return fmt.Errorf("write positions: %w", err)
+			}
+			lastAction = time.Now()
+		} else if time.Since(lastAction) > r.cfg.Timeout && r.cfg.Fallback == FallbackDisable {
+			if err := r.arm.Disable(ctx); err != nil {
+				return errors.Join(ErrTimeout, err)
+			}
+			return ErrTimeout
+		}
+		if send >= 0 {
+			if err := r.observe(ctx, send); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// step advances to the next timestep and returns its action, if queued,
+// and the timestep to send an observation for, or -1 if none is due.
+func (r *Runner) step() (action map[robot.MotorName]float64, ok bool, send int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.stats.Timestep
+	r.stats.Timestep++
+	action, ok = r.exec.Next(t)
+	if ok {
+		r.stats.Executed++
+	} else {
+		r.stats.Starved++
+	}
+
+	pending := len(r.sent) > 0 && time.Since(r.lastSent) < r.cfg.Timeout
+	if pending || float64(r.exec.Len()) > r.cfg.ChunkThreshold*float64(r.exec.LastChunk()) {
+		return action, ok, -1
+	}
+	r.sent[t] = time.Now()
+	r.lastSent = time.Now()
+	return action, ok, t
+}
+
+// observe sends the current state and camera images as the observation for
+// timestep t.
+func (r *Runner) observe(ctx context.Context, t int) error {
+	positions, err := r.arm.ReadPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("read positions: %w", err)
+	}
+	obs := message{Type: "observation", Timestep: t, Task: r.cfg.Task, State: make([]float64, len(r.motors))}
+	for i, m := range r.motors {
+		obs.State[i] = positions[m]
+	}
+	if len(r.cams) > 0 {
+		obs.Images = make(map[string]string, len(r.cams))
+	}
+	for _, g := range r.cams {
+		frame, ok := g.Latest()
+		if !ok {
+			if err := g.Err(); err != nil {
+				return err
+			}
+			continue // not started yet, the server can skip this observation
+		}
+		cam := g.Camera()
+		data, err := camera.EncodeJPEG(frame.Data, cam.Width(), cam.Height(), jpegQuality)
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", cam.Name(), err)
+		}
+		obs.Images[cam.Name()] = base64.StdEncoding.EncodeToString(data)
+	}
+	if err := websocket.JSON.Send(r.conn, obs); err != nil {
+		return fmt.Errorf("send observation: %w", err)
+	}
+	return nil
+}
+
+// receive queues incoming action chunks until the connection fails.
+func (r *Runner) receive() error {
+	for {
+		var m message
+		if err := websocket.JSON.Receive(r.conn, &m); err != nil {
+			return fmt.Errorf("receive from policy server: %w", err)
+		}
+		switch m.Type {
+		case "error":
+			return fmt.Errorf("policy server: %s", m.Error)
+		case "actions":
+			chunk := make([]map[robot.MotorName]float64, len(m.Actions))
+			for i, a := range m.Actions {
+				if len(a) != len(r.motors) {
+					return fmt.Errorf("policy server sent %d-dimensional actions, want %d", len(a), len(r.motors))
+				}
+				chunk[i] = r.positions(a)
+			}
+			r.mu.Lock()
+			r.exec.Add(m.Timestep, chunk)
+			if sent, ok := r.sent[m.Timestep]; ok {
+				r.stats.RoundTrip = time.Since(sent)
+			}
+			for t := range r.sent {
+				if t <= m.Timestep {
+					delete(r.sent, t)
+				}
+			}
+			r.stats.Chunks++
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *Runner) positions(action []float64) map[robot.MotorName]float64 {
+	positions := make(map[robot.MotorName]float64, len(r.motors))
+	for i, m := range r.motors {
+		positions[m] = action[i]
+	}
+	return positions
+}
@@ -0,0 +1,167 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// fakeArm records the positions written to it.
+type fakeArm struct {
+	mu       sync.Mutex
+	written  []float64
+	disabled bool
+}
+
+func (a *fakeArm) Disable(context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.disabled = true
+	return nil
+}
+
+func (a *fakeArm) ReadPositions(context.Context) (map[robot.MotorName]float64, error) {
+	return map[robot.MotorName]float64{robot.Gripper: 50}, nil
+}
+
+func (a *fakeArm) WritePositions(_ context.Context, p map[robot.MotorName]float64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.written = append(a.written, p[robot.Gripper])
+	return nil
+}
+
+func TestRunner(t *testing.T) {
+	var answer sync.Once
+	srv := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		var setup message
+		if err := websocket.JSON.Receive(ws, &setup); err != nil || setup.Type != "setup" {
+			t.Errorf("setup = %+v, %v", setup, err)
+			return
+		}
+		for {
+			var obs message
+			if err := websocket.JSON.Receive(ws, &obs); err != nil {
+				return
+			}
+			// Answer the first observation only, then fall silent
+			answer.Do(func() {
+				websocket.JSON.Send(ws, message{Type: "actions", Timestep: obs.Timestep, Actions: [][]float64{{obs.State[0] + 1}, {obs.State[0] + 2}}})
+			})
+		}
+	}))
+	defer srv.Close()
+
+	arm := &fakeArm{}
+	r, err := Dial(context.Background(), arm, []robot.MotorName{robot.Gripper}, nil, Config{
+		URL:      "ws" + strings.TrimPrefix(srv.URL, "http"),
+		FPS:      100,
+		Timeout:  100 * time.Millisecond,
+		Fallback: FallbackDisable,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := r.Run(ctx); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Run() = %v, want ErrTimeout", err)
+	}
+	if !arm.disabled {
+		t.Error("arm not disabled after timeout")
+	}
+	// The first action was for the step that sent the observation, so only
+	// the second one arrived in time
+	if len(arm.written) == 0 || arm.written[len(arm.written)-1] != 52 {
+		t.Errorf("written = %v, want to end with 52", arm.written)
+	}
+	if s := r.Stats(); s.Chunks != 1 || s.RoundTrip == 0 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+func TestRunner_SoftStart(t *testing.T) {
+	srv := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		var setup message
+		if err := websocket.JSON.Receive(ws, &setup); err != nil {
+			return
+		}
+		for {
+			var obs message
+			if err := websocket.JSON.Receive(ws, &obs); err != nil {
+				return
+			}
+			actions := make([][]float64, 20)
+			for i := range actions {
+				actions[i] = []float64{100}
+			}
+			websocket.JSON.Send(ws, message{Type: "actions", Timestep: obs.Timestep, Actions: actions})
+		}
+	}))
+	defer srv.Close()
+
+	arm := &fakeArm{}
+	r, err := Dial(context.Background(), arm, []robot.MotorName{robot.Gripper}, nil, Config{
+		URL:       "ws" + strings.TrimPrefix(srv.URL, "http"),
+		FPS:       100,
+		SoftStart: 200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if err := r.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The follower starts at 50 and glides to the policy's 100
+	arm.mu.Lock()
+	defer arm.mu.Unlock()
+	if len(arm.written) < 2 {
+		t.Fatalf("written = %v", arm.written)
+	}
+	if first := arm.written[0]; first < 50 || first > 70 {
+		t.Errorf("first action = %v, want close to the follower's 50", first)
+	}
+	if last := arm.written[len(arm.written)-1]; last != 100 {
+		t.Errorf("last action = %v, want 100 after the soft start", last)
+	}
+	for i := 1; i < len(arm.written); i++ {
+		if arm.written[i] < arm.written[i-1] {
+			t.Fatalf("written = %v, want a steady ramp", arm.written)
+		}
+	}
+}
+
+func TestDial_Timeout(t *testing.T) {
+	// A server that accepts the connection but never answers the handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := Dial(ctx, &fakeArm{}, []robot.MotorName{robot.Gripper}, nil, Config{URL: "ws://" + ln.Addr().String()}); err == nil {
+		t.Fatal("Dial() to a stalled server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Dial() took %v, want it to give up with ctx", elapsed)
+	}
+}
@@ -0,0 +1,124 @@
+package robot
+
+import (
+	"context"
+	"math"
+)
+
+// Units selects how joint positions are presented to the user.
+type Units string
+
+const (
+	UnitsNormalized Units = "normalized" // -100 to 100 over the calibrated range (default)
+	UnitsDegrees    Units = "degrees"
+	UnitsRadians    Units = "radians"
+)
+
+const (
+	// DefaultCenter is the raw position at 0°, the middle of a 12-bit
+	// encoder.
+	DefaultCenter = 2048
+
+	// DefaultDegreesPerCount is the resolution of an STS3215 (4096 steps
+	// per revolution) driving the joint directly.
+	DefaultDegreesPerCount = 360.0 / 4096
+)
+
+// JointAngle describes how raw positions of one joint map to angles.
+type JointAngle struct {
+	Center          int     `json:"center,omitempty"`            // raw position at 0°, 0 means DefaultCenter
+	DegreesPerCount float64 `json:"degrees_per_count,omitempty"` // e.g. less for a geared joint, 0 means DefaultDegreesPerCount
+}
+
+// AngleProfile holds the angle mapping per joint. Joints not listed use
+// the defaults, which suit a stock SO-101.
+type AngleProfile map[MotorName]JointAngle
+
+// Joint returns the angle mapping for a joint, with defaults filled in.
+func (p AngleProfile) Joint(name MotorName) JointAngle {
+	j := p[name]
+	if j.Center == 0 {
+		j.Center = DefaultCenter
+	}
+	if j.DegreesPerCount == 0 {
+		j.DegreesPerCount = DefaultDegreesPerCount
+	}
+	return j
+}
+
+// Degrees converts a raw position to degrees. The calibration's Offset and
+// Invert apply as they do for normalized positions.
+func (j JointAngle) Degrees(cal MotorCalibration, raw int) float64 {
+	deg := float64(raw-cal.Offset-j.Center) * j.DegreesPerCount
+	if cal.Invert {
+		deg = -deg
+	}
+	return deg
+}
+
+// Raw converts degrees to a raw position, the inverse of Degrees.
+func (j JointAngle) Raw(cal MotorCalibration, deg float64) int {
+	if cal.Invert {
+		deg = -deg
+	}
+	return int(math.Round(deg/j.DegreesPerCount)) + j.Center + cal.Offset
+}
+
+// Units returns the units the arm's positions are shown in.
+func (a *Arm) Units() Units {
+	if a.units == "" {
+		return UnitsNormalized
+	}
+	return a.units
+}
+
+// FromRaw converts a raw position of a motor to the arm's Units.
+func (a *Arm) FromRaw(name MotorName, raw int) float64 {
+	cal := a.calibration[name]
+	switch a.Units() {
+	case UnitsDegrees:
+		return a.angles.Joint(name).Degrees(cal, raw)
+	case UnitsRadians:
+		return a.angles.Joint(name).Degrees(cal, raw) * math.Pi / 180
+	default:
+		return cal.Normalize(raw)
+	}
+}
+
+// ReadAngles reads the joint angles of all motors, in radians if the arm's
+// Units are radians and in degrees otherwise.
+func (a *Arm) ReadAngles(ctx context.Context) (map[MotorName]float64, error) {
+	raw, err := a.ReadRawPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scale := a.angleScale()
+	angles := make(map[MotorName]float64, len(raw))
+	for name, pos := range raw {
+		angles[name] = a.angles.Joint(name).Degrees(a.calibration[name], pos) * scale
+	}
+	return angles, nil
+}
+
+// WriteAngles writes target joint angles, in the units of ReadAngles.
+// Targets are clamped like in WriteRawPositions.
+func (a *Arm) WriteAngles(ctx context.Context, angles map[MotorName]float64) error {
+	scale := a.angleScale()
+	raw := make(map[MotorName]int, len(angles))
+	for name, angle := range angles {
+		cal, ok := a.calibration[name]
+		if !ok {
+			continue
+		}
+		raw[name] = a.angles.Joint(name).Raw(cal, angle/scale)
+	}
+	return a.WriteRawPositions(ctx, raw)
+}
+
+// angleScale converts degrees to the unit of ReadAngles.
+func (a *Arm) angleScale() float64 {
+	if a.Units() == UnitsRadians {
+		return math.Pi / 180
+	}
+	return 1
+}
@@ -3,38 +3,158 @@ package robot
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/hipsterbrown/feetech-servo/feetech"
 )
 
 // Arm represents a robot arm with multiple servos.
 type Arm struct {
-	bus         *feetech.Bus
-	group       *feetech.ServoGroup
+	port        string
+	baud        int
+	retries     int
+	openBus     func() (Bus, error)
+	bus         Bus
+	ids         []int // bus IDs, including idOffset
+	idOffset    int
 	calibration Calibration
+	servoModel  string // see ArmConfig.ServoModel
+	skipVerify  bool
+
+	acceleration int
+	maxSpeed     int
+	torqueLimit  map[MotorName]int
+	noClamp      bool
+	units        Units
+	angles       AngleProfile
+
+	velocityMode bool // servos are in wheel mode, see SetVelocityMode
+
+	mu              sync.Mutex           // guards the fields below
+	tolerateMissing bool                 // see TolerateMissing
+	missing         map[int]bool         // bus IDs of servos not responding, see TolerateMissing
+	turns           map[int]*turnCounter // by calibrated ID, see decodePosition
 }
 
-// NewArm creates and initializes an arm connection.
+// NewArm creates and initializes an arm connection. It fails with
+// ErrServoMismatch unless all servos are STS3215s with the same firmware.
 func NewArm(port string, cal Calibration) (*Arm, error) {
-	// Open serial bus
-	bus, err := feetech.NewBus(feetech.BusConfig{
-		Port:     port,
-		BaudRate: 1_000_000,
-		Protocol: feetech.ProtocolSTS,
-	})
+	a := &Arm{
+		port:        port,
+		baud:        DefaultBaudRate,
+		retries:     defaultRetries,
+		calibration: cal,
+	}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.VerifyServos(ctx); err != nil {
+		a.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// OpenArm connects to the arm described by cfg, checks its servos (see
+// ErrServoMismatch and ArmConfig.SkipVerify) and applies its servo settings (acceleration, speed and
+// torque limits).
+func OpenArm(cfg ArmConfig) (*Arm, error) {
+	a := &Arm{
+		port:         cfg.Port,
+		baud:         cfg.BaudRate(),
+		retries:      cfg.RetryCount(),
+		idOffset:     cfg.IDOffset,
+		calibration:  cfg.Calibration,
+		servoModel:   cfg.ServoModel,
+		skipVerify:   cfg.SkipVerify,
+		acceleration: cfg.Acceleration,
+		maxSpeed:     cfg.MaxSpeed,
+		torqueLimit:  cfg.TorqueLimit,
+		noClamp:      cfg.NoClamp,
+		units:        cfg.Units,
+		angles:       cfg.Angles,
+	}
+	if err := a.init(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewArmWithBus creates an arm that talks to the bus returned by open
+// instead of a serial port, e.g. a robottest.FakeBus. open is called again
+// on Reconnect.
+func NewArmWithBus(cfg ArmConfig, open func() (Bus, error)) (*Arm, error) {
+	a := &Arm{
+		port:         cfg.Port,
+		openBus:      open,
+		retries:      cfg.RetryCount(),
+		idOffset:     cfg.IDOffset,
+		calibration:  cfg.Calibration,
+		servoModel:   cfg.ServoModel,
+		skipVerify:   cfg.SkipVerify,
+		acceleration: cfg.Acceleration,
+		maxSpeed:     cfg.MaxSpeed,
+		torqueLimit:  cfg.TorqueLimit,
+		noClamp:      cfg.NoClamp,
+		units:        cfg.Units,
+		angles:       cfg.Angles,
+	}
+	if err := a.init(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *Arm) init() error {
+	if err := a.open(); err != nil {
+		return err
+	}
+	if !a.skipVerify {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := a.VerifyServos(ctx); err != nil {
+			a.Close()
+			return err
+		}
+	}
+	if err := a.applySettings(); err != nil {
+		a.Close()
+		return err
+	}
+	return nil
+}
+
+func (a *Arm) open() error {
+	var bus Bus
+	var err error
+	if a.openBus != nil {
+		bus, err = a.openBus()
+	} else {
+		bus, err = serialBuses.acquire(a.port, func() (Bus, error) { return openSerialBus(a.port, a.baud) })
+	}
 	if err != nil {
-		return nil, fmt.Errorf("open bus: %w", err)
+		return err
 	}
 
-	// Create servo group from calibration IDs
-	ids := cal.MotorIDs()
-	group := feetech.NewServoGroupByIDs(bus, ids...)
+	a.bus = withRetries(bus, a.retries)
+	a.ids = nil
+	for _, id := range a.calibration.MotorIDs() {
+		a.ids = append(a.ids, id+a.idOffset)
+	}
+	return nil
+}
 
-	return &Arm{
-		bus:         bus,
-		group:       group,
-		calibration: cal,
-	}, nil
+// Port returns the serial port the arm is connected to.
+func (a *Arm) Port() string {
+	return a.port
+}
+
+// Motors returns the calibrated motors of the arm, ordered by servo ID.
+func (a *Arm) Motors() []MotorName {
+	return a.calibration.Motors()
 }
 
 // Close closes the arm's bus connection.
@@ -42,21 +162,140 @@ func (a *Arm) Close() error {
 	return a.bus.Close()
 }
 
+// Reconnect closes the current bus connection and opens the serial port again,
+// e.g. after the USB adapter was unplugged and plugged back in.
+func (a *Arm) Reconnect() error {
+	if h, ok := unwrapBus(a.bus).(*busHandle); ok {
+		// Other arms on the port use the new connection too
+		if err := h.shared.reopen(); err != nil {
+			return err
+		}
+	} else {
+		a.bus.Close()
+		if err := a.open(); err != nil {
+			return err
+		}
+	}
+	a.mu.Lock()
+	a.missing = nil
+	a.turns = nil // the servos may have lost power, and their turns with it
+	a.mu.Unlock()
+
+	// Acceleration, goal speed and torque limit live in RAM and are lost on
+	// power loss
+	return a.applySettings()
+}
+
+// applySettings writes the configured acceleration, speed and torque limits
+// to all servos, so motion is limited in firmware.
+func (a *Arm) applySettings() error {
+	if a.acceleration == 0 && a.maxSpeed == 0 && len(a.torqueLimit) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if len(a.torqueLimit) > 0 {
+		if err := a.SetTorqueLimit(ctx, 1000); err != nil {
+			return fmt.Errorf("set torque limit: %w", err)
+		}
+	}
+
+	for _, id := range a.ids {
+		if a.acceleration > 0 {
+			if err := a.bus.WriteRegister(ctx, id, feetech.RegAcceleration.Address, []byte{byte(a.acceleration)}); err != nil {
+				return fmt.Errorf("set acceleration on servo %d: %w", id, err)
+			}
+		}
+		if a.maxSpeed > 0 {
+			if err := a.bus.WriteRegister(ctx, id, feetech.RegGoalVelocity.Address, encodeWord(a.maxSpeed)); err != nil {
+				return fmt.Errorf("set max speed on servo %d: %w", id, err)
+			}
+		}
+	}
+	return nil
+}
+
 // Enable enables torque on all servos.
 func (a *Arm) Enable(ctx context.Context) error {
-	return a.group.EnableAll(ctx)
+	return a.setTorque(ctx, 1)
 }
 
 // Disable disables torque on all servos.
 func (a *Arm) Disable(ctx context.Context) error {
-	return a.group.DisableAll(ctx)
+	return a.setTorque(ctx, 0)
+}
+
+// Hold sets every goal position to the present position, so enabling torque
+// keeps the arm where it is instead of jumping to a stale goal.
+func (a *Arm) Hold(ctx context.Context) error {
+	positions, err := a.readPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("read positions: %w", err)
+	}
+	if err := a.writePositions(ctx, positions); err != nil {
+		return fmt.Errorf("write positions: %w", err)
+	}
+	return nil
+}
+
+// SetTorqueLimit limits the torque of all servos, in 0.1% of maximum
+// torque (0-1000), but never above a motor's configured TorqueLimit, so
+// 1000 restores the configured strength. The limit lives in RAM, so power
+// loss restores full torque.
+func (a *Arm) SetTorqueLimit(ctx context.Context, limit int) error {
+	limit = max(0, min(1000, limit))
+	servoData := make(map[int][]byte, len(a.ids))
+	for _, name := range a.calibration.Motors() {
+		l := limit
+		if configured, ok := a.torqueLimit[name]; ok {
+			l = min(l, configured)
+		}
+		servoData[a.calibration[name].ID+a.idOffset] = encodeWord(l)
+	}
+	return a.bus.SyncWrite(ctx, feetech.RegTorqueLimit.Address, 2, servoData)
+}
+
+func (a *Arm) setTorque(ctx context.Context, enable byte) error {
+	servoData := make(map[int][]byte, len(a.ids))
+	for _, id := range a.ids {
+		servoData[id] = []byte{enable}
+	}
+	return a.bus.SyncWrite(ctx, feetech.RegTorqueEnable.Address, 1, servoData)
+}
+
+// readPositions sync-reads the raw present position of every servo, keyed
+// by calibrated servo ID.
+func (a *Arm) readPositions(ctx context.Context) (map[int]int, error) {
+	data, err := a.syncRead(ctx, feetech.RegPresentPosition.Address, 2)
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[int]int, len(data))
+	for id, d := range data {
+		positions[id-a.idOffset] = a.decodePosition(id-a.idOffset, d)
+	}
+	return positions, nil
+}
+
+// writePositions sync-writes raw goal positions keyed by calibrated servo ID.
+func (a *Arm) writePositions(ctx context.Context, positions map[int]int) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	servoData := make(map[int][]byte, len(positions))
+	for id, pos := range positions {
+		servoData[id+a.idOffset] = encodePosition(pos)
+	}
+	return a.bus.SyncWrite(ctx, feetech.RegGoalPosition.Address, 2, servoData)
 }
 
 // ReadPositions reads current positions from all motors.
 // Returns normalized positions in the range [-100, 100].
 func (a *Arm) ReadPositions(ctx context.Context) (map[MotorName]float64, error) {
 	// Read raw positions using sync read
-	rawPositions, err := a.group.Positions(ctx)
+	rawPositions, err := a.readPositions(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("read positions: %w", err)
 	}
@@ -74,21 +313,100 @@ func (a *Arm) ReadPositions(ctx context.Context) (map[MotorName]float64, error)
 	return positions, nil
 }
 
+// ReadRawPositions reads current raw servo positions from all motors.
+func (a *Arm) ReadRawPositions(ctx context.Context) (map[MotorName]int, error) {
+	rawPositions, err := a.readPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read positions: %w", err)
+	}
+
+	positions := make(map[MotorName]int, len(rawPositions))
+	for id, raw := range rawPositions {
+		name, _, ok := a.calibration.ByID(id)
+		if !ok {
+			continue
+		}
+		positions[name] = raw
+	}
+
+	return positions, nil
+}
+
+// ReadLoad reads the present load of a single motor in 0.1% of maximum
+// torque. The sign indicates the direction of the load.
+func (a *Arm) ReadLoad(ctx context.Context, name MotorName) (int, error) {
+	cal, ok := a.calibration[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown motor %s", name)
+	}
+	load, err := readWord(ctx, a.bus, cal.ID+a.idOffset, feetech.RegPresentLoad)
+	if err != nil {
+		return 0, fmt.Errorf("read load: %w", err)
+	}
+	return decodeSignMagnitude(load, feetech.RegPresentLoad.SignBit), nil
+}
+
+// ReadLoads reads the present load of all motors in one transaction, in
+// 0.1% of maximum torque (see ReadLoad).
+func (a *Arm) ReadLoads(ctx context.Context) (map[MotorName]int, error) {
+	data, err := a.syncRead(ctx, feetech.RegPresentLoad.Address, 2)
+	if err != nil {
+		return nil, fmt.Errorf("read loads: %w", err)
+	}
+
+	loads := make(map[MotorName]int, len(data))
+	for id, d := range data {
+		name, _, ok := a.calibration.ByID(id - a.idOffset)
+		if !ok {
+			continue
+		}
+		loads[name] = decodeSignMagnitude(decodeWord(d), feetech.RegPresentLoad.SignBit)
+	}
+	return loads, nil
+}
+
 // WritePositions writes target positions to all motors.
-// Takes normalized positions in the range [-100, 100].
+// Takes normalized positions in the range [-100, 100]. Targets outside it
+// are clamped to the calibrated range unless ArmConfig.NoClamp is set.
 func (a *Arm) WritePositions(ctx context.Context, positions map[MotorName]float64) error {
 	// Denormalize positions
-	rawPositions := make(feetech.PositionMap, len(positions))
+	rawPositions := make(map[int]int, len(positions))
 	for name, norm := range positions {
 		cal, ok := a.calibration[name]
 		if !ok {
 			continue
 		}
-		rawPositions[cal.ID] = cal.Denormalize(norm)
+		m := cal.Mapper()
+		m.Clamp = !a.noClamp
+		rawPositions[cal.ID] = m.Denormalize(norm)
 	}
 
 	// Write using sync write
-	if err := a.group.SetPositions(ctx, rawPositions); err != nil {
+	if err := a.writePositions(ctx, rawPositions); err != nil {
+		return fmt.Errorf("write positions: %w", err)
+	}
+
+	return nil
+}
+
+// WriteRawPositions writes raw servo target positions to the given motors.
+// Targets are clamped to each motor's calibrated range, shifted by its
+// Offset, unless ArmConfig.NoClamp is set.
+func (a *Arm) WriteRawPositions(ctx context.Context, positions map[MotorName]int) error {
+	rawPositions := make(map[int]int, len(positions))
+	for name, raw := range positions {
+		cal, ok := a.calibration[name]
+		if !ok {
+			continue
+		}
+		if !a.noClamp {
+			lo, hi := min(cal.RangeMin, cal.RangeMax), max(cal.RangeMin, cal.RangeMax)
+			raw = min(max(raw, lo+cal.Offset), hi+cal.Offset)
+		}
+		rawPositions[cal.ID] = raw
+	}
+
+	if err := a.writePositions(ctx, rawPositions); err != nil {
 		return fmt.Errorf("write positions: %w", err)
 	}
 
@@ -0,0 +1,614 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gwillem/lerobot/pkg/robot/robottest"
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+func newFakeArm(t *testing.T, cfg ArmConfig) (*Arm, *robottest.FakeBus) {
+	t.Helper()
+	if cfg.Calibration == nil {
+		cfg.Calibration = Calibration{
+			ShoulderPan: {ID: 1, RangeMin: 1000, RangeMax: 3000},
+			Gripper:     {ID: 6, RangeMin: 2000, RangeMax: 3000},
+		}
+	}
+	var ids []int
+	for _, id := range cfg.Calibration.MotorIDs() {
+		ids = append(ids, id+cfg.IDOffset)
+	}
+	bus := robottest.NewFakeBus(ids...)
+	arm, err := NewArmWithBus(cfg, func() (Bus, error) { return bus, bus.Open() })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return arm, bus
+}
+
+func TestArm_ReadWritePositions(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{})
+	ctx := context.Background()
+
+	bus.SetPosition(1, 2500)
+	positions, err := arm.ReadPositions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(positions[ShoulderPan]-50) > 0.001 {
+		t.Errorf("shoulder_pan = %f, want 50", positions[ShoulderPan])
+	}
+
+	if err := arm.Enable(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !bus.TorqueEnabled(1) || !bus.TorqueEnabled(6) {
+		t.Error("torque not enabled on all servos")
+	}
+
+	if err := arm.WritePositions(ctx, map[MotorName]float64{ShoulderPan: -50, Gripper: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.GoalPosition(1); got != 1500 {
+		t.Errorf("goal position servo 1 = %d, want 1500", got)
+	}
+	if got := bus.GoalPosition(6); got != 3000 {
+		t.Errorf("goal position servo 6 = %d, want 3000", got)
+	}
+
+	if err := arm.WriteRawPositions(ctx, map[MotorName]int{ShoulderPan: 4000}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.GoalPosition(1); got != 3000 {
+		t.Errorf("raw write not clamped: goal position = %d, want 3000", got)
+	}
+}
+
+func TestArm_WritePositionsClamp(t *testing.T) {
+	ctx := context.Background()
+	arm, bus := newFakeArm(t, ArmConfig{})
+	if err := arm.WritePositions(ctx, map[MotorName]float64{ShoulderPan: 150, Gripper: -120}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.GoalPosition(1); got != 3000 {
+		t.Errorf("goal position servo 1 = %d, want clamped to 3000", got)
+	}
+	if got := bus.GoalPosition(6); got != 2000 {
+		t.Errorf("goal position servo 6 = %d, want clamped to 2000", got)
+	}
+
+	arm, bus = newFakeArm(t, ArmConfig{NoClamp: true})
+	if err := arm.WritePositions(ctx, map[MotorName]float64{ShoulderPan: 150}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.GoalPosition(1); got != 3500 {
+		t.Errorf("goal position servo 1 = %d, want unclamped 3500", got)
+	}
+	if err := arm.WriteRawPositions(ctx, map[MotorName]int{ShoulderPan: 4000}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.GoalPosition(1); got != 4000 {
+		t.Errorf("raw goal position servo 1 = %d, want unclamped 4000", got)
+	}
+}
+
+func TestArm_WriteRawPositionsOffset(t *testing.T) {
+	ctx := context.Background()
+	cfg := ArmConfig{Calibration: Calibration{
+		ShoulderPan: {ID: 1, RangeMin: 1000, RangeMax: 3000, Offset: 200},
+	}}
+	arm, bus := newFakeArm(t, cfg)
+
+	// The range moves with the offset, like WritePositions at -100 and 100
+	for _, tc := range []struct{ raw, want int }{
+		{3100, 3100},
+		{4000, 3200},
+		{1100, 1200},
+	} {
+		if err := arm.WriteRawPositions(ctx, map[MotorName]int{ShoulderPan: tc.raw}); err != nil {
+			t.Fatal(err)
+		}
+		if got := bus.GoalPosition(1); got != tc.want {
+			t.Errorf("WriteRawPositions(%d): goal position = %d, want %d", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestArm_MultiTurn(t *testing.T) {
+	cfg := ArmConfig{Calibration: Calibration{
+		WristRoll: {ID: 5, RangeMin: -4096, RangeMax: 8192, MultiTurn: true},
+	}}
+	arm, bus := newFakeArm(t, cfg)
+	ctx := context.Background()
+
+	// The servo wraps around within one turn; the arm keeps counting
+	for _, step := range []struct{ servo, want int }{
+		{4000, 4000},
+		{100, 4196},
+		{3000, 3000},
+		{1000, 1000},
+		{3900, -196},
+	} {
+		bus.SetPosition(5, step.servo)
+		raw, err := arm.ReadRawPositions(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raw[WristRoll] != step.want {
+			t.Errorf("servo at %d: wrist_roll = %d, want %d", step.servo, raw[WristRoll], step.want)
+		}
+	}
+
+	// A servo in multi-turn mode reports the turns itself, negative below zero
+	arm, bus = newFakeArm(t, cfg)
+	bus.SetPosition(5, -500)
+	st, err := arm.ReadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := arm.calibration[WristRoll].Normalize(-500); st.Positions[WristRoll] != want {
+		t.Errorf("wrist_roll = %f, want %f", st.Positions[WristRoll], want)
+	}
+
+	if err := arm.WriteRawPositions(ctx, map[MotorName]int{WristRoll: -3000}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.GoalPosition(5); got != -3000 {
+		t.Errorf("goal position = %d, want -3000", got)
+	}
+}
+
+func TestArm_ReadWriteAngles(t *testing.T) {
+	ctx := context.Background()
+	arm, bus := newFakeArm(t, ArmConfig{
+		Units:  UnitsRadians,
+		Angles: AngleProfile{Gripper: {Center: 2500, DegreesPerCount: 0.05}},
+	})
+
+	bus.SetPosition(1, 3072)
+	bus.SetPosition(6, 2900)
+	angles, err := arm.ReadAngles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(angles[ShoulderPan]-math.Pi/2) > 1e-9 {
+		t.Errorf("shoulder_pan = %v rad, want pi/2", angles[ShoulderPan])
+	}
+	if math.Abs(angles[Gripper]-20*math.Pi/180) > 1e-9 {
+		t.Errorf("gripper = %v rad, want 20°", angles[Gripper])
+	}
+
+	if err := arm.WriteAngles(ctx, map[MotorName]float64{ShoulderPan: -math.Pi / 4, Gripper: math.Pi}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.GoalPosition(1); got != 1536 {
+		t.Errorf("goal position servo 1 = %d, want 1536", got)
+	}
+	if got := bus.GoalPosition(6); got != 3000 {
+		t.Errorf("goal position servo 6 = %d, want clamped to 3000", got)
+	}
+}
+
+func TestArm_Velocities(t *testing.T) {
+	ctx := context.Background()
+	arm, bus := newFakeArm(t, ArmConfig{MaxSpeed: 200})
+
+	bus.SetRegister(1, feetech.RegPresentVelocity.Address, encodeWord(encodeSignMagnitude(-100, 15))...)
+	velocities, err := arm.ReadVelocities(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if velocities[ShoulderPan] != -10 {
+		t.Errorf("shoulder_pan = %v units/s, want -10", velocities[ShoulderPan])
+	}
+
+	if err := arm.WriteVelocities(ctx, map[MotorName]float64{ShoulderPan: 5}); !errors.Is(err, ErrNotVelocityMode) {
+		t.Fatalf("err = %v, want ErrNotVelocityMode", err)
+	}
+	if err := arm.SetVelocityMode(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	if mode := bus.Register(1, feetech.RegOperatingMode.Address, 1)[0]; mode != feetech.ModeVelocity {
+		t.Errorf("operating mode = %d, want velocity", mode)
+	}
+	if err := arm.WriteVelocities(ctx, map[MotorName]float64{ShoulderPan: 50, Gripper: -10}); err != nil {
+		t.Fatal(err)
+	}
+	goal := func(id int) int {
+		return decodeSignMagnitude(decodeWord(bus.Register(id, feetech.RegGoalVelocity.Address, 2)), 15)
+	}
+	if got := goal(1); got != 200 {
+		t.Errorf("goal velocity servo 1 = %d, want limited to 200", got)
+	}
+	if got := goal(6); got != -50 {
+		t.Errorf("goal velocity servo 6 = %d, want -50", got)
+	}
+
+	if err := arm.SetVelocityMode(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if mode := bus.Register(1, feetech.RegOperatingMode.Address, 1)[0]; mode != feetech.ModePosition {
+		t.Errorf("operating mode = %d, want position", mode)
+	}
+	if got := goal(1); got != 200 {
+		t.Errorf("speed limit = %d after leaving velocity mode, want 200", got)
+	}
+}
+
+func TestArm_ReadState(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{})
+	bus.SetPosition(1, 2500)
+	bus.SetRegister(1, feetech.RegPresentVelocity.Address, encodeWord(encodeSignMagnitude(-100, 15))...)
+	bus.SetRegister(1, feetech.RegPresentLoad.Address, encodeWord(encodeSignMagnitude(-300, feetech.RegPresentLoad.SignBit))...)
+
+	calls := bus.Calls()
+	st, err := arm.ReadState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bus.Calls() - calls; n != 1 {
+		t.Errorf("ReadState took %d transactions, want 1", n)
+	}
+	if math.Abs(st.Positions[ShoulderPan]-50) > 0.001 || st.Velocities[ShoulderPan] != -10 || st.Loads[ShoulderPan] != -300 {
+		t.Errorf("state = %+v, want position 50, velocity -10, load -300", st)
+	}
+}
+
+func TestArm_ReadLoad(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{})
+
+	// Sign-magnitude: the sign bit set means negative
+	raw := 1<<feetech.RegPresentLoad.SignBit | 300
+	bus.SetRegister(6, feetech.RegPresentLoad.Address, byte(raw), byte(raw>>8))
+	load, err := arm.ReadLoad(context.Background(), Gripper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if load != -300 {
+		t.Errorf("load = %d, want -300", load)
+	}
+
+	bus.SetRegister(1, feetech.RegPresentLoad.Address, 120, 0)
+	loads, err := arm.ReadLoads(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loads[ShoulderPan] != 120 || loads[Gripper] != -300 {
+		t.Errorf("loads = %v, want shoulder_pan 120, gripper -300", loads)
+	}
+}
+
+func TestArm_ReconnectAppliesSettings(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{Acceleration: 50, MaxSpeed: 1000})
+	ctx := context.Background()
+
+	bus.Fail(errors.New("port gone"))
+	if _, err := arm.ReadPositions(ctx); err == nil {
+		t.Fatal("expected scripted error")
+	}
+
+	bus.SetRegister(1, feetech.RegAcceleration.Address, 0)
+	if err := arm.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.Register(1, feetech.RegAcceleration.Address, 1)[0]; got != 50 {
+		t.Errorf("acceleration after reconnect = %d, want 50", got)
+	}
+	if _, err := arm.ReadPositions(ctx); err != nil {
+		t.Errorf("read after reconnect: %v", err)
+	}
+}
+
+func TestArm_Retries(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{})
+	ctx := context.Background()
+
+	bus.Fail(feetech.ErrTimeout, feetech.ErrInvalidPacket)
+	if _, err := arm.ReadPositions(ctx); err != nil {
+		t.Errorf("read with two lost packets: %v", err)
+	}
+	bus.Fail(feetech.ErrTimeout, feetech.ErrTimeout, feetech.ErrTimeout)
+	if _, err := arm.ReadPositions(ctx); !errors.Is(err, feetech.ErrTimeout) {
+		t.Errorf("read with three lost packets = %v, want ErrTimeout", err)
+	}
+
+	// Only lost packets are retried
+	arm, bus = newFakeArm(t, ArmConfig{Retries: -1})
+	bus.Fail(feetech.ErrTimeout)
+	if _, err := arm.ReadPositions(ctx); err == nil {
+		t.Error("expected an error without retries")
+	}
+}
+
+func TestArm_TorqueLimit(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{TorqueLimit: map[MotorName]int{Gripper: 300}})
+	limits := func() (int, int) {
+		return decodeWord(bus.Register(1, feetech.RegTorqueLimit.Address, 2)),
+			decodeWord(bus.Register(6, feetech.RegTorqueLimit.Address, 2))
+	}
+	if pan, grip := limits(); pan != 1000 || grip != 300 {
+		t.Errorf("torque limits after open = %d, %d, want 1000, 300", pan, grip)
+	}
+
+	ctx := context.Background()
+	if err := arm.SetTorqueLimit(ctx, 500); err != nil {
+		t.Fatal(err)
+	}
+	if pan, grip := limits(); pan != 500 || grip != 300 {
+		t.Errorf("torque limits at 500 = %d, %d, want 500, 300", pan, grip)
+	}
+}
+
+func TestArm_ReadFaults(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{})
+	bus.SetRegister(6, feetech.RegServoStatus.Address, byte(FaultOverheat|FaultOverload))
+
+	faults, err := arm.ReadFaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(faults) != 1 || faults[Gripper] != FaultOverheat|FaultOverload {
+		t.Errorf("faults = %v, want only gripper overheated and overloaded", faults)
+	}
+	if got, want := faults[Gripper].String(), "overheated, overloaded"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestArm_VerifyServos(t *testing.T) {
+	cfg := ArmConfig{Calibration: Calibration{
+		ShoulderPan: {ID: 1, RangeMin: 1000, RangeMax: 3000},
+		Gripper:     {ID: 6, RangeMin: 2000, RangeMax: 3000},
+	}}
+	open := func(setup func(*robottest.FakeBus)) error {
+		bus := robottest.NewFakeBus(1, 6)
+		setup(bus)
+		arm, err := NewArmWithBus(cfg, func() (Bus, error) { return bus, bus.Open() })
+		if err == nil {
+			arm.Close()
+		}
+		return err
+	}
+
+	if err := open(func(b *robottest.FakeBus) {}); err != nil {
+		t.Errorf("matching servos: %v", err)
+	}
+	err := open(func(b *robottest.FakeBus) {
+		b.SetRegister(6, feetech.RegModelNumber.Address, encodeWord(feetech.ModelSTS3250.Number)...)
+	})
+	if !errors.Is(err, ErrServoMismatch) || !strings.Contains(err.Error(), "gripper (ID 6) is a sts3250") {
+		t.Errorf("wrong model: %v", err)
+	}
+	err = open(func(b *robottest.FakeBus) {
+		b.SetRegister(1, 0, 3, 10)
+		b.SetRegister(6, 0, 3, 9)
+	})
+	if !errors.Is(err, ErrServoMismatch) || !strings.Contains(err.Error(), "shoulder_pan 3.10, gripper 3.9") {
+		t.Errorf("mixed firmware: %v", err)
+	}
+
+	// With SkipVerify the arm opens, and the mismatch is reported on request
+	bus := robottest.NewFakeBus(1, 6)
+	bus.SetRegister(6, feetech.RegModelNumber.Address, encodeWord(feetech.ModelSTS3250.Number)...)
+	skip := cfg
+	skip.SkipVerify = true
+	arm, err := NewArmWithBus(skip, func() (Bus, error) { return bus, bus.Open() })
+	if err != nil {
+		t.Fatalf("SkipVerify: %v", err)
+	}
+	defer arm.Close()
+	if err := arm.VerifyServos(context.Background()); !errors.Is(err, ErrServoMismatch) {
+		t.Errorf("VerifyServos() = %v, want ErrServoMismatch", err)
+	}
+}
+
+func TestArm_Registers(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{})
+	ctx := context.Background()
+	reg := func(name string) Register {
+		r, ok := STS3215Register(name)
+		if !ok {
+			t.Fatalf("no register %s", name)
+		}
+		return r
+	}
+
+	if r := reg("present_load"); r.Register != feetech.RegPresentLoad || r.EEPROM {
+		t.Errorf("present_load = %+v, want feetech.RegPresentLoad in RAM", r)
+	}
+	if r := reg("velocity_i_coefficient"); r.Address != 39 || !r.EEPROM {
+		t.Errorf("velocity_i_coefficient = %+v, want address 39 in EEPROM", r)
+	}
+	if got, err := arm.ReadRegister(ctx, Gripper, reg("model_number")); err != nil || got != feetech.ModelSTS3215.Number {
+		t.Errorf("model_number = %d, %v", got, err)
+	}
+	if err := arm.WriteRegister(ctx, Gripper, reg("homing_offset"), -300); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := arm.ReadRegister(ctx, Gripper, reg("homing_offset")); err != nil || got != -300 {
+		t.Errorf("homing_offset = %d, %v, want -300", got, err)
+	}
+	if lock := bus.Register(6, 55, 1)[0]; lock != 1 {
+		t.Errorf("EEPROM lock = %d after write, want 1", lock)
+	}
+
+	if err := arm.WriteRegister(ctx, Gripper, reg("present_position"), 0); err == nil {
+		t.Error("wrote a read-only register")
+	}
+	if err := arm.WriteRegister(ctx, Gripper, reg("return_delay"), 256); err == nil {
+		t.Error("wrote 256 to a byte")
+	}
+}
+
+func TestArm_EEPROMSettings(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{})
+	ctx := context.Background()
+	bus.SetRegister(6, 7, 250) // return_delay
+
+	backup, err := arm.ReadRegisters(ctx, EEPROMSettings(arm.Motors()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := backup[Gripper]["id"]; ok || backup[Gripper]["return_delay"] != 250 {
+		t.Errorf("backup of gripper = %v", backup[Gripper])
+	}
+
+	want := RecommendedSettings(arm.calibration, true)
+	diff := backup.Diff(want)
+	if diff[Gripper]["return_delay"] != 0 || diff[Gripper]["max_torque"] != 500 {
+		t.Errorf("diff of gripper = %v", diff[Gripper])
+	}
+	if err := arm.WriteRegisters(ctx, diff); err != nil {
+		t.Fatal(err)
+	}
+	got, err := arm.ReadRegisters(ctx, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got.Diff(want); len(d) != 0 {
+		t.Errorf("after writing, still differs in %v", d)
+	}
+
+	if err := arm.WriteRegisters(ctx, backup); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.Register(6, 7, 1)[0]; got != 250 {
+		t.Errorf("restored return_delay = %d, want 250", got)
+	}
+}
+
+func TestArm_TolerateMissing(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{})
+	ctx := context.Background()
+	bus.SetResponding(6, false)
+
+	if _, err := arm.ReadPositions(ctx); err == nil {
+		t.Fatal("expected an error without TolerateMissing")
+	}
+
+	arm.TolerateMissing(true)
+	positions, err := arm.ReadPositions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := positions[Gripper]; ok || len(positions) != 1 {
+		t.Errorf("positions = %v, want shoulder_pan only", positions)
+	}
+	if got := arm.Missing(); !slices.Equal(got, []MotorName{Gripper}) {
+		t.Errorf("Missing() = %v, want [gripper]", got)
+	}
+
+	// Reads don't retry it, RetryMissing does
+	bus.SetResponding(6, true)
+	if positions, _ = arm.ReadPositions(ctx); len(positions) != 1 {
+		t.Errorf("positions = %v, want shoulder_pan only before the retry", positions)
+	}
+	arm.RetryMissing(ctx)
+	if positions, _ = arm.ReadPositions(ctx); len(positions) != 2 {
+		t.Errorf("positions = %v, want both after the retry", positions)
+	}
+	if got := arm.Missing(); len(got) != 0 {
+		t.Errorf("Missing() = %v, want none", got)
+	}
+
+	// Without any servo, reads fail so a disconnect is noticed
+	bus.SetResponding(1, false)
+	bus.SetResponding(6, false)
+	if _, err := arm.ReadPositions(ctx); err == nil {
+		t.Error("expected an error without any servo responding")
+	}
+}
+
+func TestArm_IDOffset(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{IDOffset: 6, Calibration: Calibration{
+		ShoulderPan: {ID: 1, RangeMin: 1000, RangeMax: 3000},
+	}})
+	ctx := context.Background()
+
+	bus.SetPosition(7, 3000)
+	positions, err := arm.ReadPositions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if positions[ShoulderPan] != 100 {
+		t.Errorf("shoulder_pan = %f, want 100", positions[ShoulderPan])
+	}
+
+	if err := arm.Enable(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := arm.WritePositions(ctx, map[MotorName]float64{ShoulderPan: -100}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.GoalPosition(7); got != 1000 {
+		t.Errorf("servo 7 goal = %d, want 1000", got)
+	}
+}
+
+func TestBusRegistry_Shared(t *testing.T) {
+	registry := &busRegistry{buses: make(map[string]*sharedBus)}
+	var opened []*robottest.FakeBus
+	open := func() (Bus, error) {
+		bus := robottest.NewFakeBus(1, 7)
+		opened = append(opened, bus)
+		return bus, nil
+	}
+
+	leader, err := registry.acquire("/dev/ttyACM0", open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	follower, err := registry.acquire("/dev/ttyACM0", open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opened) != 1 {
+		t.Fatalf("port opened %d times, want once", len(opened))
+	}
+
+	if err := follower.shared.reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if len(opened) != 2 || !opened[0].Closed() {
+		t.Fatal("reopen should replace the connection")
+	}
+	if _, err := leader.Ping(context.Background(), 1); err != nil {
+		t.Errorf("leader after reopen: %v", err)
+	}
+
+	leader.Close()
+	leader.Close()
+	if opened[1].Closed() {
+		t.Error("bus closed while the follower still uses it")
+	}
+	follower.Close()
+	if !opened[1].Closed() || len(registry.buses) != 0 {
+		t.Error("bus not closed after the last handle")
+	}
+}
+
+func TestMoveTo(t *testing.T) {
+	arm, bus := newFakeArm(t, ArmConfig{})
+	ctx := context.Background()
+	if err := arm.Enable(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 100 units at 2000 units/s takes 50ms, a few steps
+	target := map[MotorName]float64{ShoulderPan: 100, Gripper: 0}
+	if err := MoveTo(ctx, arm, target, 2000); err != nil {
+		t.Fatal(err)
+	}
+	if got := bus.GoalPosition(1); got != 3000 {
+		t.Errorf("shoulder_pan goal = %d, want 3000", got)
+	}
+	if bus.Calls() < 4 {
+		t.Errorf("only %d bus calls, want intermediate steps", bus.Calls())
+	}
+}
@@ -0,0 +1,116 @@
+package robot
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+// Timing summarizes repeated bus transactions.
+type Timing struct {
+	Count  int // attempts
+	Errors int // failed attempts
+	P50    time.Duration
+	P95    time.Duration
+	Max    time.Duration
+}
+
+// ErrorRate returns the fraction of failed attempts.
+func (t Timing) ErrorRate() float64 {
+	if t.Count == 0 {
+		return 0
+	}
+	return float64(t.Errors) / float64(t.Count)
+}
+
+// PerSecond returns the sustained transaction rate at median latency.
+func (t Timing) PerSecond() float64 {
+	if t.P50 == 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(t.P50)
+}
+
+func summarizeTiming(samples []time.Duration, errors int) Timing {
+	t := Timing{Count: len(samples) + errors, Errors: errors}
+	if len(samples) == 0 {
+		return t
+	}
+	slices.Sort(samples)
+	at := func(p float64) time.Duration {
+		return samples[int(p*float64(len(samples)-1))]
+	}
+	t.P50, t.P95, t.Max = at(0.50), at(0.95), samples[len(samples)-1]
+	return t
+}
+
+// BenchmarkResult holds the bus timings measured by Benchmark.
+type BenchmarkResult struct {
+	SyncRead  Timing         // present position of all servos
+	StateRead Timing         // present position, velocity and load of all servos, see Arm.ReadState
+	SyncWrite Timing         // goal position of all servos
+	Ping      map[int]Timing // round trip per servo
+}
+
+// headroom is the fraction of the control period the bus may use; the rest
+// is left for scheduling jitter and retries.
+const headroom = 0.8
+
+// MaxHz returns the highest control loop frequency the bus sustains at p95
+// latency with some headroom: one sync read and one sync write per cycle,
+// plus extra state reads (e.g. 1 when recording reads back the follower).
+func (r BenchmarkResult) MaxHz(extraReads int) int {
+	cycle := r.SyncRead.P95 + time.Duration(extraReads)*r.StateRead.P95 + r.SyncWrite.P95
+	if cycle == 0 {
+		return 0
+	}
+	return int(headroom * float64(time.Second) / float64(cycle))
+}
+
+// Benchmark measures n sync reads of positions, n sync reads of the full
+// state and n sync writes of all ids, and n pings of each servo. Writes set
+// the goal position to the present position, so the arm does not move even
+// with torque enabled.
+func Benchmark(ctx context.Context, bus Bus, ids []int, n int) (BenchmarkResult, error) {
+	present, err := bus.SyncRead(ctx, feetech.RegPresentPosition.Address, 2, ids)
+	if err != nil {
+		return BenchmarkResult{}, err
+	}
+
+	timed := func(op func() error) Timing {
+		var samples []time.Duration
+		var errors int
+		for range n {
+			start := time.Now()
+			if err := op(); err != nil {
+				errors++
+				continue
+			}
+			samples = append(samples, time.Since(start))
+		}
+		return summarizeTiming(samples, errors)
+	}
+
+	var result BenchmarkResult
+	result.SyncRead = timed(func() error {
+		_, err := bus.SyncRead(ctx, feetech.RegPresentPosition.Address, 2, ids)
+		return err
+	})
+	result.StateRead = timed(func() error {
+		_, err := bus.SyncRead(ctx, feetech.RegPresentPosition.Address, stateLen, ids)
+		return err
+	})
+	result.SyncWrite = timed(func() error {
+		return bus.SyncWrite(ctx, feetech.RegGoalPosition.Address, 2, present)
+	})
+	result.Ping = make(map[int]Timing, len(ids))
+	for _, id := range ids {
+		result.Ping[id] = timed(func() error {
+			_, err := bus.Ping(ctx, id)
+			return err
+		})
+	}
+	return result, ctx.Err()
+}
@@ -0,0 +1,45 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot/robottest"
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+func TestBenchmark(t *testing.T) {
+	bus := robottest.NewFakeBus(1, 2)
+	bus.SetPosition(2, 3000)
+	// First call is the initial position read, then two failed sync reads
+	bus.Fail(nil, feetech.ErrTimeout, feetech.ErrTimeout)
+
+	r, err := Benchmark(context.Background(), bus, []int{1, 2}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.SyncRead.Count != 10 || r.SyncRead.Errors != 2 {
+		t.Errorf("sync read = %d/%d errors, want 2/10", r.SyncRead.Errors, r.SyncRead.Count)
+	}
+	if r.SyncWrite.Errors != 0 || len(r.Ping) != 2 || r.Ping[2].Count != 10 {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if got := bus.GoalPosition(2); got != 3000 {
+		t.Errorf("goal position = %d, want present position 3000", got)
+	}
+}
+
+func TestBenchmarkResult_MaxHz(t *testing.T) {
+	r := BenchmarkResult{
+		SyncRead:  Timing{P95: 3 * time.Millisecond},
+		StateRead: Timing{P95: 3 * time.Millisecond},
+		SyncWrite: Timing{P95: 2 * time.Millisecond},
+	}
+	if got := r.MaxHz(0); got != 160 {
+		t.Errorf("MaxHz(0) = %d, want 160", got)
+	}
+	if got := r.MaxHz(1); got != 100 {
+		t.Errorf("MaxHz(1) = %d, want 100", got)
+	}
+}
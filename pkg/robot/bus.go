@@ -0,0 +1,104 @@
+package robot
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+// Bus is the register-level servo bus an Arm talks to. *feetech.Bus
+// implements it; robottest.FakeBus is an in-memory implementation for tests.
+type Bus interface {
+	Ping(ctx context.Context, id int) (int, error)
+	ReadRegister(ctx context.Context, id int, address byte, length int) ([]byte, error)
+	WriteRegister(ctx context.Context, id int, address byte, data []byte) error
+	SyncRead(ctx context.Context, address byte, dataLen int, ids []int) (map[int][]byte, error)
+	SyncWrite(ctx context.Context, address byte, dataLen int, servoData map[int][]byte) error
+	Close() error
+}
+
+// DefaultBaudRate is the baud rate SO-101 servos are configured for.
+const DefaultBaudRate = 1_000_000
+
+// ProbeBaudRates are the rates tried, in order, to find the rate an arm's
+// servos answer at: the SO-101 default first, then other common settings.
+var ProbeBaudRates = []int{DefaultBaudRate, 500_000, 115_200, 250_000, 57_600}
+
+// openSerialBus opens the STS servo bus on a serial port.
+func openSerialBus(port string, baud int) (Bus, error) {
+	bus, err := feetech.NewBus(feetech.BusConfig{
+		Port:     port,
+		BaudRate: baud,
+		Protocol: feetech.ProtocolSTS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open bus: %w", err)
+	}
+	return bus, nil
+}
+
+// STS servos store words little-endian.
+
+func encodeWord(v int) []byte {
+	return binary.LittleEndian.AppendUint16(nil, uint16(v))
+}
+
+func decodeWord(b []byte) int {
+	return int(binary.LittleEndian.Uint16(b))
+}
+
+// decodeSignMagnitude decodes registers that store the sign in signBit.
+func decodeSignMagnitude(v, signBit int) int {
+	mask := 1 << signBit
+	if v&mask != 0 {
+		return -(v & (mask - 1))
+	}
+	return v
+}
+
+// encodeSignMagnitude is the inverse of decodeSignMagnitude.
+func encodeSignMagnitude(v, signBit int) int {
+	if v < 0 {
+		return -v | 1<<signBit
+	}
+	return v
+}
+
+func readWord(ctx context.Context, bus Bus, id int, reg feetech.Register) (int, error) {
+	data, err := bus.ReadRegister(ctx, id, reg.Address, 2)
+	if err != nil {
+		return 0, err
+	}
+	return decodeWord(data), nil
+}
+
+func readByte(ctx context.Context, bus Bus, id int, reg feetech.Register) (int, error) {
+	data, err := bus.ReadRegister(ctx, id, reg.Address, 1)
+	if err != nil {
+		return 0, err
+	}
+	return int(data[0]), nil
+}
+
+// ErrorKind classifies a bus error for logging: timeout, no_response,
+// invalid_packet, bus_closed, servo (a status error reported by the servo)
+// or other.
+func ErrorKind(err error) string {
+	switch {
+	case errors.Is(err, feetech.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, feetech.ErrNoResponse):
+		return "no_response"
+	case errors.Is(err, feetech.ErrInvalidPacket):
+		return "invalid_packet"
+	case errors.Is(err, feetech.ErrBusClosed):
+		return "bus_closed"
+	}
+	if _, ok := feetech.GetServoError(err); ok {
+		return "servo"
+	}
+	return "other"
+}
@@ -1,10 +1,24 @@
 package robot
 
+import (
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+)
+
 // MotorCalibration holds calibration data for a single motor.
 type MotorCalibration struct {
-	ID       int `json:"id"`
-	RangeMin int `json:"range_min"`
-	RangeMax int `json:"range_max"`
+	ID       int  `json:"id"`
+	RangeMin int  `json:"range_min"`
+	RangeMax int  `json:"range_max"`
+	Offset   int  `json:"offset,omitempty"` // see Mapper.Offset
+	Invert   bool `json:"invert,omitempty"` // see Mapper.Invert
+
+	// MultiTurn marks a joint that turns further than a full turn, such as
+	// a continuous wrist_roll. Its range may then extend beyond 0-4095, and
+	// positions keep counting when the servo wraps around.
+	MultiTurn bool `json:"multi_turn,omitempty"`
 }
 
 // Calibration holds calibration data for all motors, keyed by motor name.
@@ -12,27 +26,40 @@ type Calibration map[MotorName]MotorCalibration
 
 // Normalize converts a raw servo position to a normalized value in the range [-100, 100].
 func (c MotorCalibration) Normalize(raw int) float64 {
-	rangeSize := float64(c.RangeMax - c.RangeMin)
-	if rangeSize == 0 {
-		return 0
-	}
-	return (float64(raw-c.RangeMin)/rangeSize)*200 - 100
+	return c.Mapper().Normalize(raw)
 }
 
 // Denormalize converts a normalized value [-100, 100] to a raw servo position.
 func (c MotorCalibration) Denormalize(norm float64) int {
-	rangeSize := float64(c.RangeMax - c.RangeMin)
-	return int((norm+100)/200*rangeSize) + c.RangeMin
+	return c.Mapper().Denormalize(norm)
 }
 
-// MotorIDs returns the servo IDs for all motors in the calibration.
+// Trimmed returns the calibration with trim, in normalized units, folded
+// into its Offset, so targets land where they did with the trim added.
+func (c MotorCalibration) Trimmed(trim float64) MotorCalibration {
+	if c.Invert {
+		trim = -trim
+	}
+	c.Offset += int(math.Round(trim / 200 * float64(c.RangeMax-c.RangeMin)))
+	return c
+}
+
+// Motors returns the names of all motors in the calibration, ordered by
+// servo ID.
+func (c Calibration) Motors() []MotorName {
+	names := slices.Collect(maps.Keys(c))
+	slices.SortFunc(names, func(a, b MotorName) int {
+		return cmp.Or(cmp.Compare(c[a].ID, c[b].ID), cmp.Compare(a, b))
+	})
+	return names
+}
+
+// MotorIDs returns the servo IDs for all motors in the calibration, in
+// ascending order.
 func (c Calibration) MotorIDs() []int {
 	ids := make([]int, 0, len(c))
-	// Use AllMotors() to ensure consistent ordering
-	for _, name := range AllMotors() {
-		if mc, ok := c[name]; ok {
-			ids = append(ids, mc.ID)
-		}
+	for _, name := range c.Motors() {
+		ids = append(ids, c[name].ID)
 	}
 	return ids
 }
@@ -46,3 +73,15 @@ func (c Calibration) ByID(id int) (MotorName, MotorCalibration, bool) {
 	}
 	return "", MotorCalibration{}, false
 }
+
+// NormalizeAll converts raw positions to normalized values. Motors without
+// calibration are skipped.
+func (c Calibration) NormalizeAll(raw map[MotorName]int) map[MotorName]float64 {
+	positions := make(map[MotorName]float64, len(raw))
+	for name, r := range raw {
+		if cal, ok := c[name]; ok {
+			positions[name] = cal.Normalize(r)
+		}
+	}
+	return positions
+}
@@ -2,6 +2,7 @@ package robot
 
 import (
 	"math"
+	"slices"
 	"testing"
 )
 
@@ -119,3 +120,88 @@ func TestCalibration_ByID(t *testing.T) {
 		t.Error("ByID(99) should return false")
 	}
 }
+
+func TestCalibration_Check(t *testing.T) {
+	good := Calibration{
+		ShoulderPan:  MotorCalibration{ID: 1, RangeMin: 800, RangeMax: 3500},
+		ShoulderLift: MotorCalibration{ID: 2, RangeMin: 800, RangeMax: 3500},
+		ElbowFlex:    MotorCalibration{ID: 3, RangeMin: 800, RangeMax: 3500},
+		WristFlex:    MotorCalibration{ID: 4, RangeMin: 800, RangeMax: 3500},
+		WristRoll:    MotorCalibration{ID: 5, RangeMin: 800, RangeMax: 3500},
+		Gripper:      MotorCalibration{ID: 6, RangeMin: 2000, RangeMax: 3000},
+	}
+	if issues := good.Check(AllMotors()); len(issues) != 0 {
+		t.Fatalf("Check() on valid calibration returned %v", issues)
+	}
+
+	bad := Calibration{
+		ShoulderPan:  MotorCalibration{ID: 1, RangeMin: 3500, RangeMax: 800},  // reversed
+		ShoulderLift: MotorCalibration{ID: 2, RangeMin: 2000, RangeMax: 2100}, // too small
+		ElbowFlex:    MotorCalibration{ID: 2, RangeMin: 800, RangeMax: 3500},  // duplicate ID
+		WristFlex:    MotorCalibration{ID: 4, RangeMin: 800, RangeMax: 3500},
+		WristRoll:    MotorCalibration{ID: 5, RangeMin: 800, RangeMax: 3500},
+		// Gripper missing
+	}
+
+	issues := bad.Check(AllMotors())
+	got := make(map[MotorName]bool)
+	for _, issue := range issues {
+		got[issue.Motor] = true
+	}
+	for _, name := range []MotorName{ShoulderPan, ShoulderLift, ElbowFlex, Gripper} {
+		if !got[name] {
+			t.Errorf("Check() did not flag %s, issues: %v", name, issues)
+		}
+	}
+	if len(issues) != 4 {
+		t.Errorf("Check() returned %d issues, want 4: %v", len(issues), issues)
+	}
+}
+
+func TestCalibration_CheckPositions(t *testing.T) {
+	cal := Calibration{
+		ShoulderPan: MotorCalibration{ID: 1, RangeMin: 1000, RangeMax: 3000},
+		Gripper:     MotorCalibration{ID: 6, RangeMin: 2000, RangeMax: 3000},
+	}
+
+	issues := cal.CheckPositions(map[MotorName]int{
+		ShoulderPan: 2000,
+		Gripper:     1900,
+	})
+	if len(issues) != 1 || issues[0].Motor != Gripper {
+		t.Errorf("CheckPositions() = %v, want one issue for gripper", issues)
+	}
+
+	issues = cal.CheckPositions(map[MotorName]int{ShoulderPan: 2000})
+	if len(issues) != 1 || issues[0].Motor != Gripper {
+		t.Errorf("CheckPositions() with missing reading = %v, want one issue for gripper", issues)
+	}
+}
+
+func TestCalibration_Motors(t *testing.T) {
+	// A 7-DOF build with a custom joint
+	cal := Calibration{
+		Gripper:     MotorCalibration{ID: 7},
+		"wrist_yaw": MotorCalibration{ID: 6},
+		ShoulderPan: MotorCalibration{ID: 1},
+	}
+	got := cal.Motors()
+	want := []MotorName{ShoulderPan, "wrist_yaw", Gripper}
+	if !slices.Equal(got, want) {
+		t.Errorf("Motors() = %v, want %v", got, want)
+	}
+}
+
+func TestMotorCalibration_Trimmed(t *testing.T) {
+	for _, cal := range []MotorCalibration{
+		{RangeMin: 1000, RangeMax: 3000},
+		{RangeMin: 1000, RangeMax: 3000, Offset: -40, Invert: true},
+	} {
+		trimmed := cal.Trimmed(2.5)
+		for _, norm := range []float64{-50, 0, 80} {
+			if got, want := trimmed.Denormalize(norm), cal.Denormalize(norm+2.5); got != want {
+				t.Errorf("%+v: Denormalize(%v) = %d, want %d", cal, norm, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package robot
+
+import "fmt"
+
+// MinCalibratedRange is the smallest range (in raw counts) considered a
+// plausible calibration for a joint. Anything below this usually means the
+// joint was not moved through its full range during setup.
+const MinCalibratedRange = 500
+
+// Issue describes a problem found while checking an arm's calibration.
+type Issue struct {
+	Motor   MotorName // Empty for issues that concern the whole arm
+	Problem string
+	Fix     string
+}
+
+func (i Issue) String() string {
+	if i.Motor == "" {
+		return i.Problem
+	}
+	return fmt.Sprintf("%s: %s", i.Motor, i.Problem)
+}
+
+// Check validates the calibration itself: motors of the arm that are
+// missing, duplicate IDs, reversed or suspiciously small ranges.
+func (c Calibration) Check(motors []MotorName) []Issue {
+	var issues []Issue
+
+	seen := make(map[int]MotorName)
+	for _, name := range motors {
+		mc, ok := c[name]
+		if !ok {
+			issues = append(issues, Issue{
+				Motor:   name,
+				Problem: "missing from calibration",
+				Fix:     "run 'lerobot setup' to recalibrate the arm",
+			})
+			continue
+		}
+
+		if other, dup := seen[mc.ID]; dup {
+			issues = append(issues, Issue{
+				Motor:   name,
+				Problem: fmt.Sprintf("servo ID %d is also used by %s", mc.ID, other),
+				Fix:     "edit the calibration so every motor has a unique servo ID",
+			})
+		}
+		seen[mc.ID] = name
+
+		switch size := mc.RangeMax - mc.RangeMin; {
+		case size < 0:
+			issues = append(issues, Issue{
+				Motor:   name,
+				Problem: fmt.Sprintf("range_min %d is greater than range_max %d", mc.RangeMin, mc.RangeMax),
+				Fix:     "swap range_min and range_max, or recalibrate",
+			})
+		case size < MinCalibratedRange:
+			issues = append(issues, Issue{
+				Motor:   name,
+				Problem: fmt.Sprintf("range of %d counts is suspiciously small", size),
+				Fix:     "recalibrate and move the joint through its full range of motion",
+			})
+		}
+	}
+
+	return issues
+}
+
+// CheckPositions reports motors whose raw position lies outside the
+// calibrated range, which indicates drift or an incomplete calibration.
+func (c Calibration) CheckPositions(raw map[MotorName]int) []Issue {
+	var issues []Issue
+	for _, name := range c.Motors() {
+		mc := c[name]
+		pos, ok := raw[name]
+		if !ok {
+			issues = append(issues, Issue{
+				Motor:   name,
+				Problem: fmt.Sprintf("no position read from servo %d", mc.ID),
+				Fix:     "check the cable and power to this servo",
+			})
+			continue
+		}
+		if pos < mc.RangeMin || pos > mc.RangeMax {
+			issues = append(issues, Issue{
+				Motor:   name,
+				Problem: fmt.Sprintf("position %d is outside calibrated range [%d, %d]", pos, mc.RangeMin, mc.RangeMax),
+				Fix:     "recalibrate; the horn may have slipped or the range was not fully explored",
+			})
+		}
+	}
+	return issues
+}
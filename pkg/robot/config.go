@@ -2,21 +2,288 @@ package robot
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
 const DefaultConfigFile = "lerobot.json"
 
+// ErrProfileName is returned for a profile name that isn't a plain file
+// name, see CheckProfile.
+var ErrProfileName = errors.New("profile names may not contain a path separator or \"..\"")
+
+// CheckProfile returns ErrProfileName unless name can name a profile. It
+// becomes a file name in ConfigDir/profiles, so it may not contain a path
+// separator or "..". The empty name, the default configuration, is fine.
+func CheckProfile(name string) error {
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return fmt.Errorf("%w: %q", ErrProfileName, name)
+	}
+	return nil
+}
+
+// ConfigDir returns the directory holding the global configuration and
+// profiles, e.g. ~/.config/lerobot. It is empty if there is no home
+// directory.
+func ConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "lerobot")
+}
+
+// configExts are the accepted configuration file extensions, in order of
+// preference when several exist.
+var configExts = []string{".json", ".yaml", ".yml"}
+
+// ConfigPath returns the active configuration file for profile, a named
+// configuration in the config directory, e.g. one per robot in a lab. In
+// order of precedence: $LEROBOT_CONFIG, the profile in ConfigDir/profiles
+// unless it is empty, lerobot.json in the working directory if it exists,
+// and finally lerobot.json in ConfigDir. Where a JSON file is looked for,
+// a .yaml or .yml file of the same name is accepted too. The profile must
+// pass CheckProfile.
+func ConfigPath(profile string) string {
+	if path := os.Getenv("LEROBOT_CONFIG"); path != "" {
+		return path
+	}
+	dir := ConfigDir()
+	if profile != "" {
+		return findConfig(filepath.Join(dir, "profiles", profile+".json"))
+	}
+	if path := findConfig(DefaultConfigFile); fileExists(path) || dir == "" {
+		return path
+	}
+	return findConfig(filepath.Join(dir, DefaultConfigFile))
+}
+
+// findConfig returns path, or a YAML variant of it if only that exists.
+func findConfig(path string) string {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, ext := range configExts {
+		if fileExists(base + ext) {
+			return base + ext
+		}
+	}
+	return path
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func isYAML(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Profiles returns the names of the saved profiles.
+func Profiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(ConfigDir(), "profiles"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if !e.IsDir() && slices.Contains(configExts, ext) {
+			names = append(names, strings.TrimSuffix(e.Name(), ext))
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
 // Config holds the robot configuration
 type Config struct {
 	Leader   ArmConfig `json:"leader"`
 	Follower ArmConfig `json:"follower"`
+
+	// Deadband is the per-motor minimum leader movement (normalized units)
+	// before the follower is sent a new target.
+	Deadband map[MotorName]float64 `json:"deadband,omitempty"`
+
+	// Mapping is the per-motor transform from leader to follower positions.
+	Mapping map[MotorName]JointMapping `json:"mapping,omitempty"`
+
+	// Motors lists the joints and servo IDs of both arms, for builds other
+	// than a stock SO-101. Empty means SO101Motors.
+	Motors []Motor `json:"motors,omitempty"`
+
+	// RestPose is a safe pose to park both arms in (normalized positions),
+	// e.g. folded onto their stands, before torque is disabled.
+	RestPose map[MotorName]float64 `json:"rest_pose,omitempty"`
+
+	// Poses are named follower poses (normalized positions) for
+	// 'lerobot goto', e.g. "home" or "grasp-ready".
+	Poses map[string]map[MotorName]float64 `json:"poses,omitempty"`
+
+	// Workspace limits where the follower may move during teleoperation
+	// and policy inference. Empty means anywhere.
+	Workspace Workspace `json:"workspace,omitzero"`
+
+	// Kinematics overrides the geometric model of the follower used to
+	// check the workspace. Nil means SO101Kinematics.
+	Kinematics *Kinematics `json:"kinematics,omitempty"`
+
+	// Teleop holds defaults for teleoperation. Command line flags take
+	// precedence.
+	Teleop TeleopSettings `json:"teleop,omitzero"`
+
+	// Cameras are recorded when 'lerobot record' is given no --camera, as
+	// name=device[@WIDTHxHEIGHT][+LATENCY].
+	Cameras []string `json:"cameras,omitempty"`
+
+	path            string // the file loaded from, see Save
+	migrated        bool   // loaded from an old layout, see migrateConfig
+	leaderPortEnv   *portOverride
+	followerPortEnv *portOverride
+}
+
+// portOverride is a port LoadConfig took from the environment instead of
+// the file, which SaveTo doesn't write.
+type portOverride struct {
+	env, file string
+}
+
+// restore returns the port to save for port: the file's, if port is still
+// the one from the environment.
+func (o *portOverride) restore(port string) string {
+	if o != nil && port == o.env {
+		return o.file
+	}
+	return port
+}
+
+// TeleopSettings are configured defaults for teleoperation.
+type TeleopSettings struct {
+	Hz     int  `json:"hz,omitempty"`     // control loop frequency, 0 means 60
+	Mirror bool `json:"mirror,omitempty"` // invert shoulder_pan and wrist_roll
+
+	// Smoothing is the cutoff frequency in Hz of a low-pass filter on the
+	// follower targets, 0 disables it.
+	Smoothing float64 `json:"smoothing,omitempty"`
+
+	Trigger *Trigger `json:"trigger,omitempty"` // leader joint used as a button, nil for none
+}
+
+// Pose returns the named pose. "rest" falls back to RestPose.
+func (c *Config) Pose(name string) (map[MotorName]float64, bool) {
+	if pose, ok := c.Poses[name]; ok {
+		return pose, true
+	}
+	if name == "rest" && len(c.RestPose) > 0 {
+		return c.RestPose, true
+	}
+	return nil, false
+}
+
+// KinematicModel returns the configured kinematics, or the SO-101 model.
+func (c *Config) KinematicModel() Kinematics {
+	if c.Kinematics == nil {
+		return SO101Kinematics()
+	}
+	return *c.Kinematics
+}
+
+// MotorList returns the configured motors, or the SO-101 motors if none
+// are configured.
+func (c *Config) MotorList() []Motor {
+	if len(c.Motors) == 0 {
+		return SO101Motors()
+	}
+	return c.Motors
 }
 
 // ArmConfig holds configuration for a single arm
 type ArmConfig struct {
-	Port        string      `json:"port"`
-	Calibration Calibration `json:"calibration,omitempty"`
+	Port        string       `json:"port"`
+	USB         *USBIdentity `json:"usb,omitempty"` // Used to find Port again if the device path changes
+	Calibration Calibration  `json:"calibration,omitempty"`
+
+	// Baud is the rate the arm's servos are configured for, as detected by
+	// 'lerobot setup'. 0 means DefaultBaudRate.
+	Baud int `json:"baud,omitempty"`
+
+	// ServoModel is the model all of the arm's servos must be, e.g.
+	// "sts3250" for a build with stronger servos. Opening the arm fails
+	// with ErrServoMismatch if one isn't, or if their firmware differs.
+	// Empty means "sts3215".
+	ServoModel string `json:"servo_model,omitempty"`
+
+	// SkipVerify opens the arm without checking its servos, for commands
+	// that inspect or repair an arm, which should still work when a servo
+	// was swapped for another model. They report the mismatch with
+	// Arm.VerifyServos instead.
+	SkipVerify bool `json:"-"`
+
+	// Retries is how many times a bus transaction that failed on a lost or
+	// garbled packet is tried again, after a short random delay, before the
+	// read or write fails. 0 means 2; -1 tries only once.
+	Retries int `json:"retries,omitempty"`
+
+	// Acceleration limits servo acceleration in units of 100 steps/s²
+	// (1-254). 0 leaves the firmware default (no ramp).
+	Acceleration int `json:"acceleration,omitempty"`
+
+	// MaxSpeed limits servo speed in steps/s. 0 means unlimited.
+	MaxSpeed int `json:"max_speed,omitempty"`
+
+	// TorqueLimit caps the torque of each listed motor, in 0.1% of maximum
+	// torque (1-1000), so the arm runs weaker while a setup is tried out.
+	// Motors not listed run at full torque.
+	TorqueLimit map[MotorName]int `json:"torque_limit,omitempty"`
+
+	// IDOffset is added to the calibrated servo IDs on the bus, so two arms
+	// can be daisy-chained on one port, e.g. leader 1-6 and follower 7-12
+	// with an offset of 6. Both arms then use the same Port.
+	IDOffset int `json:"id_offset,omitempty"`
+
+	// NoClamp lets targets outside -100 to 100 drive the servos beyond the
+	// calibrated range. By default they are clamped to it, so a mapping or
+	// offset can't run a joint into its hard stop.
+	NoClamp bool `json:"no_clamp,omitempty"`
+
+	// CollisionCheck refuses targets that would make the arm hit itself,
+	// such as the wrist folding into the base, using the capsules around
+	// the links of Config.Kinematics.
+	CollisionCheck bool `json:"collision_check,omitempty"`
+
+	// Units are how positions of this arm are shown, e.g. by 'lerobot
+	// status'. Empty means UnitsNormalized.
+	Units Units `json:"units,omitempty"`
+
+	// Angles maps raw positions to joint angles for Arm.ReadAngles and
+	// WriteAngles. Joints not listed use the SO-101 defaults.
+	Angles AngleProfile `json:"angles,omitempty"`
+}
+
+// RetryCount returns the configured number of retries, or the default.
+func (a *ArmConfig) RetryCount() int {
+	switch {
+	case a.Retries == 0:
+		return defaultRetries
+	case a.Retries < 0:
+		return 0
+	}
+	return a.Retries
+}
+
+// BaudRate returns the configured baud rate, or DefaultBaudRate.
+func (a *ArmConfig) BaudRate() int {
+	if a.Baud == 0 {
+		return DefaultBaudRate
+	}
+	return a.Baud
 }
 
 // IsCalibrated returns true if the arm has calibration data
@@ -24,40 +291,149 @@ func (a *ArmConfig) IsCalibrated() bool {
 	return len(a.Calibration) > 0
 }
 
-// LoadConfig loads configuration from the default config file
-func LoadConfig() (*Config, error) {
-	return LoadConfigFrom(DefaultConfigFile)
+// LoadConfig loads the active configuration of profile (see ConfigPath).
+// The ports can be overridden with $LEROBOT_LEADER_PORT and
+// $LEROBOT_FOLLOWER_PORT; Save keeps the file's ports unless they were
+// changed.
+func LoadConfig(profile string) (*Config, error) {
+	if err := CheckProfile(profile); err != nil {
+		return nil, err
+	}
+	cfg, err := LoadConfigFrom(ConfigPath(profile))
+	if cfg == nil {
+		return nil, err
+	}
+	if port := os.Getenv("LEROBOT_LEADER_PORT"); port != "" {
+		cfg.leaderPortEnv = &portOverride{env: port, file: cfg.Leader.Port}
+		cfg.Leader.Port = port
+	}
+	if port := os.Getenv("LEROBOT_FOLLOWER_PORT"); port != "" {
+		cfg.followerPortEnv = &portOverride{env: port, file: cfg.Follower.Port}
+		cfg.Follower.Port = port
+	}
+	return cfg, err
 }
 
-// LoadConfigFrom loads configuration from a specific file
+// LoadConfigFrom loads configuration from a specific JSON or YAML file and
+// validates it (see Config.Validate). Old layouts are upgraded in memory,
+// see migrateConfig; the file is only rewritten by Save. If only
+// validation fails, the configuration is returned
+// along with the error, so setup can repair it.
 func LoadConfigFrom(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	if isYAML(path) {
+		// Decode through JSON so the json tags apply to YAML too
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	data, migrated, err := migrateConfig(data, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+		if isYAML(path) {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return nil, fmt.Errorf("%s: %w", path, syntaxError(data, err))
+	}
+	cfg.path, cfg.migrated = path, migrated
+	if err := cfg.Validate(); err != nil {
+		return &cfg, fmt.Errorf("%s: invalid configuration:\n%w", path, err)
 	}
 	return &cfg, nil
 }
 
-// Save saves configuration to the default config file
+// Path returns the file the configuration was loaded from, empty for a
+// new one.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Save writes the configuration back to the file it was loaded from. Use
+// SaveTo for a new one. A file in an old layout is kept next to it with a
+// .bak suffix. If saving fails, the file is left as it was.
 func (c *Config) Save() error {
-	return c.SaveTo(DefaultConfigFile)
+	if c.path == "" {
+		return errors.New("configuration was not loaded from a file")
+	}
+	if !c.migrated {
+		return c.SaveTo(c.path)
+	}
+	old, err := os.ReadFile(c.path)
+	if err != nil {
+		return fmt.Errorf("back up old configuration: %w", err)
+	}
+	backup := func() error {
+		if err := os.WriteFile(c.path+".bak", old, 0644); err != nil {
+			return fmt.Errorf("back up old configuration: %w", err)
+		}
+		return nil
+	}
+	if err := c.saveTo(c.path, backup); err != nil {
+		return err
+	}
+	c.migrated = false
+	return nil
+}
+
+// Migrated reports whether the configuration was loaded from an old
+// layout that Save will rewrite.
+func (c *Config) Migrated() bool {
+	return c.migrated
 }
 
-// SaveTo saves configuration to a specific file
+// SaveTo saves configuration to a specific file, creating its directory.
+// Files ending in .yaml or .yml are written as YAML, others as JSON. Ports
+// from the environment are left out, see LoadConfig. The file is replaced
+// only once the new one is written in full.
 func (c *Config) SaveTo(path string) error {
-	data, err := json.MarshalIndent(c, "", "  ")
+	return c.saveTo(path, nil)
+}
+
+// saveTo writes the configuration to a temporary file next to path, calls
+// beforeReplace if set, and renames the file over path. Nothing is
+// replaced if any step fails.
+func (c *Config) saveTo(path string, beforeReplace func() error) error {
+	out := *c
+	out.Leader.Port = c.leaderPortEnv.restore(c.Leader.Port)
+	out.Follower.Port = c.followerPortEnv.restore(c.Follower.Port)
+	data, err := json.MarshalIndent(&out, "", "  ")
+	if err != nil {
+		return err
+	}
+	if isYAML(path) {
+		if data, err = jsonToYAML(data); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	defer os.Remove(tmp.Name()) // fails once renamed
+	_, err = tmp.Write(data)
+	if err := errors.Join(err, tmp.Chmod(0644), tmp.Close()); err != nil {
+		return err
+	}
+	if beforeReplace != nil {
+		if err := beforeReplace(); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
-// ConfigExists returns true if the default config file exists
-func ConfigExists() bool {
-	_, err := os.Stat(DefaultConfigFile)
-	return err == nil
+// ConfigExists returns true if the active config file of profile exists
+func ConfigExists(profile string) bool {
+	return fileExists(ConfigPath(profile))
 }
@@ -0,0 +1,164 @@
+package robot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)            // os.UserConfigDir on macOS
+	t.Setenv("XDG_CONFIG_HOME", home) // and on other Unix systems
+	t.Setenv("AppData", home)         // and on Windows
+	t.Setenv("LEROBOT_CONFIG", "")
+	t.Chdir(t.TempDir())
+	dir := ConfigDir()
+	if !strings.HasPrefix(dir, home) {
+		t.Fatalf("ConfigDir() = %s, not in %s", dir, home)
+	}
+	global := filepath.Join(dir, DefaultConfigFile)
+
+	if got := ConfigPath(""); got != global {
+		t.Errorf("without local file: %s, want %s", got, global)
+	}
+	if err := os.WriteFile(DefaultConfigFile, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := ConfigPath(""); got != DefaultConfigFile {
+		t.Errorf("with local file: %s, want %s", got, DefaultConfigFile)
+	}
+
+	profile := filepath.Join(dir, "profiles", "lab-arm-2.json")
+	if got := ConfigPath("lab-arm-2"); got != profile {
+		t.Errorf("profile: %s, want %s", got, profile)
+	}
+	if err := (&Config{}).SaveTo(profile); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := Profiles(); len(names) != 1 || names[0] != "lab-arm-2" {
+		t.Errorf("Profiles() = %v", names)
+	}
+	for _, name := range []string{"../x", "a/b", `a\b`, ".."} {
+		if _, err := LoadConfig(name); !errors.Is(err, ErrProfileName) {
+			t.Errorf("LoadConfig(%q) = %v, want ErrProfileName", name, err)
+		}
+	}
+
+	t.Setenv("LEROBOT_CONFIG", "/etc/lerobot.json")
+	if got := ConfigPath(""); got != "/etc/lerobot.json" {
+		t.Errorf("LEROBOT_CONFIG: %s", got)
+	}
+}
+
+func TestLoadConfig_EnvOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "robot.json")
+	t.Setenv("LEROBOT_CONFIG", path)
+	t.Setenv("LEROBOT_LEADER_PORT", "/dev/ttyACM8")
+	t.Setenv("LEROBOT_FOLLOWER_PORT", "/dev/ttyACM9")
+	if err := (&Config{Leader: ArmConfig{Port: "/dev/ttyACM0"}, Follower: ArmConfig{Port: "/dev/ttyACM1"}}).SaveTo(path); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Leader.Port != "/dev/ttyACM8" || cfg.Follower.Port != "/dev/ttyACM9" {
+		t.Errorf("ports = %s, %s", cfg.Leader.Port, cfg.Follower.Port)
+	}
+
+	// Saving keeps the file's ports, unless one was changed
+	cfg.Leader.Port = "/dev/ttyUSB0"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadConfigFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Leader.Port != "/dev/ttyUSB0" || saved.Follower.Port != "/dev/ttyACM1" {
+		t.Errorf("saved ports = %s, %s, want /dev/ttyUSB0, /dev/ttyACM1", saved.Leader.Port, saved.Follower.Port)
+	}
+}
+
+func TestLoadConfigFrom_YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lerobot.yaml")
+	cfg := &Config{
+		Leader:   ArmConfig{Port: "/dev/ttyACM0", Calibration: Calibration{Gripper: {ID: 6, RangeMin: 1000, RangeMax: 3000}}},
+		Motors:   []Motor{{Name: Gripper, ID: 6}},
+		RestPose: map[MotorName]float64{Gripper: -12.5},
+	}
+	if err := cfg.SaveTo(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "range_min: 1000") {
+		t.Errorf("not saved as YAML:\n%s", data)
+	}
+
+	got, err := LoadConfigFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Leader.Calibration[Gripper].RangeMax != 3000 || got.RestPose[Gripper] != -12.5 {
+		t.Errorf("round trip: %+v", got)
+	}
+}
+
+func TestLoadConfigFrom_MigratesCalibrationPaths(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "calibration"), 0755)
+	cal := `{"gripper": {"id": 6, "drive_mode": 0, "homing_offset": 12, "range_min": 1000, "range_max": 3000}}`
+	os.WriteFile(filepath.Join(dir, "calibration", "leader.json"), []byte(cal), 0644)
+	os.WriteFile(filepath.Join(dir, "calibration", "follower.json"), []byte(cal), 0644)
+	path := filepath.Join(dir, "lerobot.json")
+	old := `{"motors": [{"name": "gripper", "id": 6}],
+		"leader": {"port": "/dev/a", "calibration": "calibration/leader.json"},
+		"follower": {"port": "/dev/b", "calibration": "calibration/follower.json"}}`
+	os.WriteFile(path, []byte(old), 0644)
+
+	cfg, err := LoadConfigFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Follower.Calibration[Gripper].RangeMin != 1000 || cfg.Leader.Port != "/dev/a" {
+		t.Errorf("migrated config = %+v", cfg)
+	}
+	if data, _ := os.ReadFile(path); string(data) != old {
+		t.Error("loading rewrote the config file")
+	}
+	if !cfg.Migrated() {
+		t.Error("Migrated() = false")
+	}
+
+	// A failed save leaves the old file, and a retry still backs it up
+	if err := os.Mkdir(path+".bak", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err == nil {
+		t.Fatal("Save() with an unwritable backup succeeded")
+	}
+	if data, _ := os.ReadFile(path); string(data) != old || !cfg.Migrated() {
+		t.Errorf("after a failed Save() the config is %q, migrated %v", data, cfg.Migrated())
+	}
+	if matches, _ := filepath.Glob(path + ".*.tmp"); len(matches) > 0 {
+		t.Errorf("temporary files left: %v", matches)
+	}
+	os.Remove(path + ".bak")
+
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path + ".bak"); string(data) != old {
+		t.Errorf("backup of the old config = %q", data)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "calibration/leader.json") {
+		t.Error("config file was not upgraded")
+	}
+	if cfg.Migrated() {
+		t.Error("Migrated() after Save() = true")
+	}
+}
@@ -0,0 +1,70 @@
+package robot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+// ServoFault holds the hardware error flags a servo reports in its status
+// register. The servo cuts or limits torque while most of them are set.
+type ServoFault byte
+
+const (
+	FaultVoltage     ServoFault = 1 << 0 // input voltage outside the configured range
+	FaultSensor      ServoFault = 1 << 1 // magnetic position sensor
+	FaultOverheat    ServoFault = 1 << 2 // above the maximum temperature
+	FaultOvercurrent ServoFault = 1 << 3 // above the protection current
+	FaultOverload    ServoFault = 1 << 5 // load above the overload torque for too long
+)
+
+var faultNames = []struct {
+	fault ServoFault
+	name  string
+}{
+	{FaultVoltage, "voltage out of range"},
+	{FaultSensor, "position sensor error"},
+	{FaultOverheat, "overheated"},
+	{FaultOvercurrent, "overcurrent"},
+	{FaultOverload, "overloaded"},
+}
+
+// String describes the set flags, e.g. "overheated, overloaded", or "ok".
+func (f ServoFault) String() string {
+	if f == 0 {
+		return "ok"
+	}
+	var names []string
+	for _, fn := range faultNames {
+		if f&fn.fault != 0 {
+			names = append(names, fn.name)
+			f &^= fn.fault
+		}
+	}
+	if f != 0 {
+		names = append(names, fmt.Sprintf("unknown fault 0x%02x", byte(f)))
+	}
+	return strings.Join(names, ", ")
+}
+
+// ReadFaults reads the status register of all motors in one transaction
+// and returns the faults of those that report any. It is empty while all
+// servos are healthy.
+func (a *Arm) ReadFaults(ctx context.Context) (map[MotorName]ServoFault, error) {
+	data, err := a.syncRead(ctx, feetech.RegServoStatus.Address, 1)
+	if err != nil {
+		return nil, fmt.Errorf("read faults: %w", err)
+	}
+
+	faults := make(map[MotorName]ServoFault)
+	for id, d := range data {
+		name, _, ok := a.calibration.ByID(id - a.idOffset)
+		if !ok || d[0] == 0 {
+			continue
+		}
+		faults[name] = ServoFault(d[0])
+	}
+	return faults, nil
+}
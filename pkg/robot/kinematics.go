@@ -0,0 +1,260 @@
+package robot
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrSelfCollision is returned when links of the arm would collide.
+var ErrSelfCollision = errors.New("self-collision")
+
+// ErrUnreachable is returned by Kinematics.Inverse for a pose the arm
+// cannot reach.
+var ErrUnreachable = errors.New("unreachable")
+
+// Vec3 is a point in the base frame, in meters: x forward, y to the left
+// and z up, from where the shoulder_pan axis meets the base plate.
+type Vec3 [3]float64
+
+// Kinematics is a simple geometric model of an SO-101 for forward
+// kinematics: shoulder_pan turns the arm about the vertical axis, and
+// shoulder_lift, elbow_flex and wrist_flex bend it in the vertical plane
+// that results. wrist_roll and the gripper don't move the gripper tip.
+type Kinematics struct {
+	BaseHeight     float64 `json:"base_height"`     // base plate to the shoulder_lift axis
+	ShoulderOffset float64 `json:"shoulder_offset"` // pan axis to the shoulder_lift axis, forward
+	UpperArm       float64 `json:"upper_arm"`       // shoulder_lift to elbow_flex axis
+	Forearm        float64 `json:"forearm"`         // elbow_flex to wrist_flex axis
+	Hand           float64 `json:"hand"`            // wrist_flex axis to the gripper tip
+
+	// Radii of the capsules around the links for self-collision checks,
+	// generous rather than tight. The base is a capsule around the pan axis
+	// up to the shoulder_lift axis.
+	BaseRadius float64 `json:"base_radius,omitempty"`
+	LinkRadius float64 `json:"link_radius,omitempty"`
+
+	// Zero is the geometric angle in degrees of each joint at joint angle
+	// 0 (see JointAngle): the azimuth for shoulder_pan, the elevation above
+	// horizontal for shoulder_lift, and the bend relative to the previous
+	// link for the others. Joints not listed are 0.
+	Zero map[MotorName]float64 `json:"zero,omitempty"`
+	// Reverse lists the joints whose angle grows in the opposite direction:
+	// clockwise seen from above for shoulder_pan, downwards for the others.
+	Reverse []MotorName `json:"reverse,omitempty"`
+}
+
+// SO101Kinematics returns the model of a stock SO-101, with joint angle 0
+// in the pose of the middle of calibration: upper arm straight up, forearm
+// pointing forward and the hand in line with it.
+func SO101Kinematics() Kinematics {
+	return Kinematics{
+		BaseHeight:     0.117,
+		ShoulderOffset: 0.030,
+		UpperArm:       0.116,
+		Forearm:        0.135,
+		Hand:           0.155,
+		BaseRadius:     0.05,
+		LinkRadius:     0.025,
+		Zero:           map[MotorName]float64{ShoulderLift: 90, ElbowFlex: -90},
+	}
+}
+
+// ArmPose is where the joints of an arm are, from forward kinematics.
+type ArmPose struct {
+	Shoulder Vec3 // shoulder_lift axis
+	Elbow    Vec3 // elbow_flex axis
+	Wrist    Vec3 // wrist_flex axis
+	Tip      Vec3 // gripper tip
+
+	Pitch float64 // of the hand, in degrees above horizontal
+}
+
+// Points returns the moving points of the pose by name, from the elbow out.
+func (p ArmPose) Points() []NamedPoint {
+	return []NamedPoint{{"elbow", p.Elbow}, {"wrist", p.Wrist}, {"gripper tip", p.Tip}}
+}
+
+// NamedPoint is a point of the arm, named for messages.
+type NamedPoint struct {
+	Name string
+	At   Vec3
+}
+
+// Forward returns the pose of the arm at the given joint angles in degrees.
+// Missing joints are at 0.
+func (k Kinematics) Forward(degrees map[MotorName]float64) ArmPose {
+	angle := func(name MotorName) float64 {
+		a := degrees[name]
+		for _, r := range k.Reverse {
+			if r == name {
+				a = -a
+			}
+		}
+		return (k.Zero[name] + a) * math.Pi / 180
+	}
+
+	// Walk the chain in the arm plane: r outwards from the pan axis, z up
+	pan := angle(ShoulderPan)
+	r, z := k.ShoulderOffset, k.BaseHeight
+	at := func() Vec3 { return Vec3{r * math.Cos(pan), r * math.Sin(pan), z} }
+
+	var p ArmPose
+	p.Shoulder = at()
+	elevation := 0.0
+	for _, link := range []struct {
+		joint  MotorName
+		length float64
+		end    *Vec3
+	}{
+		{ShoulderLift, k.UpperArm, &p.Elbow},
+		{ElbowFlex, k.Forearm, &p.Wrist},
+		{WristFlex, k.Hand, &p.Tip},
+	} {
+		elevation += angle(link.joint)
+		r += link.length * math.Cos(elevation)
+		z += link.length * math.Sin(elevation)
+		*link.end = at()
+	}
+	p.Pitch = elevation * 180 / math.Pi
+	return p
+}
+
+// Inverse returns the joint angles in degrees that put the gripper tip at
+// tip with the hand at pitch degrees above horizontal, with the elbow up.
+// It returns ErrUnreachable if the wrist can't get there. Only
+// shoulder_pan, shoulder_lift, elbow_flex and wrist_flex are set.
+func (k Kinematics) Inverse(tip Vec3, pitch float64) (map[MotorName]float64, error) {
+	joint := func(name MotorName, geometric float64) float64 {
+		a := geometric*180/math.Pi - k.Zero[name]
+		for _, r := range k.Reverse {
+			if r == name {
+				a = -a
+			}
+		}
+		return a
+	}
+
+	// The wrist_flex axis, in the arm plane relative to shoulder_lift
+	pan := math.Atan2(tip[1], tip[0])
+	hand := pitch * math.Pi / 180
+	r := math.Hypot(tip[0], tip[1]) - k.ShoulderOffset - k.Hand*math.Cos(hand)
+	z := tip[2] - k.BaseHeight - k.Hand*math.Sin(hand)
+	d := math.Hypot(r, z)
+
+	// Law of cosines for the bend at the elbow, negative to keep it up
+	cos := (d*d - k.UpperArm*k.UpperArm - k.Forearm*k.Forearm) / (2 * k.UpperArm * k.Forearm)
+	if cos < -1 || cos > 1 {
+		return nil, fmt.Errorf("%w: tip at %v is %.3fm from the shoulder", ErrUnreachable, tip, d)
+	}
+	bend := -math.Acos(cos)
+	lift := math.Atan2(z, r) - math.Atan2(k.Forearm*math.Sin(bend), k.UpperArm+k.Forearm*math.Cos(bend))
+
+	return map[MotorName]float64{
+		ShoulderPan:  joint(ShoulderPan, pan),
+		ShoulderLift: joint(ShoulderLift, lift),
+		ElbowFlex:    joint(ElbowFlex, bend),
+		WristFlex:    joint(WristFlex, hand-lift-bend),
+	}, nil
+}
+
+// Capsule is a line segment with a radius, the shape of a link for
+// collision checks.
+type Capsule struct {
+	Name     string
+	From, To Vec3
+	Radius   float64
+}
+
+// Capsules returns the links of the arm in a pose, from the base out.
+func (k Kinematics) Capsules(p ArmPose) []Capsule {
+	return []Capsule{
+		{"base", Vec3{}, Vec3{0, 0, k.BaseHeight}, k.BaseRadius},
+		{"upper arm", p.Shoulder, p.Elbow, k.LinkRadius},
+		{"forearm", p.Elbow, p.Wrist, k.LinkRadius},
+		{"hand", p.Wrist, p.Tip, k.LinkRadius},
+	}
+}
+
+// SelfCollision returns an error wrapping ErrSelfCollision naming the first
+// two links that touch in a pose, or nil. Adjacent links share a joint and
+// are not checked against each other.
+func (k Kinematics) SelfCollision(p ArmPose) error {
+	links := k.Capsules(p)
+	for i, a := range links {
+		for j := i + 2; j < len(links); j++ {
+			b := links[j]
+			if segmentDistance(a.From, a.To, b.From, b.To) < a.Radius+b.Radius {
+				return fmt.Errorf("%w: %s would hit %s", ErrSelfCollision, b.Name, a.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// segmentDistance returns the shortest distance between the segments p1-q1
+// and p2-q2 (Ericson, Real-Time Collision Detection, 5.1.9).
+func segmentDistance(p1, q1, p2, q2 Vec3) float64 {
+	d1, d2, r := q1.sub(p1), q2.sub(p2), p1.sub(p2)
+	a, e, f := d1.dot(d1), d2.dot(d2), d2.dot(r)
+
+	var s, t float64
+	switch {
+	case a == 0 && e == 0:
+		// Both segments are points
+	case a == 0:
+		t = clamp01(f / e)
+	default:
+		c := d1.dot(r)
+		if e == 0 {
+			s = clamp01(-c / a)
+		} else {
+			b := d1.dot(d2)
+			if denom := a*e - b*b; denom != 0 {
+				s = clamp01((b*f - c*e) / denom)
+			}
+			t = (b*s + f) / e
+			if t < 0 {
+				t, s = 0, clamp01(-c/a)
+			} else if t > 1 {
+				t, s = 1, clamp01((b-c)/a)
+			}
+		}
+	}
+	return p1.add(d1.scale(s)).sub(p2.add(d2.scale(t))).norm()
+}
+
+func (v Vec3) add(w Vec3) Vec3      { return Vec3{v[0] + w[0], v[1] + w[1], v[2] + w[2]} }
+func (v Vec3) sub(w Vec3) Vec3      { return Vec3{v[0] - w[0], v[1] - w[1], v[2] - w[2]} }
+func (v Vec3) scale(f float64) Vec3 { return Vec3{v[0] * f, v[1] * f, v[2] * f} }
+func (v Vec3) dot(w Vec3) float64   { return v[0]*w[0] + v[1]*w[1] + v[2]*w[2] }
+func (v Vec3) norm() float64        { return math.Sqrt(v.dot(v)) }
+func clamp01(x float64) float64     { return max(0, min(1, x)) }
+
+// Degrees converts normalized positions of this arm to joint angles in
+// degrees, e.g. for Kinematics.Forward.
+func (c ArmConfig) Degrees(positions map[MotorName]float64) map[MotorName]float64 {
+	degrees := make(map[MotorName]float64, len(positions))
+	for name, norm := range positions {
+		cal, ok := c.Calibration[name]
+		if !ok {
+			continue
+		}
+		degrees[name] = c.Angles.Joint(name).Degrees(cal, cal.Mapper().Denormalize(norm))
+	}
+	return degrees
+}
+
+// Positions converts joint angles in degrees to normalized positions of
+// this arm, the inverse of Degrees.
+func (c ArmConfig) Positions(degrees map[MotorName]float64) map[MotorName]float64 {
+	positions := make(map[MotorName]float64, len(degrees))
+	for name, deg := range degrees {
+		cal, ok := c.Calibration[name]
+		if !ok {
+			continue
+		}
+		positions[name] = cal.Mapper().Normalize(c.Angles.Joint(name).Raw(cal, deg))
+	}
+	return positions
+}
@@ -0,0 +1,135 @@
+package robot
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func near(a, b Vec3) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestForward(t *testing.T) {
+	k := SO101Kinematics()
+
+	// Upper arm up, forearm and hand forward
+	p := k.Forward(nil)
+	if want := (Vec3{0.030, 0, 0.233}); !near(p.Elbow, want) {
+		t.Errorf("elbow at %v, want %v", p.Elbow, want)
+	}
+	if want := (Vec3{0.320, 0, 0.233}); !near(p.Tip, want) {
+		t.Errorf("tip at %v, want %v", p.Tip, want)
+	}
+
+	// Panned to the left with the hand pointing down
+	p = k.Forward(map[MotorName]float64{ShoulderPan: 90, WristFlex: -90})
+	if want := (Vec3{0, 0.165, 0.078}); !near(p.Tip, want) {
+		t.Errorf("tip at %v, want %v", p.Tip, want)
+	}
+
+	k.Reverse = []MotorName{WristFlex}
+	p = k.Forward(map[MotorName]float64{WristFlex: 90})
+	if want := (Vec3{0.165, 0, 0.078}); !near(p.Tip, want) {
+		t.Errorf("reversed wrist: tip at %v, want %v", p.Tip, want)
+	}
+}
+
+func TestWorkspaceGuard(t *testing.T) {
+	// -100 to 100 is -90° to 90° on every joint
+	cal := Calibration{}
+	for _, m := range SO101Motors() {
+		cal[m.Name] = MotorCalibration{ID: m.ID, RangeMin: 1024, RangeMax: 3072}
+	}
+	// Keep the tip above a table 0.1m up
+	table := Box{Min: Vec3{-1, -1, -1}, Max: Vec3{1, 1, 0.1}}
+	g := NewMotionGuard(ArmConfig{Calibration: cal}, SO101Kinematics(), Workspace{Exclude: []Box{table}})
+	pose := func(wrist float64) map[MotorName]float64 {
+		return map[MotorName]float64{ShoulderPan: 0, ShoulderLift: 0, ElbowFlex: 0, WristFlex: wrist}
+	}
+
+	// Without a full pose there is nothing to check yet
+	if err := g.Check(map[MotorName]float64{WristFlex: -100}); err != nil {
+		t.Errorf("Check(partial) = %v, want unchecked", err)
+	}
+	if _, err := g.Limit(pose(0)); err != nil {
+		t.Fatalf("Limit(level) = %v", err)
+	}
+	// Pointing the hand straight down would put the tip at 0.078m
+	got, err := g.Limit(pose(-100))
+	if !errors.Is(err, ErrOutsideWorkspace) {
+		t.Fatalf("Limit(down) error = %v, want ErrOutsideWorkspace", err)
+	}
+	// sin(angle) = (0.233-0.1)/0.155 at the table
+	want := -math.Asin(0.133/0.155) * 180 / math.Pi / 90 * 100
+	if math.Abs(got[WristFlex]-want) > 0.5 {
+		t.Errorf("limited wrist_flex to %.1f, want about %.1f", got[WristFlex], want)
+	}
+	if err := g.Check(got); err != nil {
+		t.Errorf("limited target is outside: %v", err)
+	}
+
+	// A missing joint keeps its last safe target instead of 0°
+	g.Limit(pose(0))
+	if err := g.Check(map[MotorName]float64{ShoulderPan: 0, ShoulderLift: 0, WristFlex: -100}); !errors.Is(err, ErrOutsideWorkspace) {
+		t.Errorf("Check(without elbow_flex) = %v, want ErrOutsideWorkspace", err)
+	}
+	g.Limit(map[MotorName]float64{ShoulderPan: 0, ShoulderLift: 0, ElbowFlex: 100, WristFlex: 0})
+	if err := g.Check(map[MotorName]float64{ShoulderPan: 0, ShoulderLift: 0, WristFlex: -100}); err != nil {
+		t.Errorf("Check(without elbow_flex, last raised) = %v", err)
+	}
+}
+
+func TestSelfCollision(t *testing.T) {
+	for _, tt := range []struct {
+		p1, q1, p2, q2 Vec3
+		want           float64
+	}{
+		{Vec3{0, 0, 0}, Vec3{1, 0, 0}, Vec3{0, 1, 1}, Vec3{1, 1, 1}, math.Sqrt2}, // parallel
+		{Vec3{0, 0, 0}, Vec3{1, 0, 0}, Vec3{0.5, -1, 1}, Vec3{0.5, 1, 1}, 1},     // crossing
+		{Vec3{0, 0, 0}, Vec3{1, 0, 0}, Vec3{2, 0, 0}, Vec3{3, 0, 0}, 1},          // in line
+		{Vec3{0, 0, 0}, Vec3{0, 0, 0}, Vec3{0, 3, 4}, Vec3{0, 3, 4}, 5},          // points
+	} {
+		if got := segmentDistance(tt.p1, tt.q1, tt.p2, tt.q2); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("segmentDistance(%v, %v, %v, %v) = %v, want %v", tt.p1, tt.q1, tt.p2, tt.q2, got, tt.want)
+		}
+	}
+
+	k := SO101Kinematics()
+	if err := k.SelfCollision(k.Forward(nil)); err != nil {
+		t.Errorf("SelfCollision(zero pose) = %v", err)
+	}
+	// Forearm pointing down and the hand folded back into the base
+	p := k.Forward(map[MotorName]float64{ElbowFlex: -90, WristFlex: -90})
+	if err := k.SelfCollision(p); !errors.Is(err, ErrSelfCollision) {
+		t.Errorf("SelfCollision(folded) = %v, want ErrSelfCollision", err)
+	}
+}
+
+func TestInverse(t *testing.T) {
+	k := SO101Kinematics()
+	k.Reverse = []MotorName{ElbowFlex}
+	for _, degrees := range []map[MotorName]float64{
+		{},
+		{ShoulderPan: 30, ShoulderLift: -20, ElbowFlex: -10, WristFlex: 15},
+		{ShoulderPan: -60, ShoulderLift: 10, ElbowFlex: -40, WristFlex: -50},
+	} {
+		p := k.Forward(degrees)
+		got, err := k.Inverse(p.Tip, p.Pitch)
+		if err != nil {
+			t.Fatalf("Inverse(%v) = %v", degrees, err)
+		}
+		if q := k.Forward(got); !near(q.Tip, p.Tip) || math.Abs(q.Pitch-p.Pitch) > 1e-9 {
+			t.Errorf("Inverse(%v) = %v, reaching %v pitch %.1f, want %v pitch %.1f", degrees, got, q.Tip, q.Pitch, p.Tip, p.Pitch)
+		}
+	}
+
+	if _, err := k.Inverse(Vec3{1, 0, 0.2}, 0); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Inverse(1m away) = %v, want ErrUnreachable", err)
+	}
+}
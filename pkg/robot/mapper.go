@@ -0,0 +1,67 @@
+package robot
+
+// Mapper converts between raw servo positions and normalized positions
+// (-100 to 100) for one motor. It is what Arm uses internally, exported for
+// programs that read or write servos themselves.
+type Mapper struct {
+	RangeMin int // raw position at -100, after Offset is applied
+	RangeMax int // raw position at 100, after Offset is applied
+
+	// Offset is subtracted from raw positions before normalizing and added
+	// back when denormalizing, e.g. to correct a horn that was mounted one
+	// tooth off without recalibrating.
+	Offset int
+
+	// Invert flips the direction: RangeMin maps to 100 and RangeMax to
+	// -100, for servos mounted the other way around.
+	Invert bool
+
+	// Clamp limits Denormalize to [RangeMin, RangeMax], so targets outside
+	// -100 to 100 never drive the joint past its calibrated range.
+	Clamp bool
+}
+
+// Mapper returns the mapper for the calibration. Clamping is off.
+func (c MotorCalibration) Mapper() Mapper {
+	return Mapper{RangeMin: c.RangeMin, RangeMax: c.RangeMax, Offset: c.Offset, Invert: c.Invert}
+}
+
+// Normalize converts a raw servo position to a normalized one. Positions
+// outside the calibrated range map beyond -100 or 100. A calibration with
+// an empty range maps everything to 0.
+func (m Mapper) Normalize(raw int) float64 {
+	rangeSize := float64(m.RangeMax - m.RangeMin)
+	if rangeSize == 0 {
+		return 0
+	}
+	norm := (float64(raw-m.Offset-m.RangeMin)/rangeSize)*200 - 100
+	if m.Invert {
+		norm = -norm
+	}
+	return norm
+}
+
+// Denormalize converts a normalized position to a raw servo position.
+func (m Mapper) Denormalize(norm float64) int {
+	raw, _ := m.DenormalizeClamped(norm)
+	return raw
+}
+
+// DenormalizeClamped is Denormalize that also reports whether the result
+// was clamped to the calibrated range.
+func (m Mapper) DenormalizeClamped(norm float64) (int, bool) {
+	if m.Invert {
+		norm = -norm
+	}
+	rangeSize := float64(m.RangeMax - m.RangeMin)
+	raw := int((norm+100)/200*rangeSize) + m.RangeMin
+
+	clamped := false
+	if m.Clamp {
+		lo, hi := min(m.RangeMin, m.RangeMax), max(m.RangeMin, m.RangeMax)
+		if raw < lo || raw > hi {
+			raw, clamped = max(lo, min(hi, raw)), true
+		}
+	}
+	return raw + m.Offset, clamped
+}
@@ -0,0 +1,61 @@
+package robot
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMapper(t *testing.T) {
+	tests := []struct {
+		name   string
+		m      Mapper
+		raw    int
+		norm   float64
+		target float64 // denormalized to want
+		want   int
+	}{
+		{"plain", Mapper{RangeMin: 1000, RangeMax: 3000}, 1500, -50, 50, 2500},
+		{"offset", Mapper{RangeMin: 1000, RangeMax: 3000, Offset: 100}, 1600, -50, 50, 2600},
+		{"invert", Mapper{RangeMin: 1000, RangeMax: 3000, Invert: true}, 1500, 50, 50, 1500},
+		{"clamp high", Mapper{RangeMin: 1000, RangeMax: 3000, Clamp: true}, 3000, 100, 150, 3000},
+		{"clamp low with offset", Mapper{RangeMin: 1000, RangeMax: 3000, Offset: -50, Clamp: true}, 950, -100, -120, 950},
+		{"no clamp", Mapper{RangeMin: 1000, RangeMax: 3000}, 3000, 100, 150, 3500},
+		{"empty range", Mapper{RangeMin: 2000, RangeMax: 2000}, 2500, 0, 50, 2000},
+		{"empty range clamped", Mapper{RangeMin: 2000, RangeMax: 2000, Clamp: true}, 0, 0, -100, 2000},
+		{"reversed range clamped", Mapper{RangeMin: 3000, RangeMax: 1000, Clamp: true}, 3000, -100, 150, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.Normalize(tt.raw); math.Abs(got-tt.norm) > 1e-9 {
+				t.Errorf("Normalize(%d) = %v, want %v", tt.raw, got, tt.norm)
+			}
+			if got := tt.m.Denormalize(tt.target); got != tt.want {
+				t.Errorf("Denormalize(%v) = %d, want %d", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapper_RoundTrip(t *testing.T) {
+	for _, m := range []Mapper{
+		{RangeMin: 823, RangeMax: 3540},
+		{RangeMin: 823, RangeMax: 3540, Offset: 37, Invert: true},
+		{RangeMin: 823, RangeMax: 3540, Offset: -12, Clamp: true},
+	} {
+		for raw := m.RangeMin + m.Offset; raw <= m.RangeMax+m.Offset; raw += 97 {
+			if back := m.Denormalize(m.Normalize(raw)); back-raw > 1 || raw-back > 1 {
+				t.Errorf("%+v: %d -> %d", m, raw, back)
+			}
+		}
+	}
+}
+
+func TestMapper_DenormalizeClamped(t *testing.T) {
+	m := Mapper{RangeMin: 1000, RangeMax: 3000, Clamp: true}
+	if _, clamped := m.DenormalizeClamped(99); clamped {
+		t.Error("99 should not be clamped")
+	}
+	if raw, clamped := m.DenormalizeClamped(-101); !clamped || raw != 1000 {
+		t.Errorf("-101 = %d, clamped %v; want 1000, true", raw, clamped)
+	}
+}
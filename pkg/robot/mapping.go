@@ -0,0 +1,23 @@
+package robot
+
+// JointMapping transforms a normalized leader position into a normalized
+// follower target, for arms with mismatched geometry or for deliberately
+// scaled-down motion during fine manipulation.
+type JointMapping struct {
+	Scale  float64 `json:"scale,omitempty"` // 0 is treated as 1
+	Offset float64 `json:"offset,omitempty"`
+	Invert bool    `json:"invert,omitempty"`
+}
+
+// Apply maps a leader position to a follower target: the position is
+// inverted if requested, then scaled, then offset.
+func (m JointMapping) Apply(pos float64) float64 {
+	scale := m.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	if m.Invert {
+		pos = -pos
+	}
+	return pos*scale + m.Offset
+}
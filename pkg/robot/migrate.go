@@ -0,0 +1,74 @@
+package robot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// migrateConfig upgrades old configuration layouts in data (JSON) to the
+// current one, resolving relative paths against dir. It reports whether
+// anything changed.
+//
+// The only old layout so far is the one written by the original robot-info
+// tool, where each arm's calibration was a path to a separate file, e.g.
+// "calibration": "calibration/leader.json", instead of being embedded.
+func migrateConfig(data []byte, dir string) ([]byte, bool, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return data, false, nil // reported with position by the caller
+	}
+
+	migrated := false
+	for _, arm := range []string{"leader", "follower"} {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw[arm], &fields); err != nil {
+			continue
+		}
+		var calPath string
+		if err := json.Unmarshal(fields["calibration"], &calPath); err != nil {
+			continue // embedded already
+		}
+		calPath = filepath.FromSlash(calPath)
+		if !filepath.IsAbs(calPath) {
+			calPath = filepath.Join(dir, calPath)
+		}
+		cal, err := os.ReadFile(calPath)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s calibration: %w", arm, err)
+		}
+		var c Calibration
+		if err := json.Unmarshal(cal, &c); err != nil {
+			return nil, false, fmt.Errorf("%s calibration %s: %w", arm, calPath, err)
+		}
+		fields["calibration"], _ = json.Marshal(c)
+		raw[arm], _ = json.Marshal(fields)
+		migrated = true
+	}
+	if !migrated {
+		return data, false, nil
+	}
+	data, err := json.Marshal(raw)
+	return data, true, err
+}
+
+// yamlToJSON converts a YAML document to JSON.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// jsonToYAML converts a JSON document to YAML.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
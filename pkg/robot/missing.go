@@ -0,0 +1,118 @@
+package robot
+
+import (
+	"context"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+// TolerateMissing lets reads carry on without servos that stop responding,
+// e.g. after a loose cable, instead of failing for the whole arm. The reads
+// then leave those motors out and Missing reports them. They are only
+// tried again by RetryMissing, so a servo that is gone doesn't cost every
+// read a bus timeout. Reads still fail if no servo answers, so a
+// disconnected arm is noticed as before.
+func (a *Arm) TolerateMissing(on bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.tolerateMissing = on
+	a.missing = nil
+}
+
+// Missing returns the motors that stopped responding, see TolerateMissing.
+func (a *Arm) Missing() []MotorName {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var names []MotorName
+	for _, name := range a.calibration.Motors() {
+		if a.missing[a.calibration[name].ID+a.idOffset] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RetryMissing reads each missing servo to see whether it is back, and
+// reports those that answered as present again. Each servo that is still
+// gone costs a bus timeout, so call it now and then, e.g. every second,
+// outside a control loop.
+func (a *Arm) RetryMissing(ctx context.Context) {
+	a.mu.Lock()
+	var retry []int
+	for _, id := range a.ids {
+		if a.missing[id] {
+			retry = append(retry, id)
+		}
+	}
+	a.mu.Unlock()
+	if len(retry) == 0 {
+		return
+	}
+	data, _ := a.readEach(ctx, feetech.RegPresentPosition.Address, 2, retry)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for id := range data {
+		delete(a.missing, id)
+	}
+}
+
+// syncRead reads length bytes at address from every servo, keyed by bus
+// ID. With TolerateMissing, missing servos are left out, as are servos
+// that don't answer, which become missing.
+func (a *Arm) syncRead(ctx context.Context, address byte, length int) (map[int][]byte, error) {
+	// Plan the read under the lock but talk to the bus without it, so
+	// Missing doesn't wait for a bus timeout
+	a.mu.Lock()
+	tolerate := a.tolerateMissing
+	var ids []int
+	for _, id := range a.ids {
+		if !a.missing[id] {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		// All gone: start over with a full read, so a disconnect still fails
+		a.missing = nil
+	}
+	a.mu.Unlock()
+	if !tolerate || len(ids) == 0 {
+		return a.bus.SyncRead(ctx, address, length, a.ids)
+	}
+
+	data, err := a.bus.SyncRead(ctx, address, length, ids)
+	if err == nil {
+		return data, nil
+	}
+	// Find out which servos don't answer
+	data, gone := a.readEach(ctx, address, length, ids)
+	if len(data) == 0 {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.missing == nil {
+		a.missing = make(map[int]bool)
+	}
+	for _, id := range gone {
+		a.missing[id] = true
+	}
+	return data, nil
+}
+
+// readEach reads length bytes at address from each servo in turn, and
+// returns the data of those that answered and the IDs of those that didn't.
+// A servo that is likely gone isn't worth the time of retries.
+func (a *Arm) readEach(ctx context.Context, address byte, length int, ids []int) (map[int][]byte, []int) {
+	bus := unwrapBus(a.bus)
+	data := make(map[int][]byte, len(ids))
+	var failed []int
+	for _, id := range ids {
+		d, err := bus.ReadRegister(ctx, id, address, length)
+		if err != nil {
+			failed = append(failed, id)
+			continue
+		}
+		data[id] = d
+	}
+	return data, failed
+}
@@ -14,7 +14,7 @@ const (
 	Gripper      MotorName = "gripper"
 )
 
-// AllMotors returns all motor names in order (matching servo IDs 1-6).
+// AllMotors returns the SO-101 motor names in order (matching servo IDs 1-6).
 func AllMotors() []MotorName {
 	return []MotorName{
 		ShoulderPan,
@@ -25,3 +25,37 @@ func AllMotors() []MotorName {
 		Gripper,
 	}
 }
+
+// Motor is a joint of an arm and the ID of the servo driving it.
+type Motor struct {
+	Name MotorName `json:"name"`
+	ID   int       `json:"id"`
+}
+
+// SO101Motors returns the motors of an SO-101 arm, with servo IDs 1-6.
+func SO101Motors() []Motor {
+	names := AllMotors()
+	motors := make([]Motor, len(names))
+	for i, name := range names {
+		motors[i] = Motor{Name: name, ID: i + 1}
+	}
+	return motors
+}
+
+// MotorNames returns the names of motors in order.
+func MotorNames(motors []Motor) []MotorName {
+	names := make([]MotorName, len(motors))
+	for i, m := range motors {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// MotorIDs returns the servo IDs of motors in order.
+func MotorIDs(motors []Motor) []int {
+	ids := make([]int, len(motors))
+	for i, m := range motors {
+		ids[i] = m.ID
+	}
+	return ids
+}
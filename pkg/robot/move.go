@@ -0,0 +1,74 @@
+package robot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// moveStep is the interval between targets written by MoveTo.
+const moveStep = 20 * time.Millisecond
+
+// Positioner reads and writes normalized joint positions. *Arm implements
+// it, as does the simulated arm in package sim.
+type Positioner interface {
+	ReadPositions(ctx context.Context) (map[MotorName]float64, error)
+	WritePositions(ctx context.Context, positions map[MotorName]float64) error
+}
+
+// MoveTo drives the arm to target along a straight line in joint space,
+// with no joint moving faster than speed (normalized units per second).
+// Motors not in target are left alone. Torque must be enabled.
+func MoveTo(ctx context.Context, arm Positioner, target map[MotorName]float64, speed float64) error {
+	if speed <= 0 {
+		return fmt.Errorf("invalid speed %v", speed)
+	}
+	start, err := arm.ReadPositions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var dist float64
+	for name, pos := range target {
+		if from, ok := start[name]; ok {
+			dist = max(dist, math.Abs(pos-from))
+		}
+	}
+	duration := time.Duration(dist / speed * float64(time.Second))
+
+	ticker := time.NewTicker(moveStep)
+	defer ticker.Stop()
+	begin := time.Now()
+	for {
+		frac := 1.0
+		if duration > 0 {
+			frac = min(1, float64(time.Since(begin))/float64(duration))
+		}
+		if err := arm.WritePositions(ctx, interpolatePose(start, target, frac)); err != nil {
+			return err
+		}
+		if frac == 1 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// interpolatePose returns the pose frac of the way from start to target.
+// Motors without a start position jump to the target.
+func interpolatePose(start, target map[MotorName]float64, frac float64) map[MotorName]float64 {
+	pose := make(map[MotorName]float64, len(target))
+	for name, to := range target {
+		from, ok := start[name]
+		if !ok {
+			from = to
+		}
+		pose[name] = from + (to-from)*frac
+	}
+	return pose
+}
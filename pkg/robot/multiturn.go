@@ -0,0 +1,61 @@
+package robot
+
+const (
+	// positionSignBit holds the sign of present and goal positions, which
+	// go negative in multi-turn mode.
+	positionSignBit = 15
+	// maxMultiTurnRaw is the largest raw position of a multi-turn joint,
+	// either way.
+	maxMultiTurnRaw = 1<<positionSignBit - 1
+
+	stepsPerTurn = 4096
+)
+
+// turnCounter counts the whole turns of a multi-turn joint across reads,
+// see MotorCalibration.MultiTurn.
+type turnCounter struct {
+	last  int // last reading, as reported by the servo
+	turns int
+}
+
+// unwrap returns raw plus the turns counted so far. A jump of more than
+// half a turn since the last reading is taken as the servo wrapping around
+// between 4095 and 0, which no joint moves that fast between reads.
+func (t *turnCounter) unwrap(raw int) int {
+	switch delta := raw - t.last; {
+	case delta > stepsPerTurn/2:
+		t.turns--
+	case delta < -stepsPerTurn/2:
+		t.turns++
+	}
+	t.last = raw
+	return raw + t.turns*stepsPerTurn
+}
+
+// decodePosition decodes the present position of the servo with
+// calibrated ID id. Positions of multi-turn joints are unwrapped, so they
+// keep counting past a full turn also when the servo reports them within
+// one.
+func (a *Arm) decodePosition(id int, d []byte) int {
+	raw := decodeSignMagnitude(decodeWord(d), positionSignBit)
+	if _, cal, ok := a.calibration.ByID(id); !ok || !cal.MultiTurn {
+		return raw
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	t, ok := a.turns[id]
+	if !ok {
+		if a.turns == nil {
+			a.turns = make(map[int]*turnCounter)
+		}
+		t = &turnCounter{last: raw}
+		a.turns[id] = t
+	}
+	return t.unwrap(raw)
+}
+
+// encodePosition encodes a goal position, negative for a multi-turn joint
+// below its zero.
+func encodePosition(raw int) []byte {
+	return encodeWord(encodeSignMagnitude(raw, positionSignBit))
+}
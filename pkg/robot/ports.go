@@ -0,0 +1,155 @@
+package robot
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.bug.st/serial"
+	"go.bug.st/serial/enumerator"
+)
+
+// ListPorts returns the serial ports a controller board may be attached to,
+// in natural order (COM3 before COM10). On Windows these are the COMx ports
+// listed in the registry; macOS Bluetooth ports are left out.
+func ListPorts() ([]string, error) {
+	ports, err := serial.GetPortsList()
+	if err != nil {
+		return nil, err
+	}
+	return filterPorts(runtime.GOOS, ports), nil
+}
+
+// filterPorts drops ports that cannot hold an arm and sorts the rest.
+func filterPorts(goos string, ports []string) []string {
+	var filtered []string
+	for _, port := range ports {
+		switch {
+		case strings.Contains(port, "Bluetooth"):
+			continue
+		case goos == "windows" && !strings.HasPrefix(normalizePort(goos, port), "COM"):
+			continue
+		}
+		filtered = append(filtered, port)
+	}
+	slices.SortFunc(filtered, comparePorts)
+	return slices.Compact(filtered)
+}
+
+// comparePorts orders port names by their prefix and then by the number
+// they end in.
+func comparePorts(a, b string) int {
+	pa, na := splitPortNumber(a)
+	pb, nb := splitPortNumber(b)
+	return cmp.Or(strings.Compare(pa, pb), cmp.Compare(na, nb), strings.Compare(a, b))
+}
+
+func splitPortNumber(port string) (string, int) {
+	i := len(port)
+	for i > 0 && port[i-1] >= '0' && port[i-1] <= '9' {
+		i--
+	}
+	n, err := strconv.Atoi(port[i:])
+	if err != nil {
+		return port, -1
+	}
+	return port[:i], n
+}
+
+// NormalizePort returns the canonical name of a serial port, so that names
+// the user typed compare equal to enumerated ones. See SamePort.
+func NormalizePort(port string) string {
+	return normalizePort(runtime.GOOS, port)
+}
+
+// normalizePort canonicalizes port for goos. Windows device names are case
+// insensitive and may carry the \\.\ device namespace prefix required for
+// COM10 and up, e.g. \\.\com12 is COM12. Other systems use paths as is.
+func normalizePort(goos, port string) string {
+	if goos != "windows" {
+		return port
+	}
+	port = strings.TrimPrefix(port, `\\.\`)
+	return strings.ToUpper(strings.TrimSuffix(port, ":"))
+}
+
+// SamePort reports whether two port names refer to the same device.
+func SamePort(a, b string) bool {
+	return NormalizePort(a) == NormalizePort(b)
+}
+
+// USBIdentity identifies a USB serial adapter independently of the device
+// path the OS assigned to it, which may change between reboots.
+type USBIdentity struct {
+	VID          string `json:"vid"`
+	PID          string `json:"pid"`
+	SerialNumber string `json:"serial_number"`
+}
+
+// Equal reports whether both identities are set and name the same adapter.
+func (u *USBIdentity) Equal(o *USBIdentity) bool {
+	return u != nil && o != nil && *u == *o
+}
+
+// LookupUSBIdentity returns the USB identity of the adapter behind port, or
+// nil if the port is not a USB device or has no serial number.
+func LookupUSBIdentity(port string) *USBIdentity {
+	ports, err := enumerator.GetDetailedPortsList()
+	if err != nil {
+		return nil
+	}
+	for _, p := range ports {
+		if SamePort(p.Name, port) && p.IsUSB && p.SerialNumber != "" {
+			return &USBIdentity{
+				VID:          p.VID,
+				PID:          p.PID,
+				SerialNumber: p.SerialNumber,
+			}
+		}
+	}
+	return nil
+}
+
+// findPortByUSB returns the device path currently assigned to the adapter
+// with the given identity. It fails if several adapters have it, as cheap
+// adapters sharing a serial number do, rather than guess between them.
+func findPortByUSB(id *USBIdentity) (string, bool) {
+	ports, err := enumerator.GetDetailedPortsList()
+	if err != nil {
+		return "", false
+	}
+	var found []string
+	for _, p := range ports {
+		if p.IsUSB && id.Equal(&USBIdentity{VID: p.VID, PID: p.PID, SerialNumber: p.SerialNumber}) {
+			found = append(found, p.Name)
+		}
+	}
+	if len(found) != 1 {
+		return "", false
+	}
+	return found[0], true
+}
+
+// ResolvePort updates Port to the device path currently assigned to the arm's
+// USB adapter. If the arm has no stored USB identity, or the adapter is not
+// found or not the only one with that identity, the stored path is kept. Returns true if the port changed.
+func (a *ArmConfig) ResolvePort() bool {
+	if a.USB == nil {
+		return false
+	}
+	port, ok := findPortByUSB(a.USB)
+	if !ok || SamePort(port, a.Port) {
+		return false
+	}
+	a.Port = port
+	return true
+}
+
+// ResolvePorts resolves the device paths of both arms, see ArmConfig.ResolvePort.
+func (c *Config) ResolvePorts() bool {
+	leader := c.Leader.ResolvePort()
+	follower := c.Follower.ResolvePort()
+	return leader || follower
+}
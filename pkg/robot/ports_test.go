@@ -0,0 +1,55 @@
+package robot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilterPorts(t *testing.T) {
+	got := filterPorts("windows", []string{"COM10", "COM3", "LPT1", "COM1"})
+	if want := []string{"COM1", "COM3", "COM10"}; !slices.Equal(got, want) {
+		t.Errorf("windows: %v, want %v", got, want)
+	}
+
+	got = filterPorts("linux", []string{"/dev/ttyUSB0", "/dev/ttyACM10", "/dev/ttyACM2"})
+	if want := []string{"/dev/ttyACM2", "/dev/ttyACM10", "/dev/ttyUSB0"}; !slices.Equal(got, want) {
+		t.Errorf("linux: %v, want %v", got, want)
+	}
+
+	got = filterPorts("darwin", []string{"/dev/cu.usbmodem5678", "/dev/cu.Bluetooth-Incoming-Port"})
+	if want := []string{"/dev/cu.usbmodem5678"}; !slices.Equal(got, want) {
+		t.Errorf("darwin: %v, want %v", got, want)
+	}
+}
+
+func TestNormalizePort(t *testing.T) {
+	for _, tt := range []struct {
+		goos, port, want string
+	}{
+		{"windows", "COM3", "COM3"},
+		{"windows", "com3", "COM3"},
+		{"windows", `\\.\COM12`, "COM12"},
+		{"windows", "COM4:", "COM4"},
+		{"linux", "/dev/ttyACM0", "/dev/ttyACM0"},
+		{"darwin", "/dev/cu.usbmodem1234", "/dev/cu.usbmodem1234"},
+	} {
+		if got := normalizePort(tt.goos, tt.port); got != tt.want {
+			t.Errorf("normalizePort(%s, %q) = %q, want %q", tt.goos, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestUSBIdentity_Equal(t *testing.T) {
+	a := &USBIdentity{VID: "1A86", PID: "55D3", SerialNumber: "58FA083324"}
+	b := *a
+	if !a.Equal(&b) {
+		t.Error("identical identities differ")
+	}
+	b.SerialNumber = "58FA083325"
+	if a.Equal(&b) {
+		t.Error("different serial numbers match")
+	}
+	if a.Equal(nil) || (*USBIdentity)(nil).Equal(nil) {
+		t.Error("missing identity matches")
+	}
+}
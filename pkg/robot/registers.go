@@ -0,0 +1,185 @@
+package robot
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+// Register is an entry of a servo's control table: a feetech register
+// with the name LeRobot gives it.
+type Register struct {
+	feetech.Register
+	Name   string // e.g. "return_delay"
+	EEPROM bool   // kept on power loss, written with the EEPROM lock open
+}
+
+// sts3215Names names the feetech registers of the STS3215 the way LeRobot
+// does.
+var sts3215Names = map[string]feetech.Register{
+	"firmware_major":               feetech.RegFirmwareVersion,
+	"model_number":                 feetech.RegModelNumber,
+	"id":                           feetech.RegID,
+	"baud_rate":                    feetech.RegBaudRate,
+	"return_delay":                 feetech.RegResponseDelay,
+	"min_position_limit":           feetech.RegMinAngleLimit,
+	"max_position_limit":           feetech.RegMaxAngleLimit,
+	"max_temperature":              feetech.RegMaxTemp,
+	"max_voltage":                  feetech.RegMaxVoltage,
+	"min_voltage":                  feetech.RegMinVoltage,
+	"max_torque":                   feetech.RegMaxTorque,
+	"phase":                        feetech.RegPhase,
+	"unloading_condition":          feetech.RegUnloadCondition,
+	"led_alarm_condition":          feetech.RegLEDAlarm,
+	"p_coefficient":                feetech.RegPGain,
+	"d_coefficient":                feetech.RegDGain,
+	"i_coefficient":                feetech.RegIGain,
+	"min_startup_force":            feetech.RegMinStartupForce,
+	"cw_dead_zone":                 feetech.RegClockwiseDeadband,
+	"ccw_dead_zone":                feetech.RegCounterClockwiseDeadband,
+	"protection_current":           feetech.RegProtectionCurrent,
+	"angular_resolution":           feetech.RegAngularResolution,
+	"homing_offset":                feetech.RegPositionOffset,
+	"operating_mode":               feetech.RegOperatingMode,
+	"protective_torque":            feetech.RegProtectionTorque,
+	"protection_time":              feetech.RegProtectionTime,
+	"overload_torque":              feetech.RegOverloadTorque,
+	"velocity_p_coefficient":       feetech.RegSpeedClosedLoop,
+	"over_current_protection_time": feetech.RegCurrentClosedLoop,
+	"torque_enable":                feetech.RegTorqueEnable,
+	"acceleration":                 feetech.RegAcceleration,
+	"goal_position":                feetech.RegGoalPosition,
+	"goal_time":                    feetech.RegGoalTime,
+	"goal_velocity":                feetech.RegGoalVelocity,
+	"torque_limit":                 feetech.RegTorqueLimit,
+	"lock":                         feetech.RegLock,
+	"present_position":             feetech.RegPresentPosition,
+	"present_velocity":             feetech.RegPresentVelocity,
+	"present_load":                 feetech.RegPresentLoad,
+	"present_voltage":              feetech.RegPresentVoltage,
+	"present_temperature":          feetech.RegPresentTemp,
+	"status":                       feetech.RegServoStatus,
+	"moving":                       feetech.RegMoving,
+	"present_current":              feetech.RegPresentCurrent,
+
+	// Missing from feetech
+	"firmware_minor":         {Address: 1, Size: 1, ReadOnly: true},
+	"response_level":         {Address: 8, Size: 1},
+	"velocity_i_coefficient": {Address: 39, Size: 1},
+}
+
+// STS3215Registers is the control table of the STS3215 servos of the
+// SO-101, ordered by address. Most of it is configuration in EEPROM; from
+// torque_enable on it is RAM, lost on power loss.
+var STS3215Registers = sts3215Registers()
+
+func sts3215Registers() []Register {
+	regs := make([]Register, 0, len(sts3215Names))
+	for name, reg := range sts3215Names {
+		if name == "goal_position" || name == "present_position" {
+			// feetech reads positions as unsigned, which multi-turn
+			// positions are not
+			reg.SignBit = positionSignBit
+		}
+		eeprom := reg.Address < feetech.RegTorqueEnable.Address && !reg.ReadOnly
+		regs = append(regs, Register{Register: reg, Name: name, EEPROM: eeprom})
+	}
+	slices.SortFunc(regs, func(a, b Register) int { return cmp.Compare(a.Address, b.Address) })
+	return regs
+}
+
+// STS3215Register returns the register of STS3215Registers with the given
+// name.
+func STS3215Register(name string) (Register, bool) {
+	for _, reg := range STS3215Registers {
+		if reg.Name == name {
+			return reg, true
+		}
+	}
+	return Register{}, false
+}
+
+// lockRegister protects the EEPROM: while it is 1, writes to EEPROM
+// registers are not kept.
+var lockRegister, _ = STS3215Register("lock")
+
+// encode returns v as stored in reg, or an error if it doesn't fit.
+func (reg Register) encode(v int) ([]byte, error) {
+	if reg.SignBit > 0 {
+		limit := 1<<reg.SignBit - 1
+		if v < -limit || v > limit {
+			return nil, fmt.Errorf("%s: %d out of range -%d to %d", reg.Name, v, limit, limit)
+		}
+		v = encodeSignMagnitude(v, reg.SignBit)
+	} else if limit := 1<<(8*reg.Size) - 1; v < 0 || v > limit {
+		return nil, fmt.Errorf("%s: %d out of range 0 to %d", reg.Name, v, limit)
+	}
+	if reg.Size == 1 {
+		return []byte{byte(v)}, nil
+	}
+	return encodeWord(v), nil
+}
+
+// decode is the inverse of encode.
+func (reg Register) decode(data []byte) int {
+	v := int(data[0])
+	if reg.Size == 2 {
+		v = decodeWord(data)
+	}
+	if reg.SignBit > 0 {
+		v = decodeSignMagnitude(v, reg.SignBit)
+	}
+	return v
+}
+
+// ReadRegister reads a register of motor's servo, e.g. one of
+// STS3215Registers. Sign-magnitude values are returned as signed ints.
+func (a *Arm) ReadRegister(ctx context.Context, motor MotorName, reg Register) (int, error) {
+	cal, ok := a.calibration[motor]
+	if !ok {
+		return 0, fmt.Errorf("unknown motor %s", motor)
+	}
+	data, err := a.bus.ReadRegister(ctx, cal.ID+a.idOffset, reg.Address, reg.Size)
+	if err != nil {
+		return 0, fmt.Errorf("read %s of %s: %w", reg.Name, motor, err)
+	}
+	return reg.decode(data), nil
+}
+
+// WriteRegister writes value to a register of motor's servo. The EEPROM
+// lock is opened for EEPROM registers and closed again afterwards, so the
+// value is kept on power loss; torque should be off while writing them.
+// Read-only registers and values that don't fit are refused.
+func (a *Arm) WriteRegister(ctx context.Context, motor MotorName, reg Register, value int) error {
+	cal, ok := a.calibration[motor]
+	if !ok {
+		return fmt.Errorf("unknown motor %s", motor)
+	}
+	if reg.ReadOnly {
+		return fmt.Errorf("%s is read-only", reg.Name)
+	}
+	data, err := reg.encode(value)
+	if err != nil {
+		return err
+	}
+
+	id := cal.ID + a.idOffset
+	if reg.EEPROM {
+		if err := a.bus.WriteRegister(ctx, id, lockRegister.Address, []byte{0}); err != nil {
+			return fmt.Errorf("unlock EEPROM of %s: %w", motor, err)
+		}
+	}
+	err = a.bus.WriteRegister(ctx, id, reg.Address, data)
+	if reg.EEPROM {
+		if lerr := a.bus.WriteRegister(ctx, id, lockRegister.Address, []byte{1}); err == nil && lerr != nil {
+			return fmt.Errorf("lock EEPROM of %s: %w", motor, lerr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("write %s of %s: %w", reg.Name, motor, err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+const (
+	defaultRetries = 2
+
+	// retryBackoff is the delay before the first retry. It doubles with
+	// each retry, and up to as much again is added at random, so arms
+	// sharing a bus don't retry in lockstep.
+	retryBackoff = 2 * time.Millisecond
+)
+
+// retryBus retries transactions that failed on a garbled or lost packet,
+// such as from a flaky cable, instead of failing the whole read or write.
+type retryBus struct {
+	Bus
+	retries int
+}
+
+// withRetries wraps bus to retry failed transactions up to retries times.
+func withRetries(bus Bus, retries int) Bus {
+	if retries <= 0 {
+		return bus
+	}
+	return &retryBus{Bus: bus, retries: retries}
+}
+
+// unwrapBus returns the bus under any retries.
+func unwrapBus(bus Bus) Bus {
+	if r, ok := bus.(*retryBus); ok {
+		return r.Bus
+	}
+	return bus
+}
+
+// retryable reports whether err is worth another attempt: a transmission
+// error rather than a closed bus, a cancelled context or a servo refusing
+// the instruction.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return errors.Is(err, feetech.ErrTimeout) || errors.Is(err, feetech.ErrNoResponse) || errors.Is(err, feetech.ErrInvalidPacket)
+}
+
+// do runs op until it succeeds, fails for good, or runs out of retries.
+func (b *retryBus) do(ctx context.Context, op func() error) error {
+	err := op()
+	backoff := retryBackoff
+	for range b.retries {
+		if err == nil || !retryable(ctx, err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff + rand.N(backoff)):
+		}
+		backoff *= 2
+		err = op()
+	}
+	return err
+}
+
+func (b *retryBus) Ping(ctx context.Context, id int) (model int, err error) {
+	err = b.do(ctx, func() error {
+		model, err = b.Bus.Ping(ctx, id)
+		return err
+	})
+	return model, err
+}
+
+func (b *retryBus) ReadRegister(ctx context.Context, id int, address byte, length int) (data []byte, err error) {
+	err = b.do(ctx, func() error {
+		data, err = b.Bus.ReadRegister(ctx, id, address, length)
+		return err
+	})
+	return data, err
+}
+
+func (b *retryBus) WriteRegister(ctx context.Context, id int, address byte, data []byte) error {
+	return b.do(ctx, func() error { return b.Bus.WriteRegister(ctx, id, address, data) })
+}
+
+func (b *retryBus) SyncRead(ctx context.Context, address byte, dataLen int, ids []int) (data map[int][]byte, err error) {
+	err = b.do(ctx, func() error {
+		data, err = b.Bus.SyncRead(ctx, address, dataLen, ids)
+		return err
+	})
+	return data, err
+}
+
+func (b *retryBus) SyncWrite(ctx context.Context, address byte, dataLen int, servoData map[int][]byte) error {
+	return b.do(ctx, func() error { return b.Bus.SyncWrite(ctx, address, dataLen, servoData) })
+}
@@ -0,0 +1,249 @@
+// Package robottest provides an in-memory servo bus for testing code built
+// on robot.Arm without hardware.
+package robottest
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+// FakeBus simulates STS servos as in-memory register tables. Writing a goal
+// position while torque is enabled moves the servo there instantly.
+//
+// Failures are scripted with Fail: each call to the bus consumes the next
+// queued error, so tests can reproduce flaky or disconnected buses.
+type FakeBus struct {
+	mu      sync.Mutex
+	servos  map[int]*[256]byte
+	silent  map[int]bool // servos that don't respond, see SetResponding
+	pending []error
+	closed  bool
+	calls   int
+}
+
+// NewFakeBus returns a bus with STS3215 servos at the given IDs, centered
+// at position 2048 with torque disabled.
+func NewFakeBus(ids ...int) *FakeBus {
+	b := &FakeBus{servos: make(map[int]*[256]byte, len(ids))}
+	for _, id := range ids {
+		regs := new([256]byte)
+		binary.LittleEndian.PutUint16(regs[feetech.RegModelNumber.Address:], uint16(feetech.ModelSTS3215.Number))
+		binary.LittleEndian.PutUint16(regs[feetech.RegPresentPosition.Address:], 2048)
+		binary.LittleEndian.PutUint16(regs[feetech.RegGoalPosition.Address:], 2048)
+		regs[feetech.RegPresentVoltage.Address] = 120
+		regs[feetech.RegPresentTemp.Address] = 30
+		b.servos[id] = regs
+	}
+	return b
+}
+
+// Fail queues errors returned by the next bus calls, one per call. A nil
+// entry lets that call succeed.
+func (b *FakeBus) Fail(errs ...error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, errs...)
+}
+
+// SetResponding makes servo id stop or start responding, like a servo
+// with a loose cable. A silent servo fails reads of it, sync reads that
+// include it, and ignores writes.
+func (b *FakeBus) SetResponding(id int, on bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.silent == nil {
+		b.silent = make(map[int]bool)
+	}
+	b.silent[id] = !on
+}
+
+// Calls returns the number of bus transactions so far.
+func (b *FakeBus) Calls() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls
+}
+
+// Closed reports whether Close was called.
+func (b *FakeBus) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// Register returns length bytes of servo id's register table at address.
+func (b *FakeBus) Register(id int, address byte, length int) []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.servos[id][address:int(address)+length]...)
+}
+
+// SetRegister writes data into servo id's register table, bypassing any
+// scripted failures. Use it to set up present position, load, etc.
+func (b *FakeBus) SetRegister(id int, address byte, data ...byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	copy(b.servos[id][address:], data)
+}
+
+// Position returns the present position of servo id.
+func (b *FakeBus) Position(id int) int {
+	return fromSignMagnitude(binary.LittleEndian.Uint16(b.Register(id, feetech.RegPresentPosition.Address, 2)))
+}
+
+// SetPosition sets the present position of servo id, e.g. to simulate a
+// leader arm being moved by hand.
+func (b *FakeBus) SetPosition(id, pos int) {
+	b.SetRegister(id, feetech.RegPresentPosition.Address, binary.LittleEndian.AppendUint16(nil, signMagnitude(pos))...)
+}
+
+// GoalPosition returns the last goal position written to servo id.
+func (b *FakeBus) GoalPosition(id int) int {
+	return fromSignMagnitude(binary.LittleEndian.Uint16(b.Register(id, feetech.RegGoalPosition.Address, 2)))
+}
+
+// positionSign is the sign bit of positions, negative in multi-turn mode.
+const positionSign = 1 << 15
+
+func signMagnitude(pos int) uint16 {
+	if pos < 0 {
+		return uint16(-pos) | positionSign
+	}
+	return uint16(pos)
+}
+
+func fromSignMagnitude(v uint16) int {
+	if v&positionSign != 0 {
+		return -int(v &^ positionSign)
+	}
+	return int(v)
+}
+
+// TorqueEnabled reports whether torque is enabled on servo id.
+func (b *FakeBus) TorqueEnabled(id int) bool {
+	return b.Register(id, feetech.RegTorqueEnable.Address, 1)[0] != 0
+}
+
+// begin accounts for a transaction and returns its scripted error, if any.
+// The caller must hold b.mu.
+func (b *FakeBus) begin() error {
+	b.calls++
+	if b.closed {
+		return feetech.ErrBusClosed
+	}
+	if len(b.pending) > 0 {
+		err := b.pending[0]
+		b.pending = b.pending[1:]
+		return err
+	}
+	return nil
+}
+
+func (b *FakeBus) servo(id int) (*[256]byte, error) {
+	regs, ok := b.servos[id]
+	if !ok || b.silent[id] {
+		return nil, fmt.Errorf("servo %d: %w", id, feetech.ErrNoResponse)
+	}
+	return regs, nil
+}
+
+// write stores data and applies goal positions when torque is on.
+func (b *FakeBus) write(regs *[256]byte, address byte, data []byte) {
+	copy(regs[address:], data)
+	if regs[feetech.RegTorqueEnable.Address] != 0 {
+		copy(regs[feetech.RegPresentPosition.Address:feetech.RegPresentPosition.Address+2],
+			regs[feetech.RegGoalPosition.Address:feetech.RegGoalPosition.Address+2])
+	}
+}
+
+func (b *FakeBus) Ping(ctx context.Context, id int) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.begin(); err != nil {
+		return 0, err
+	}
+	regs, err := b.servo(id)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.LittleEndian.Uint16(regs[feetech.RegModelNumber.Address:])), nil
+}
+
+func (b *FakeBus) ReadRegister(ctx context.Context, id int, address byte, length int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.begin(); err != nil {
+		return nil, err
+	}
+	regs, err := b.servo(id)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), regs[address:int(address)+length]...), nil
+}
+
+func (b *FakeBus) WriteRegister(ctx context.Context, id int, address byte, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.begin(); err != nil {
+		return err
+	}
+	regs, err := b.servo(id)
+	if err != nil {
+		return err
+	}
+	b.write(regs, address, data)
+	return nil
+}
+
+func (b *FakeBus) SyncRead(ctx context.Context, address byte, dataLen int, ids []int) (map[int][]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.begin(); err != nil {
+		return nil, err
+	}
+	data := make(map[int][]byte, len(ids))
+	for _, id := range ids {
+		regs, err := b.servo(id)
+		if err != nil {
+			return nil, err
+		}
+		data[id] = append([]byte(nil), regs[address:int(address)+dataLen]...)
+	}
+	return data, nil
+}
+
+func (b *FakeBus) SyncWrite(ctx context.Context, address byte, dataLen int, servoData map[int][]byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.begin(); err != nil {
+		return err
+	}
+	// Sync write has no response, so unknown IDs are silently ignored
+	for id, data := range servoData {
+		if regs, ok := b.servos[id]; ok && !b.silent[id] {
+			b.write(regs, address, data[:dataLen])
+		}
+	}
+	return nil
+}
+
+// Open reopens the bus after Close, like plugging an adapter back in.
+func (b *FakeBus) Open() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = false
+	return nil
+}
+
+// Close marks the bus closed; later calls fail with feetech.ErrBusClosed.
+func (b *FakeBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
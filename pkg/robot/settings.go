@@ -0,0 +1,139 @@
+package robot
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// RegisterValues are servo register values by motor and register name, see
+// STS3215Registers.
+type RegisterValues map[MotorName]map[string]int
+
+// RecommendedSettings returns the EEPROM settings LeRobot recommends for
+// the motors of a leader or, with follower, a follower arm: servos answer
+// reads at once and nothing else, in position mode over the full turn, or
+// without position limits for a MultiTurn joint so it counts turns. A
+// follower gets stiffer gains, and its gripper limits its current so it
+// doesn't overheat holding an object.
+func RecommendedSettings(cal Calibration, follower bool) RegisterValues {
+	values := make(RegisterValues, len(cal))
+	for _, name := range cal.Motors() {
+		v := map[string]int{
+			"return_delay":       0,
+			"response_level":     1,
+			"operating_mode":     0,
+			"min_position_limit": 0,
+			"max_position_limit": maxRawRange,
+		}
+		if cal[name].MultiTurn {
+			v["max_position_limit"] = 0
+		}
+		if follower {
+			v["p_coefficient"] = 16
+			v["i_coefficient"] = 0
+			v["d_coefficient"] = 32
+			if name == Gripper {
+				v["max_torque"] = 500
+				v["protection_current"] = 250
+				v["overload_torque"] = 25
+			}
+		}
+		values[name] = v
+	}
+	return values
+}
+
+// EEPROMSettings returns the EEPROM registers that configure the motors'
+// servos, for ReadRegisters: all but the ID and baud rate, which address a
+// servo on the bus. Their values are 0.
+func EEPROMSettings(motors []MotorName) RegisterValues {
+	values := make(RegisterValues, len(motors))
+	for _, name := range motors {
+		values[name] = make(map[string]int)
+		for _, reg := range STS3215Registers {
+			if reg.EEPROM && reg.Name != "id" && reg.Name != "baud_rate" {
+				values[name][reg.Name] = 0
+			}
+		}
+	}
+	return values
+}
+
+// registersOf returns the registers named in values, ordered by address.
+func registersOf(values map[string]int) ([]Register, error) {
+	regs := make([]Register, 0, len(values))
+	for name := range values {
+		reg, ok := STS3215Register(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown register %s", name)
+		}
+		regs = append(regs, reg)
+	}
+	slices.SortFunc(regs, func(a, b Register) int { return cmp.Compare(a.Address, b.Address) })
+	return regs, nil
+}
+
+// ReadRegisters reads the registers named in want of each of its motors,
+// e.g. to compare them with RecommendedSettings. The values in want are
+// ignored.
+func (a *Arm) ReadRegisters(ctx context.Context, want RegisterValues) (RegisterValues, error) {
+	got := make(RegisterValues, len(want))
+	for _, name := range a.calibration.Motors() {
+		if _, ok := want[name]; !ok {
+			continue
+		}
+		regs, err := registersOf(want[name])
+		if err != nil {
+			return nil, err
+		}
+		got[name] = make(map[string]int, len(regs))
+		for _, reg := range regs {
+			v, err := a.ReadRegister(ctx, name, reg)
+			if err != nil {
+				return nil, err
+			}
+			got[name][reg.Name] = v
+		}
+	}
+	return got, nil
+}
+
+// WriteRegisters disables torque and writes values, motor by motor in
+// order of address. Torque is left off.
+func (a *Arm) WriteRegisters(ctx context.Context, values RegisterValues) error {
+	if err := a.Disable(ctx); err != nil {
+		return fmt.Errorf("disable torque: %w", err)
+	}
+	for _, name := range a.calibration.Motors() {
+		regs, err := registersOf(values[name])
+		if err != nil {
+			return err
+		}
+		for _, reg := range regs {
+			if err := a.WriteRegister(ctx, name, reg, values[name][reg.Name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Diff returns the values of want that differ from v, by motor and
+// register name.
+func (v RegisterValues) Diff(want RegisterValues) RegisterValues {
+	diff := make(RegisterValues)
+	for name, regs := range want {
+		for reg, value := range regs {
+			if cur, ok := v[name][reg]; ok && cur == value {
+				continue
+			}
+			if diff[name] == nil {
+				diff[name] = make(map[string]int)
+			}
+			diff[name][reg] = value
+		}
+	}
+	return diff
+}
@@ -0,0 +1,123 @@
+package robot
+
+import (
+	"context"
+	"sync"
+)
+
+// Leader and follower can be daisy-chained on one serial port with distinct
+// servo IDs (see ArmConfig.IDOffset). A port can only be opened once, so
+// arms get handles to a reference-counted connection per port instead.
+
+// serialBuses holds the open serial connections by port.
+var serialBuses = &busRegistry{buses: make(map[string]*sharedBus)}
+
+type busRegistry struct {
+	mu    sync.Mutex
+	buses map[string]*sharedBus
+}
+
+// sharedBus is one connection used by several arms. Transactions are
+// serialized so arms on different goroutines don't interleave packets.
+type sharedBus struct {
+	registry *busRegistry
+	port     string
+	open     func() (Bus, error)
+
+	mu   sync.Mutex
+	bus  Bus
+	refs int // guarded by registry.mu
+}
+
+// acquire returns a handle to the connection on port, opening it with open
+// if no other arm holds it.
+func (r *busRegistry) acquire(port string, open func() (Bus, error)) (*busHandle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	port = NormalizePort(port)
+	s, ok := r.buses[port]
+	if !ok {
+		bus, err := open()
+		if err != nil {
+			return nil, err
+		}
+		s = &sharedBus{registry: r, port: port, open: open, bus: bus}
+		r.buses[port] = s
+	}
+	s.refs++
+	return &busHandle{shared: s}, nil
+}
+
+// release drops a reference and closes the connection when it was the last.
+func (r *busRegistry) release(s *sharedBus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s.refs--
+	if s.refs > 0 {
+		return nil
+	}
+	delete(r.buses, s.port)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bus.Close()
+}
+
+// reopen replaces the connection for all arms sharing it.
+func (s *sharedBus) reopen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bus.Close()
+	bus, err := s.open()
+	if err != nil {
+		// Keep the closed bus so calls fail until the next attempt
+		return err
+	}
+	s.bus = bus
+	return nil
+}
+
+// busHandle is one arm's reference to a sharedBus.
+type busHandle struct {
+	shared *sharedBus
+	once   sync.Once
+}
+
+func (h *busHandle) Ping(ctx context.Context, id int) (int, error) {
+	h.shared.mu.Lock()
+	defer h.shared.mu.Unlock()
+	return h.shared.bus.Ping(ctx, id)
+}
+
+func (h *busHandle) ReadRegister(ctx context.Context, id int, address byte, length int) ([]byte, error) {
+	h.shared.mu.Lock()
+	defer h.shared.mu.Unlock()
+	return h.shared.bus.ReadRegister(ctx, id, address, length)
+}
+
+func (h *busHandle) WriteRegister(ctx context.Context, id int, address byte, data []byte) error {
+	h.shared.mu.Lock()
+	defer h.shared.mu.Unlock()
+	return h.shared.bus.WriteRegister(ctx, id, address, data)
+}
+
+func (h *busHandle) SyncRead(ctx context.Context, address byte, dataLen int, ids []int) (map[int][]byte, error) {
+	h.shared.mu.Lock()
+	defer h.shared.mu.Unlock()
+	return h.shared.bus.SyncRead(ctx, address, dataLen, ids)
+}
+
+func (h *busHandle) SyncWrite(ctx context.Context, address byte, dataLen int, servoData map[int][]byte) error {
+	h.shared.mu.Lock()
+	defer h.shared.mu.Unlock()
+	return h.shared.bus.SyncWrite(ctx, address, dataLen, servoData)
+}
+
+// Close releases the handle. The connection is closed once no arm uses it.
+// Closing a handle twice is a no-op.
+func (h *busHandle) Close() error {
+	var err error
+	h.once.Do(func() { err = h.shared.registry.release(h.shared) })
+	return err
+}
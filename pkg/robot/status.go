@@ -0,0 +1,75 @@
+package robot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+// ServoStatus holds diagnostic readings from a single servo.
+type ServoStatus struct {
+	Motor         MotorName
+	ID            int
+	Model         string
+	Position      int
+	Temperature   int     // degrees Celsius
+	Voltage       float64 // volts
+	Load          int     // 0.1% of max torque, signed
+	TorqueEnabled bool
+	Faults        ServoFault // hardware errors the servo reports
+	Err           error      // first error encountered while reading this servo
+}
+
+// ReadStatus reads diagnostic information from every servo in the arm.
+// Errors are reported per servo in ServoStatus.Err rather than aborting.
+func (a *Arm) ReadStatus(ctx context.Context) []ServoStatus {
+	statuses := make([]ServoStatus, 0, len(a.calibration))
+	for _, name := range a.calibration.Motors() {
+		cal := a.calibration[name]
+		statuses = append(statuses, readServoStatus(ctx, a.bus, name, cal.ID+a.idOffset))
+	}
+	return statuses
+}
+
+func readServoStatus(ctx context.Context, bus Bus, name MotorName, id int) ServoStatus {
+	st := ServoStatus{Motor: name, ID: id}
+
+	modelNum, err := bus.Ping(ctx, id)
+	if err != nil {
+		st.Err = fmt.Errorf("ping: %w", err)
+		return st
+	}
+	if model, ok := feetech.GetModelByNumber(modelNum); ok {
+		st.Model = model.Name
+	} else {
+		st.Model = fmt.Sprintf("unknown (%d)", modelNum)
+	}
+
+	// Keep reading after a failure so the dashboard shows as much as possible
+	record := func(op string, err error) {
+		if err != nil && st.Err == nil {
+			st.Err = fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	position, err := readWord(ctx, bus, id, feetech.RegPresentPosition)
+	record("position", err)
+	st.Position = decodeSignMagnitude(position, positionSignBit)
+	st.Temperature, err = readByte(ctx, bus, id, feetech.RegPresentTemp)
+	record("temperature", err)
+	voltage, err := readByte(ctx, bus, id, feetech.RegPresentVoltage)
+	record("voltage", err)
+	st.Voltage = float64(voltage) / 10
+	load, err := readWord(ctx, bus, id, feetech.RegPresentLoad)
+	record("load", err)
+	st.Load = decodeSignMagnitude(load, feetech.RegPresentLoad.SignBit)
+	torque, err := readByte(ctx, bus, id, feetech.RegTorqueEnable)
+	record("torque", err)
+	st.TorqueEnabled = torque != 0
+	faults, err := readByte(ctx, bus, id, feetech.RegServoStatus)
+	record("faults", err)
+	st.Faults = ServoFault(faults)
+
+	return st
+}
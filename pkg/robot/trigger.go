@@ -0,0 +1,50 @@
+package robot
+
+import "cmp"
+
+// Trigger uses a leader joint, by default the gripper, as a button while it
+// keeps driving the follower: moving it past Press presses the trigger, and
+// moving it back past Release releases it. The gap between the two keeps a
+// joint resting near a threshold from toggling it on noise. Press may lie
+// on either side of Release, e.g. -80 and -60 to press by squeezing a
+// gripper that closes towards -100.
+type Trigger struct {
+	Motor   MotorName `json:"motor,omitempty"` // empty means the gripper
+	Press   float64   `json:"press"`
+	Release float64   `json:"release"`
+
+	// Action is what pressing the trigger does in 'lerobot teleoperate'
+	// and 'lerobot record': "record" ends the episode being recorded or
+	// starts the next, like the right arrow, "pause" pauses or resumes the
+	// follower, and "tool" runs Command to switch a tool on or off. Empty
+	// only reports it.
+	Action string `json:"action,omitempty"`
+
+	// Command is run by the tool action on every press, with LEROBOT_TOOL
+	// set to "on" and "off" in turn, e.g. to switch a suction pump.
+	Command []string `json:"command,omitempty"`
+}
+
+// Trigger actions, see Trigger.Action.
+const (
+	TriggerRecord = "record"
+	TriggerPause  = "pause"
+	TriggerTool   = "tool"
+)
+
+// Joint returns the joint used as the trigger.
+func (t Trigger) Joint() MotorName {
+	return cmp.Or(t.Motor, Gripper)
+}
+
+// Pressed returns whether the trigger is pressed with its joint at pos,
+// given whether it was pressed before.
+func (t Trigger) Pressed(pos float64, pressed bool) bool {
+	if t.Press < t.Release {
+		pos, t.Press, t.Release = -pos, -t.Press, -t.Release
+	}
+	if pressed {
+		return pos > t.Release
+	}
+	return pos >= t.Press
+}
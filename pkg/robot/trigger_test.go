@@ -0,0 +1,33 @@
+package robot
+
+import "testing"
+
+func TestTrigger_Pressed(t *testing.T) {
+	for _, trig := range []Trigger{
+		{Press: -80, Release: -60},
+		{Press: 80, Release: 60},
+	} {
+		sign := 1.0
+		if trig.Press < 0 {
+			sign = -1
+		}
+		pressed := false
+		for _, step := range []struct {
+			pos  float64
+			want bool
+		}{
+			{0, false},
+			{79, false},
+			{80, true},
+			{70, true}, // between the thresholds: still pressed
+			{60, false},
+			{70, false}, // and still released
+			{95, true},
+		} {
+			pressed = trig.Pressed(sign*step.pos, pressed)
+			if pressed != step.want {
+				t.Errorf("%+v at %v: pressed = %v, want %v", trig, sign*step.pos, pressed, step.want)
+			}
+		}
+	}
+}
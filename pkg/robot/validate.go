@@ -0,0 +1,244 @@
+package robot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+const (
+	maxServoID  = 253  // 254 is the broadcast ID
+	maxRawRange = 4095 // 12-bit position encoders
+)
+
+// FieldError is a problem with one field of the configuration, e.g.
+// "follower.calibration.elbow_flex.range_min".
+type FieldError struct {
+	Field   string
+	Problem string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Problem
+}
+
+// Validate checks the configuration for values that would otherwise fail
+// later in confusing ways: reversed or out-of-range calibrations, duplicate
+// servo IDs, missing or unknown motors and out-of-range poses. It returns
+// all problems found, joined, as *FieldError values.
+func (c *Config) Validate() error {
+	v := &validator{}
+	motors := c.MotorList()
+	known := make(map[MotorName]bool, len(motors))
+
+	ids := make(map[int]MotorName)
+	for i, m := range c.Motors {
+		field := fmt.Sprintf("motors[%d]", i)
+		switch {
+		case m.Name == "":
+			v.add(field+".name", "is empty")
+		case known[m.Name]:
+			v.add(field+".name", fmt.Sprintf("%s is listed twice", m.Name))
+		}
+		if other, dup := ids[m.ID]; dup {
+			v.add(field+".id", fmt.Sprintf("%d is also used by %s", m.ID, other))
+		}
+		v.checkID(field+".id", m.ID)
+		known[m.Name] = true
+		ids[m.ID] = m.Name
+	}
+	for _, m := range motors {
+		known[m.Name] = true
+	}
+
+	v.checkArm("leader", c.Leader, motors, known)
+	v.checkArm("follower", c.Follower, motors, known)
+	if c.Leader.Port != "" && SamePort(c.Leader.Port, c.Follower.Port) && c.Leader.BaudRate() != c.Follower.BaudRate() {
+		v.add("follower.baud", fmt.Sprintf("%d differs from the leader's %d on the shared port", c.Follower.BaudRate(), c.Leader.BaudRate()))
+	}
+
+	for _, name := range sortedNames(c.Deadband) {
+		v.checkMotor("deadband."+string(name), name, known)
+		if c.Deadband[name] < 0 {
+			v.add("deadband."+string(name), "must not be negative")
+		}
+	}
+	for _, name := range sortedNames(c.Mapping) {
+		v.checkMotor("mapping."+string(name), name, known)
+	}
+	if c.Teleop.Hz < 0 {
+		v.add("teleop.hz", "must not be negative")
+	}
+	if c.Teleop.Smoothing < 0 {
+		v.add("teleop.smoothing", "must not be negative")
+	}
+	if t := c.Teleop.Trigger; t != nil {
+		v.checkMotor("teleop.trigger.motor", t.Joint(), known)
+		if t.Press == t.Release {
+			v.add("teleop.trigger.press", fmt.Sprintf("%g must differ from release", t.Press))
+		}
+		switch t.Action {
+		case "", TriggerRecord, TriggerPause:
+		case TriggerTool:
+			if len(t.Command) == 0 {
+				v.add("teleop.trigger.command", "is needed by the tool action")
+			}
+		default:
+			v.add("teleop.trigger.action", fmt.Sprintf("%q is not record, pause or tool", t.Action))
+		}
+	}
+	v.checkPose("rest_pose", c.RestPose, known)
+	for _, pose := range slices.Sorted(maps.Keys(c.Poses)) {
+		v.checkPose("poses."+pose, c.Poses[pose], known)
+	}
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(field, problem string) {
+	v.errs = append(v.errs, &FieldError{Field: field, Problem: problem})
+}
+
+func (v *validator) checkID(field string, id int) {
+	if id < 0 || id > maxServoID {
+		v.add(field, fmt.Sprintf("servo ID %d outside 0-%d", id, maxServoID))
+	}
+}
+
+func (v *validator) checkMotor(field string, name MotorName, known map[MotorName]bool) {
+	if !known[name] {
+		v.add(field, fmt.Sprintf("unknown motor %s", name))
+	}
+}
+
+func (v *validator) checkPose(field string, pose map[MotorName]float64, known map[MotorName]bool) {
+	for _, name := range sortedNames(pose) {
+		f := field + "." + string(name)
+		v.checkMotor(f, name, known)
+		if pos := pose[name]; pos < -100 || pos > 100 {
+			v.add(f, fmt.Sprintf("%g outside -100 to 100", pos))
+		}
+	}
+}
+
+func (v *validator) checkArm(arm string, a ArmConfig, motors []Motor, known map[MotorName]bool) {
+	if a.Acceleration < 0 || a.Acceleration > 254 {
+		v.add(arm+".acceleration", fmt.Sprintf("%d outside 0-254", a.Acceleration))
+	}
+	if a.MaxSpeed < 0 {
+		v.add(arm+".max_speed", "must not be negative")
+	}
+	for _, name := range sortedNames(a.TorqueLimit) {
+		f := arm + ".torque_limit." + string(name)
+		v.checkMotor(f, name, known)
+		if l := a.TorqueLimit[name]; l < 1 || l > 1000 {
+			v.add(f, fmt.Sprintf("%d outside 1-1000", l))
+		}
+	}
+	if a.Retries < -1 {
+		v.add(arm+".retries", fmt.Sprintf("%d below -1", a.Retries))
+	}
+	if a.IDOffset < 0 {
+		v.add(arm+".id_offset", "must not be negative")
+	}
+	if a.Baud != 0 && !slices.Contains(feetech.DefaultBaudRates, a.Baud) {
+		v.add(arm+".baud", fmt.Sprintf("%d is not a servo baud rate", a.Baud))
+	}
+	if _, ok := feetech.GetModel(a.ServoModel); a.ServoModel != "" && !ok {
+		v.add(arm+".servo_model", fmt.Sprintf("unknown model %q", a.ServoModel))
+	}
+	v.checkAngles(arm, a, known)
+	if !a.IsCalibrated() {
+		return // not set up yet
+	}
+
+	for _, m := range motors {
+		if _, ok := a.Calibration[m.Name]; !ok {
+			v.add(arm+".calibration", fmt.Sprintf("%s is missing, run 'lerobot setup'", m.Name))
+		}
+	}
+	ids := make(map[int]MotorName)
+	for _, name := range a.Calibration.Motors() {
+		mc := a.Calibration[name]
+		field := arm + ".calibration." + string(name)
+		v.checkMotor(field, name, known)
+		v.checkID(field+".id", mc.ID+a.IDOffset)
+		if other, dup := ids[mc.ID]; dup {
+			v.add(field+".id", fmt.Sprintf("%d is also used by %s", mc.ID, other))
+		}
+		ids[mc.ID] = name
+
+		lo, hi := 0, maxRawRange
+		if mc.MultiTurn {
+			lo, hi = -maxMultiTurnRaw, maxMultiTurnRaw
+		}
+		if mc.RangeMin < lo || mc.RangeMin > hi {
+			v.add(field+".range_min", fmt.Sprintf("%d outside %d-%d", mc.RangeMin, lo, hi))
+		}
+		if mc.RangeMax < lo || mc.RangeMax > hi {
+			v.add(field+".range_max", fmt.Sprintf("%d outside %d-%d", mc.RangeMax, lo, hi))
+		}
+		if mc.RangeMin >= mc.RangeMax {
+			v.add(field+".range_min", fmt.Sprintf("%d must be below range_max %d", mc.RangeMin, mc.RangeMax))
+		}
+	}
+}
+
+// checkAngles validates the units and angle profile of an arm.
+func (v *validator) checkAngles(arm string, a ArmConfig, known map[MotorName]bool) {
+	switch a.Units {
+	case "", UnitsNormalized, UnitsDegrees, UnitsRadians:
+	default:
+		v.add(arm+".units", fmt.Sprintf("%q is not normalized, degrees or radians", a.Units))
+	}
+	for _, name := range sortedNames(a.Angles) {
+		field := arm + ".angles." + string(name)
+		v.checkMotor(field, name, known)
+		j := a.Angles[name]
+		if j.Center < 0 || j.Center > maxRawRange {
+			v.add(field+".center", fmt.Sprintf("%d outside 0-%d", j.Center, maxRawRange))
+		}
+		if j.DegreesPerCount < 0 {
+			v.add(field+".degrees_per_count", "must not be negative")
+		}
+	}
+}
+
+func sortedNames[V any](m map[MotorName]V) []MotorName {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// syntaxError adds the line and column to JSON syntax and type errors,
+// which otherwise only carry a byte offset.
+func syntaxError(data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+		if typeErr.Field != "" {
+			err = fmt.Errorf("%s: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+	default:
+		return err
+	}
+	line, col := 1, 1
+	for _, b := range data[:min(offset, int64(len(data)))] {
+		if b == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return fmt.Errorf("line %d, column %d: %w", line, col, err)
+}
@@ -0,0 +1,71 @@
+package robot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	cal := func() Calibration {
+		cal := make(Calibration)
+		for _, m := range SO101Motors() {
+			cal[m.Name] = MotorCalibration{ID: m.ID, RangeMin: 1000, RangeMax: 3000}
+		}
+		return cal
+	}
+	valid := Config{Leader: ArmConfig{Calibration: cal()}, Follower: ArmConfig{Calibration: cal()}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	cfg := valid
+	cfg.Follower.Calibration = cal()
+	cfg.Follower.Calibration[ElbowFlex] = MotorCalibration{ID: 3, RangeMin: 3000, RangeMax: 1000}
+	cfg.Follower.Calibration[WristFlex] = MotorCalibration{ID: 3, RangeMin: 1000, RangeMax: 5000}
+	delete(cfg.Follower.Calibration, Gripper)
+	cfg.RestPose = map[MotorName]float64{"elbow": 0, ShoulderPan: 150}
+	cfg.Leader.Units = "turns"
+	cfg.Leader.Baud = 12345
+	cfg.Teleop.Trigger = &Trigger{Press: -80, Release: -80, Action: "grab"}
+
+	err := cfg.Validate()
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("err = %v, want FieldErrors", err)
+	}
+	for _, want := range []string{
+		"follower.calibration: gripper is missing",
+		"follower.calibration.elbow_flex.range_min: 3000 must be below range_max 1000",
+		"follower.calibration.wrist_flex.id: 3 is also used by elbow_flex",
+		"follower.calibration.wrist_flex.range_max: 5000 outside 0-4095",
+		"leader.baud: 12345 is not a servo baud rate",
+		`leader.units: "turns" is not normalized, degrees or radians`,
+		"rest_pose.elbow: unknown motor elbow",
+		"rest_pose.shoulder_pan: 150 outside -100 to 100",
+		"teleop.trigger.press: -80 must differ from release",
+		`teleop.trigger.action: "grab" is not record, pause or tool`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+
+	cfg = valid
+	cfg.Teleop.Trigger = &Trigger{Press: -80, Release: -60, Action: TriggerTool}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "teleop.trigger.command: is needed by the tool action") {
+		t.Errorf("tool trigger without a command: %v", err)
+	}
+}
+
+func TestLoadConfigFrom_SyntaxError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lerobot.json")
+	os.WriteFile(path, []byte("{\n  \"leader\": {\n    \"port\": 5\n  }\n}"), 0644)
+
+	_, err := LoadConfigFrom(path)
+	if err == nil || !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), "leader.port") {
+		t.Errorf("err = %v, want line 3 and leader.port", err)
+	}
+}
@@ -0,0 +1,155 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+// ErrNotVelocityMode is returned by WriteVelocities unless SetVelocityMode
+// was called first.
+var ErrNotVelocityMode = errors.New("arm is not in velocity mode")
+
+// ReadVelocities reads the present velocity of all motors, in normalized
+// units per second (see ReadPositions).
+func (a *Arm) ReadVelocities(ctx context.Context) (map[MotorName]float64, error) {
+	data, err := a.syncRead(ctx, feetech.RegPresentVelocity.Address, 2)
+	if err != nil {
+		return nil, fmt.Errorf("read velocities: %w", err)
+	}
+
+	velocities := make(map[MotorName]float64, len(data))
+	for id, d := range data {
+		name, cal, ok := a.calibration.ByID(id - a.idOffset)
+		if !ok {
+			continue
+		}
+		steps := decodeSignMagnitude(decodeWord(d), feetech.RegPresentVelocity.SignBit)
+		velocities[name] = stepsToVelocity(cal, steps)
+	}
+	return velocities, nil
+}
+
+// ArmState is what ReadState reads back from an arm in one transaction.
+type ArmState struct {
+	Positions  map[MotorName]float64 // normalized, see ReadPositions
+	Velocities map[MotorName]float64 // normalized units per second
+	Loads      map[MotorName]int     // 0.1% of max torque, see ReadLoad
+}
+
+// stateLen covers the adjacent present position, velocity and load
+// registers.
+const stateLen = 6
+
+// ReadState reads the present position, velocity and load of all motors
+// with a single sync read, which takes little longer than reading the
+// positions alone and much less than three separate reads.
+func (a *Arm) ReadState(ctx context.Context) (ArmState, error) {
+	data, err := a.syncRead(ctx, feetech.RegPresentPosition.Address, stateLen)
+	if err != nil {
+		return ArmState{}, fmt.Errorf("read state: %w", err)
+	}
+
+	st := ArmState{
+		Positions:  make(map[MotorName]float64, len(data)),
+		Velocities: make(map[MotorName]float64, len(data)),
+		Loads:      make(map[MotorName]int, len(data)),
+	}
+	velocityAt := feetech.RegPresentVelocity.Address - feetech.RegPresentPosition.Address
+	loadAt := feetech.RegPresentLoad.Address - feetech.RegPresentPosition.Address
+	for id, d := range data {
+		name, cal, ok := a.calibration.ByID(id - a.idOffset)
+		if !ok || len(d) < stateLen {
+			continue
+		}
+		st.Positions[name] = cal.Normalize(a.decodePosition(cal.ID, d))
+		st.Velocities[name] = stepsToVelocity(cal, decodeSignMagnitude(decodeWord(d[velocityAt:]), feetech.RegPresentVelocity.SignBit))
+		st.Loads[name] = decodeSignMagnitude(decodeWord(d[loadAt:]), feetech.RegPresentLoad.SignBit)
+	}
+	return st, nil
+}
+
+// SetVelocityMode switches all servos between position control and
+// velocity (wheel) mode. Torque is disabled first and left off; call Enable
+// afterwards.
+//
+// In velocity mode the servos turn continuously and ignore the calibrated
+// range, so the caller must watch the positions and stop the joints itself.
+func (a *Arm) SetVelocityMode(ctx context.Context, on bool) error {
+	if err := a.Disable(ctx); err != nil {
+		return fmt.Errorf("disable torque: %w", err)
+	}
+	mode := feetech.ModePosition
+	if on {
+		mode = feetech.ModeVelocity
+	}
+	for _, id := range a.ids {
+		if err := a.bus.WriteRegister(ctx, id, feetech.RegOperatingMode.Address, []byte{byte(mode)}); err != nil {
+			return fmt.Errorf("set operating mode on servo %d: %w", id, err)
+		}
+	}
+	a.velocityMode = on
+	if on {
+		return nil
+	}
+
+	// Goal velocity doubles as the speed limit in position mode
+	limit := encodeWord(a.maxSpeed)
+	for _, id := range a.ids {
+		if err := a.bus.WriteRegister(ctx, id, feetech.RegGoalVelocity.Address, limit); err != nil {
+			return fmt.Errorf("restore max speed on servo %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// WriteVelocities writes target velocities in normalized units per second.
+// The arm must be in velocity mode (see SetVelocityMode). Velocities are
+// limited to ArmConfig.MaxSpeed if set.
+func (a *Arm) WriteVelocities(ctx context.Context, velocities map[MotorName]float64) error {
+	if !a.velocityMode {
+		return ErrNotVelocityMode
+	}
+	servoData := make(map[int][]byte, len(velocities))
+	for name, v := range velocities {
+		cal, ok := a.calibration[name]
+		if !ok {
+			continue
+		}
+		steps := velocityToSteps(cal, v)
+		if a.maxSpeed > 0 {
+			steps = max(-a.maxSpeed, min(a.maxSpeed, steps))
+		}
+		servoData[cal.ID+a.idOffset] = encodeWord(encodeSignMagnitude(steps, feetech.RegGoalVelocity.SignBit))
+	}
+	if len(servoData) == 0 {
+		return nil
+	}
+	if err := a.bus.SyncWrite(ctx, feetech.RegGoalVelocity.Address, 2, servoData); err != nil {
+		return fmt.Errorf("write velocities: %w", err)
+	}
+	return nil
+}
+
+// stepsToVelocity converts steps/s to normalized units per second.
+func stepsToVelocity(cal MotorCalibration, steps int) float64 {
+	rangeSize := float64(cal.RangeMax - cal.RangeMin)
+	if rangeSize == 0 {
+		return 0
+	}
+	v := float64(steps) * 200 / rangeSize
+	if cal.Invert {
+		v = -v
+	}
+	return v
+}
+
+// velocityToSteps converts normalized units per second to steps/s.
+func velocityToSteps(cal MotorCalibration, v float64) int {
+	if cal.Invert {
+		v = -v
+	}
+	return int(v * float64(cal.RangeMax-cal.RangeMin) / 200)
+}
@@ -0,0 +1,60 @@
+package robot
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hipsterbrown/feetech-servo/feetech"
+)
+
+// ErrServoMismatch is returned when an arm is opened whose servos are not
+// all of its ArmConfig.ServoModel, or don't all run the same firmware.
+var ErrServoMismatch = errors.New("servo mismatch")
+
+// identityLen is the length of the firmware version and model number
+// registers, read in one go from address 0.
+const identityLen = 5
+
+// VerifyServos reads the model number and firmware version of every servo
+// in one transaction and checks that they are all of the arm's servo model
+// and run the same firmware, so a wrong or mixed set of servos is caught
+// before it is driven. Opening an arm does this unless
+// ArmConfig.SkipVerify is set.
+func (a *Arm) VerifyServos(ctx context.Context) error {
+	data, err := a.bus.SyncRead(ctx, 0, identityLen, a.ids)
+	if err != nil {
+		return fmt.Errorf("read servo models: %w", err)
+	}
+
+	want := cmp.Or(a.servoModel, feetech.ModelSTS3215.Name)
+	var problems []string
+	var firmware []string // "motor version" of every servo, reported if they differ
+	versions := make(map[string]bool)
+	for _, name := range a.calibration.Motors() {
+		id := a.calibration[name].ID + a.idOffset
+		d := data[id]
+		if len(d) < identityLen {
+			problems = append(problems, fmt.Sprintf("%s (ID %d) did not answer", name, id))
+			continue
+		}
+		number := decodeWord(d[feetech.RegModelNumber.Address:])
+		if model, ok := feetech.GetModelByNumber(number); !ok {
+			problems = append(problems, fmt.Sprintf("%s (ID %d) is an unknown model %d, want %s", name, id, number, want))
+		} else if model.Name != want {
+			problems = append(problems, fmt.Sprintf("%s (ID %d) is a %s, want %s", name, id, model.Name, want))
+		}
+		version := fmt.Sprintf("%d.%d", d[0], d[1])
+		versions[version] = true
+		firmware = append(firmware, fmt.Sprintf("%s %s", name, version))
+	}
+	if len(versions) > 1 {
+		problems = append(problems, "mixed firmware versions: "+strings.Join(firmware, ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w on %s: %s", ErrServoMismatch, a.port, strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package robot
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// ErrOutsideWorkspace is returned when the arm would leave its workspace.
+var ErrOutsideWorkspace = errors.New("outside workspace")
+
+// Box is an axis-aligned box in the base frame.
+type Box struct {
+	Min Vec3 `json:"min"`
+	Max Vec3 `json:"max"`
+}
+
+// Contains reports whether p is inside the box or on its surface.
+func (b Box) Contains(p Vec3) bool {
+	for i := range p {
+		if p[i] < b.Min[i] || p[i] > b.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Workspace is where the follower may move, to protect cameras and
+// fixtures around it. The elbow, wrist and gripper tip must stay inside
+// Bounds, if set, and out of every Exclude box, such as the table below
+// z = 0.
+type Workspace struct {
+	Bounds  *Box  `json:"bounds,omitempty"`
+	Exclude []Box `json:"exclude,omitempty"`
+}
+
+// IsZero reports whether the workspace is unconstrained.
+func (w Workspace) IsZero() bool { return w.Bounds == nil && len(w.Exclude) == 0 }
+
+// Check returns an error wrapping ErrOutsideWorkspace naming the first
+// point of the pose that is out of the workspace, or nil.
+func (w Workspace) Check(p ArmPose) error {
+	for _, pt := range p.Points() {
+		if w.Bounds != nil && !w.Bounds.Contains(pt.At) {
+			return fmt.Errorf("%w: %s at %s leaves the bounds", ErrOutsideWorkspace, pt.Name, pt.At)
+		}
+		for i, b := range w.Exclude {
+			if b.Contains(pt.At) {
+				return fmt.Errorf("%w: %s at %s enters exclusion zone %d", ErrOutsideWorkspace, pt.Name, pt.At, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+func (v Vec3) String() string {
+	return fmt.Sprintf("(%.3f, %.3f, %.3f)", v[0], v[1], v[2])
+}
+
+// guardSteps is the number of bisection steps to find how far toward a
+// target the arm can move. The remaining error is 1/2^steps of the move.
+const guardSteps = 10
+
+// MotionGuard keeps the follower's targets in safe poses: inside a
+// workspace and, if the arm's CollisionCheck is set, free of
+// self-collision. It remembers the last safe target, and limits a move
+// that would leave safety to the part of it that stays safe, interpolating
+// in joint space. While the arm is in an unsafe pose, e.g. because it
+// started there, any target is allowed so it can be moved out.
+type MotionGuard struct {
+	arm       ArmConfig
+	model     Kinematics
+	workspace Workspace
+	last      map[MotorName]float64 // last safe target
+}
+
+// NewMotionGuard returns a guard for the arm described by arm, or nil if
+// it has nothing to check.
+func NewMotionGuard(arm ArmConfig, model Kinematics, workspace Workspace) *MotionGuard {
+	if workspace.IsZero() && !arm.CollisionCheck {
+		return nil
+	}
+	return &MotionGuard{arm: arm, model: model, workspace: workspace}
+}
+
+// chainJoints are the joints that place the links, see Kinematics.Forward.
+var chainJoints = []MotorName{ShoulderPan, ShoulderLift, ElbowFlex, WristFlex}
+
+// Check checks normalized positions against the workspace and for
+// self-collision. A joint missing from positions, e.g. because its servo
+// stopped responding, is taken at its last safe target; without one the
+// pose is unknown and passes.
+func (g *MotionGuard) Check(positions map[MotorName]float64) error {
+	positions, ok := g.complete(positions)
+	if !ok {
+		return nil
+	}
+	pose := g.model.Forward(g.arm.Degrees(positions))
+	if g.arm.CollisionCheck {
+		if err := g.model.SelfCollision(pose); err != nil {
+			return err
+		}
+	}
+	return g.workspace.Check(pose)
+}
+
+// complete returns positions with the chain joints it lacks filled in from
+// the last safe target, and false if that lacks them too.
+func (g *MotionGuard) complete(positions map[MotorName]float64) (map[MotorName]float64, bool) {
+	var filled map[MotorName]float64
+	for _, name := range chainJoints {
+		if _, ok := positions[name]; ok {
+			continue
+		}
+		last, ok := g.last[name]
+		if !ok {
+			return nil, false
+		}
+		if filled == nil {
+			filled = make(map[MotorName]float64, len(positions)+1)
+			maps.Copy(filled, positions)
+		}
+		filled[name] = last
+	}
+	if filled == nil {
+		return positions, true
+	}
+	return filled, true
+}
+
+// remember makes targets, with the joints it lacks as before, the last
+// safe target.
+func (g *MotionGuard) remember(targets map[MotorName]float64) {
+	last := maps.Clone(g.last)
+	if last == nil {
+		last = make(map[MotorName]float64, len(targets))
+	}
+	maps.Copy(last, targets)
+	g.last = last
+}
+
+// Limit returns targets, or as far toward them from the last safe target
+// as the arm can move safely. The error reports why targets were limited.
+func (g *MotionGuard) Limit(targets map[MotorName]float64) (map[MotorName]float64, error) {
+	err := g.Check(targets)
+	if err == nil || g.last == nil {
+		if err == nil {
+			g.remember(targets)
+		}
+		return targets, nil
+	}
+
+	// Bisect for the furthest safe fraction of the move
+	lo, hi := 0.0, 1.0
+	for range guardSteps {
+		mid := (lo + hi) / 2
+		if g.Check(interpolatePose(g.last, targets, mid)) == nil {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	limited := interpolatePose(g.last, targets, lo)
+	g.remember(limited)
+	return limited, err
+}
@@ -0,0 +1,295 @@
+// Package ros2 bridges an arm to ROS 2 through rosbridge.
+//
+// The bridge talks to a rosbridge_server websocket instead of joining the DDS
+// network directly, so it needs no ROS installation on the machine running
+// lerobot. It publishes sensor_msgs/JointState and accepts position commands
+// as sensor_msgs/JointState or trajectory_msgs/JointTrajectory. Joint
+// positions are in radians, with 0 at the servo center (raw position 2048).
+package ros2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// Topic names, relative to the bridge namespace.
+const (
+	JointStatesTopic = "joint_states"
+	CommandTopic     = "joint_commands"
+	TrajectoryTopic  = "joint_trajectory"
+)
+
+const (
+	jointStateType      = "sensor_msgs/msg/JointState"
+	jointTrajectoryType = "trajectory_msgs/msg/JointTrajectory"
+
+	stepsPerRev  = 4096
+	centerOffset = 2048
+)
+
+// Time is builtin_interfaces/Time and builtin_interfaces/Duration.
+type Time struct {
+	Sec     int32  `json:"sec"`
+	Nanosec uint32 `json:"nanosec"`
+}
+
+// Header is std_msgs/Header.
+type Header struct {
+	Stamp   Time   `json:"stamp"`
+	FrameID string `json:"frame_id"`
+}
+
+// JointState is sensor_msgs/JointState.
+type JointState struct {
+	Header   Header    `json:"header"`
+	Name     []string  `json:"name"`
+	Position []float64 `json:"position"`
+	Velocity []float64 `json:"velocity"`
+	Effort   []float64 `json:"effort"`
+}
+
+// JointTrajectoryPoint is trajectory_msgs/JointTrajectoryPoint.
+type JointTrajectoryPoint struct {
+	Positions     []float64 `json:"positions"`
+	TimeFromStart Time      `json:"time_from_start"`
+}
+
+// JointTrajectory is trajectory_msgs/JointTrajectory.
+type JointTrajectory struct {
+	Header     Header                 `json:"header"`
+	JointNames []string               `json:"joint_names"`
+	Points     []JointTrajectoryPoint `json:"points"`
+}
+
+// Config configures a Bridge.
+type Config struct {
+	URL       string // rosbridge websocket, e.g. ws://localhost:9090
+	Namespace string // topic prefix, e.g. /so101
+	Hz        int    // joint state publish and trajectory rate
+}
+
+// Bridge connects an arm to ROS 2 topics.
+type Bridge struct {
+	arm *robot.Arm
+	cfg Config
+	rb  *rosbridge
+
+	mu               sync.Mutex
+	cancelTrajectory context.CancelFunc
+}
+
+// NewBridge connects to rosbridge and advertises and subscribes to the
+// bridge topics. The arm is commanded as-is; enable torque before Run to
+// let commands move it.
+func NewBridge(arm *robot.Arm, cfg Config) (*Bridge, error) {
+	if cfg.Hz <= 0 {
+		cfg.Hz = 30
+	}
+	rb, err := dialRosbridge(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bridge{arm: arm, cfg: cfg, rb: rb}
+
+	err = errors.Join(
+		rb.advertise(b.Topic(JointStatesTopic), jointStateType),
+		rb.subscribe(b.Topic(CommandTopic), jointStateType),
+		rb.subscribe(b.Topic(TrajectoryTopic), jointTrajectoryType),
+	)
+	if err != nil {
+		rb.close()
+		return nil, fmt.Errorf("set up topics: %w", err)
+	}
+	return b, nil
+}
+
+// Topic returns the full name of a bridge topic.
+func (b *Bridge) Topic(name string) string {
+	if b.cfg.Namespace == "" {
+		return "/" + name
+	}
+	return b.cfg.Namespace + "/" + name
+}
+
+// Run publishes joint states and executes incoming commands until ctx is
+// cancelled or the rosbridge connection fails.
+func (b *Bridge) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- b.receive(ctx) }()
+
+	ticker := time.NewTicker(time.Second / time.Duration(b.cfg.Hz))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			return err
+		case <-ticker.C:
+			if err := b.publishState(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close stops any running trajectory and disconnects from rosbridge.
+func (b *Bridge) Close() error {
+	b.mu.Lock()
+	if b.cancelTrajectory != nil {
+		b.cancelTrajectory()
+	}
+	b.mu.Unlock()
+	return b.rb.close()
+}
+
+func (b *Bridge) publishState(ctx context.Context) error {
+	raw, err := b.arm.ReadRawPositions(ctx)
+	if err != nil {
+		// A missed read is not fatal; the next tick retries
+		return nil
+	}
+
+	now := time.Now()
+	msg := JointState{
+		Header: Header{Stamp: Time{Sec: int32(now.Unix()), Nanosec: uint32(now.Nanosecond())}},
+	}
+	for _, name := range b.arm.Motors() {
+		if pos, ok := raw[name]; ok {
+			msg.Name = append(msg.Name, string(name))
+			msg.Position = append(msg.Position, toRadians(pos))
+		}
+	}
+	return b.rb.publish(b.Topic(JointStatesTopic), msg)
+}
+
+func (b *Bridge) receive(ctx context.Context) error {
+	for {
+		m, err := b.rb.receive()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("rosbridge: %w", err)
+		}
+		if m.Op != "publish" {
+			continue
+		}
+
+		switch m.Topic {
+		case b.Topic(CommandTopic):
+			var js JointState
+			if err := json.Unmarshal(m.Msg, &js); err != nil {
+				continue
+			}
+			b.stopTrajectory()
+			b.arm.WriteRawPositions(ctx, jointTargets(js.Name, js.Position))
+		case b.Topic(TrajectoryTopic):
+			var jt JointTrajectory
+			if err := json.Unmarshal(m.Msg, &jt); err != nil || len(jt.Points) == 0 {
+				continue
+			}
+			b.startTrajectory(ctx, jt)
+		}
+	}
+}
+
+func (b *Bridge) stopTrajectory() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cancelTrajectory != nil {
+		b.cancelTrajectory()
+		b.cancelTrajectory = nil
+	}
+}
+
+// startTrajectory replaces any running trajectory with jt.
+func (b *Bridge) startTrajectory(ctx context.Context, jt JointTrajectory) {
+	b.stopTrajectory()
+
+	start, err := b.arm.ReadRawPositions(ctx)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	b.mu.Lock()
+	b.cancelTrajectory = cancel
+	b.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		ticker := time.NewTicker(time.Second / time.Duration(b.cfg.Hz))
+		defer ticker.Stop()
+
+		begin := time.Now()
+		end := duration(jt.Points[len(jt.Points)-1].TimeFromStart)
+		for {
+			elapsed := time.Since(begin)
+			b.arm.WriteRawPositions(ctx, interpolate(jt, start, elapsed))
+			if elapsed >= end {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// interpolate returns the raw targets at time t along jt, moving linearly
+// from start to the first point and between subsequent points.
+func interpolate(jt JointTrajectory, start map[robot.MotorName]int, t time.Duration) map[robot.MotorName]int {
+	prevTime := time.Duration(0)
+	prev := start
+	for _, p := range jt.Points {
+		next := jointTargets(jt.JointNames, p.Positions)
+		at := duration(p.TimeFromStart)
+		if t < at {
+			frac := float64(t-prevTime) / float64(at-prevTime)
+			targets := make(map[robot.MotorName]int, len(next))
+			for name, to := range next {
+				from, ok := prev[name]
+				if !ok {
+					from = to
+				}
+				targets[name] = from + int(math.Round(frac*float64(to-from)))
+			}
+			return targets
+		}
+		prevTime, prev = at, next
+	}
+	return prev
+}
+
+// jointTargets converts joint names and radian positions to raw targets.
+func jointTargets(names []string, positions []float64) map[robot.MotorName]int {
+	targets := make(map[robot.MotorName]int, len(names))
+	for i, name := range names {
+		if i < len(positions) {
+			targets[robot.MotorName(name)] = fromRadians(positions[i])
+		}
+	}
+	return targets
+}
+
+func duration(t Time) time.Duration {
+	return time.Duration(t.Sec)*time.Second + time.Duration(t.Nanosec)
+}
+
+func toRadians(raw int) float64 {
+	return float64(raw-centerOffset) * 2 * math.Pi / stepsPerRev
+}
+
+func fromRadians(rad float64) int {
+	return int(math.Round(rad*stepsPerRev/(2*math.Pi))) + centerOffset
+}
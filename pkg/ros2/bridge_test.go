@@ -0,0 +1,47 @@
+package ros2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+func TestRadiansRoundTrip(t *testing.T) {
+	for _, raw := range []int{0, 1024, 2048, 3000, 4095} {
+		if got := fromRadians(toRadians(raw)); got != raw {
+			t.Errorf("fromRadians(toRadians(%d)) = %d", raw, got)
+		}
+	}
+	if got := toRadians(2048); got != 0 {
+		t.Errorf("toRadians(2048) = %v, want 0", got)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	jt := JointTrajectory{
+		JointNames: []string{"gripper"},
+		Points: []JointTrajectoryPoint{
+			{Positions: []float64{toRadians(3048)}, TimeFromStart: Time{Sec: 1}},
+			{Positions: []float64{toRadians(2048)}, TimeFromStart: Time{Sec: 2}},
+		},
+	}
+	start := map[robot.MotorName]int{robot.Gripper: 2048}
+
+	tests := []struct {
+		t    time.Duration
+		want int
+	}{
+		{0, 2048},
+		{500 * time.Millisecond, 2548},
+		{time.Second, 3048},
+		{1500 * time.Millisecond, 2548},
+		{3 * time.Second, 2048},
+	}
+	for _, tt := range tests {
+		got := interpolate(jt, start, tt.t)[robot.Gripper]
+		if got != tt.want {
+			t.Errorf("interpolate at %v = %d, want %d", tt.t, got, tt.want)
+		}
+	}
+}
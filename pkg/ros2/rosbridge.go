@@ -0,0 +1,65 @@
+package ros2
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// rosbridge is a minimal client for the rosbridge v2 JSON protocol
+// (https://github.com/RobotWebTools/rosbridge_suite).
+type rosbridge struct {
+	conn *websocket.Conn
+	mu   sync.Mutex // serializes writes
+}
+
+// message is a rosbridge protocol operation.
+type message struct {
+	Op    string          `json:"op"`
+	Topic string          `json:"topic,omitempty"`
+	Type  string          `json:"type,omitempty"`
+	Msg   json.RawMessage `json:"msg,omitempty"`
+}
+
+func dialRosbridge(url string) (*rosbridge, error) {
+	conn, err := websocket.Dial(url, "", "http://localhost/")
+	if err != nil {
+		return nil, fmt.Errorf("connect to rosbridge at %s: %w", url, err)
+	}
+	return &rosbridge{conn: conn}, nil
+}
+
+func (r *rosbridge) send(m message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return websocket.JSON.Send(r.conn, m)
+}
+
+func (r *rosbridge) advertise(topic, typ string) error {
+	return r.send(message{Op: "advertise", Topic: topic, Type: typ})
+}
+
+func (r *rosbridge) subscribe(topic, typ string) error {
+	return r.send(message{Op: "subscribe", Topic: topic, Type: typ})
+}
+
+func (r *rosbridge) publish(topic string, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return r.send(message{Op: "publish", Topic: topic, Msg: data})
+}
+
+// receive blocks until the next message arrives.
+func (r *rosbridge) receive() (message, error) {
+	var m message
+	err := websocket.JSON.Receive(r.conn, &m)
+	return m, err
+}
+
+func (r *rosbridge) close() error {
+	return r.conn.Close()
+}
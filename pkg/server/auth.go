@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokenCookie keeps a browser signed in after it opened the URL with the
+// token, so the dashboard's own requests and camera streams pass.
+const tokenCookie = "lerobot_token"
+
+// NewToken returns a random token for RequireToken.
+func NewToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// IsLoopback reports whether the listen address addr only accepts
+// connections from this computer, e.g. "127.0.0.1:8080" or
+// "localhost:8080" but not ":8080".
+func IsLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// RequireToken only passes requests to next that carry token, as
+// "Authorization: Bearer <token>", a ?token= query parameter, or the
+// cookie set when a browser first opened a URL with the parameter.
+// Others get 401 Unauthorized.
+func RequireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query().Get("token"); validToken(q, token) {
+			http.SetCookie(w, &http.Cookie{Name: tokenCookie, Value: q, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+			next.ServeHTTP(w, r)
+			return
+		}
+		if c, err := r.Cookie(tokenCookie); err == nil && validToken(c.Value, token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if validToken(bearer(r.Header.Get("Authorization")), token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		writeError(w, http.StatusUnauthorized, errors.New("missing or wrong token"))
+	})
+}
+
+// TokenOptions returns gRPC server options that reject calls without
+// "authorization: Bearer <token>" metadata with UNAUTHENTICATED.
+func TokenOptions(token string) []grpc.ServerOption {
+	check := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get("authorization") {
+			if validToken(bearer(v), token) {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "missing or wrong token")
+	}
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+func bearer(header string) string {
+	token, _ := strings.CutPrefix(header, "Bearer ")
+	return token
+}
+
+func validToken(got, want string) bool {
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireToken(t *testing.T) {
+	h := RequireToken("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	do := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do(httptest.NewRequest("GET", "/state", nil)); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status %d, want 401", w.Code)
+	}
+	if w := do(httptest.NewRequest("GET", "/state?token=wrong", nil)); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status %d, want 401", w.Code)
+	}
+
+	r := httptest.NewRequest("GET", "/state", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	if w := do(r); w.Code != http.StatusOK {
+		t.Errorf("bearer token: status %d, want 200", w.Code)
+	}
+
+	// Opening the URL with the token signs the browser in
+	w := do(httptest.NewRequest("GET", "/?token=secret", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("query token: status %d, want 200", w.Code)
+	}
+	r = httptest.NewRequest("POST", "/estop", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	if w := do(r); w.Code != http.StatusOK {
+		t.Errorf("cookie: status %d, want 200", w.Code)
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8080": true,
+		"localhost:8080": true,
+		"[::1]:8080":     true,
+		":8080":          false,
+		"0.0.0.0:8080":   false,
+		"10.0.0.5:8080":  false,
+		"robot.lan:8080": false,
+	} {
+		if got := IsLoopback(addr); got != want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/camera"
+)
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+const (
+	// streamInterval is the frame interval of camera streams, enough to
+	// operate by without loading the encoder.
+	streamInterval = 66 * time.Millisecond // 15 fps
+
+	// eventInterval is how often state events are sent. While idle, every
+	// event reads both arms.
+	eventInterval = 100 * time.Millisecond
+
+	jpegQuality = 70
+)
+
+// Dashboard returns the REST API of Handler with a browser dashboard at /:
+// live camera streams, joint gauges, teleoperation, torque and recording
+// controls, and an emergency stop. It also serves GET /events, a
+// server-sent event stream of the state.
+//
+// Cameras are streamed as MJPEG, which browsers show in an img element
+// without any client code, at the cost of more bandwidth than a video
+// codec. This stands in for the WebRTC view the dashboard was requested
+// with, which is not implemented yet.
+func (s *Server) Dashboard() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", s.Handler())
+
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(dashboardHTML)
+	})
+
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		ticker := time.NewTicker(eventInterval)
+		defer ticker.Stop()
+		for {
+			data, err := json.Marshal(s.State(r.Context()))
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	return mux
+}
+
+// streamMJPEG streams the latest image of a camera as multipart JPEG until
+// the client disconnects.
+func streamMJPEG(w http.ResponseWriter, r *http.Request, g *camera.Grabber) {
+	const boundary = "frame"
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+boundary)
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+
+	cam := g.Camera()
+	var last time.Time
+
+	ticker := time.NewTicker(streamInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+		frame, ok := g.Latest()
+		if !ok || frame.Timestamp.Equal(last) {
+			continue
+		}
+		last = frame.Timestamp
+
+		data, err := camera.EncodeJPEG(frame.Data, cam.Width(), cam.Height(), jpegQuality)
+		if err != nil {
+			return
+		}
+		_, err = fmt.Fprintf(w, "--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n", boundary, len(data))
+		if err == nil {
+			_, err = w.Write(append(data, '\r', '\n'))
+		}
+		if err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
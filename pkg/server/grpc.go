@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/server/robotpb"
+)
+
+// RegisterGRPC registers the Robot gRPC service (see robotpb/robot.proto)
+// on g.
+func (s *Server) RegisterGRPC(g *grpc.Server) {
+	robotpb.RegisterRobotServer(g, &grpcServer{s: s})
+}
+
+type grpcServer struct {
+	robotpb.UnimplementedRobotServer
+	s *Server
+}
+
+func (g *grpcServer) ReadState(ctx context.Context, _ *robotpb.ReadStateRequest) (*robotpb.RobotState, error) {
+	return toProtoState(g.s.State(ctx)), nil
+}
+
+func (g *grpcServer) WriteAction(ctx context.Context, req *robotpb.Action) (*robotpb.WriteActionResponse, error) {
+	positions := make(map[robot.MotorName]float64, len(req.GetPositions()))
+	for name, pos := range req.GetPositions() {
+		positions[robot.MotorName(name)] = pos
+	}
+	if err := g.s.WritePositions(ctx, positions); err != nil {
+		return nil, grpcError(err)
+	}
+	return &robotpb.WriteActionResponse{}, nil
+}
+
+func (g *grpcServer) StreamStates(req *robotpb.StreamStatesRequest, stream robotpb.Robot_StreamStatesServer) error {
+	hz := req.GetHz()
+	if hz <= 0 {
+		hz = 30
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / hz))
+	defer ticker.Stop()
+
+	ctx := stream.Context()
+	for {
+		if err := stream.Send(toProtoState(g.s.State(ctx))); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *grpcServer) Enable(ctx context.Context, _ *robotpb.EnableRequest) (*robotpb.EnableResponse, error) {
+	if err := g.s.SetTorque(ctx, true); err != nil {
+		return nil, grpcError(err)
+	}
+	return &robotpb.EnableResponse{}, nil
+}
+
+func (g *grpcServer) Disable(ctx context.Context, _ *robotpb.DisableRequest) (*robotpb.DisableResponse, error) {
+	if err := g.s.SetTorque(ctx, false); err != nil {
+		return nil, grpcError(err)
+	}
+	return &robotpb.DisableResponse{}, nil
+}
+
+func (g *grpcServer) Pause(_ context.Context, _ *robotpb.PauseRequest) (*robotpb.PauseResponse, error) {
+	if err := g.s.PauseTeleop(); err != nil {
+		return nil, grpcError(err)
+	}
+	return &robotpb.PauseResponse{}, nil
+}
+
+func (g *grpcServer) Resume(_ context.Context, _ *robotpb.ResumeRequest) (*robotpb.ResumeResponse, error) {
+	if err := g.s.ResumeTeleop(); err != nil {
+		return nil, grpcError(err)
+	}
+	return &robotpb.ResumeResponse{}, nil
+}
+
+func toProtoState(st State) *robotpb.RobotState {
+	return &robotpb.RobotState{
+		Teleop:      st.Teleop,
+		Paused:      st.Paused,
+		Recording:   st.Recording,
+		Leader:      toProtoPositions(st.Leader),
+		Follower:    toProtoPositions(st.Follower),
+		TimestampNs: st.Timestamp.UnixNano(),
+		Error:       st.Error,
+	}
+}
+
+func toProtoPositions(positions map[robot.MotorName]float64) map[string]float64 {
+	if positions == nil {
+		return nil
+	}
+	m := make(map[string]float64, len(positions))
+	for name, pos := range positions {
+		m[string(name)] = pos
+	}
+	return m
+}
+
+// grpcError maps mode errors to FAILED_PRECONDITION, like the 409 of the
+// HTTP API.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrTeleopRunning), errors.Is(err, ErrTeleopNotRunning),
+		errors.Is(err, ErrRecording), errors.Is(err, ErrNotRecording),
+		errors.Is(err, ErrNotConnected):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// Handler returns an http.Handler exposing the server as a JSON REST API:
+//
+//	GET  /state            current state
+//	POST /positions        {"shoulder_pan": 10, ...} follower targets (idle only)
+//	POST /torque           {"enabled": true} follower torque (idle only)
+//	POST /teleop/start     start teleoperation
+//	POST /teleop/stop      stop teleoperation
+//	POST /teleop/pause     hold the follower, teleoperation keeps running
+//	POST /teleop/resume    re-engage the follower
+//	POST /record/start     {"dataset": "demo"} start an episode, see SetDataDir
+//	POST /record/stop      save the episode
+//	POST /estop            stop teleoperation and disable follower torque
+//	GET  /cameras          camera names
+//	GET  /cameras/{name}   MJPEG stream of a camera
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /state", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.State(r.Context()))
+	})
+
+	mux.HandleFunc("POST /positions", func(w http.ResponseWriter, r *http.Request) {
+		var positions map[robot.MotorName]float64
+		if !readJSON(w, r, &positions) {
+			return
+		}
+		writeResult(w, s.WritePositions(r.Context(), positions), nil)
+	})
+
+	mux.HandleFunc("POST /torque", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Enabled bool `json:"enabled"`
+		}
+		if !readJSON(w, r, &req) {
+			return
+		}
+		writeResult(w, s.SetTorque(r.Context(), req.Enabled), nil)
+	})
+
+	mux.HandleFunc("POST /teleop/start", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, s.StartTeleop(), nil)
+	})
+
+	mux.HandleFunc("POST /teleop/stop", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, s.StopTeleop(), nil)
+	})
+
+	mux.HandleFunc("POST /teleop/pause", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, s.PauseTeleop(), nil)
+	})
+
+	mux.HandleFunc("POST /teleop/resume", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, s.ResumeTeleop(), nil)
+	})
+
+	mux.HandleFunc("POST /record/start", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Dataset string `json:"dataset"`
+		}
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if req.Dataset == "" {
+			writeError(w, http.StatusBadRequest, errors.New("dataset is required"))
+			return
+		}
+		index, err := s.StartRecording(req.Dataset)
+		writeResult(w, err, map[string]int{"episode_index": index})
+	})
+
+	mux.HandleFunc("POST /record/stop", func(w http.ResponseWriter, r *http.Request) {
+		ep, err := s.StopRecording()
+		writeResult(w, err, ep)
+	})
+
+	mux.HandleFunc("POST /estop", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, s.EmergencyStop(r.Context()), nil)
+	})
+
+	mux.HandleFunc("GET /cameras", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.cameraNames())
+	})
+
+	mux.HandleFunc("GET /cameras/{name}", func(w http.ResponseWriter, r *http.Request) {
+		g, ok := s.camera(r.PathValue("name"))
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("no such camera"))
+			return
+		}
+		streamMJPEG(w, r, g)
+	})
+
+	return mux
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+// writeResult writes v (or {"ok": true}) on success, or the error with a
+// status code matching its kind.
+func writeResult(w http.ResponseWriter, err error, v any) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrTeleopRunning), errors.Is(err, ErrTeleopNotRunning),
+			errors.Is(err, ErrRecording), errors.Is(err, ErrNotRecording):
+			status = http.StatusConflict
+		case errors.Is(err, ErrNotConnected):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, ErrDatasetPath):
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err)
+		return
+	}
+	if v == nil {
+		v = map[string]bool{"ok": true}
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,5 @@
+// Package robotpb contains the generated protobuf and gRPC code for the
+// Robot service.
+package robotpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative robot.proto
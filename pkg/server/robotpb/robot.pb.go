@@ -0,0 +1,691 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: robot.proto
+
+package robotpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ReadStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadStateRequest) Reset() {
+	*x = ReadStateRequest{}
+	mi := &file_robot_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadStateRequest) ProtoMessage() {}
+
+func (x *ReadStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadStateRequest.ProtoReflect.Descriptor instead.
+func (*ReadStateRequest) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{0}
+}
+
+type RobotState struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Teleop    bool                   `protobuf:"varint,1,opt,name=teleop,proto3" json:"teleop,omitempty"`
+	Recording bool                   `protobuf:"varint,2,opt,name=recording,proto3" json:"recording,omitempty"`
+	Leader    map[string]float64     `protobuf:"bytes,3,rep,name=leader,proto3" json:"leader,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Follower  map[string]float64     `protobuf:"bytes,4,rep,name=follower,proto3" json:"follower,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	// Unix time in nanoseconds.
+	TimestampNs   int64  `protobuf:"varint,5,opt,name=timestamp_ns,json=timestampNs,proto3" json:"timestamp_ns,omitempty"`
+	Error         string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Paused        bool   `protobuf:"varint,7,opt,name=paused,proto3" json:"paused,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RobotState) Reset() {
+	*x = RobotState{}
+	mi := &file_robot_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RobotState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RobotState) ProtoMessage() {}
+
+func (x *RobotState) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RobotState.ProtoReflect.Descriptor instead.
+func (*RobotState) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RobotState) GetTeleop() bool {
+	if x != nil {
+		return x.Teleop
+	}
+	return false
+}
+
+func (x *RobotState) GetRecording() bool {
+	if x != nil {
+		return x.Recording
+	}
+	return false
+}
+
+func (x *RobotState) GetLeader() map[string]float64 {
+	if x != nil {
+		return x.Leader
+	}
+	return nil
+}
+
+func (x *RobotState) GetFollower() map[string]float64 {
+	if x != nil {
+		return x.Follower
+	}
+	return nil
+}
+
+func (x *RobotState) GetTimestampNs() int64 {
+	if x != nil {
+		return x.TimestampNs
+	}
+	return 0
+}
+
+func (x *RobotState) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RobotState) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+type Action struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Positions     map[string]float64     `protobuf:"bytes,1,rep,name=positions,proto3" json:"positions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Action) Reset() {
+	*x = Action{}
+	mi := &file_robot_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Action) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Action) ProtoMessage() {}
+
+func (x *Action) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Action.ProtoReflect.Descriptor instead.
+func (*Action) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Action) GetPositions() map[string]float64 {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+type WriteActionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteActionResponse) Reset() {
+	*x = WriteActionResponse{}
+	mi := &file_robot_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteActionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteActionResponse) ProtoMessage() {}
+
+func (x *WriteActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteActionResponse.ProtoReflect.Descriptor instead.
+func (*WriteActionResponse) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{3}
+}
+
+type StreamStatesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Updates per second, defaults to 30.
+	Hz            float64 `protobuf:"fixed64,1,opt,name=hz,proto3" json:"hz,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamStatesRequest) Reset() {
+	*x = StreamStatesRequest{}
+	mi := &file_robot_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamStatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamStatesRequest) ProtoMessage() {}
+
+func (x *StreamStatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamStatesRequest.ProtoReflect.Descriptor instead.
+func (*StreamStatesRequest) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamStatesRequest) GetHz() float64 {
+	if x != nil {
+		return x.Hz
+	}
+	return 0
+}
+
+type EnableRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableRequest) Reset() {
+	*x = EnableRequest{}
+	mi := &file_robot_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableRequest) ProtoMessage() {}
+
+func (x *EnableRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableRequest.ProtoReflect.Descriptor instead.
+func (*EnableRequest) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{5}
+}
+
+type EnableResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableResponse) Reset() {
+	*x = EnableResponse{}
+	mi := &file_robot_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableResponse) ProtoMessage() {}
+
+func (x *EnableResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableResponse.ProtoReflect.Descriptor instead.
+func (*EnableResponse) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{6}
+}
+
+type DisableRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableRequest) Reset() {
+	*x = DisableRequest{}
+	mi := &file_robot_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableRequest) ProtoMessage() {}
+
+func (x *DisableRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableRequest.ProtoReflect.Descriptor instead.
+func (*DisableRequest) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{7}
+}
+
+type DisableResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableResponse) Reset() {
+	*x = DisableResponse{}
+	mi := &file_robot_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableResponse) ProtoMessage() {}
+
+func (x *DisableResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableResponse.ProtoReflect.Descriptor instead.
+func (*DisableResponse) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{8}
+}
+
+type PauseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseRequest) Reset() {
+	*x = PauseRequest{}
+	mi := &file_robot_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseRequest) ProtoMessage() {}
+
+func (x *PauseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseRequest.ProtoReflect.Descriptor instead.
+func (*PauseRequest) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{9}
+}
+
+type PauseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseResponse) Reset() {
+	*x = PauseResponse{}
+	mi := &file_robot_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseResponse) ProtoMessage() {}
+
+func (x *PauseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseResponse.ProtoReflect.Descriptor instead.
+func (*PauseResponse) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{10}
+}
+
+type ResumeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeRequest) Reset() {
+	*x = ResumeRequest{}
+	mi := &file_robot_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeRequest) ProtoMessage() {}
+
+func (x *ResumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeRequest.ProtoReflect.Descriptor instead.
+func (*ResumeRequest) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{11}
+}
+
+type ResumeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeResponse) Reset() {
+	*x = ResumeResponse{}
+	mi := &file_robot_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeResponse) ProtoMessage() {}
+
+func (x *ResumeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_robot_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeResponse.ProtoReflect.Descriptor instead.
+func (*ResumeResponse) Descriptor() ([]byte, []int) {
+	return file_robot_proto_rawDescGZIP(), []int{12}
+}
+
+var File_robot_proto protoreflect.FileDescriptor
+
+const file_robot_proto_rawDesc = "" +
+	"\n" +
+	"\vrobot.proto\x12\n" +
+	"lerobot.v1\"\x12\n" +
+	"\x10ReadStateRequest\"\x89\x03\n" +
+	"\n" +
+	"RobotState\x12\x16\n" +
+	"\x06teleop\x18\x01 \x01(\bR\x06teleop\x12\x1c\n" +
+	"\trecording\x18\x02 \x01(\bR\trecording\x12:\n" +
+	"\x06leader\x18\x03 \x03(\v2\".lerobot.v1.RobotState.LeaderEntryR\x06leader\x12@\n" +
+	"\bfollower\x18\x04 \x03(\v2$.lerobot.v1.RobotState.FollowerEntryR\bfollower\x12!\n" +
+	"\ftimestamp_ns\x18\x05 \x01(\x03R\vtimestampNs\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12\x16\n" +
+	"\x06paused\x18\a \x01(\bR\x06paused\x1a9\n" +
+	"\vLeaderEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\x1a;\n" +
+	"\rFollowerEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\x87\x01\n" +
+	"\x06Action\x12?\n" +
+	"\tpositions\x18\x01 \x03(\v2!.lerobot.v1.Action.PositionsEntryR\tpositions\x1a<\n" +
+	"\x0ePositionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\x15\n" +
+	"\x13WriteActionResponse\"%\n" +
+	"\x13StreamStatesRequest\x12\x0e\n" +
+	"\x02hz\x18\x01 \x01(\x01R\x02hz\"\x0f\n" +
+	"\rEnableRequest\"\x10\n" +
+	"\x0eEnableResponse\"\x10\n" +
+	"\x0eDisableRequest\"\x11\n" +
+	"\x0fDisableResponse\"\x0e\n" +
+	"\fPauseRequest\"\x0f\n" +
+	"\rPauseResponse\"\x0f\n" +
+	"\rResumeRequest\"\x10\n" +
+	"\x0eResumeResponse2\xdd\x03\n" +
+	"\x05Robot\x12A\n" +
+	"\tReadState\x12\x1c.lerobot.v1.ReadStateRequest\x1a\x16.lerobot.v1.RobotState\x12B\n" +
+	"\vWriteAction\x12\x12.lerobot.v1.Action\x1a\x1f.lerobot.v1.WriteActionResponse\x12I\n" +
+	"\fStreamStates\x12\x1f.lerobot.v1.StreamStatesRequest\x1a\x16.lerobot.v1.RobotState0\x01\x12?\n" +
+	"\x06Enable\x12\x19.lerobot.v1.EnableRequest\x1a\x1a.lerobot.v1.EnableResponse\x12B\n" +
+	"\aDisable\x12\x1a.lerobot.v1.DisableRequest\x1a\x1b.lerobot.v1.DisableResponse\x12<\n" +
+	"\x05Pause\x12\x18.lerobot.v1.PauseRequest\x1a\x19.lerobot.v1.PauseResponse\x12?\n" +
+	"\x06Resume\x12\x19.lerobot.v1.ResumeRequest\x1a\x1a.lerobot.v1.ResumeResponseB/Z-github.com/gwillem/lerobot/pkg/server/robotpbb\x06proto3"
+
+var (
+	file_robot_proto_rawDescOnce sync.Once
+	file_robot_proto_rawDescData []byte
+)
+
+func file_robot_proto_rawDescGZIP() []byte {
+	file_robot_proto_rawDescOnce.Do(func() {
+		file_robot_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_robot_proto_rawDesc), len(file_robot_proto_rawDesc)))
+	})
+	return file_robot_proto_rawDescData
+}
+
+var file_robot_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_robot_proto_goTypes = []any{
+	(*ReadStateRequest)(nil),    // 0: lerobot.v1.ReadStateRequest
+	(*RobotState)(nil),          // 1: lerobot.v1.RobotState
+	(*Action)(nil),              // 2: lerobot.v1.Action
+	(*WriteActionResponse)(nil), // 3: lerobot.v1.WriteActionResponse
+	(*StreamStatesRequest)(nil), // 4: lerobot.v1.StreamStatesRequest
+	(*EnableRequest)(nil),       // 5: lerobot.v1.EnableRequest
+	(*EnableResponse)(nil),      // 6: lerobot.v1.EnableResponse
+	(*DisableRequest)(nil),      // 7: lerobot.v1.DisableRequest
+	(*DisableResponse)(nil),     // 8: lerobot.v1.DisableResponse
+	(*PauseRequest)(nil),        // 9: lerobot.v1.PauseRequest
+	(*PauseResponse)(nil),       // 10: lerobot.v1.PauseResponse
+	(*ResumeRequest)(nil),       // 11: lerobot.v1.ResumeRequest
+	(*ResumeResponse)(nil),      // 12: lerobot.v1.ResumeResponse
+	nil,                         // 13: lerobot.v1.RobotState.LeaderEntry
+	nil,                         // 14: lerobot.v1.RobotState.FollowerEntry
+	nil,                         // 15: lerobot.v1.Action.PositionsEntry
+}
+var file_robot_proto_depIdxs = []int32{
+	13, // 0: lerobot.v1.RobotState.leader:type_name -> lerobot.v1.RobotState.LeaderEntry
+	14, // 1: lerobot.v1.RobotState.follower:type_name -> lerobot.v1.RobotState.FollowerEntry
+	15, // 2: lerobot.v1.Action.positions:type_name -> lerobot.v1.Action.PositionsEntry
+	0,  // 3: lerobot.v1.Robot.ReadState:input_type -> lerobot.v1.ReadStateRequest
+	2,  // 4: lerobot.v1.Robot.WriteAction:input_type -> lerobot.v1.Action
+	4,  // 5: lerobot.v1.Robot.StreamStates:input_type -> lerobot.v1.StreamStatesRequest
+	5,  // 6: lerobot.v1.Robot.Enable:input_type -> lerobot.v1.EnableRequest
+	7,  // 7: lerobot.v1.Robot.Disable:input_type -> lerobot.v1.DisableRequest
+	9,  // 8: lerobot.v1.Robot.Pause:input_type -> lerobot.v1.PauseRequest
+	11, // 9: lerobot.v1.Robot.Resume:input_type -> lerobot.v1.ResumeRequest
+	1,  // 10: lerobot.v1.Robot.ReadState:output_type -> lerobot.v1.RobotState
+	3,  // 11: lerobot.v1.Robot.WriteAction:output_type -> lerobot.v1.WriteActionResponse
+	1,  // 12: lerobot.v1.Robot.StreamStates:output_type -> lerobot.v1.RobotState
+	6,  // 13: lerobot.v1.Robot.Enable:output_type -> lerobot.v1.EnableResponse
+	8,  // 14: lerobot.v1.Robot.Disable:output_type -> lerobot.v1.DisableResponse
+	10, // 15: lerobot.v1.Robot.Pause:output_type -> lerobot.v1.PauseResponse
+	12, // 16: lerobot.v1.Robot.Resume:output_type -> lerobot.v1.ResumeResponse
+	10, // [10:17] is the sub-list for method output_type
+	3,  // [3:10] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_robot_proto_init() }
+func file_robot_proto_init() {
+	if File_robot_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_robot_proto_rawDesc), len(file_robot_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_robot_proto_goTypes,
+		DependencyIndexes: file_robot_proto_depIdxs,
+		MessageInfos:      file_robot_proto_msgTypes,
+	}.Build()
+	File_robot_proto = out.File
+	file_robot_proto_goTypes = nil
+	file_robot_proto_depIdxs = nil
+}
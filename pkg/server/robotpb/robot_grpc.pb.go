@@ -0,0 +1,385 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: robot.proto
+
+package robotpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Robot_ReadState_FullMethodName    = "/lerobot.v1.Robot/ReadState"
+	Robot_WriteAction_FullMethodName  = "/lerobot.v1.Robot/WriteAction"
+	Robot_StreamStates_FullMethodName = "/lerobot.v1.Robot/StreamStates"
+	Robot_Enable_FullMethodName       = "/lerobot.v1.Robot/Enable"
+	Robot_Disable_FullMethodName      = "/lerobot.v1.Robot/Disable"
+	Robot_Pause_FullMethodName        = "/lerobot.v1.Robot/Pause"
+	Robot_Resume_FullMethodName       = "/lerobot.v1.Robot/Resume"
+)
+
+// RobotClient is the client API for Robot service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Robot controls a leader/follower arm pair served by `lerobot serve`.
+//
+// Positions are normalized per motor to -100..100, including the gripper,
+// and keyed by motor name (shoulder_pan, ..., gripper).
+type RobotClient interface {
+	// ReadState returns the current leader and follower positions.
+	ReadState(ctx context.Context, in *ReadStateRequest, opts ...grpc.CallOption) (*RobotState, error)
+	// WriteAction moves the follower to the given positions. Fails with
+	// FAILED_PRECONDITION while teleoperation is running.
+	WriteAction(ctx context.Context, in *Action, opts ...grpc.CallOption) (*WriteActionResponse, error)
+	// StreamStates streams the robot state at the requested rate until the
+	// client cancels.
+	StreamStates(ctx context.Context, in *StreamStatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RobotState], error)
+	// Enable turns on follower torque.
+	Enable(ctx context.Context, in *EnableRequest, opts ...grpc.CallOption) (*EnableResponse, error)
+	// Disable turns off follower torque so the arm can be moved by hand.
+	Disable(ctx context.Context, in *DisableRequest, opts ...grpc.CallOption) (*DisableResponse, error)
+	// Pause holds the follower in place while teleoperation keeps running.
+	// Fails with FAILED_PRECONDITION unless teleoperation is running.
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error)
+	// Resume re-engages the follower after Pause, ramping it smoothly back
+	// to the leader pose.
+	Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error)
+}
+
+type robotClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRobotClient(cc grpc.ClientConnInterface) RobotClient {
+	return &robotClient{cc}
+}
+
+func (c *robotClient) ReadState(ctx context.Context, in *ReadStateRequest, opts ...grpc.CallOption) (*RobotState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RobotState)
+	err := c.cc.Invoke(ctx, Robot_ReadState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *robotClient) WriteAction(ctx context.Context, in *Action, opts ...grpc.CallOption) (*WriteActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriteActionResponse)
+	err := c.cc.Invoke(ctx, Robot_WriteAction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *robotClient) StreamStates(ctx context.Context, in *StreamStatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RobotState], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Robot_ServiceDesc.Streams[0], Robot_StreamStates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamStatesRequest, RobotState]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Robot_StreamStatesClient = grpc.ServerStreamingClient[RobotState]
+
+func (c *robotClient) Enable(ctx context.Context, in *EnableRequest, opts ...grpc.CallOption) (*EnableResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnableResponse)
+	err := c.cc.Invoke(ctx, Robot_Enable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *robotClient) Disable(ctx context.Context, in *DisableRequest, opts ...grpc.CallOption) (*DisableResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisableResponse)
+	err := c.cc.Invoke(ctx, Robot_Disable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *robotClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseResponse)
+	err := c.cc.Invoke(ctx, Robot_Pause_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *robotClient) Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeResponse)
+	err := c.cc.Invoke(ctx, Robot_Resume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RobotServer is the server API for Robot service.
+// All implementations must embed UnimplementedRobotServer
+// for forward compatibility.
+//
+// Robot controls a leader/follower arm pair served by `lerobot serve`.
+//
+// Positions are normalized per motor to -100..100, including the gripper,
+// and keyed by motor name (shoulder_pan, ..., gripper).
+type RobotServer interface {
+	// ReadState returns the current leader and follower positions.
+	ReadState(context.Context, *ReadStateRequest) (*RobotState, error)
+	// WriteAction moves the follower to the given positions. Fails with
+	// FAILED_PRECONDITION while teleoperation is running.
+	WriteAction(context.Context, *Action) (*WriteActionResponse, error)
+	// StreamStates streams the robot state at the requested rate until the
+	// client cancels.
+	StreamStates(*StreamStatesRequest, grpc.ServerStreamingServer[RobotState]) error
+	// Enable turns on follower torque.
+	Enable(context.Context, *EnableRequest) (*EnableResponse, error)
+	// Disable turns off follower torque so the arm can be moved by hand.
+	Disable(context.Context, *DisableRequest) (*DisableResponse, error)
+	// Pause holds the follower in place while teleoperation keeps running.
+	// Fails with FAILED_PRECONDITION unless teleoperation is running.
+	Pause(context.Context, *PauseRequest) (*PauseResponse, error)
+	// Resume re-engages the follower after Pause, ramping it smoothly back
+	// to the leader pose.
+	Resume(context.Context, *ResumeRequest) (*ResumeResponse, error)
+	mustEmbedUnimplementedRobotServer()
+}
+
+// UnimplementedRobotServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRobotServer struct{}
+
+func (UnimplementedRobotServer) ReadState(context.Context, *ReadStateRequest) (*RobotState, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReadState not implemented")
+}
+func (UnimplementedRobotServer) WriteAction(context.Context, *Action) (*WriteActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method WriteAction not implemented")
+}
+func (UnimplementedRobotServer) StreamStates(*StreamStatesRequest, grpc.ServerStreamingServer[RobotState]) error {
+	return status.Error(codes.Unimplemented, "method StreamStates not implemented")
+}
+func (UnimplementedRobotServer) Enable(context.Context, *EnableRequest) (*EnableResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Enable not implemented")
+}
+func (UnimplementedRobotServer) Disable(context.Context, *DisableRequest) (*DisableResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Disable not implemented")
+}
+func (UnimplementedRobotServer) Pause(context.Context, *PauseRequest) (*PauseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedRobotServer) Resume(context.Context, *ResumeRequest) (*ResumeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedRobotServer) mustEmbedUnimplementedRobotServer() {}
+func (UnimplementedRobotServer) testEmbeddedByValue()               {}
+
+// UnsafeRobotServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RobotServer will
+// result in compilation errors.
+type UnsafeRobotServer interface {
+	mustEmbedUnimplementedRobotServer()
+}
+
+func RegisterRobotServer(s grpc.ServiceRegistrar, srv RobotServer) {
+	// If the following call panics, it indicates UnimplementedRobotServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Robot_ServiceDesc, srv)
+}
+
+func _Robot_ReadState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RobotServer).ReadState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Robot_ReadState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RobotServer).ReadState(ctx, req.(*ReadStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Robot_WriteAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Action)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RobotServer).WriteAction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Robot_WriteAction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RobotServer).WriteAction(ctx, req.(*Action))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Robot_StreamStates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamStatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RobotServer).StreamStates(m, &grpc.GenericServerStream[StreamStatesRequest, RobotState]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Robot_StreamStatesServer = grpc.ServerStreamingServer[RobotState]
+
+func _Robot_Enable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RobotServer).Enable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Robot_Enable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RobotServer).Enable(ctx, req.(*EnableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Robot_Disable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RobotServer).Disable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Robot_Disable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RobotServer).Disable(ctx, req.(*DisableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Robot_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RobotServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Robot_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RobotServer).Pause(ctx, req.(*PauseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Robot_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RobotServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Robot_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RobotServer).Resume(ctx, req.(*ResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Robot_ServiceDesc is the grpc.ServiceDesc for Robot service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Robot_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lerobot.v1.Robot",
+	HandlerType: (*RobotServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReadState",
+			Handler:    _Robot_ReadState_Handler,
+		},
+		{
+			MethodName: "WriteAction",
+			Handler:    _Robot_WriteAction_Handler,
+		},
+		{
+			MethodName: "Enable",
+			Handler:    _Robot_Enable_Handler,
+		},
+		{
+			MethodName: "Disable",
+			Handler:    _Robot_Disable_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _Robot_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _Robot_Resume_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamStates",
+			Handler:       _Robot_StreamStates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "robot.proto",
+}
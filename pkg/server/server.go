@@ -0,0 +1,487 @@
+// Package server exposes robot control over the network.
+//
+// Server owns the arms and switches between two modes: idle, where the
+// follower can be commanded directly, and teleoperation, where a
+// teleop.Controller drives the follower from the leader. Recording is
+// available during teleoperation, including the images of any cameras
+// added with SetCameras.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/camera"
+	"github.com/gwillem/lerobot/pkg/dataset"
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/teleop"
+)
+
+// Errors returned for requests that are invalid in the current mode.
+var (
+	ErrTeleopRunning    = errors.New("teleoperation is running")
+	ErrTeleopNotRunning = errors.New("teleoperation is not running")
+	ErrRecording        = errors.New("already recording")
+	ErrNotRecording     = errors.New("not recording")
+	ErrNotConnected     = errors.New("arms are not connected")
+	ErrDatasetPath      = errors.New("dataset must be a path inside the data directory")
+)
+
+// stopTimeout bounds stopping teleoperation, parking included.
+const stopTimeout = 20 * time.Second
+
+// State is a snapshot of the robot.
+type State struct {
+	Mode      string                      `json:"mode"` // see teleop.Mode
+	Teleop    bool                        `json:"teleop"`
+	Paused    bool                        `json:"paused"`
+	Recording bool                        `json:"recording"`
+	Leader    map[robot.MotorName]float64 `json:"leader,omitempty"`
+	Follower  map[robot.MotorName]float64 `json:"follower,omitempty"`
+	Timestamp time.Time                   `json:"timestamp"`
+	Error     string                      `json:"error,omitempty"`
+}
+
+// Server controls a leader/follower pair.
+type Server struct {
+	teleopCfg teleop.Config
+	openArm   func(robot.ArmConfig) (*robot.Arm, error) // robot.OpenArm, replaced in tests
+
+	mu       sync.Mutex
+	leader   *robot.Arm // open while idle
+	follower *robot.Arm // open while idle
+
+	ctrl   *teleop.Controller // non-nil during teleoperation
+	cancel context.CancelFunc
+	done   chan struct{}
+	latest teleop.State
+
+	ds       *dataset.Dataset
+	episode  *dataset.EpisodeWriter
+	recStart time.Time
+	recErr   error // failure adding an image, reported by StopRecording
+
+	cams    []*camera.Grabber
+	dataDir string // see SetDataDir
+}
+
+// New creates a server for the arms in cfg. The leader, follower, and
+// options in cfg are used when teleoperation is started.
+func New(cfg teleop.Config) (*Server, error) {
+	cfg.ReadFollower = true
+	s := &Server{teleopCfg: cfg, openArm: robot.OpenArm}
+	if err := s.openArms(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Server) openArms() error {
+	leader, err := s.openArm(s.teleopCfg.Leader)
+	if err != nil {
+		return fmt.Errorf("open leader: %w", err)
+	}
+	follower, err := s.openArm(s.teleopCfg.Follower)
+	if err != nil {
+		leader.Close()
+		return fmt.Errorf("open follower: %w", err)
+	}
+	s.leader, s.follower = leader, follower
+	return nil
+}
+
+// armsLocked makes sure the arms are open while idle, opening them again
+// if that failed when teleoperation stopped. It returns ErrNotConnected
+// if they still can't be opened.
+func (s *Server) armsLocked() error {
+	if s.leader != nil && s.follower != nil {
+		return nil
+	}
+	s.closeArms()
+	if err := s.openArms(); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotConnected, err)
+	}
+	return nil
+}
+
+func (s *Server) closeArms() {
+	if s.leader != nil {
+		s.leader.Close()
+		s.leader = nil
+	}
+	if s.follower != nil {
+		s.follower.Close()
+		s.follower = nil
+	}
+}
+
+// SetCameras adds cameras to stream (see Dashboard) and to record with
+// every episode. The server does not close them.
+func (s *Server) SetCameras(cams []*camera.Grabber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cams = cams
+}
+
+// SetDataDir confines the datasets of StartRecording to dir: their names
+// are paths inside it, e.g. "demo" for dir/demo.
+func (s *Server) SetDataDir(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dataDir = dir
+}
+
+// camera returns the camera with the given name.
+func (s *Server) camera(name string) (*camera.Grabber, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, g := range s.cams {
+		if g.Camera().Name() == name {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+// cameraNames returns the names of the cameras.
+func (s *Server) cameraNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, len(s.cams))
+	for i, g := range s.cams {
+		names[i] = g.Camera().Name()
+	}
+	return names
+}
+
+// Close stops teleoperation and recording and releases the arms.
+func (s *Server) Close() error {
+	s.StopTeleop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeArms()
+	return nil
+}
+
+// State returns the current robot state. While idle, positions are read
+// from the arms; during teleoperation the latest controller state is used.
+func (s *Server) State(ctx context.Context) State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctrl != nil {
+		st := State{
+			Mode:      s.ctrl.Mode().String(),
+			Teleop:    true,
+			Paused:    s.latest.Paused,
+			Recording: s.episode != nil,
+			Leader:    s.latest.Positions,
+			Follower:  s.latest.FollowerPositions,
+			Timestamp: s.latest.Timestamp,
+		}
+		if s.latest.Error != nil {
+			st.Error = s.latest.Error.Error()
+		}
+		return st
+	}
+
+	st := State{Mode: teleop.ModeIdle.String(), Timestamp: time.Now()}
+	if err := s.armsLocked(); err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	var errs []error
+	var err error
+	if st.Leader, err = s.leader.ReadPositions(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("leader: %w", err))
+	}
+	if st.Follower, err = s.follower.ReadPositions(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("follower: %w", err))
+	}
+	if err := errors.Join(errs...); err != nil {
+		st.Error = err.Error()
+	}
+	return st
+}
+
+// WritePositions commands the follower to normalized positions. Only
+// allowed while teleoperation is stopped.
+func (s *Server) WritePositions(ctx context.Context, positions map[robot.MotorName]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctrl != nil {
+		return ErrTeleopRunning
+	}
+	if err := s.armsLocked(); err != nil {
+		return err
+	}
+	return s.follower.WritePositions(ctx, positions)
+}
+
+// SetTorque enables or disables follower torque. Only allowed while
+// teleoperation is stopped.
+func (s *Server) SetTorque(ctx context.Context, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctrl != nil {
+		return ErrTeleopRunning
+	}
+	if err := s.armsLocked(); err != nil {
+		return err
+	}
+	if enabled {
+		return s.follower.Enable(ctx)
+	}
+	return s.follower.Disable(ctx)
+}
+
+// StartTeleop hands the arms to a teleoperation controller.
+func (s *Server) StartTeleop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctrl != nil {
+		return ErrTeleopRunning
+	}
+
+	// The controller opens its own connections to the serial ports
+	s.closeArms()
+	ctrl, err := teleop.NewController(s.teleopCfg)
+	if err != nil {
+		if openErr := s.openArms(); openErr != nil {
+			err = errors.Join(err, openErr)
+		}
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctrl = ctrl
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	returned := make(chan struct{})
+	go func() {
+		defer close(s.done)
+		err := ctrl.Start(ctx)
+		close(returned)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			// E.g. a pose mismatch; report it in the state until stopped
+			s.mu.Lock()
+			s.latest.Error = err
+			s.mu.Unlock()
+		}
+	}()
+	go s.consume(ctx, ctrl)
+	go func() {
+		// Drain logs so the controller never blocks on them
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ctrl.Logs():
+			}
+		}
+	}()
+
+	// Start changes to teleop mode first thing; wait for it, so a
+	// StartRecording right after this returns finds the controller running
+	select {
+	case <-ctrl.Modes():
+	case <-returned:
+	}
+	return nil
+}
+
+// consume keeps the latest controller state and feeds the recorder.
+func (s *Server) consume(ctx context.Context, ctrl *teleop.Controller) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case st := <-ctrl.States():
+			s.mu.Lock()
+			s.latest = st
+			if s.episode != nil && st.Positions != nil && st.FollowerPositions != nil {
+				if s.recStart.IsZero() {
+					s.recStart = st.Timestamp
+				}
+				s.episode.Add(st.Timestamp.Sub(s.recStart).Seconds(), st.Positions, st.FollowerPositions)
+				s.addImagesLocked()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// addImagesLocked adds the latest image of every camera to the episode, a
+// black one before the first. The first failure is kept for StopRecording.
+func (s *Server) addImagesLocked() {
+	for _, g := range s.cams {
+		cam := g.Camera()
+		frame, ok := g.Latest()
+		if !ok {
+			frame.Data = make([]byte, cam.Width()*cam.Height()*3)
+		}
+		if err := s.episode.AddImage(cam.Name(), frame.Data); err != nil && s.recErr == nil {
+			s.recErr = err
+		}
+	}
+}
+
+// StopTeleop stops teleoperation, saving any episode being recorded, and
+// reopens the arms for direct control. If they fail to open, requests
+// that need them try again and return ErrNotConnected meanwhile.
+func (s *Server) StopTeleop() error {
+	s.mu.Lock()
+	if s.ctrl == nil {
+		s.mu.Unlock()
+		return ErrTeleopNotRunning
+	}
+	var saveErr error
+	if s.episode != nil {
+		_, saveErr = s.stopRecordingLocked()
+	}
+	ctrl, cancel, done := s.ctrl, s.cancel, s.done
+	s.mu.Unlock()
+
+	// Close waits for the loop to park the follower and disable its
+	// torque before closing the arms
+	cancel()
+	ctx, stop := context.WithTimeout(context.Background(), stopTimeout)
+	defer stop()
+	ctrl.Close(ctx)
+	<-done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctrl = nil
+	s.latest = teleop.State{}
+	return errors.Join(saveErr, s.openArms())
+}
+
+// PauseTeleop holds the follower in place while teleoperation keeps
+// running. See teleop.Controller.Pause.
+func (s *Server) PauseTeleop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctrl == nil {
+		return ErrTeleopNotRunning
+	}
+	s.ctrl.Pause()
+	return nil
+}
+
+// ResumeTeleop re-engages the follower after PauseTeleop.
+func (s *Server) ResumeTeleop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctrl == nil {
+		return ErrTeleopNotRunning
+	}
+	s.ctrl.Resume()
+	return nil
+}
+
+// StartRecording starts a new episode in the dataset at dir, creating the
+// dataset if needed. Teleoperation must be running. With SetDataDir, dir
+// is a path inside the data directory, and ErrDatasetPath is returned for
+// any other.
+func (s *Server) StartRecording(dir string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctrl == nil {
+		return 0, ErrTeleopNotRunning
+	}
+	if s.episode != nil {
+		return 0, ErrRecording
+	}
+	if s.dataDir != "" {
+		if !filepath.IsLocal(dir) {
+			return 0, ErrDatasetPath
+		}
+		dir = filepath.Join(s.dataDir, dir)
+	}
+
+	if s.ds == nil || s.ds.Root() != dir {
+		ds, err := dataset.Open(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			ds, err = dataset.Create(dir, s.ctrl.Hz(), s.teleopCfg.Leader.Calibration.Motors())
+		}
+		if err != nil {
+			return 0, err
+		}
+		s.ds = ds
+	}
+	for _, g := range s.cams {
+		cam := g.Camera()
+		if err := s.ds.AddCamera(cam.Name(), cam.Width(), cam.Height()); err != nil {
+			return 0, err
+		}
+	}
+
+	ep := s.ds.NewEpisode()
+	if err := s.ctrl.EpisodeStarted(ep.Index()); err != nil {
+		ep.Discard()
+		return 0, err
+	}
+	s.episode = ep
+	s.recStart = time.Time{}
+	s.recErr = nil
+	return ep.Index(), nil
+}
+
+// StopRecording saves the current episode and returns its index and length.
+func (s *Server) StopRecording() (dataset.Episode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopRecordingLocked()
+}
+
+func (s *Server) stopRecordingLocked() (dataset.Episode, error) {
+	if s.episode == nil {
+		return dataset.Episode{}, ErrNotRecording
+	}
+	ep := s.episode
+	s.episode = nil
+	if s.recErr != nil {
+		ep.Discard()
+		s.ctrl.EpisodeEnded(ep.Index(), false)
+		return dataset.Episode{}, fmt.Errorf("episode %d discarded: %w", ep.Index(), s.recErr)
+	}
+	if err := ep.Save(); err != nil {
+		s.ctrl.EpisodeEnded(ep.Index(), false)
+		return dataset.Episode{}, err
+	}
+	s.ctrl.EpisodeEnded(ep.Index(), true)
+	return dataset.Episode{Index: ep.Index(), Length: ep.Len()}, nil
+}
+
+// EmergencyStop stops teleoperation, saving any episode being recorded,
+// and disables follower torque so the arm can be moved by hand. The arm
+// drops under gravity once torque is off, also when a rest pose is set.
+// The controller's Hooks.OnEStop is called, also while idle.
+func (s *Server) EmergencyStop(ctx context.Context) error {
+	// Hooks are called unlocked, so they may call back into the server
+	s.mu.Lock()
+	ctrl := s.ctrl
+	s.mu.Unlock()
+	if ctrl != nil {
+		ctrl.EmergencyStop()
+	} else if h := s.teleopCfg.Hooks.OnEStop; h != nil {
+		h()
+	}
+	err := s.StopTeleop()
+	if errors.Is(err, ErrTeleopNotRunning) {
+		err = nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if aerr := s.armsLocked(); aerr != nil {
+		return errors.Join(err, aerr)
+	}
+	return errors.Join(err, s.follower.Disable(ctx))
+}
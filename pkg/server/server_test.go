@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/robot/robottest"
+	"github.com/gwillem/lerobot/pkg/teleop"
+)
+
+func TestServer_ReopenFails(t *testing.T) {
+	cal := robot.Calibration{robot.Gripper: {ID: 6, RangeMin: 2000, RangeMax: 3000}}
+	unplugged := true
+	s := &Server{
+		teleopCfg: teleop.Config{Leader: robot.ArmConfig{Calibration: cal}, Follower: robot.ArmConfig{Calibration: cal}},
+		openArm: func(cfg robot.ArmConfig) (*robot.Arm, error) {
+			if unplugged {
+				return nil, errors.New("no such device")
+			}
+			bus := robottest.NewFakeBus(6)
+			return robot.NewArmWithBus(cfg, func() (robot.Bus, error) { return bus, bus.Open() })
+		},
+	}
+	ctx := context.Background()
+
+	// As left by StopTeleop when the arms could not be opened again
+	if err := s.openArms(); err == nil {
+		t.Fatal("openArms() succeeded while unplugged")
+	}
+	if err := s.WritePositions(ctx, map[robot.MotorName]float64{robot.Gripper: 0}); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("WritePositions() = %v, want ErrNotConnected", err)
+	}
+	if err := s.SetTorque(ctx, true); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("SetTorque() = %v, want ErrNotConnected", err)
+	}
+	if st := s.State(ctx); st.Error == "" {
+		t.Error("State() reports no error without arms")
+	}
+	if err := s.EmergencyStop(ctx); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("EmergencyStop() = %v, want ErrNotConnected", err)
+	}
+
+	// Plugged back in, the next request opens them
+	unplugged = false
+	if err := s.SetTorque(ctx, true); err != nil {
+		t.Errorf("SetTorque() after reconnecting = %v", err)
+	}
+	if st := s.State(ctx); st.Error != "" {
+		t.Errorf("State().Error = %q", st.Error)
+	}
+	s.Close()
+}
+
+func TestServer_EStopHookCallsBack(t *testing.T) {
+	cal := robot.Calibration{robot.Gripper: {ID: 6, RangeMin: 2000, RangeMax: 3000}}
+	bus := robottest.NewFakeBus(6)
+	s := &Server{
+		teleopCfg: teleop.Config{Leader: robot.ArmConfig{Calibration: cal}, Follower: robot.ArmConfig{Calibration: cal}},
+		openArm: func(cfg robot.ArmConfig) (*robot.Arm, error) {
+			return robot.NewArmWithBus(cfg, func() (robot.Bus, error) { return bus, bus.Open() })
+		},
+	}
+	ctx := context.Background()
+	var st State
+	s.teleopCfg.Hooks.OnEStop = func() { st = s.State(ctx) }
+
+	done := make(chan error)
+	go func() { done <- s.EmergencyStop(ctx) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("EmergencyStop() = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("EmergencyStop deadlocked on a hook calling the server")
+	}
+	if st.Mode != teleop.ModeIdle.String() {
+		t.Errorf("state from hook = %+v", st)
+	}
+	s.Close()
+}
@@ -0,0 +1,193 @@
+// Package sim drives a simulated SO-101 follower over a socket.
+//
+// The simulator (see scripts/mujoco_sim.py) listens on TCP and speaks
+// newline-delimited JSON. Each request gets exactly one response:
+//
+//	{"cmd": "read"}                              -> {"positions": {"shoulder_pan": 1.5, ...}, "loads": {"shoulder_pan": -120, ...}}
+//	{"cmd": "write", "positions": {...}}         -> {}
+//	{"cmd": "enable"} / {"cmd": "disable"}       -> {}
+//
+// Failed requests are answered with {"error": "..."}. Positions use the same
+// normalized [-100, 100] range as robot.Arm; the simulator maps them onto its
+// joint ranges. Loads are the actuator forces in 0.1% of their maximum, like
+// a servo's present load; a simulator that leaves them out reads as 0.
+package sim
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+const dialTimeout = 2 * time.Second
+
+type request struct {
+	Cmd       string                      `json:"cmd"`
+	Positions map[robot.MotorName]float64 `json:"positions,omitempty"`
+}
+
+type response struct {
+	Positions map[robot.MotorName]float64 `json:"positions,omitempty"`
+	Loads     map[robot.MotorName]int     `json:"loads,omitempty"`
+	Error     string                      `json:"error,omitempty"`
+}
+
+// Arm is a simulated arm. It provides the same control methods as
+// robot.Arm, so it can stand in for a follower.
+type Arm struct {
+	addr string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Dial connects to a simulator listening on addr, e.g. localhost:5555.
+func Dial(addr string) (*Arm, error) {
+	a := &Arm{addr: addr}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *Arm) open() error {
+	conn, err := net.DialTimeout("tcp", a.addr, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("connect to simulator: %w", err)
+	}
+	a.conn = conn
+	a.reader = bufio.NewReader(conn)
+	return nil
+}
+
+// Port returns the simulator address.
+func (a *Arm) Port() string {
+	return a.addr
+}
+
+// Close closes the connection to the simulator.
+func (a *Arm) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.conn.Close()
+}
+
+// Reconnect closes and reopens the connection to the simulator.
+func (a *Arm) Reconnect() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.conn.Close()
+	return a.open()
+}
+
+// Enable turns on the simulated actuators.
+func (a *Arm) Enable(ctx context.Context) error {
+	_, err := a.call(ctx, request{Cmd: "enable"})
+	return err
+}
+
+// Disable turns off the simulated actuators, letting the arm go limp.
+func (a *Arm) Disable(ctx context.Context) error {
+	_, err := a.call(ctx, request{Cmd: "disable"})
+	return err
+}
+
+// ReadPositions returns the simulated joint positions in [-100, 100].
+func (a *Arm) ReadPositions(ctx context.Context) (map[robot.MotorName]float64, error) {
+	resp, err := a.call(ctx, request{Cmd: "read"})
+	if err != nil {
+		return nil, fmt.Errorf("read positions: %w", err)
+	}
+	return resp.Positions, nil
+}
+
+// WritePositions sets actuator targets in [-100, 100].
+func (a *Arm) WritePositions(ctx context.Context, positions map[robot.MotorName]float64) error {
+	if _, err := a.call(ctx, request{Cmd: "write", Positions: positions}); err != nil {
+		return fmt.Errorf("write positions: %w", err)
+	}
+	return nil
+}
+
+// ReadLoad returns the simulated load of a joint in 0.1% of its maximum
+// force, see ReadLoads.
+func (a *Arm) ReadLoad(ctx context.Context, name robot.MotorName) (int, error) {
+	loads, err := a.ReadLoads(ctx)
+	return loads[name], err
+}
+
+// ReadState returns the simulated joint positions and loads. Velocities
+// are not reported.
+func (a *Arm) ReadState(ctx context.Context) (robot.ArmState, error) {
+	resp, err := a.call(ctx, request{Cmd: "read"})
+	if err != nil {
+		return robot.ArmState{}, fmt.Errorf("read state: %w", err)
+	}
+	return robot.ArmState{Positions: resp.Positions, Loads: loadsOf(resp)}, nil
+}
+
+// ReadLoads returns the simulated load of every joint in 0.1% of its
+// maximum force, 0 for all if the simulator doesn't report loads.
+func (a *Arm) ReadLoads(ctx context.Context) (map[robot.MotorName]int, error) {
+	resp, err := a.call(ctx, request{Cmd: "read"})
+	if err != nil {
+		return nil, fmt.Errorf("read loads: %w", err)
+	}
+	return loadsOf(resp), nil
+}
+
+// loadsOf returns the loads of a read response, with 0 for the joints it
+// has no load of.
+func loadsOf(resp response) map[robot.MotorName]int {
+	loads := make(map[robot.MotorName]int, len(resp.Positions))
+	for name := range resp.Positions {
+		loads[name] = resp.Loads[name]
+	}
+	return loads
+}
+
+// ReadVelocities always returns nil: the simulator does not report joint
+// velocities.
+func (a *Arm) ReadVelocities(ctx context.Context) (map[robot.MotorName]float64, error) {
+	return nil, nil
+}
+
+func (a *Arm) call(ctx context.Context, req request) (response, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		a.conn.SetDeadline(deadline)
+	} else {
+		a.conn.SetDeadline(time.Now().Add(dialTimeout))
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return response{}, err
+	}
+	if _, err := a.conn.Write(append(data, '\n')); err != nil {
+		return response{}, err
+	}
+
+	line, err := a.reader.ReadBytes('\n')
+	if err != nil {
+		return response{}, err
+	}
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return response{}, fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Error != "" {
+		return response{}, errors.New(resp.Error)
+	}
+	return resp, nil
+}
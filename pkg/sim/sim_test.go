@@ -0,0 +1,99 @@
+package sim
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// fakeSim answers reads with the last written positions, and a load of
+// the gripper only, the negated position.
+func fakeSim(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		positions := map[robot.MotorName]float64{}
+		scanner := bufio.NewScanner(conn)
+		enc := json.NewEncoder(conn)
+		for scanner.Scan() {
+			var req request
+			json.Unmarshal(scanner.Bytes(), &req)
+			switch req.Cmd {
+			case "read":
+				resp := response{Positions: positions}
+				if pos, ok := positions[robot.Gripper]; ok {
+					resp.Loads = map[robot.MotorName]int{robot.Gripper: -int(pos)}
+				}
+				enc.Encode(resp)
+			case "write":
+				for name, pos := range req.Positions {
+					positions[name] = pos
+				}
+				enc.Encode(response{})
+			default:
+				enc.Encode(response{Error: "unknown command"})
+			}
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func TestArm_WriteRead(t *testing.T) {
+	arm, err := Dial(fakeSim(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer arm.Close()
+
+	ctx := context.Background()
+	if err := arm.WritePositions(ctx, map[robot.MotorName]float64{robot.Gripper: 42}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := arm.ReadPositions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[robot.Gripper] != 42 {
+		t.Errorf("gripper = %v, want 42", got[robot.Gripper])
+	}
+	if err := arm.Enable(ctx); err == nil {
+		t.Error("expected error from simulator to be returned")
+	}
+}
+
+func TestArm_Loads(t *testing.T) {
+	arm, err := Dial(fakeSim(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer arm.Close()
+
+	ctx := context.Background()
+	if err := arm.WritePositions(ctx, map[robot.MotorName]float64{robot.Gripper: 42, robot.ShoulderPan: 10}); err != nil {
+		t.Fatal(err)
+	}
+	loads, err := arm.ReadLoads(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loads) != 2 || loads[robot.Gripper] != -42 || loads[robot.ShoulderPan] != 0 {
+		t.Errorf("ReadLoads() = %v, want gripper -42 and shoulder_pan 0", loads)
+	}
+	if st, err := arm.ReadState(ctx); err != nil || st.Loads[robot.Gripper] != -42 {
+		t.Errorf("ReadState() = %+v, %v", st, err)
+	}
+}
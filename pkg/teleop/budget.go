@@ -0,0 +1,80 @@
+package teleop
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrErrorBudget is returned by Start when too many bus transactions fail,
+// see Config.ErrorBudget.
+var ErrErrorBudget = errors.New("error budget exceeded")
+
+const (
+	// budgetWindow is how far back the error rate looks, in one-second
+	// buckets.
+	budgetWindow = 10
+
+	// budgetMinTransactions is how many transactions the window must hold
+	// before the rate is judged, so a few failures at startup don't stop
+	// the loop.
+	budgetMinTransactions = 50
+)
+
+// errorBudget tracks the rate of failed bus transactions over a rolling
+// window. It is shared by the leader and follower goroutines in pipeline
+// mode.
+type errorBudget struct {
+	max float64 // highest tolerated failure rate, 0 disables the check
+
+	mu      sync.Mutex
+	buckets [budgetWindow]budgetBucket
+}
+
+type budgetBucket struct {
+	second        int64
+	total, failed int
+}
+
+// add records a transaction at time now.
+func (b *errorBudget) add(now time.Time, err error) {
+	sec := now.Unix()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	bucket := &b.buckets[sec%budgetWindow]
+	if bucket.second != sec {
+		*bucket = budgetBucket{second: sec}
+	}
+	bucket.total++
+	if err != nil {
+		bucket.failed++
+	}
+}
+
+// check returns an error wrapping ErrErrorBudget if more than the budget of
+// transactions failed over the window up to now.
+func (b *errorBudget) check(now time.Time) error {
+	if b.max <= 0 {
+		return nil
+	}
+	sec := now.Unix()
+	var total, failed int
+	b.mu.Lock()
+	for _, bucket := range b.buckets {
+		if bucket.second > sec-budgetWindow && bucket.second <= sec {
+			total += bucket.total
+			failed += bucket.failed
+		}
+	}
+	b.mu.Unlock()
+
+	if total < budgetMinTransactions {
+		return nil
+	}
+	if rate := float64(failed) / float64(total); rate > b.max {
+		return fmt.Errorf("%w: %d of %d bus transactions failed in the last %ds (%.0f%%, budget %.0f%%)",
+			ErrErrorBudget, failed, total, budgetWindow, rate*100, b.max*100)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package teleop
+
+import (
+	"math"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// ChunkConfig configures a ChunkExecutor.
+type ChunkConfig struct {
+	// Ensemble averages the predictions of overlapping chunks for each step
+	// (ACT's temporal ensembling) instead of letting the newest chunk
+	// replace queued actions.
+	Ensemble bool
+	// Decay weighs the i-th prediction for a step, oldest first, by
+	// exp(-Decay*i), like ACT's temporal_ensemble_coeff. 0 weighs all
+	// predictions equally, larger values favor older ones, negative values
+	// newer ones.
+	Decay float64
+}
+
+// ChunkExecutor turns chunks of actions predicted by a policy, such as ACT,
+// into one action per control tick. A chunk predicts the actions of several
+// steps from one observation; chunks from later observations overlap the
+// steps of earlier ones, which are either replaced or blended.
+type ChunkExecutor struct {
+	cfg   ChunkConfig
+	start int          // step of steps[0]
+	steps []prediction // upcoming steps
+	chunk int          // length of the last chunk added
+}
+
+// prediction accumulates the weighted predictions for one step.
+type prediction struct {
+	sum    map[robot.MotorName]float64
+	weight float64
+	n      int
+}
+
+// NewChunkExecutor returns an executor with no actions queued.
+func NewChunkExecutor(cfg ChunkConfig) *ChunkExecutor { return &ChunkExecutor{cfg: cfg} }
+
+// Len returns the number of actions queued.
+func (e *ChunkExecutor) Len() int { return len(e.steps) }
+
+// LastChunk returns the length of the last chunk added.
+func (e *ChunkExecutor) LastChunk() int { return e.chunk }
+
+// Add queues a chunk of actions, the first of which is for step t. Actions
+// for steps that have already been executed, because the chunk took a while
+// to compute, are dropped.
+func (e *ChunkExecutor) Add(t int, chunk []map[robot.MotorName]float64) {
+	e.chunk = len(chunk)
+	if skip := e.start - t; skip > 0 {
+		chunk = chunk[min(skip, len(chunk)):]
+		t = e.start
+	}
+	if len(chunk) == 0 {
+		return
+	}
+	if t > e.start+len(e.steps) {
+		// A gap after the queued steps: start over at this chunk
+		e.start, e.steps = t, nil
+	}
+
+	offset := t - e.start
+	if !e.cfg.Ensemble {
+		e.steps = e.steps[:offset]
+	}
+	for i, action := range chunk {
+		if offset+i == len(e.steps) {
+			e.steps = append(e.steps, prediction{sum: make(map[robot.MotorName]float64, len(action))})
+		}
+		p := &e.steps[offset+i]
+		w := math.Exp(-e.cfg.Decay * float64(p.n))
+		for m, v := range action {
+			p.sum[m] += w * v
+		}
+		p.weight += w
+		p.n++
+	}
+}
+
+// Next returns the action for step t, dropping those of earlier steps that
+// were not executed, or false if no action for t is queued.
+func (e *ChunkExecutor) Next(t int) (map[robot.MotorName]float64, bool) {
+	if skip := t - e.start; skip > 0 {
+		e.steps = e.steps[min(skip, len(e.steps)):]
+		e.start = t
+	}
+	if e.start != t || len(e.steps) == 0 {
+		return nil, false
+	}
+	p := e.steps[0]
+	e.steps = e.steps[1:]
+	e.start++
+
+	action := make(map[robot.MotorName]float64, len(p.sum))
+	for m, v := range p.sum {
+		action[m] = v / p.weight
+	}
+	return action, true
+}
@@ -0,0 +1,38 @@
+package teleop
+
+import (
+	"log/slog"
+
+	"github.com/gwillem/lerobot/pkg/logging"
+)
+
+// Event is a log event in structured form: its severity, the component and
+// motor it concerns, and the error, if any. See Controller.Events.
+type Event = logging.Event
+
+// eventSink delivers log events to the Events channel, and formatted as
+// lines to the Logs channel, dropping them while a channel is full.
+type eventSink struct {
+	events chan Event
+	lines  chan string
+}
+
+func newEventSink() eventSink {
+	return eventSink{events: make(chan Event, 10), lines: make(chan string, 10)}
+}
+
+// handler returns a handler that sends records at or above level.
+func (s eventSink) handler(level slog.Leveler) slog.Handler {
+	return logging.NewEventHandler(level, s.send)
+}
+
+func (s eventSink) send(e Event) {
+	select {
+	case s.events <- e:
+	default:
+	}
+	select {
+	case s.lines <- e.String():
+	default:
+	}
+}
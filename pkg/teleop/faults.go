@@ -0,0 +1,112 @@
+package teleop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// faultPeriod is how often the follower's servos are polled for hardware
+// faults. Overheating and overload build up over seconds, so this costs a
+// transaction per second rather than one per cycle.
+const faultPeriod = time.Second
+
+// FaultReader is implemented by arms that report servo hardware faults,
+// such as robot.Arm. Others are not polled.
+type FaultReader interface {
+	ReadFaults(ctx context.Context) (map[robot.MotorName]robot.ServoFault, error)
+}
+
+// StatusReader is implemented by arms that report the temperature and
+// voltage of their servos, such as robot.Arm. See Config.TelemetryHz.
+type StatusReader interface {
+	ReadStatus(ctx context.Context) []robot.ServoStatus
+}
+
+// telemetry is the latest temperature and voltage of the follower servos.
+type telemetry struct {
+	temperatures map[robot.MotorName]int
+	voltages     map[robot.MotorName]float64
+}
+
+// pollFaults reads the follower's faults and logs those that appear or
+// clear. It is scheduled every faultPeriod.
+func (c *Controller) pollFaults(ctx context.Context, fr FaultReader) error {
+	faults, err := fr.ReadFaults(ctx)
+	if err != nil {
+		return err
+	}
+	c.pollMu.Lock()
+	defer c.pollMu.Unlock()
+	for _, name := range slices.Sorted(maps.Keys(faults)) {
+		if f := faults[name]; f != c.faults[name] {
+			c.logger.Error("Servo fault", "component", "follower", "motor", name, "fault", f.String())
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.faults)) {
+		if _, ok := faults[name]; !ok {
+			c.logger.Info("Servo fault cleared", "component", "follower", "motor", name)
+		}
+	}
+	c.faults = faults
+	return nil
+}
+
+// pollTelemetry reads the temperature and voltage of the follower's
+// servos. Servos that don't answer are left out.
+func (c *Controller) pollTelemetry(ctx context.Context, sr StatusReader) error {
+	t := telemetry{
+		temperatures: make(map[robot.MotorName]int),
+		voltages:     make(map[robot.MotorName]float64),
+	}
+	var errs []error
+	for _, st := range sr.ReadStatus(ctx) {
+		if st.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", st.Motor, st.Err))
+			continue
+		}
+		t.temperatures[st.Motor] = st.Temperature
+		t.voltages[st.Motor] = st.Voltage
+	}
+	c.pollMu.Lock()
+	c.telemetry = t
+	c.pollMu.Unlock()
+	return errors.Join(errs...)
+}
+
+// polled returns copies of the latest faults and telemetry.
+func (c *Controller) polled() (map[robot.MotorName]robot.ServoFault, telemetry) {
+	c.pollMu.Lock()
+	defer c.pollMu.Unlock()
+	return maps.Clone(c.faults), telemetry{maps.Clone(c.telemetry.temperatures), maps.Clone(c.telemetry.voltages)}
+}
+
+// pollTasks returns the tasks polling the arms: the follower's faults,
+// telemetry at hz if positive, and retries of missing servos.
+func (c *Controller) pollTasks(hz float64) []Task {
+	var tasks []Task
+	if fr, ok := c.follower.(FaultReader); ok {
+		tasks = append(tasks, Task{Name: "faults", Hz: float64(time.Second / faultPeriod), Run: func(ctx context.Context) error {
+			return c.pollFaults(ctx, fr)
+		}})
+	}
+	if sr, ok := c.follower.(StatusReader); ok && hz > 0 {
+		tasks = append(tasks, Task{Name: "telemetry", Hz: hz, Run: func(ctx context.Context) error {
+			return c.pollTelemetry(ctx, sr)
+		}})
+	}
+	if t, ok := retryTask("follower missing", c.follower); ok {
+		tasks = append(tasks, t)
+	}
+	if c.leader != nil {
+		if t, ok := retryTask("leader missing", c.leader); ok {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,222 @@
+package teleop
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// HandFrame is one reading of a hand tracker, as sent to a Hand. A bridge
+// for the tracker, such as a Leap Motion or MediaPipe script, converts its
+// readings to these axes.
+type HandFrame struct {
+	Position robot.Vec3 `json:"position"` // of the palm in meters: x away from the operator, y to their left, z up
+	Pitch    float64    `json:"pitch"`    // of the hand in degrees, up positive
+	Roll     float64    `json:"roll"`     // of the hand in degrees, as wrist_roll turns
+	Grip     float64    `json:"grip"`     // 0 open to 1 closed, e.g. the pinch strength
+}
+
+// HandConfig configures a Hand.
+type HandConfig struct {
+	// Follower converts joint angles to its normalized positions.
+	Follower robot.ArmConfig
+	// Kinematics is the follower's model for inverse kinematics. Nil means
+	// robot.SO101Kinematics.
+	Kinematics *robot.Kinematics
+	// Scale is how far the gripper tip moves per meter of hand motion. 0
+	// means 1.
+	Scale float64
+}
+
+// Hand is an Input driven by a hand tracker over TCP, one JSON HandFrame
+// per line. Hand motion moves the follower's gripper tip by inverse
+// kinematics, relative to where the follower was when the tracker
+// connected: the first frame only anchors the hand. The follower holds its
+// pose at the edge of its reach. Once frames stop arriving for 250ms,
+// reading fails with ErrStale, so the watchdog holds and releases it.
+type Hand struct {
+	cfg   HandConfig
+	model robot.Kinematics
+	ln    net.Listener
+	parse func(line []byte) (f HandFrame, engaged bool, err error)
+
+	mu          sync.Mutex
+	conn        net.Conn
+	positions   map[robot.MotorName]float64 // nil until seeded
+	base        handBase                    // the follower where the hand was anchored
+	anchor      *HandFrame                  // first frame since engaging
+	unreachable bool
+	updated     time.Time // of the last frame
+	err         error     // reported once
+}
+
+// handBase is the pose of the follower the hand moves relative to.
+type handBase struct {
+	tip   robot.Vec3
+	pitch float64
+	roll  float64 // wrist_roll in degrees
+}
+
+// ListenHand listens for a hand tracker on the TCP address addr. One
+// tracker is served at a time.
+func ListenHand(addr string, cfg HandConfig) (*Hand, error) {
+	return listenHand(addr, cfg, parseHandFrame)
+}
+
+func parseHandFrame(line []byte) (HandFrame, bool, error) {
+	var f HandFrame
+	err := json.Unmarshal(line, &f)
+	return f, true, err
+}
+
+func listenHand(addr string, cfg HandConfig, parse func([]byte) (HandFrame, bool, error)) (*Hand, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("hand: %w", err)
+	}
+	h := &Hand{cfg: cfg, model: robot.SO101Kinematics(), ln: ln, parse: parse}
+	if cfg.Kinematics != nil {
+		h.model = *cfg.Kinematics
+	}
+	if h.cfg.Scale == 0 {
+		h.cfg.Scale = 1
+	}
+	go h.accept()
+	return h, nil
+}
+
+// Addr returns the address the Hand listens on.
+func (h *Hand) Addr() net.Addr {
+	return h.ln.Addr()
+}
+
+func (h *Hand) accept() {
+	for {
+		conn, err := h.ln.Accept()
+		if err != nil {
+			return
+		}
+		h.serve(conn)
+	}
+}
+
+// serve reads frames from conn until it closes, then re-anchors at the
+// current pose so the next tracker continues from there.
+func (h *Hand) serve(conn net.Conn) {
+	h.mu.Lock()
+	h.conn = conn
+	h.mu.Unlock()
+	defer func() {
+		conn.Close()
+		h.mu.Lock()
+		h.conn = nil
+		h.rebase(h.positions)
+		h.mu.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		f, engaged, err := h.parse(scanner.Bytes())
+		if err != nil {
+			h.mu.Lock()
+			h.err = fmt.Errorf("hand: %w", err)
+			h.mu.Unlock()
+			continue
+		}
+		h.mu.Lock()
+		h.updated = time.Now()
+		h.update(f, engaged)
+		h.mu.Unlock()
+	}
+}
+
+// update moves the targets by the hand's motion since the anchor. Without
+// engaged, the follower holds and the hand is anchored anew when engaged
+// again. It is called with mu held.
+func (h *Hand) update(f HandFrame, engaged bool) {
+	if h.positions == nil {
+		return // not seeded yet
+	}
+	if _, ok := h.positions[robot.Gripper]; ok {
+		h.positions[robot.Gripper] = 100 - 200*max(0, min(1, f.Grip))
+	}
+	if !engaged {
+		if h.anchor != nil {
+			h.rebase(h.positions)
+		}
+		return
+	}
+	if h.anchor == nil {
+		h.anchor = &f
+		return
+	}
+	moved := f.Position
+	for i := range moved {
+		moved[i] = h.base.tip[i] + h.cfg.Scale*(f.Position[i]-h.anchor.Position[i])
+	}
+	degrees, err := h.model.Inverse(moved, h.base.pitch+f.Pitch-h.anchor.Pitch)
+	if err != nil {
+		if !h.unreachable {
+			h.err = fmt.Errorf("hand: %w", err)
+		}
+		h.unreachable = true
+		return
+	}
+	h.unreachable = false
+	degrees[robot.WristRoll] = h.base.roll + f.Roll - h.anchor.Roll
+
+	maps.Copy(h.positions, h.cfg.Follower.Positions(degrees))
+}
+
+// rebase anchors the hand anew at positions. It is called with mu held.
+func (h *Hand) rebase(positions map[robot.MotorName]float64) {
+	degrees := h.cfg.Follower.Degrees(positions)
+	pose := h.model.Forward(degrees)
+	h.base = handBase{tip: pose.Tip, pitch: pose.Pitch, roll: degrees[robot.WristRoll]}
+	h.anchor = nil
+}
+
+// Seed sets the follower pose the hand starts from.
+func (h *Hand) Seed(positions map[robot.MotorName]float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.positions = maps.Clone(positions)
+	h.rebase(positions)
+}
+
+// ReadPositions returns the current targets. A frame that couldn't be
+// parsed or reached is reported once, and a tracker gone quiet with
+// ErrStale.
+func (h *Hand) ReadPositions(ctx context.Context) (map[robot.MotorName]float64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.positions == nil {
+		return nil, errNotSeeded
+	}
+	if err := h.err; err != nil {
+		h.err = nil
+		return nil, err
+	}
+	if err := checkStale(h.updated); err != nil {
+		return nil, fmt.Errorf("hand: %w", err)
+	}
+	return maps.Clone(h.positions), nil
+}
+
+// Close stops listening and disconnects the tracker.
+func (h *Hand) Close() error {
+	err := h.ln.Close()
+	h.mu.Lock()
+	if h.conn != nil {
+		h.conn.Close()
+	}
+	h.mu.Unlock()
+	return err
+}
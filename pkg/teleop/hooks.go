@@ -0,0 +1,154 @@
+package teleop
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// ErrEmergencyStop is returned by Start after Controller.EmergencyStop.
+var ErrEmergencyStop = errors.New("emergency stop")
+
+// Hooks are called on events of a Controller, so a program embedding it
+// can react, e.g. light a lamp while the follower is live, without parsing
+// Logs. Each is optional. They are called from the goroutine the event
+// happens on, often the control loop, so they must return quickly.
+type Hooks struct {
+	// OnStart is called when the control loop starts, with the follower's
+	// torque on.
+	OnStart func()
+	// OnStop is called when the control loop has stopped and the follower's
+	// torque is off, with the error Start returns.
+	OnStop func(err error)
+	// OnError is called on the first of a run of failed reads or writes of
+	// an arm, with "leader" or "follower", and with "controller" for the
+	// error that stops the loop or keeps it from starting.
+	OnError func(component string, err error)
+	// OnEStop is called on Controller.EmergencyStop.
+	OnEStop func()
+	// OnEpisodeStart and OnEpisodeEnd are called when a recorder reports
+	// an episode with Controller.EpisodeStarted and EpisodeEnded.
+	OnEpisodeStart func(index int)
+	OnEpisodeEnd   func(index int, saved bool)
+	// OnTrigger is called when the Config.Trigger joint presses the
+	// trigger, and when it releases it.
+	OnTrigger func(pressed bool)
+}
+
+func (h Hooks) start() {
+	if h.OnStart != nil {
+		h.OnStart()
+	}
+}
+
+func (h Hooks) stop(err error) {
+	if h.OnStop != nil {
+		h.OnStop(err)
+	}
+}
+
+func (h Hooks) error(component string, err error) {
+	if h.OnError != nil {
+		h.OnError(component, err)
+	}
+}
+
+// checkTrigger presses or releases the trigger with the leader positions,
+// reporting the change to Hooks.OnTrigger.
+func (c *Controller) checkTrigger(positions map[robot.MotorName]float64) {
+	name := c.trigger.Joint()
+	pos, ok := positions[name]
+	if !ok {
+		return
+	}
+	pressed := c.trigger.Pressed(pos, c.triggered)
+	if pressed == c.triggered {
+		return
+	}
+	c.triggered = pressed
+	msg := "Trigger released"
+	if pressed {
+		msg = "Trigger pressed"
+	}
+	c.logger.Debug(msg, "component", "leader", "motor", name)
+	if pressed && c.trigger.Action == robot.TriggerTool {
+		c.switchTool()
+	}
+	if c.hooks.OnTrigger != nil {
+		c.hooks.OnTrigger(pressed)
+	}
+}
+
+// switchTool runs the trigger's tool command to switch the tool on or off,
+// see robot.Trigger.Command. It runs off the control loop, and presses
+// while it runs are ignored.
+func (c *Controller) switchTool() {
+	if !c.toolBusy.CompareAndSwap(false, true) {
+		return
+	}
+	c.toolOn = !c.toolOn
+	state := "off"
+	if c.toolOn {
+		state = "on"
+	}
+	command := c.trigger.Command
+	go func() {
+		defer c.toolBusy.Store(false)
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Env = append(os.Environ(), "LEROBOT_TOOL="+state)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			c.logger.Error("Tool command failed", "tool", state, "error", err, "output", strings.TrimSpace(string(out)))
+			return
+		}
+		c.logger.Info("Tool switched "+state, "tool", state)
+	}()
+}
+
+// EmergencyStop stops the control loop within a cycle and disables the
+// follower's torque without parking it first, so the arm drops under
+// gravity. The controller stays in ModeEStopped, where Start returns
+// ErrEmergencyStop, until ResetEStop.
+func (c *Controller) EmergencyStop() {
+	c.mu.Lock()
+	if c.mode == ModeEStopped {
+		c.mu.Unlock()
+		return
+	}
+	c.setModeLocked(ModeEStopped)
+	close(c.estop)
+	c.mu.Unlock()
+
+	c.logger.Warn("Emergency stop", "component", "controller")
+	if c.hooks.OnEStop != nil {
+		c.hooks.OnEStop()
+	}
+}
+
+// EpisodeStarted switches from ModeTeleop to ModeRecord when a recorder
+// starts episode index, and reports it to Hooks.OnEpisodeStart.
+func (c *Controller) EpisodeStarted(index int) error {
+	if err := c.setMode(ModeRecord); err != nil {
+		return err
+	}
+	if c.hooks.OnEpisodeStart != nil {
+		c.hooks.OnEpisodeStart(index)
+	}
+	return nil
+}
+
+// EpisodeEnded switches back to ModeTeleop when a recorder ended episode
+// index, and reports to Hooks.OnEpisodeEnd whether it was saved or
+// discarded. The mode is left alone if the loop stopped meanwhile.
+func (c *Controller) EpisodeEnded(index int, saved bool) {
+	c.mu.Lock()
+	if c.mode == ModeRecord {
+		c.setModeLocked(ModeTeleop)
+	}
+	c.mu.Unlock()
+	if c.hooks.OnEpisodeEnd != nil {
+		c.hooks.OnEpisodeEnd(index, saved)
+	}
+}
@@ -0,0 +1,72 @@
+package teleop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// Input is a source of follower targets other than a leader arm, such as
+// the keyboard. Positions are normalized like robot.Arm.ReadPositions and
+// go through the same soft start, guards and watchdog as a leader's.
+type Input interface {
+	ReadPositions(ctx context.Context) (map[robot.MotorName]float64, error)
+	Close() error
+}
+
+// Seeder is implemented by inputs that move relative to a starting pose,
+// such as Keyboard. Start seeds them with the follower's pose, so the
+// follower doesn't jump when torque comes on.
+type Seeder interface {
+	Seed(positions map[robot.MotorName]float64)
+}
+
+// errNotSeeded is returned by inputs read before they are seeded.
+var errNotSeeded = errors.New("no starting pose")
+
+// ErrStale is returned by inputs whose source stopped sending, such as a
+// hand tracker that froze, so the watchdog holds and then releases the
+// follower instead of it following the last pose forever.
+var ErrStale = errors.New("no fresh input")
+
+// staleAfter is how long the latest update of an input stays fresh.
+const staleAfter = defaultWatchdogHold
+
+// checkStale returns ErrStale if the input's latest update, at updated,
+// is older than staleAfter. The zero time means there was no update since
+// it started, which leaves the starting pose held.
+func checkStale(updated time.Time) error {
+	if updated.IsZero() {
+		return nil
+	}
+	if age := time.Since(updated); age > staleAfter {
+		return fmt.Errorf("%w for %v", ErrStale, age.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// seedInput seeds the input with the follower's pose if it is a Seeder.
+func (c *Controller) seedInput(ctx context.Context) error {
+	s, ok := c.input.(Seeder)
+	if !ok {
+		return nil
+	}
+	pose, err := c.follower.ReadPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("read follower pose: %w", err)
+	}
+	s.Seed(pose)
+	return nil
+}
+
+// readLeaderPositions reads the normalized positions of the leader, or of
+// the input that replaces it.
+func (c *Controller) readLeaderPositions(ctx context.Context) (map[robot.MotorName]float64, error) {
+	if c.input != nil {
+		return c.input.ReadPositions(ctx)
+	}
+	return c.leader.ReadPositions(ctx)
+}
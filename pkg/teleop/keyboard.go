@@ -0,0 +1,96 @@
+package teleop
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// DefaultKeyboardStep is how far Keyboard.Nudge moves a joint by default,
+// in normalized units.
+const DefaultKeyboardStep = 2.0
+
+// Keyboard is an Input driven by key presses instead of a leader arm: one
+// joint is selected at a time and nudged up or down by a fixed step. It
+// starts from the follower's pose, see Seeder. The keys themselves are read
+// by the caller, such as the teleoperate TUI.
+type Keyboard struct {
+	motors []robot.MotorName
+	step   float64
+
+	mu        sync.Mutex
+	selected  int
+	positions map[robot.MotorName]float64 // nil until seeded
+}
+
+// NewKeyboard returns a Keyboard for motors that moves step per nudge. A
+// step of 0 means DefaultKeyboardStep.
+func NewKeyboard(motors []robot.MotorName, step float64) *Keyboard {
+	if step <= 0 {
+		step = DefaultKeyboardStep
+	}
+	return &Keyboard{motors: motors, step: step}
+}
+
+// Select selects the i-th motor, counting from 0. It reports whether there
+// is one.
+func (k *Keyboard) Select(i int) bool {
+	if i < 0 || i >= len(k.motors) {
+		return false
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.selected = i
+	return true
+}
+
+// Selected returns the selected motor.
+func (k *Keyboard) Selected() robot.MotorName {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.motors) == 0 {
+		return ""
+	}
+	return k.motors[k.selected]
+}
+
+// Nudge moves the selected motor by steps times the step, within -100 to
+// 100. It does nothing before the Keyboard is seeded.
+func (k *Keyboard) Nudge(steps int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.positions == nil || len(k.motors) == 0 {
+		return
+	}
+	name := k.motors[k.selected]
+	k.positions[name] = max(-100, min(100, k.positions[name]+float64(steps)*k.step))
+}
+
+// Seed sets the pose to nudge from.
+func (k *Keyboard) Seed(positions map[robot.MotorName]float64) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.positions = make(map[robot.MotorName]float64, len(k.motors))
+	for _, name := range k.motors {
+		if pos, ok := positions[name]; ok {
+			k.positions[name] = pos
+		}
+	}
+}
+
+// ReadPositions returns the current targets.
+func (k *Keyboard) ReadPositions(ctx context.Context) (map[robot.MotorName]float64, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.positions == nil {
+		return nil, errNotSeeded
+	}
+	return maps.Clone(k.positions), nil
+}
+
+// Close does nothing.
+func (k *Keyboard) Close() error {
+	return nil
+}
@@ -0,0 +1,70 @@
+package teleop
+
+import (
+	"slices"
+	"time"
+)
+
+// metricsWindow is the number of recent cycles latency percentiles are
+// computed over.
+const metricsWindow = 512
+
+// Latency summarizes a latency distribution.
+type Latency struct {
+	P50 time.Duration
+	P95 time.Duration
+	Max time.Duration
+}
+
+// Metrics describes control loop timing over the most recent cycles.
+type Metrics struct {
+	Read        Latency // leader read
+	Write       Latency // follower write
+	Cycle       Latency // full step, including optional follower read-back; with Pipeline from leader read to follower done
+	Cycles      int     // total cycles run
+	MissedTicks int     // total ticks skipped because a cycle overran its period
+	Dropped     int     // total leader samples the follower had no time for, with Config.Pipeline
+	Overruns    int     // total cycles that took longer than the period
+
+	Hz          int     // current target rate, lowered under OverrunDegrade
+	EffectiveHz float64 // cycles per second actually run over the last second
+
+	Tasks []TaskStats // tasks run beside the loop, see Config.Tasks
+}
+
+// latencies is a fixed-size ring buffer of samples.
+type latencies struct {
+	samples []time.Duration
+	next    int
+}
+
+func (l *latencies) add(d time.Duration) {
+	if len(l.samples) < metricsWindow {
+		l.samples = append(l.samples, d)
+		return
+	}
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % metricsWindow
+}
+
+func (l *latencies) summary() Latency {
+	if len(l.samples) == 0 {
+		return Latency{}
+	}
+	sorted := slices.Clone(l.samples)
+	slices.Sort(sorted)
+	at := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+	return Latency{P50: at(0.50), P95: at(0.95), Max: sorted[len(sorted)-1]}
+}
+
+// missedTicks returns how many ticks of the given period were skipped
+// between two consecutive ticks elapsed apart. time.Ticker drops ticks
+// when the receiver is slow, so a long gap means the loop overran.
+func missedTicks(elapsed, period time.Duration) int {
+	if elapsed < period*3/2 {
+		return 0
+	}
+	return int((elapsed+period/2)/period) - 1
+}
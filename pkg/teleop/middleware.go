@@ -0,0 +1,93 @@
+package teleop
+
+import (
+	"maps"
+	"math"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// Frame is a control cycle as middleware sees it: the leader positions as
+// read, and the follower targets derived from them so far.
+type Frame struct {
+	Time    time.Time                   // when the leader was read
+	Leader  map[robot.MotorName]float64 // leader positions, not to be modified
+	Targets map[robot.MotorName]float64 // follower targets, nil writes nothing
+}
+
+// Middleware transforms the follower targets of every cycle, e.g. to
+// filter, scale or log them. Middleware runs in the order it was added,
+// on the control loop, so it must be quick. It should return a new
+// Targets map rather than modify the one it is given. The grip force
+// limit, pausing and the workspace and collision checks apply after it.
+type Middleware func(Frame) Frame
+
+// Mirror inverts shoulder_pan and wrist_roll, so the follower moves as
+// the leader's mirror image, e.g. when the arms face each other. It is
+// the first middleware with Config.Mirror.
+func Mirror() Middleware {
+	return func(f Frame) Frame {
+		targets := maps.Clone(f.Targets)
+		for _, name := range mirrorMotors {
+			if pos, ok := targets[name]; ok {
+				targets[name] = -pos
+			}
+		}
+		f.Targets = targets
+		return f
+	}
+}
+
+// MapJoints applies a JointMapping per motor to the targets. It follows
+// Mirror with Config.Mapping.
+func MapJoints(mapping map[robot.MotorName]robot.JointMapping) Middleware {
+	return func(f Frame) Frame {
+		f.Targets = applyMapping(f.Targets, mapping)
+		return f
+	}
+}
+
+// Smooth low-pass filters the targets of every motor with a first-order
+// filter of the given cutoff frequency in Hz, taking out leader jitter at
+// the cost of lag, about 1/(2π·cutoff): 16ms at 10 Hz. It follows
+// MapJoints with Config.Smoothing.
+func Smooth(cutoff float64) Middleware {
+	var last map[robot.MotorName]float64
+	var at time.Time
+	return func(f Frame) Frame {
+		if f.Targets == nil {
+			return f
+		}
+		targets := maps.Clone(f.Targets)
+		alpha := 1 - math.Exp(-2*math.Pi*cutoff*f.Time.Sub(at).Seconds())
+		for name, pos := range targets {
+			if prev, ok := last[name]; ok {
+				targets[name] = prev + max(alpha, 0)*(pos-prev)
+			}
+		}
+		last, at = targets, f.Time
+		f.Targets = targets
+		return f
+	}
+}
+
+// Use adds middleware after that already added, also while running.
+func (c *Controller) Use(mw ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middleware = append(c.middleware, mw...)
+}
+
+// transform runs the middleware on leader positions read at t, and
+// returns the follower targets with their trims.
+func (c *Controller) transform(t time.Time, positions map[robot.MotorName]float64) map[robot.MotorName]float64 {
+	c.mu.RLock()
+	chain, trims := c.middleware, c.trims
+	c.mu.RUnlock()
+	f := Frame{Time: t, Leader: positions, Targets: positions}
+	for _, mw := range chain {
+		f = mw(f)
+	}
+	return applyTrims(f.Targets, trims)
+}
@@ -0,0 +1,57 @@
+package teleop
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// missingRetry is how often missing servos are tried again. Each one still
+// missing costs a bus timeout, so this runs beside the control loop.
+const missingRetry = time.Second
+
+// MissingReporter is implemented by arms that can carry on without servos
+// that stop responding, such as robot.Arm. The controller turns this on,
+// so one bad servo only stops its own joint.
+type MissingReporter interface {
+	TolerateMissing(on bool)
+	Missing() []robot.MotorName
+	RetryMissing(ctx context.Context)
+}
+
+// retryTask returns the task retrying the missing servos of arm, or false
+// if arm doesn't report them.
+func retryTask(name string, arm any) (Task, bool) {
+	mr, ok := arm.(MissingReporter)
+	if !ok {
+		return Task{}, false
+	}
+	return Task{Name: name, Hz: float64(time.Second / missingRetry), Run: func(ctx context.Context) error {
+		mr.RetryMissing(ctx)
+		return nil
+	}}, true
+}
+
+// checkMissing logs the servos of arm that stopped or started responding
+// since the last check, remembered in last, and returns those missing now.
+func (c *Controller) checkMissing(component string, arm any, last *[]robot.MotorName) []robot.MotorName {
+	mr, ok := arm.(MissingReporter)
+	if !ok {
+		return nil
+	}
+	missing := mr.Missing()
+	for _, name := range missing {
+		if !slices.Contains(*last, name) {
+			c.logger.Error("Servo not responding, continuing without it", "component", component, "motor", name)
+		}
+	}
+	for _, name := range *last {
+		if !slices.Contains(missing, name) {
+			c.logger.Info("Servo responding again", "component", component, "motor", name)
+		}
+	}
+	*last = missing
+	return missing
+}
@@ -0,0 +1,104 @@
+package teleop
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Mode is what a Controller is doing. It starts Idle; Start drives the
+// follower in ModeTeleop until the loop stops.
+type Mode int
+
+const (
+	ModeIdle     Mode = iota // the loop is not running
+	ModeTeleop               // the follower follows the leader or an Input
+	ModeRecord               // as ModeTeleop, while a recorder records an episode
+	ModeEStopped             // after EmergencyStop, until ResetEStop
+)
+
+var modeNames = [...]string{"idle", "teleop", "record", "estopped"}
+
+func (m Mode) String() string {
+	if m < 0 || int(m) >= len(modeNames) {
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+	return modeNames[m]
+}
+
+// modeTransitions lists the modes each mode may change to. Any running
+// mode can stop or be emergency stopped; recording is only possible
+// during teleoperation.
+var modeTransitions = map[Mode][]Mode{
+	ModeIdle:     {ModeTeleop, ModeEStopped},
+	ModeTeleop:   {ModeRecord, ModeIdle, ModeEStopped},
+	ModeRecord:   {ModeTeleop, ModeIdle, ModeEStopped},
+	ModeEStopped: {ModeIdle},
+}
+
+// ErrModeTransition is returned for a change of mode that isn't allowed,
+// such as recording while idle.
+var ErrModeTransition = errors.New("invalid mode change")
+
+// ModeChange is a change of a Controller's mode, see Controller.Modes.
+type ModeChange struct {
+	From, To Mode
+	Time     time.Time
+}
+
+// Mode returns the controller's current mode.
+func (c *Controller) Mode() Mode {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.mode
+}
+
+// Modes returns a channel that receives every change of mode. Changes are
+// dropped while the channel is full.
+func (c *Controller) Modes() <-chan ModeChange {
+	return c.modeCh
+}
+
+// setMode changes the mode to to if that is allowed from the current one.
+func (c *Controller) setMode(to Mode) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.setModeLocked(to)
+}
+
+func (c *Controller) setModeLocked(to Mode) error {
+	from := c.mode
+	if !slices.Contains(modeTransitions[from], to) {
+		return fmt.Errorf("%w: %s to %s", ErrModeTransition, from, to)
+	}
+	c.mode = to
+	c.logger.Info("Mode changed", "component", "controller", "from", from, "to", to)
+	select {
+	case c.modeCh <- ModeChange{From: from, To: to, Time: time.Now()}:
+	default:
+	}
+	return nil
+}
+
+// stopped returns to ModeIdle after the loop stopped, unless it was
+// emergency stopped.
+func (c *Controller) stopped() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mode != ModeIdle && c.mode != ModeEStopped {
+		c.setModeLocked(ModeIdle)
+	}
+}
+
+// ResetEStop returns to ModeIdle after EmergencyStop, so Start can run the
+// loop again.
+func (c *Controller) ResetEStop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.setModeLocked(ModeIdle); err != nil {
+		return err
+	}
+	c.estop = make(chan struct{})
+	return nil
+}
@@ -0,0 +1,235 @@
+package teleop
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// Observation is one reading of a sensor, a value per named channel such
+// as "fx", "fy" and "fz" of a force-torque sensor.
+type Observation map[string]float64
+
+// Observer is a custom source of observations, such as a force-torque
+// sensor, an extra encoder or a scale, read every control cycle and
+// reported in State.Observations, so they are recorded with the arms.
+type Observer interface {
+	// Name identifies the sensor, e.g. in State.Observations.
+	Name() string
+	// Read returns the latest reading. It's called from the control loop
+	// and should return right away: a sensor slower than the loop should
+	// be polled in the background.
+	Read(ctx context.Context) (Observation, error)
+}
+
+// ObserverFactory opens an observer named name, configured by arg, such as
+// a port.
+type ObserverFactory func(name, arg string) (Observer, error)
+
+var (
+	observersMu sync.Mutex
+	observers   = map[string]ObserverFactory{
+		"serial": openSerialObserver,
+	}
+)
+
+// RegisterObserver makes a kind of observer available to OpenObserver, so
+// programs can add their own sensors to the record command's --sensor.
+// It replaces an earlier registration of kind.
+func RegisterObserver(kind string, open ObserverFactory) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	observers[kind] = open
+}
+
+// ObserverKinds returns the registered kinds of observers, sorted.
+func ObserverKinds() []string {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	return slices.Sorted(maps.Keys(observers))
+}
+
+// OpenObserver opens an observer from a spec of the form name=kind:arg,
+// such as "scale=serial:/dev/ttyUSB0@9600".
+func OpenObserver(spec string) (Observer, error) {
+	name, rest, ok := strings.Cut(spec, "=")
+	kind, arg, _ := strings.Cut(rest, ":")
+	if !ok || name == "" || kind == "" {
+		return nil, fmt.Errorf("invalid sensor %q, want name=kind:arg", spec)
+	}
+	observersMu.Lock()
+	open, ok := observers[kind]
+	observersMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown sensor kind %q, want one of %s", kind, strings.Join(ObserverKinds(), ", "))
+	}
+	obs, err := open(name, arg)
+	if err != nil {
+		return nil, fmt.Errorf("open sensor %s: %w", name, err)
+	}
+	return obs, nil
+}
+
+// observerSet reads the observers of a Controller or Puppet.
+type observerSet struct {
+	observers []Observer
+	failing   map[string]bool // observers whose last read failed
+}
+
+func newObserverSet(observers []Observer) observerSet {
+	return observerSet{observers: observers, failing: make(map[string]bool)}
+}
+
+// read reads every observer, logging those that start or stop failing,
+// and returns the readings of those that succeeded.
+func (s *observerSet) read(ctx context.Context, logger *slog.Logger) map[string]Observation {
+	if len(s.observers) == 0 {
+		return nil
+	}
+	readings := make(map[string]Observation, len(s.observers))
+	for _, o := range s.observers {
+		obs, err := o.Read(ctx)
+		name := o.Name()
+		if err != nil {
+			if !s.failing[name] {
+				logger.Warn("Sensor read failed", "component", name, "error", err)
+			}
+			s.failing[name] = true
+			continue
+		}
+		if s.failing[name] {
+			logger.Info("Sensor reading again", "component", name)
+			delete(s.failing, name)
+		}
+		readings[name] = obs
+	}
+	return readings
+}
+
+// close closes the observers that are an io.Closer.
+func (s *observerSet) close() []error {
+	var errs []error
+	for _, o := range s.observers {
+		if c, ok := o.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+const (
+	// lineObserverBaud is the default baud rate of serial sensors.
+	lineObserverBaud = 115200
+	// lineObserverStale is how long a line sensor may stay silent before
+	// its last reading is no longer reported.
+	lineObserverStale = time.Second
+)
+
+var errNoReading = errors.New("no reading yet")
+
+// lineObserver reads a sensor that prints a line per reading, such as a
+// microcontroller on a serial port. See parseObservation for the format.
+type lineObserver struct {
+	name string
+	rc   io.ReadCloser
+
+	mu     sync.Mutex
+	latest Observation
+	at     time.Time
+	err    error
+}
+
+// openSerialObserver opens a line sensor on a serial port, given as
+// port@baud or just port for 115200 baud.
+func openSerialObserver(name, arg string) (Observer, error) {
+	port, baud := arg, lineObserverBaud
+	if p, b, ok := strings.Cut(arg, "@"); ok {
+		n, err := strconv.Atoi(b)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid baud rate %q", b)
+		}
+		port, baud = p, n
+	}
+	if port == "" {
+		return nil, errors.New("no serial port, want port@baud")
+	}
+	sp, err := serial.Open(port, &serial.Mode{BaudRate: baud})
+	if err != nil {
+		return nil, err
+	}
+	return newLineObserver(name, sp), nil
+}
+
+func newLineObserver(name string, rc io.ReadCloser) *lineObserver {
+	o := &lineObserver{name: name, rc: rc, err: errNoReading}
+	go o.run()
+	return o
+}
+
+func (o *lineObserver) run() {
+	scanner := bufio.NewScanner(o.rc)
+	for scanner.Scan() {
+		obs, err := parseObservation(scanner.Text())
+		if err != nil || len(obs) == 0 {
+			continue // e.g. half a line when we started, or a banner
+		}
+		o.mu.Lock()
+		o.latest, o.at, o.err = obs, time.Now(), nil
+		o.mu.Unlock()
+	}
+	o.mu.Lock()
+	o.err = cmp.Or(scanner.Err(), io.EOF)
+	o.mu.Unlock()
+}
+
+func (o *lineObserver) Name() string { return o.name }
+
+func (o *lineObserver) Read(ctx context.Context) (Observation, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err != nil {
+		return nil, o.err
+	}
+	if since := time.Since(o.at); since > lineObserverStale {
+		return nil, fmt.Errorf("no reading for %s", since.Round(time.Second))
+	}
+	return o.latest, nil
+}
+
+func (o *lineObserver) Close() error { return o.rc.Close() }
+
+// parseObservation parses a line of key=value pairs separated by spaces or
+// commas, such as "fx=0.12, fy=-3.4". Bare numbers are named by their
+// position from 0, so "512 498" reads as 0=512 1=498.
+func parseObservation(line string) (Observation, error) {
+	fields := strings.FieldsFunc(line, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r'
+	})
+	obs := make(Observation, len(fields))
+	for i, f := range fields {
+		key, value, ok := strings.Cut(f, "=")
+		if !ok {
+			key, value = strconv.Itoa(i), f
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q", f)
+		}
+		obs[key] = v
+	}
+	return obs, nil
+}
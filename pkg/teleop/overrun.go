@@ -0,0 +1,96 @@
+package teleop
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OverrunPolicy decides what the control loop does when cycles take longer
+// than the control period, e.g. with a slow USB serial adapter.
+type OverrunPolicy string
+
+const (
+	// OverrunSkip drops the ticks that passed during a slow cycle and
+	// carries on at the configured rate. This is the default.
+	OverrunSkip OverrunPolicy = "skip"
+
+	// OverrunDegrade lowers the control frequency to one the cycles fit in.
+	OverrunDegrade OverrunPolicy = "degrade"
+
+	// OverrunError stops the control loop with ErrOverrun.
+	OverrunError OverrunPolicy = "error"
+)
+
+// ErrOverrun is returned by Start under OverrunError when cycles keep
+// taking longer than the control period.
+var ErrOverrun = errors.New("control loop overrun")
+
+const (
+	// overrunLimit is the number of consecutive overrunning cycles before
+	// the degrade and error policies act, so a single slow cycle is ignored.
+	overrunLimit = 5
+
+	// overrunHeadroom is the fraction of a degraded period a cycle may use.
+	overrunHeadroom = 0.8
+)
+
+// rateWindow is how often the effective control rate is recomputed.
+const rateWindow = time.Second
+
+// degradedHz returns the highest rate below hz whose period fits a cycle of
+// the given duration with some headroom, and at least 1.
+func degradedHz(hz int, cycle time.Duration) int {
+	fit := int(overrunHeadroom * float64(time.Second) / float64(cycle))
+	return max(1, min(fit, hz-1))
+}
+
+// checkOverrun applies the overrun policy after a cycle that took the given
+// time. It returns the new control frequency, or 0 to keep the current one.
+func (c *Controller) checkOverrun(cycle time.Duration) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	period := time.Second / time.Duration(c.hz)
+	if cycle <= period {
+		c.consecutiveOverruns = 0
+		return 0, nil
+	}
+	c.overruns++
+	c.consecutiveOverruns++
+	if c.consecutiveOverruns < overrunLimit {
+		return 0, nil
+	}
+	c.consecutiveOverruns = 0
+
+	switch c.overrunPolicy {
+	case OverrunDegrade:
+		if c.hz == 1 {
+			return 0, nil
+		}
+		hz := degradedHz(c.hz, cycle)
+		c.logger.Warn("Cycles overrun, lowering rate", "component", "controller", "cycle", cycle, "from_hz", c.hz, "to_hz", hz)
+		c.hz = hz
+		return hz, nil
+	case OverrunError:
+		return 0, fmt.Errorf("%w: %d cycles took longer than %s (last %s)", ErrOverrun, overrunLimit, period, cycle)
+	default:
+		return 0, nil
+	}
+}
+
+// countCycle updates the effective control rate, measured over rateWindow.
+func (c *Controller) countCycle(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rateStart.IsZero() {
+		c.rateStart = now
+		return
+	}
+	c.rateCycles++
+	if elapsed := now.Sub(c.rateStart); elapsed >= rateWindow {
+		c.effectiveHz = float64(c.rateCycles) / elapsed.Seconds()
+		c.rateStart = now
+		c.rateCycles = 0
+	}
+}
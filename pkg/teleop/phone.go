@@ -0,0 +1,262 @@
+package teleop
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"math"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+//go:embed phone.html
+var phoneHTML []byte
+
+// phoneReading is what the phone page sends over its WebSocket, about 30
+// times a second: the DeviceOrientationEvent angles in degrees, whether
+// the move button is held, and the gripper slider from 0 open to 1 closed.
+type phoneReading struct {
+	Alpha   float64 `json:"alpha"` // about the screen's normal, 0 to 360
+	Beta    float64 `json:"beta"`  // tilt forward and back, -180 to 180
+	Gamma   float64 `json:"gamma"` // tilt left and right, -90 to 90
+	Engaged bool    `json:"engaged"`
+	Grip    float64 `json:"grip"`
+}
+
+// PhoneConfig configures a Phone.
+type PhoneConfig struct {
+	// Follower converts joint angles to its normalized positions.
+	Follower robot.ArmConfig
+	// Gain is how many degrees a joint turns per degree the phone turns.
+	// 0 means 1, negative reverses all joints.
+	Gain float64
+}
+
+// Phone is an Input driven by the motion sensors of a phone. It serves a
+// web page over HTTPS, which browsers require for the sensors, with a
+// self-signed certificate. While the page's move button is held, turning
+// the phone about its screen's normal turns shoulder_pan, tilting it
+// forward and back bends wrist_flex, and tilting it sideways turns
+// wrist_roll, relative to where they were when the button was pressed. A
+// slider on the page closes the gripper.
+//
+// Anyone on the network could open the page, so it only connects with the
+// pairing token in its URL, see URL, and only one phone is in control at a
+// time.
+type Phone struct {
+	cfg   PhoneConfig
+	ln    net.Listener
+	srv   *http.Server
+	token string
+
+	mu        sync.Mutex
+	ws        *websocket.Conn             // the phone in control, if any
+	positions map[robot.MotorName]float64 // nil until seeded
+	base      map[robot.MotorName]float64 // joint degrees at the anchor
+	anchor    *phoneReading               // when the move button was pressed
+	updated   time.Time                   // of the last reading
+}
+
+// phoneJoints are the joints the phone turns.
+var phoneJoints = []robot.MotorName{robot.ShoulderPan, robot.WristFlex, robot.WristRoll}
+
+// ListenPhone serves the phone page on the TCP address addr.
+func ListenPhone(addr string, cfg PhoneConfig) (*Phone, error) {
+	cert, err := selfSignedCert()
+	if err != nil {
+		return nil, fmt.Errorf("phone: %w", err)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("phone: %w", err)
+	}
+	if cfg.Gain == 0 {
+		cfg.Gain = 1
+	}
+	p := &Phone{cfg: cfg, ln: ln, token: rand.Text()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(phoneHTML)
+	})
+	mux.Handle("GET /ws", websocket.Server{Handshake: p.handshake, Handler: p.serve})
+	p.srv = &http.Server{
+		Handler:   mux,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		// Browsers reject the certificate until it is accepted, which
+		// would otherwise be logged on every attempt
+		ErrorLog: log.New(io.Discard, "", 0),
+	}
+	go p.srv.ServeTLS(ln, "", "")
+	return p, nil
+}
+
+// Addr returns the address the Phone listens on.
+func (p *Phone) Addr() net.Addr {
+	return p.ln.Addr()
+}
+
+// URL returns the address of the page for host, such as the phone's view
+// of this computer's IP address, with the pairing token.
+func (p *Phone) URL(host string) string {
+	port := p.ln.Addr().(*net.TCPAddr).Port
+	return fmt.Sprintf("https://%s/?token=%s", net.JoinHostPort(host, strconv.Itoa(port)), p.token)
+}
+
+// handshake refuses WebSocket connections without the pairing token, and
+// while another phone is in control.
+func (p *Phone) handshake(_ *websocket.Config, r *http.Request) error {
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(p.token)) != 1 {
+		return errors.New("wrong pairing token")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ws != nil {
+		return errors.New("another phone is in control")
+	}
+	return nil
+}
+
+// serve applies the readings of one phone until it disconnects.
+func (p *Phone) serve(ws *websocket.Conn) {
+	defer ws.Close()
+	p.mu.Lock()
+	if p.ws != nil {
+		// Another phone got in after the handshake
+		p.mu.Unlock()
+		return
+	}
+	p.ws = ws
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.ws = nil
+		p.anchor = nil
+		p.mu.Unlock()
+	}()
+	for {
+		var r phoneReading
+		if err := websocket.JSON.Receive(ws, &r); err != nil {
+			return
+		}
+		p.mu.Lock()
+		p.updated = time.Now()
+		p.update(r)
+		p.mu.Unlock()
+	}
+}
+
+// update applies a reading. It is called with mu held.
+func (p *Phone) update(r phoneReading) {
+	if p.positions == nil {
+		return // not seeded yet
+	}
+	if _, ok := p.positions[robot.Gripper]; ok {
+		p.positions[robot.Gripper] = 100 - 200*max(0, min(1, r.Grip))
+	}
+	if !r.Engaged {
+		p.anchor = nil
+		return
+	}
+	if p.anchor == nil {
+		p.anchor = &r
+		p.base = p.cfg.Follower.Degrees(p.positions)
+		return
+	}
+
+	turned := map[robot.MotorName]float64{
+		robot.ShoulderPan: wrapDegrees(r.Alpha - p.anchor.Alpha),
+		robot.WristFlex:   r.Beta - p.anchor.Beta,
+		robot.WristRoll:   r.Gamma - p.anchor.Gamma,
+	}
+	degrees := make(map[robot.MotorName]float64, len(phoneJoints))
+	for _, name := range phoneJoints {
+		if base, ok := p.base[name]; ok {
+			degrees[name] = base + p.cfg.Gain*turned[name]
+		}
+	}
+	maps.Copy(p.positions, p.cfg.Follower.Positions(degrees))
+}
+
+// wrapDegrees wraps an angle difference to -180 to 180.
+func wrapDegrees(d float64) float64 {
+	return math.Remainder(d, 360)
+}
+
+// Seed sets the follower pose the phone starts from.
+func (p *Phone) Seed(positions map[robot.MotorName]float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.positions = maps.Clone(positions)
+	p.anchor = nil
+}
+
+// ReadPositions returns the current targets, or ErrStale once the phone
+// stopped sending readings for 250ms, e.g. with its screen locked.
+func (p *Phone) ReadPositions(ctx context.Context) (map[robot.MotorName]float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.positions == nil {
+		return nil, errNotSeeded
+	}
+	if err := checkStale(p.updated); err != nil {
+		return nil, fmt.Errorf("phone: %w", err)
+	}
+	return maps.Clone(p.positions), nil
+}
+
+// Close stops serving the page and disconnects the phone.
+func (p *Phone) Close() error {
+	err := p.srv.Close()
+	p.mu.Lock()
+	if p.ws != nil {
+		p.ws.Close() // hijacked, so not closed by the server
+	}
+	p.mu.Unlock()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// selfSignedCert makes a throwaway certificate for serving HTTPS on the
+// local network. Browsers warn about it once.
+func selfSignedCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "lerobot"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,70 @@
+package teleop
+
+import (
+	"context"
+	"sync"
+)
+
+// mailbox holds the latest leader sample for the follower. A newer sample
+// replaces one the follower has not taken yet, so a slow follower write
+// never delays the next leader read and never works through a backlog.
+type mailbox struct {
+	ch chan sample
+}
+
+func newMailbox() *mailbox {
+	return &mailbox{ch: make(chan sample, 1)}
+}
+
+// put stores s and reports whether it replaced an untaken sample. It must
+// be called from a single goroutine.
+func (m *mailbox) put(s sample) (replaced bool) {
+	for {
+		select {
+		case m.ch <- s:
+			return replaced
+		default:
+		}
+		select {
+		case <-m.ch:
+			replaced = true
+		default:
+		}
+	}
+}
+
+// startFollower drives the follower from the mailbox in its own goroutine
+// until the returned stop function is called, which waits for the
+// follower's current cycle to finish.
+func (c *Controller) startFollower(ctx context.Context, box *mailbox) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		defer c.releaseOnPanic()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case s := <-box.ch:
+				c.drive(ctx, s)
+			}
+		}
+	})
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// stepLeader reads the leader and hands the sample to the follower.
+func (c *Controller) stepLeader(ctx context.Context, box *mailbox) {
+	s, ok := c.readLeader(ctx)
+	if !ok {
+		return
+	}
+	if box.put(s) {
+		c.mu.Lock()
+		c.dropped++
+		c.mu.Unlock()
+	}
+}
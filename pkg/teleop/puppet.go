@@ -0,0 +1,172 @@
+package teleop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/logging"
+	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/timesync"
+)
+
+// Puppet is for kinesthetic teaching without a leader: the follower is
+// moved by hand with its torque off, and its read-back positions are
+// reported as both the action and the observation. It sends States like a
+// Controller, so recording works the same way.
+type Puppet struct {
+	follower  Arm
+	hz        int
+	readLoads bool
+	observers observerSet
+
+	stateCh chan State
+	latest  atomic.Pointer[State]
+	logger  *slog.Logger
+	sink    eventSink
+
+	loop run // the running loop, stopped by Close
+}
+
+// NewPuppet connects to the follower in cfg. Of the rest of cfg, only Hz,
+// ReadLoads, Observers, Logger and LogLevel apply.
+func NewPuppet(cfg Config) (*Puppet, error) {
+	follower, err := robot.OpenArm(cfg.Follower)
+	if err != nil {
+		return nil, fmt.Errorf("create follower arm: %w", err)
+	}
+	p := &Puppet{
+		follower:  follower,
+		hz:        max(cfg.Hz, 1),
+		readLoads: cfg.ReadLoads,
+		observers: newObserverSet(cfg.Observers),
+		stateCh:   make(chan State, 1),
+		sink:      newEventSink(),
+	}
+	var sink slog.Handler
+	if cfg.Logger != nil {
+		sink = cfg.Logger.Handler()
+	}
+	p.logger = slog.New(logging.Fanout(p.sink.handler(cfg.LogLevel), sink))
+	return p, nil
+}
+
+// States returns a channel of the latest readings.
+func (p *Puppet) States() <-chan State {
+	return p.stateCh
+}
+
+// Snapshot returns the latest reading, see Controller.Snapshot.
+func (p *Puppet) Snapshot() State {
+	if s := p.latest.Load(); s != nil {
+		return *s
+	}
+	return State{}
+}
+
+// Events returns a channel of log events, see Controller.Events.
+func (p *Puppet) Events() <-chan Event {
+	return p.sink.events
+}
+
+// Logs returns a channel of log messages, see Controller.Logs.
+func (p *Puppet) Logs() <-chan string {
+	return p.sink.lines
+}
+
+// Close stops the loop if it runs, waiting for it until ctx is done, and
+// closes the follower and observers.
+func (p *Puppet) Close(ctx context.Context) error {
+	var errs []error
+	if !p.loop.stop(ctx) {
+		errs = append(errs, fmt.Errorf("stop: %w", ctx.Err()))
+	}
+	errs = append(errs, p.follower.Close())
+	errs = append(errs, p.observers.close()...)
+	return errors.Join(errs...)
+}
+
+// Start disables the follower's torque and reads it at Hz until ctx is
+// cancelled. The follower is reconnected after too many failed reads in a
+// row.
+func (p *Puppet) Start(ctx context.Context) error {
+	ctx, end := p.loop.begin(ctx)
+	defer end()
+
+	if err := p.follower.Disable(ctx); err != nil {
+		p.logger.Warn("Failed to disable torque", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+	} else {
+		p.logger.Info("Torque disabled, move the follower by hand", "component", "follower")
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(p.hz))
+	defer ticker.Stop()
+	errs := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		observed, err := p.follower.ReadState(ctx)
+		end := time.Now()
+		if err != nil {
+			errs++
+			level := slog.LevelDebug
+			if errs == 1 {
+				level = slog.LevelWarn
+			}
+			p.logger.Log(ctx, level, "Read failed", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+			p.send(State{Error: err, Timestamp: start})
+			if errs >= maxConsecutiveErrors {
+				if err := p.follower.Reconnect(); err != nil {
+					p.logger.Warn("Reconnect failed", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+				} else {
+					p.logger.Info("Reconnected", "component", "follower")
+					// Torque comes back on with power, keep it off
+					p.follower.Disable(ctx)
+				}
+				errs = 0
+			}
+			continue
+		}
+		errs = 0
+
+		read := timesync.Between(start, end)
+		state := State{
+			Positions:          observed.Positions,
+			FollowerPositions:  observed.Positions,
+			FollowerVelocities: observed.Velocities,
+			Timestamp:          read.At,
+			Leader:             read,
+			Follower:           read,
+			ReadLatency:        end.Sub(start),
+		}
+		if p.readLoads {
+			state.Loads = observed.Loads
+		}
+		state.Observations = p.observers.read(ctx, p.logger)
+		p.send(state)
+	}
+}
+
+// send replaces any state not taken yet with s.
+func (p *Puppet) send(s State) {
+	p.latest.Store(&s)
+	for {
+		select {
+		case p.stateCh <- s:
+			return
+		default:
+		}
+		select {
+		case <-p.stateCh:
+		default:
+		}
+	}
+}
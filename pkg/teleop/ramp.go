@@ -0,0 +1,215 @@
+package teleop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// defaultResync is how long the follower takes to catch up with the leader
+// after Resume.
+const defaultResync = time.Second
+
+// Ramp blends follower targets from the pose the follower was in when the
+// ramp began to the targets derived from the leader, or from a policy, so
+// re-engaging never jumps.
+type Ramp struct {
+	start    time.Time
+	duration time.Duration
+	from     map[robot.MotorName]float64
+}
+
+// NewRamp starts a ramp from the pose from that takes duration.
+func NewRamp(from map[robot.MotorName]float64, duration time.Duration) *Ramp {
+	return &Ramp{start: time.Now(), duration: duration, from: from}
+}
+
+// Apply returns targets blended at time now, and whether the ramp is done.
+func (r *Ramp) Apply(targets map[robot.MotorName]float64, now time.Time) (map[robot.MotorName]float64, bool) {
+	frac := 1.0
+	if r.duration > 0 {
+		frac = min(1, float64(now.Sub(r.start))/float64(r.duration))
+	}
+	blended := make(map[robot.MotorName]float64, len(targets))
+	for name, to := range targets {
+		if from, ok := r.from[name]; ok {
+			to = from + (to-from)*frac
+		}
+		blended[name] = to
+	}
+	return blended, frac == 1
+}
+
+// ErrPoseMismatch is returned by Start when the leader and follower poses
+// are too far apart to engage without a soft start.
+var ErrPoseMismatch = errors.New("leader and follower poses differ")
+
+// checkMismatch compares the follower's pose to the target derived from the
+// leader. Above the threshold it warns if a soft start will ramp the
+// follower over, and refuses otherwise.
+func (c *Controller) checkMismatch(ctx context.Context) error {
+	leader, err := c.readLeaderPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("mismatch check: leader: %w", err)
+	}
+	follower, err := c.follower.ReadPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("mismatch check: follower: %w", err)
+	}
+	name, diff := worstMismatch(c.transform(time.Now(), leader), follower)
+	if diff <= c.maxMismatch {
+		return nil
+	}
+	if c.softStartDuration > 0 {
+		c.logger.Warn("Leader and follower poses differ, ramping", "component", "controller", "motor", name, "diff", math.Round(diff))
+		return nil
+	}
+	return fmt.Errorf("%w: %s by %.0f (max %.0f), line the arms up or use a soft start", ErrPoseMismatch, name, diff, c.maxMismatch)
+}
+
+// worstMismatch returns the motor whose target and actual position differ
+// most, and by how much.
+func worstMismatch(targets, actual map[robot.MotorName]float64) (robot.MotorName, float64) {
+	var worst robot.MotorName
+	var maxDiff float64
+	for name, to := range targets {
+		at, ok := actual[name]
+		if !ok {
+			continue
+		}
+		if diff := math.Abs(to - at); diff > maxDiff {
+			worst, maxDiff = name, diff
+		}
+	}
+	return worst, maxDiff
+}
+
+// softStart makes the follower hold its current pose when torque comes on,
+// then sets up a ramp from there to the leader pose, so starting never
+// snaps the follower across the table.
+func (c *Controller) softStart(ctx context.Context) {
+	from, err := c.follower.ReadPositions(ctx)
+	if err != nil {
+		c.logger.Warn("Soft start skipped", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+		return
+	}
+	if err := c.follower.WritePositions(ctx, from); err != nil {
+		c.logger.Warn("Soft start skipped", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+		return
+	}
+	c.ramp = NewRamp(from, c.softStartDuration)
+}
+
+// rearm re-enables the follower after the watchdog released it. It holds
+// the pose the follower sagged into and re-syncs from there, like Resume.
+// It reports whether torque is back on.
+func (c *Controller) rearm(ctx context.Context) bool {
+	from, err := c.follower.ReadPositions(ctx)
+	if err == nil {
+		err = c.follower.WritePositions(ctx, from)
+	}
+	if err == nil {
+		err = c.follower.Enable(ctx)
+	}
+	if err != nil {
+		c.logger.Warn("Failed to re-enable torque", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+		return false
+	}
+	c.logger.Info("Torque enabled, re-syncing", "component", "follower")
+	c.mu.Lock()
+	c.resync = true
+	c.mu.Unlock()
+	return true
+}
+
+// Pause freezes the follower in its current pose while the leader keeps
+// being read, like a clutch, e.g. to reposition the leader.
+func (c *Controller) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		c.paused = true
+		c.logger.Info("Paused, follower holding", "component", "controller")
+	}
+}
+
+// Resume re-engages the follower after Pause. It ramps from where it was
+// held to the leader pose instead of jumping there, or in relative mode
+// continues from where it was held.
+func (c *Controller) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		c.paused = false
+		c.resync = true
+		c.logger.Info("Resumed", "component", "controller")
+	}
+}
+
+// Paused reports whether teleoperation is paused.
+func (c *Controller) Paused() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.paused
+}
+
+// engage applies pause and re-sync to the follower targets for this cycle.
+// It returns nil if nothing should be written.
+func (c *Controller) engage(ctx context.Context, targets map[robot.MotorName]float64) map[robot.MotorName]float64 {
+	c.mu.Lock()
+	paused, resync := c.paused, c.resync
+	c.resync = false
+	c.mu.Unlock()
+
+	if paused {
+		return nil
+	}
+	if resync {
+		from, err := c.follower.ReadPositions(ctx)
+		if err != nil {
+			// Without knowing where the follower is, jumping is unsafe; retry
+			c.logger.Warn("Re-sync read failed", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+			c.mu.Lock()
+			c.resync = true
+			c.mu.Unlock()
+			return nil
+		}
+		if c.relative {
+			// Clutch: from here on the follower follows the leader's motion
+			// rather than its pose, so nothing jumps and no ramp is needed
+			c.offset = make(map[robot.MotorName]float64, len(targets))
+			for name, to := range targets {
+				if at, ok := from[name]; ok {
+					c.offset[name] = at - to
+				}
+			}
+		} else {
+			c.ramp = NewRamp(from, c.resyncDuration)
+		}
+	}
+	if c.offset != nil {
+		targets = applyOffset(targets, c.offset)
+	}
+	if c.ramp != nil {
+		var done bool
+		targets, done = c.ramp.Apply(targets, time.Now())
+		if done {
+			c.ramp = nil
+		}
+	}
+	return targets
+}
+
+// applyOffset shifts targets by the per-motor offset, clamped to the
+// normalized range.
+func applyOffset(targets, offset map[robot.MotorName]float64) map[robot.MotorName]float64 {
+	shifted := make(map[robot.MotorName]float64, len(targets))
+	for name, pos := range targets {
+		shifted[name] = max(-100, min(100, pos+offset[name]))
+	}
+	return shifted
+}
@@ -0,0 +1,121 @@
+package teleop
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Task is periodic work at its own rate next to the control loop, such as
+// polling servo temperatures at 1 Hz or grabbing camera images at 30 Hz.
+type Task struct {
+	Name string
+	Hz   float64
+	Run  func(ctx context.Context) error
+}
+
+// TaskStats describes how a scheduled task kept up with its rate.
+type TaskStats struct {
+	Name    string
+	Hz      float64
+	Runs    int           // total runs
+	Skipped int           // total ticks missed because a run took longer than the period
+	Errors  int           // total runs that failed
+	Last    time.Duration // duration of the last run
+}
+
+// Scheduler runs tasks at their own rates, each on its own goroutine, so a
+// slow one never delays the control loop or the others. Transactions on a
+// shared serial bus are still serialized, but a slow poll then only holds
+// up the control loop for one transaction at a time. A run that takes
+// longer than its period skips the ticks it missed rather than queueing
+// them. A failing task is logged on its first failure and when it works
+// again.
+type Scheduler struct {
+	logger *slog.Logger
+	tasks  []Task
+
+	mu    sync.Mutex
+	stats []TaskStats
+}
+
+// NewScheduler returns a scheduler for tasks. Tasks with no rate are left
+// out.
+func NewScheduler(logger *slog.Logger, tasks ...Task) *Scheduler {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	s := &Scheduler{logger: logger}
+	for _, t := range tasks {
+		if t.Hz > 0 {
+			s.tasks = append(s.tasks, t)
+			s.stats = append(s.stats, TaskStats{Name: t.Name, Hz: t.Hz})
+		}
+	}
+	return s
+}
+
+// Run runs the tasks until ctx is cancelled, and returns when they all
+// stopped.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := range s.tasks {
+		wg.Go(func() { s.run(ctx, i) })
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context, i int) {
+	t := s.tasks[i]
+	period := time.Duration(float64(time.Second) / t.Hz)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	var failing bool
+	var last time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case tick := <-ticker.C:
+			start := time.Now()
+			err := t.Run(ctx)
+			took := time.Since(start)
+			if ctx.Err() != nil {
+				return
+			}
+
+			switch {
+			case err != nil && !failing:
+				s.logger.Warn("Task failed", "component", t.Name, "error", err)
+			case err == nil && failing:
+				s.logger.Info("Task working again", "component", t.Name)
+			}
+			failing = err != nil
+
+			s.mu.Lock()
+			st := &s.stats[i]
+			st.Runs++
+			st.Last = took
+			if err != nil {
+				st.Errors++
+			}
+			if !last.IsZero() {
+				st.Skipped += missedTicks(tick.Sub(last), period)
+			}
+			s.mu.Unlock()
+			last = tick
+		}
+	}
+}
+
+// Stats returns the statistics of every task, in the order they were
+// given. A nil Scheduler has none.
+func (s *Scheduler) Stats() []TaskStats {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]TaskStats(nil), s.stats...)
+}
@@ -0,0 +1,92 @@
+package teleop
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// run tracks the loop of Start so Close can stop it and wait for it. The
+// zero value has no loop running.
+type run struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{} // closed when the loop returned
+}
+
+// begin derives the loop's context from ctx. end must be called when the
+// loop returned.
+func (r *run) begin(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	r.mu.Lock()
+	r.cancel, r.done = cancel, done
+	r.mu.Unlock()
+	return ctx, func() {
+		cancel()
+		close(done)
+	}
+}
+
+// stop cancels the loop, if any, and waits for it to return. It reports
+// false if ctx was done first.
+func (r *run) stop(ctx context.Context) bool {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+	if cancel == nil {
+		return true
+	}
+	cancel()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// disableTimeout bounds disabling the follower's torque when the loop
+// didn't stop in time to do it.
+const disableTimeout = time.Second
+
+// loopExitTimeout is how long a loop that didn't stop in time gets to
+// return after its torque was disabled, before Close leaves the arms open
+// rather than closing them under it.
+const loopExitTimeout = 2 * time.Second
+
+// errLoopRunning is returned by stop when the loop didn't return at all.
+var errLoopRunning = errors.New("control loop still running")
+
+// stop stops the control loop, which parks the follower if a rest pose is
+// set and disables its torque. If ctx is done before the loop returned, the
+// torque is disabled here without parking, and the loop gets another
+// loopExitTimeout to return. If it still runs, errLoopRunning is returned.
+func (c *Controller) stop(ctx context.Context) error {
+	if c.loop.stop(ctx) {
+		c.stopped()
+		return nil
+	}
+	c.logger.Warn("Loop did not stop in time, disabling torque", "component", "controller", "error", ctx.Err())
+	dctx, cancel := context.WithTimeout(context.Background(), disableTimeout)
+	defer cancel()
+	var errs []error
+	if err := c.follower.Disable(dctx); err != nil {
+		c.logger.Error("Failed to disable torque", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+		errs = append(errs, err)
+	} else {
+		c.logger.Info("Torque disabled", "component", "follower")
+	}
+
+	wctx, cancel := context.WithTimeout(context.Background(), loopExitTimeout)
+	defer cancel()
+	if !c.loop.stop(wctx) {
+		c.logger.Error("Loop still running, leaving the arms open", "component", "controller")
+		return errors.Join(append(errs, errLoopRunning)...)
+	}
+	c.stopped()
+	return errors.Join(append(errs, ctx.Err())...)
+}
@@ -0,0 +1,120 @@
+package teleop
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// Stream is an Input fed by another program: one JSON object of normalized
+// positions per line, such as {"shoulder_pan": 10, "gripper": -20}, or a
+// line with them under "positions" as written by lerobot monitor --no-tui.
+// Motors missing from a line keep their last position. The follower holds
+// the latest line until the next one arrives, and reading fails with
+// ErrStale once none arrived for 250ms.
+type Stream struct {
+	r     io.ReadCloser
+	ready chan struct{} // closed at the first positions or the end
+
+	mu        sync.Mutex
+	positions map[robot.MotorName]float64
+	updated   time.Time // of the last good line
+	err       error     // of the last bad line, reported once
+	done      error     // why the stream ended
+}
+
+// streamLine is a line with its positions under "positions".
+type streamLine struct {
+	Positions map[robot.MotorName]float64 `json:"positions"`
+}
+
+// NewStream reads positions from r until it ends or Close is called.
+func NewStream(r io.ReadCloser) *Stream {
+	s := &Stream{r: r, ready: make(chan struct{})}
+	go s.read()
+	return s
+}
+
+func (s *Stream) read() {
+	scanner := bufio.NewScanner(s.r)
+	var once sync.Once
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		positions, err := parseStreamLine(line)
+		s.mu.Lock()
+		if err != nil {
+			s.err = fmt.Errorf("stream: line %d: %w", n, err)
+		} else {
+			if s.positions == nil {
+				s.positions = make(map[robot.MotorName]float64, len(positions))
+			}
+			maps.Copy(s.positions, positions)
+			s.updated = time.Now()
+		}
+		s.mu.Unlock()
+		if err == nil {
+			once.Do(func() { close(s.ready) })
+		}
+	}
+
+	s.mu.Lock()
+	s.done = io.EOF
+	if err := scanner.Err(); err != nil {
+		s.done = err
+	}
+	s.done = fmt.Errorf("stream: %w", s.done)
+	s.mu.Unlock()
+	once.Do(func() { close(s.ready) })
+}
+
+func parseStreamLine(line []byte) (map[robot.MotorName]float64, error) {
+	var wrapped streamLine
+	if err := json.Unmarshal(line, &wrapped); err == nil && wrapped.Positions != nil {
+		return wrapped.Positions, nil
+	}
+	var positions map[robot.MotorName]float64
+	if err := json.Unmarshal(line, &positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// ReadPositions returns the latest positions. Until the first line arrives
+// it blocks, so the follower waits for the program to start. Once the
+// stream ends or stalls, it fails.
+func (s *Stream) ReadPositions(ctx context.Context) (map[robot.MotorName]float64, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.ready:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.err; err != nil {
+		s.err = nil
+		return nil, err
+	}
+	if s.done != nil {
+		return nil, s.done
+	}
+	if err := checkStale(s.updated); err != nil {
+		return nil, fmt.Errorf("stream: %w", err)
+	}
+	return maps.Clone(s.positions), nil
+}
+
+// Close stops reading and closes the underlying reader.
+func (s *Stream) Close() error {
+	return s.r.Close()
+}
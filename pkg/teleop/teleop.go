@@ -2,86 +2,464 @@
 package teleop
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gwillem/lerobot/pkg/logging"
 	"github.com/gwillem/lerobot/pkg/robot"
+	"github.com/gwillem/lerobot/pkg/sim"
+	"github.com/gwillem/lerobot/pkg/timesync"
+)
+
+const (
+	// maxConsecutiveErrors is the number of failed bus transactions in a row
+	// after which an arm is considered disconnected.
+	maxConsecutiveErrors = 5
+
+	reconnectMinBackoff = 250 * time.Millisecond
+	reconnectMaxBackoff = 5 * time.Second
+
+	// parkSpeed is how fast arms move to the rest pose, in normalized units
+	// per second (a full range in about 5s).
+	parkSpeed   = 40
+	parkTimeout = 15 * time.Second
 )
 
 // State represents the current state of teleoperation.
 type State struct {
-	Positions map[robot.MotorName]float64
-	Timestamp time.Time
-	Error     error
+	Positions          map[robot.MotorName]float64 // leader positions (the action)
+	FollowerPositions  map[robot.MotorName]float64 // observed follower positions, if ReadFollower is set
+	FollowerVelocities map[robot.MotorName]float64 // observed follower velocities (normalized units/s), if ReadFollower is set
+	Timestamp          time.Time                   // when the leader was read, Leader.At
+	Leader             timesync.Stamp              // leader read, the time of the action
+	Follower           timesync.Stamp              // follower read, if ReadFollower is set
+	Error              error
+
+	ReadLatency  time.Duration // leader read this cycle
+	WriteLatency time.Duration // follower write this cycle, 0 if nothing was written
+	MissedTicks  int           // total ticks skipped so far
+	Paused       bool          // follower is holding, see Controller.Pause
+	Triggered    bool          // the leader trigger is pressed, see Config.Trigger
+
+	Raw     map[robot.MotorName]int     // raw leader counts
+	Targets map[robot.MotorName]float64 // follower targets this cycle, nil while paused
+	Loads   map[robot.MotorName]int     // follower loads in 0.1% of max torque, see Config.ReadLoads and Controller.WatchLoads
+
+	Errors map[robot.MotorName]robot.ServoFault // follower servos reporting hardware faults, polled every second
+
+	// Follower servo temperatures in degrees Celsius and voltages, polled
+	// at Config.TelemetryHz.
+	Temperatures map[robot.MotorName]int
+	Voltages     map[robot.MotorName]float64
+
+	// Servos that stopped responding. Their joints are left out until they
+	// answer again, while the others carry on.
+	LeaderMissing   []robot.MotorName
+	FollowerMissing []robot.MotorName
+
+	Observations map[string]Observation // latest reading of each Config.Observers sensor, missing while it fails
+}
+
+// Arm is the part of robot.Arm the controller uses to drive the follower,
+// so a simulated arm can take its place.
+type Arm interface {
+	Port() string
+	Enable(ctx context.Context) error
+	Disable(ctx context.Context) error
+	ReadPositions(ctx context.Context) (map[robot.MotorName]float64, error)
+	WritePositions(ctx context.Context, positions map[robot.MotorName]float64) error
+	ReadLoad(ctx context.Context, name robot.MotorName) (int, error)
+	ReadLoads(ctx context.Context) (map[robot.MotorName]int, error)
+	ReadState(ctx context.Context) (robot.ArmState, error)
+	Reconnect() error
+	Close() error
 }
 
 // Controller manages the teleoperation control loop.
 type Controller struct {
-	leader   *robot.Arm
-	follower *robot.Arm
-	hz       int
-	mirror   bool
+	leader    *robot.Arm // nil with input
+	leaderCal robot.Calibration
+	input     Input
+	follower  Arm
+	hz        int // guarded by mu
+	deadband  map[robot.MotorName]float64
+
+	middleware []Middleware                // guarded by mu
+	trims      map[robot.MotorName]float64 // see Trim, replaced rather than modified, guarded by mu
+
+	readFollower bool
+	readLoads    bool
+
+	gripForce int     // load threshold for gripper closing, 0 disables
+	gripping  bool    // grip force reached, gripper held at gripHold
+	gripHold  float64 // normalized gripper target while gripping
+
+	trigger   *robot.Trigger // nil unless a leader joint is used as a button
+	triggered bool           // the trigger is pressed
+	toolOn    bool           // last switched on by the tool action, see switchTool
+	toolBusy  atomic.Bool    // the tool command is running
+
+	mu      sync.RWMutex
+	latest  atomic.Pointer[State] // the last state sent, see Snapshot
+	mode    Mode                  // guarded by mu
+	modeCh  chan ModeChange
+	stateCh chan State
+	logger  *slog.Logger
+	sink    eventSink
+
+	leaderErrs   int // consecutive read errors
+	followerErrs int // consecutive write errors
+	budget       *errorBudget
+
+	lastWritten map[robot.MotorName]float64 // last targets sent to the follower
+
+	noClamp    bool                     // follower targets are not clamped, see robot.ArmConfig.NoClamp
+	outOfRange map[robot.MotorName]bool // motors whose last target was outside -100 to 100
+
+	// Loop timing, guarded by mu
+	readLat  latencies
+	writeLat latencies
+	cycleLat latencies
+	cycles   int
+	missed   int
+	dropped  int // leader samples replaced before the follower took them
+
+	overrunPolicy       OverrunPolicy
+	overruns            int // total cycles longer than the period, guarded by mu
+	consecutiveOverruns int
+
+	// Effective rate over the last rateWindow, guarded by mu
+	rateStart   time.Time
+	rateCycles  int
+	effectiveHz float64
+
+	pipeline bool
+
+	traceMu sync.Mutex    // the leader and follower may trace concurrently with Pipeline
+	trace   *json.Encoder // nil unless tracing
+
+	restPose map[robot.MotorName]float64 // nil unless parking
+
+	paused         bool // guarded by mu
+	resync         bool // ramp the follower in on the next cycle, guarded by mu
+	resyncDuration time.Duration
+	ramp           *Ramp // non-nil while re-syncing or soft-starting
+
+	softStartDuration time.Duration
+	maxMismatch       float64
+	relative          bool
+	offset            map[robot.MotorName]float64 // follower minus leader since the last Resume, relative mode only
+
+	watchdog *Watchdog
+
+	guard    *robot.MotionGuard // nil unless a workspace or collision check is configured
+	limiting bool               // the last target was limited by guard
+	released bool               // torque is off after the watchdog released the follower
+
+	watchLoads bool                    // guarded by mu
+	loads      map[robot.MotorName]int // latest follower load per motor
+	loadNext   int                     // next motor to poll
+	loadsErr   bool                    // ReadLoads is failing, logged once
+
+	sched     *Scheduler
+	pollMu    sync.Mutex                           // guards faults and telemetry, written by sched
+	faults    map[robot.MotorName]robot.ServoFault // latest follower faults
+	telemetry telemetry
 
-	mu       sync.RWMutex
-	state    State
-	running  bool
-	stateCh  chan State
-	logs     []string
-	logCh    chan string
+	leaderMissing   []robot.MotorName // servos not responding at the last check
+	followerMissing []robot.MotorName
+
+	observers observerSet
+
+	hooks Hooks
+	estop chan struct{} // closed by EmergencyStop, guarded by mu
+
+	loop      run // the running loop, stopped by Close
+	flush     func(ctx context.Context) error
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // Config holds configuration for the controller.
 type Config struct {
-	LeaderPort        string
-	LeaderCalibration robot.Calibration
-	FollowerPort      string
-	FollowerCalibration robot.Calibration
-	Hz                int
-	Mirror            bool // Invert positions for shoulder_pan (servo 1) and wrist_roll (servo 5)
+	Leader   robot.ArmConfig
+	Follower robot.ArmConfig
+	Hz       int
+	Mirror   bool // Invert positions for shoulder_pan (servo 1) and wrist_roll (servo 5)
+
+	// Input, if set, drives the follower instead of the arm in Leader.
+	// Its positions are follower targets: Mapping and Mirror don't apply.
+	Input Input
+
+	// Mapping transforms leader positions into follower targets per motor.
+	// Mirror is applied on top of it.
+	Mapping map[robot.MotorName]robot.JointMapping
+
+	// Smoothing is the cutoff frequency in Hz of a low-pass filter on the
+	// follower targets, after Mirror and Mapping. 0 disables it.
+	Smoothing float64
+
+	// Middleware transforms the follower targets every cycle, after Mirror,
+	// Mapping and Smoothing. See also Controller.Use.
+	Middleware []Middleware
+
+	// Deadband is the minimum change in normalized position per motor before
+	// a new target is written to the follower. Motors not listed have no deadband.
+	Deadband map[robot.MotorName]float64
+
+	// GripForce limits how hard the follower gripper closes, as present load
+	// in 0.1% of max torque (0-1000). Once reached, the gripper holds its
+	// position until the leader opens it again. 0 disables the limit.
+	GripForce int
+
+	// ReadFollower reads back the follower's present positions and
+	// velocities every cycle and reports them in State.FollowerPositions and
+	// FollowerVelocities, e.g. for recording.
+	ReadFollower bool
+
+	// ReadLoads reads the load of every follower motor each cycle and
+	// reports them in State.Loads, e.g. for recording effort. With
+	// ReadFollower this comes with the position read, otherwise it costs one
+	// more bus transaction per cycle.
+	ReadLoads bool
+
+	// SimAddr, if set, drives a simulated follower at this address (see
+	// package sim) instead of the arm in Follower.
+	SimAddr string
+
+	// Logger receives structured log events in addition to Logs. Nil only
+	// sends them to Logs.
+	Logger *slog.Logger
+
+	// LogLevel is the minimum level of events sent to Logs.
+	LogLevel slog.Level
+
+	// Trace, if set, receives a TraceRecord as JSON for every control
+	// cycle, for offline analysis of jitter and drift.
+	Trace io.Writer
+
+	// Overrun is what to do when cycles take longer than 1/Hz. Empty means
+	// OverrunSkip.
+	Overrun OverrunPolicy
+
+	// ErrorBudget stops the loop with ErrErrorBudget, parking and releasing
+	// the follower, when more than this fraction of leader reads and
+	// follower writes failed over the last 10 seconds, e.g. 0.25 for a
+	// quarter. Occasional failures are retried by the arms (see
+	// robot.ArmConfig.Retries) and a disconnected arm is reconnected; this
+	// catches a cable so flaky that control is unreliable. 0 disables it.
+	ErrorBudget float64
+
+	// RestPose, if set, is where both arms are slowly driven when the loop
+	// stops, before torque is disabled, so they don't drop onto the desk.
+	RestPose map[robot.MotorName]float64
+
+	// Resync is how long the follower takes to catch up with the leader
+	// after Resume. 0 means 1s.
+	Resync time.Duration
+
+	// SoftStart is how long the follower takes to move from its own pose
+	// to the leader's when teleoperation starts. 0 disables the ramp.
+	SoftStart time.Duration
+
+	// MaxMismatch is the largest difference in normalized units between
+	// the leader and follower poses on any joint at start. Beyond it, Start
+	// warns and soft-starts, or returns ErrPoseMismatch if SoftStart is 0.
+	// 0 disables the check.
+	MaxMismatch float64
+
+	// Pipeline reads the leader and writes the follower in separate
+	// goroutines. The follower always gets the latest leader sample, and a
+	// slow follower write no longer delays the next leader read; samples it
+	// had no time for are counted in Metrics.Dropped. Overruns then only
+	// consider the leader read.
+	Pipeline bool
+
+	// Relative makes Resume act as a clutch: the follower continues from
+	// where it was held and follows the leader's motion from that point,
+	// so a large motion can be made in steps with a small leader workspace.
+	Relative bool
+
+	// Watchdog holds the follower when leader readings stop arriving, and
+	// ramps its torque down if they stay away for Watchdog.Release. When
+	// the leader is back, the follower re-syncs as after Resume.
+	Watchdog WatchdogConfig
+
+	// Workspace keeps the follower's elbow, wrist and gripper tip inside a
+	// region around it: a move that would leave it stops at the boundary.
+	// Empty means anywhere. Follower.CollisionCheck likewise stops moves
+	// before the follower would hit itself.
+	Workspace robot.Workspace
+	// Kinematics locates the follower's links for Workspace and the
+	// collision check. Nil means robot.SO101Kinematics.
+	Kinematics *robot.Kinematics
+
+	// Observers are extra sensors read every cycle and reported in
+	// State.Observations. Close closes those that are an io.Closer.
+	Observers []Observer
+
+	// Hooks are called on events such as the loop starting and stopping.
+	Hooks Hooks
+
+	// TelemetryHz is how often the temperature and voltage of the
+	// follower's servos are read for State.Temperatures and Voltages, e.g.
+	// 1. The servos are read beside the control loop, which waits for at
+	// most one of their bus transactions per cycle. 0 disables it.
+	TelemetryHz float64
+
+	// Tasks run at their own rates next to the control loop while it runs,
+	// see Scheduler. Their statistics are in Metrics.Tasks.
+	Tasks []Task
+
+	// Trigger, if set, uses a leader joint, usually the gripper, as a button
+	// too: Hooks.OnTrigger is called when it is pressed and released, and
+	// State.Triggered tells whether it is. With the tool action, each press
+	// also runs its command. The joint still drives the follower.
+	Trigger *robot.Trigger
+
+	// Flush, if set, is called by Close once the loop stopped and the
+	// follower's torque is off, before the arms are closed, e.g. to save
+	// the episode being recorded.
+	Flush func(ctx context.Context) error
 }
 
 // NewController creates a new teleoperation controller.
 func NewController(cfg Config) (*Controller, error) {
-	leader, err := robot.NewArm(cfg.LeaderPort, cfg.LeaderCalibration)
-	if err != nil {
+	var leader *robot.Arm
+	var err error
+	if cfg.Input != nil {
+		// The input's positions are in the follower's terms
+		cfg.Leader.Calibration = cfg.Follower.Calibration
+		cfg.Mapping, cfg.Mirror = nil, false
+	} else if leader, err = robot.OpenArm(cfg.Leader); err != nil {
 		return nil, fmt.Errorf("create leader arm: %w", err)
 	}
 
-	follower, err := robot.NewArm(cfg.FollowerPort, cfg.FollowerCalibration)
+	var follower Arm
+	if cfg.SimAddr != "" {
+		follower, err = sim.Dial(cfg.SimAddr)
+	} else {
+		follower, err = robot.OpenArm(cfg.Follower)
+	}
 	if err != nil {
-		leader.Close()
+		if leader != nil {
+			leader.Close()
+		}
 		return nil, fmt.Errorf("create follower arm: %w", err)
 	}
 
 	if cfg.Hz <= 0 {
 		cfg.Hz = 60
 	}
+	if cfg.Overrun == "" {
+		cfg.Overrun = OverrunSkip
+	}
+	c := &Controller{
+		leader:            leader,
+		leaderCal:         cfg.Leader.Calibration,
+		input:             cfg.Input,
+		follower:          follower,
+		hz:                cfg.Hz,
+		middleware:        buildMiddleware(cfg),
+		deadband:          cfg.Deadband,
+		noClamp:           cfg.Follower.NoClamp,
+		gripForce:         cfg.GripForce,
+		trigger:           cfg.Trigger,
+		readFollower:      cfg.ReadFollower,
+		readLoads:         cfg.ReadLoads,
+		overrunPolicy:     cfg.Overrun,
+		restPose:          cfg.RestPose,
+		resyncDuration:    cmp.Or(cfg.Resync, defaultResync),
+		relative:          cfg.Relative,
+		pipeline:          cfg.Pipeline,
+		softStartDuration: cfg.SoftStart,
+		maxMismatch:       cfg.MaxMismatch,
+		budget:            &errorBudget{max: cfg.ErrorBudget},
+		observers:         newObserverSet(cfg.Observers),
+		hooks:             cfg.Hooks,
+		flush:             cfg.Flush,
+		estop:             make(chan struct{}),
+		modeCh:            make(chan ModeChange, 10),
+		stateCh:           make(chan State, 1),
+		sink:              newEventSink(),
+	}
+	if cfg.Trace != nil {
+		c.trace = json.NewEncoder(cfg.Trace)
+	}
 
-	return &Controller{
-		leader:   leader,
-		follower: follower,
-		hz:       cfg.Hz,
-		mirror:   cfg.Mirror,
-		stateCh:  make(chan State, 1),
-		logCh:    make(chan string, 10),
-	}, nil
+	var sink slog.Handler
+	if cfg.Logger != nil {
+		sink = cfg.Logger.Handler()
+	}
+	c.logger = slog.New(logging.Fanout(c.sink.handler(cfg.LogLevel), sink))
+	arms := []any{follower}
+	if leader != nil {
+		arms = append(arms, leader)
+	}
+	for _, arm := range arms {
+		if mr, ok := arm.(MissingReporter); ok {
+			mr.TolerateMissing(true)
+		}
+	}
+	c.watchdog = NewWatchdog(cfg.Watchdog, follower, c.logger)
+	model := robot.SO101Kinematics()
+	if cfg.Kinematics != nil {
+		model = *cfg.Kinematics
+	}
+	c.guard = robot.NewMotionGuard(cfg.Follower, model, cfg.Workspace)
+	c.sched = NewScheduler(c.logger, append(c.pollTasks(cfg.TelemetryHz), cfg.Tasks...)...)
+	return c, nil
 }
 
-// Close closes the controller and releases resources.
-func (c *Controller) Close() error {
-	c.mu.Lock()
-	c.running = false
-	c.mu.Unlock()
+// Close shuts the controller down in order: it stops the control loop if
+// it runs, which parks the follower if a rest pose is set and disables its
+// torque, calls Config.Flush, and then closes the input or leader, the
+// follower and the observers. If ctx is done before the loop stopped, the
+// follower's torque is disabled without parking and the rest goes on, but
+// the arms are left open if the loop doesn't return shortly after, since
+// it may still be using them. Calls after the first return its result.
+func (c *Controller) Close(ctx context.Context) error {
+	c.closeOnce.Do(func() { c.closeErr = c.close(ctx) })
+	return c.closeErr
+}
 
+func (c *Controller) close(ctx context.Context) error {
 	var errs []error
-	if err := c.leader.Close(); err != nil {
+	err := c.stop(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("stop: %w", err))
+	}
+	if c.flush != nil {
+		if err := c.flush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("flush: %w", err))
+		}
+	}
+	if errors.Is(err, errLoopRunning) {
+		return fmt.Errorf("close errors: %v", errs)
+	}
+
+	if c.input != nil {
+		if err := c.input.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	} else if err := c.leader.Close(); err != nil {
 		errs = append(errs, err)
 	}
 	if err := c.follower.Close(); err != nil {
 		errs = append(errs, err)
 	}
+	errs = append(errs, c.observers.close()...)
 	if len(errs) > 0 {
 		return fmt.Errorf("close errors: %v", errs)
 	}
@@ -93,122 +471,720 @@ func (c *Controller) States() <-chan State {
 	return c.stateCh
 }
 
-// Logs returns a channel that receives log messages.
+// Snapshot returns the state last sent on States, or the zero State before
+// the first, without taking it from the channel, so any number of readers
+// can follow the controller. It never blocks the control loop. The maps of
+// the state are shared and must not be modified.
+func (c *Controller) Snapshot() State {
+	if s := c.latest.Load(); s != nil {
+		return *s
+	}
+	return State{}
+}
+
+// Events returns a channel that receives log events at or above
+// Config.LogLevel. Events are dropped while the channel is full.
+func (c *Controller) Events() <-chan Event {
+	return c.sink.events
+}
+
+// Logs returns a channel that receives the same events as Events,
+// formatted as single lines by Event.String.
 func (c *Controller) Logs() <-chan string {
-	return c.logCh
+	return c.sink.lines
 }
 
-// Hz returns the control frequency.
+// Hz returns the control frequency. It may drop while running under
+// OverrunDegrade.
 func (c *Controller) Hz() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.hz
 }
 
-func (c *Controller) log(format string, args ...any) {
-	msg := fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
-	select {
-	case c.logCh <- msg:
-	default:
-		// Drop if channel full
+// Metrics returns control loop timing statistics.
+func (c *Controller) Metrics() Metrics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Metrics{
+		Read:        c.readLat.summary(),
+		Write:       c.writeLat.summary(),
+		Cycle:       c.cycleLat.summary(),
+		Cycles:      c.cycles,
+		MissedTicks: c.missed,
+		Dropped:     c.dropped,
+		Overruns:    c.overruns,
+		Hz:          c.hz,
+		EffectiveHz: c.effectiveHz,
+		Tasks:       c.sched.Stats(),
 	}
 }
 
-// Start begins the teleoperation control loop.
+// Start begins the teleoperation control loop, which runs until ctx is
+// cancelled, EmergencyStop or Close. If the loop panics, the follower's
+// torque is disabled before the panic goes on.
 func (c *Controller) Start(ctx context.Context) error {
+	defer c.releaseOnPanic()
+
 	c.mu.Lock()
-	if c.running {
+	if c.mode == ModeEStopped {
 		c.mu.Unlock()
-		return fmt.Errorf("already running")
+		return ErrEmergencyStop
 	}
-	c.running = true
+	if err := c.setModeLocked(ModeTeleop); err != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("already running: %w", err)
+	}
+	estop := c.estop
 	c.mu.Unlock()
+	ctx, end := c.loop.begin(ctx)
+	defer end()
 
 	// Initialize arms
-	if err := c.leader.Disable(ctx); err != nil {
-		c.log("Warning: failed to disable leader: %v", err)
+	if c.input != nil {
+		if err := c.seedInput(ctx); err != nil {
+			c.stopped()
+			return err
+		}
+	} else if err := c.leader.Disable(ctx); err != nil {
+		c.logger.Warn("Failed to disable torque", "component", "leader", "kind", robot.ErrorKind(err), "error", err)
 	} else {
-		c.log("Leader arm: torque disabled (passive mode)")
+		c.logger.Info("Torque disabled (passive mode)", "component", "leader")
 	}
 
+	if c.maxMismatch > 0 {
+		if err := c.checkMismatch(ctx); err != nil {
+			c.stopped()
+			c.hooks.error("controller", err)
+			return err
+		}
+	}
+	if c.softStartDuration > 0 {
+		c.softStart(ctx)
+	}
 	if err := c.follower.Enable(ctx); err != nil {
-		c.log("Warning: failed to enable follower: %v", err)
+		c.logger.Warn("Failed to enable torque", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
 	} else {
-		c.log("Follower arm: torque enabled")
+		c.logger.Info("Torque enabled", "component", "follower")
 	}
 
-	c.log("Teleoperation started at %d Hz", c.hz)
+	hz := c.Hz()
+	c.logger.Info("Teleoperation started", "component", "controller", "hz", hz, "overrun", c.overrunPolicy, "pipeline", c.pipeline)
+	c.hooks.start()
 
 	// Control loop
-	ticker := time.NewTicker(time.Second / time.Duration(c.hz))
+	period := time.Second / time.Duration(hz)
+	ticker := time.NewTicker(period)
 	defer ticker.Stop()
 
+	var box *mailbox
+	stopFollower := func() {}
+	if c.pipeline {
+		box = newMailbox()
+		stopFollower = c.startFollower(ctx, box)
+	}
+
+	watchdogCtx, stopWatchdog := context.WithCancel(ctx)
+	defer stopWatchdog()
+	c.watchdog.Feed(ctx)
+	go func() {
+		defer c.releaseOnPanic()
+		c.watchdog.Run(watchdogCtx)
+	}()
+
+	// Slower polls run beside the loop, and stop before the follower is
+	// parked
+	tasksCtx, cancelTasks := context.WithCancel(ctx)
+	tasksDone := make(chan struct{})
+	go func() {
+		defer close(tasksDone)
+		c.sched.Run(tasksCtx)
+	}()
+	stopTasks := func() {
+		cancelTasks()
+		<-tasksDone
+	}
+	defer stopTasks()
+
+	var lastTick time.Time
 	for {
 		select {
 		case <-ctx.Done():
-			c.shutdown()
+			stopFollower()
+			stopWatchdog()
+			stopTasks()
+			c.shutdown(true)
+			c.hooks.stop(ctx.Err())
 			return ctx.Err()
-		case <-ticker.C:
-			c.step(ctx)
+		case <-estop:
+			stopFollower()
+			stopWatchdog()
+			stopTasks()
+			c.shutdown(false)
+			c.hooks.stop(ErrEmergencyStop)
+			return ErrEmergencyStop
+		case tick := <-ticker.C:
+			if !lastTick.IsZero() {
+				if n := missedTicks(tick.Sub(lastTick), period); n > 0 {
+					c.mu.Lock()
+					c.missed += n
+					c.mu.Unlock()
+				}
+			}
+			lastTick = tick
+
+			start := time.Now()
+			if box != nil {
+				c.stepLeader(ctx, box)
+			} else {
+				c.step(ctx)
+			}
+			c.countCycle(start)
+
+			hz, err := c.checkOverrun(time.Since(start))
+			if err == nil {
+				err = c.budget.check(time.Now())
+			}
+			if err != nil {
+				c.logger.Error("Stopping", "component", "controller", "error", err)
+				c.hooks.error("controller", err)
+				stopFollower()
+				stopWatchdog()
+				stopTasks()
+				c.shutdown(true)
+				c.hooks.stop(err)
+				return err
+			}
+			if hz > 0 {
+				period = time.Second / time.Duration(hz)
+				ticker.Reset(period)
+			}
 		}
 	}
 }
 
+// sample is one leader reading, handed from readLeader to drive.
+type sample struct {
+	start       time.Time // when the read began
+	raw         map[robot.MotorName]int
+	positions   map[robot.MotorName]float64 // normalized
+	readLatency time.Duration
+}
+
+// step runs one control cycle: read the leader, then drive the follower.
 func (c *Controller) step(ctx context.Context) {
-	// Read leader positions
-	positions, err := c.leader.ReadPositions(ctx)
-	if err != nil {
-		c.log("Read error: %v", err)
-		c.sendState(State{Error: err, Timestamp: time.Now()})
-		return
+	if s, ok := c.readLeader(ctx); ok {
+		c.drive(ctx, s)
 	}
+}
 
-	// Apply mirroring if enabled (invert shoulder_pan and wrist_roll)
-	followerPositions := positions
-	if c.mirror {
-		followerPositions = make(map[robot.MotorName]float64, len(positions))
-		for name, pos := range positions {
-			if name == robot.ShoulderPan || name == robot.WristRoll {
-				followerPositions[name] = -pos
-			} else {
-				followerPositions[name] = pos
+// readLeader reads the leader positions. Failed reads are reported and
+// traced here, and reconnect after too many in a row.
+func (c *Controller) readLeader(ctx context.Context) (sample, bool) {
+	start := time.Now()
+	var raw map[robot.MotorName]int
+	var positions map[robot.MotorName]float64
+	var err error
+	if c.input != nil {
+		positions, err = c.input.ReadPositions(ctx)
+	} else if raw, err = c.leader.ReadRawPositions(ctx); err == nil {
+		positions = c.leaderCal.NormalizeAll(raw)
+	}
+	readLatency := time.Since(start)
+	if c.input == nil {
+		c.budget.add(start, err)
+	}
+	if err != nil {
+		c.writeTrace(&TraceRecord{
+			Time:      start,
+			ReadUs:    readLatency.Microseconds(),
+			CycleUs:   readLatency.Microseconds(),
+			ReadError: err.Error(),
+		})
+		c.leaderErrs++
+		level := slog.LevelDebug
+		if c.leaderErrs == 1 {
+			level = slog.LevelWarn
+			c.hooks.error("leader", err)
+		}
+		c.logger.Log(ctx, level, "Read failed", "component", "leader", "kind", robot.ErrorKind(err), "error", err)
+		c.sendState(State{Error: err, Timestamp: time.Now()})
+		if c.leaderErrs >= maxConsecutiveErrors {
+			if c.input == nil {
+				c.reconnect(ctx, "leader", c.leader, false)
 			}
+			c.leaderErrs = 0
 		}
+		return sample{}, false
+	}
+	c.leaderErrs = 0
+	return sample{start: start, raw: raw, positions: positions, readLatency: readLatency}, true
+}
+
+// drive turns a leader sample into follower targets, writes them and
+// reports the resulting State.
+func (c *Controller) drive(ctx context.Context, s sample) {
+	start, raw, positions, readLatency := s.start, s.raw, s.positions, s.readLatency
+	trace := &TraceRecord{Time: start, LeaderRaw: raw, Leader: positions, ReadUs: readLatency.Microseconds()}
+	defer func() {
+		trace.CycleUs = time.Since(start).Microseconds()
+		c.writeTrace(trace)
+	}()
+
+	// A fresh sample: bring the follower back if the watchdog released it
+	if c.watchdog.Feed(ctx) == WatchdogReleased || c.released {
+		c.released = !c.rearm(ctx)
+	}
+
+	// A leader joint used as a button also works while paused
+	if c.trigger != nil {
+		c.checkTrigger(positions)
+	}
+
+	// Map leader positions to follower targets (mirror, scale, offset and
+	// the user's middleware)
+	followerPositions := c.transform(start, positions)
+
+	// Stop closing the gripper once the grip force is reached
+	if c.gripForce > 0 {
+		followerPositions = c.limitGrip(ctx, followerPositions)
 	}
 
+	// Hold while paused, ramp back in after resuming
+	followerPositions = c.engage(ctx, followerPositions)
+
+	// Stop at the workspace boundary or before hitting itself
+	if c.guard != nil && followerPositions != nil {
+		followerPositions = c.limitMotion(followerPositions)
+	}
+	targets := followerPositions
+
+	// Skip motors that haven't moved beyond their deadband
+	followerPositions = applyDeadband(followerPositions, c.lastWritten, c.deadband)
+
 	// Write to follower
-	if err := c.follower.WritePositions(ctx, followerPositions); err != nil {
-		c.log("Write error: %v", err)
+	var writeLatency time.Duration
+	if len(followerPositions) > 0 {
+		writeStart := time.Now()
+		err := c.writeFollower(ctx, followerPositions)
+		writeLatency = time.Since(writeStart)
+		trace.Written = followerPositions
+		trace.WriteError = errString(err)
+		trace.WriteUs = writeLatency.Microseconds()
+	}
+
+	leader := timesync.Between(start, start.Add(readLatency))
+	state := State{
+		Paused:       c.Paused(),
+		Triggered:    c.triggered,
+		Raw:          raw,
+		Targets:      targets,
+		Positions:    positions,
+		Timestamp:    leader.At,
+		Leader:       leader,
+		ReadLatency:  readLatency,
+		WriteLatency: writeLatency,
+	}
+
+	// Read back where the follower actually is, with its velocities and
+	// loads in the same transaction
+	if c.readFollower {
+		readStart := time.Now()
+		observed, err := c.follower.ReadState(ctx)
+		state.Follower = timesync.Between(readStart, time.Now())
+		if err != nil {
+			state.Error = err
+		}
+		state.FollowerPositions = observed.Positions
+		state.FollowerVelocities = observed.Velocities
+		trace.Follower = observed.Positions
+		if c.readLoads {
+			state.Loads = observed.Loads
+		}
+	}
+	if !c.readFollower || !c.readLoads {
+		state.Loads = c.pollLoad(ctx)
+	}
+	faults, tel := c.polled()
+	state.Errors, state.Temperatures, state.Voltages = faults, tel.temperatures, tel.voltages
+	if c.leader != nil {
+		state.LeaderMissing = c.checkMissing("leader", c.leader, &c.leaderMissing)
 	}
+	state.FollowerMissing = c.checkMissing("follower", c.follower, &c.followerMissing)
+	state.Observations = c.observers.read(ctx, c.logger)
+
+	c.mu.Lock()
+	c.readLat.add(readLatency)
+	if writeLatency > 0 {
+		c.writeLat.add(writeLatency)
+	}
+	c.cycleLat.add(time.Since(start))
+	c.cycles++
+	state.MissedTicks = c.missed
+	trace.Cycle = c.cycles
+	trace.MissedTicks = c.missed
+	c.mu.Unlock()
 
 	// Send state update
-	c.sendState(State{
-		Positions: positions,
-		Timestamp: time.Now(),
-	})
+	c.sendState(state)
+}
+
+func (c *Controller) writeFollower(ctx context.Context, positions map[robot.MotorName]float64) error {
+	err := c.follower.WritePositions(ctx, positions)
+	c.budget.add(time.Now(), err)
+	if err != nil {
+		c.followerErrs++
+		level := slog.LevelDebug
+		if c.followerErrs == 1 {
+			level = slog.LevelWarn
+			c.hooks.error("follower", err)
+		}
+		c.logger.Log(ctx, level, "Write failed", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+		if c.followerErrs >= maxConsecutiveErrors {
+			c.reconnect(ctx, "follower", c.follower, true)
+			c.followerErrs = 0
+			c.lastWritten = nil
+		}
+		return err
+	}
+
+	c.followerErrs = 0
+	c.logOutOfRange(positions)
+	if c.lastWritten == nil {
+		c.lastWritten = make(map[robot.MotorName]float64, len(positions))
+	}
+	for name, pos := range positions {
+		c.lastWritten[name] = pos
+	}
+	return nil
+}
+
+// limitMotion limits targets to safe poses, logging when the follower is
+// stopped short and when it can follow again.
+func (c *Controller) limitMotion(targets map[robot.MotorName]float64) map[robot.MotorName]float64 {
+	limited, err := c.guard.Limit(targets)
+	switch {
+	case err != nil && !c.limiting:
+		c.logger.Warn("Stopping short of target", "component", "follower", "reason", err)
+	case err == nil && c.limiting:
+		c.logger.Info("Following again", "component", "follower")
+	}
+	c.limiting = err != nil
+	return limited
+}
+
+// logOutOfRange warns when a motor's target leaves the calibrated range,
+// where the follower clamps it, and notes when it returns. Each motor is
+// logged once per excursion rather than every cycle.
+func (c *Controller) logOutOfRange(positions map[robot.MotorName]float64) {
+	if c.outOfRange == nil {
+		c.outOfRange = make(map[robot.MotorName]bool)
+	}
+	crossed, back := updateOutOfRange(positions, c.outOfRange)
+	msg := "Target clamped to calibrated range"
+	if c.noClamp {
+		msg = "Target beyond calibrated range"
+	}
+	for _, name := range crossed {
+		c.logger.Warn(msg, "component", "follower", "motor", name, "target", math.Round(positions[name]))
+	}
+	for _, name := range back {
+		c.logger.Info("Target back within calibrated range", "component", "follower", "motor", name)
+	}
+}
+
+// updateOutOfRange records which targets are outside -100 to 100 and
+// returns the motors that just left the range and those that came back.
+func updateOutOfRange(targets map[robot.MotorName]float64, outside map[robot.MotorName]bool) (crossed, back []robot.MotorName) {
+	for name, pos := range targets {
+		out := pos < -100 || pos > 100
+		if out == outside[name] {
+			continue
+		}
+		outside[name] = out
+		if out {
+			crossed = append(crossed, name)
+		} else {
+			back = append(back, name)
+		}
+	}
+	slices.Sort(crossed)
+	slices.Sort(back)
+	return crossed, back
+}
+
+// WatchLoads turns polling of the follower loads for State.Loads on or
+// off. One motor is read per cycle, so each load refreshes at Hz divided by
+// the number of motors. It has no effect with Config.ReadLoads.
+func (c *Controller) WatchLoads(on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchLoads = on
+}
+
+// pollLoad reads the loads of all motors with ReadLoads, or else the load
+// of the next motor if loads are watched, and returns a copy of the loads
+// so far.
+func (c *Controller) pollLoad(ctx context.Context) map[robot.MotorName]int {
+	if c.readLoads {
+		loads, err := c.follower.ReadLoads(ctx)
+		if err != nil {
+			if !c.loadsErr {
+				c.logger.Warn("Load read failed", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+			}
+			c.loadsErr = true
+			return nil
+		}
+		if c.loadsErr {
+			c.logger.Info("Loads reading again", "component", "follower")
+			c.loadsErr = false
+		}
+		return loads
+	}
+
+	c.mu.RLock()
+	watch := c.watchLoads
+	c.mu.RUnlock()
+	motors := c.leaderCal.Motors()
+	if !watch || len(motors) == 0 {
+		c.loads = nil
+		return nil
+	}
+
+	name := motors[c.loadNext%len(motors)]
+	c.loadNext++
+	if load, err := c.follower.ReadLoad(ctx, name); err == nil {
+		if c.loads == nil {
+			c.loads = make(map[robot.MotorName]int, len(motors))
+		}
+		c.loads[name] = load
+	}
+	return maps.Clone(c.loads)
+}
+
+// limitGrip holds the follower gripper in place once its load exceeds the
+// grip force while closing. Closing is assumed to be towards -100. The grip
+// is released as soon as the leader opens past the hold position.
+func (c *Controller) limitGrip(ctx context.Context, positions map[robot.MotorName]float64) map[robot.MotorName]float64 {
+	target, ok := positions[robot.Gripper]
+	if !ok {
+		return positions
+	}
+
+	if c.gripping {
+		if target > c.gripHold {
+			c.gripping = false
+			c.logger.Info("Grip released", "component", "follower", "motor", robot.Gripper)
+			return positions
+		}
+	} else {
+		prev, written := c.lastWritten[robot.Gripper]
+		if !written || target >= prev {
+			return positions // not closing
+		}
+		load, err := c.follower.ReadLoad(ctx, robot.Gripper)
+		if err != nil || abs(load) < c.gripForce {
+			return positions
+		}
+		c.gripping = true
+		c.gripHold = prev
+		c.logger.Info("Grip force reached, holding", "component", "follower", "motor", robot.Gripper, "load", load)
+	}
+
+	limited := make(map[robot.MotorName]float64, len(positions))
+	for name, pos := range positions {
+		limited[name] = pos
+	}
+	limited[robot.Gripper] = c.gripHold
+	return limited
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// mirrorMotors are the motors inverted in mirror mode.
+var mirrorMotors = []robot.MotorName{robot.ShoulderPan, robot.WristRoll}
+
+// buildMiddleware returns the middleware for mirror mode, the per-motor
+// mapping and smoothing, followed by that configured.
+func buildMiddleware(cfg Config) []Middleware {
+	var mw []Middleware
+	if cfg.Mirror {
+		mw = append(mw, Mirror())
+	}
+	if len(cfg.Mapping) > 0 {
+		mw = append(mw, MapJoints(cfg.Mapping))
+	}
+	if cfg.Smoothing > 0 {
+		mw = append(mw, Smooth(cfg.Smoothing))
+	}
+	return append(mw, cfg.Middleware...)
+}
+
+// applyMapping returns follower targets for the given leader positions.
+func applyMapping(positions map[robot.MotorName]float64, mapping map[robot.MotorName]robot.JointMapping) map[robot.MotorName]float64 {
+	if len(mapping) == 0 {
+		return positions
+	}
+	mapped := make(map[robot.MotorName]float64, len(positions))
+	for name, pos := range positions {
+		if m, ok := mapping[name]; ok {
+			pos = m.Apply(pos)
+		}
+		mapped[name] = pos
+	}
+	return mapped
+}
+
+// applyDeadband returns the subset of positions that moved more than the
+// motor's deadband away from the last written target.
+func applyDeadband(positions, last, deadband map[robot.MotorName]float64) map[robot.MotorName]float64 {
+	if len(deadband) == 0 || last == nil {
+		return positions
+	}
+	moved := make(map[robot.MotorName]float64, len(positions))
+	for name, pos := range positions {
+		prev, ok := last[name]
+		if !ok || math.Abs(pos-prev) > deadband[name] {
+			moved[name] = pos
+		}
+	}
+	return moved
+}
+
+// reconnect blocks the control loop until the arm's serial connection is
+// re-established, retrying with exponential backoff. While blocked, no new
+// targets are written, so the follower holds its last commanded pose.
+func (c *Controller) reconnect(ctx context.Context, name string, arm Arm, torque bool) {
+	c.logger.Warn("Arm disconnected, holding follower and reconnecting", "component", name, "port", arm.Port())
+
+	backoff := reconnectMinBackoff
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		err := arm.Reconnect()
+		if err == nil {
+			if torque {
+				err = arm.Enable(ctx)
+			} else {
+				err = arm.Disable(ctx)
+			}
+		}
+		if err == nil {
+			c.logger.Info("Reconnected", "component", name, "attempts", attempt)
+			return
+		}
+
+		level := slog.LevelDebug
+		if attempt == 1 || attempt%10 == 0 {
+			level = slog.LevelWarn
+		}
+		c.logger.Log(ctx, level, "Reconnect failed", "component", name, "attempt", attempt, "kind", robot.ErrorKind(err), "error", err)
+		backoff = min(backoff*2, reconnectMaxBackoff)
+	}
 }
 
+// sendState replaces any state not yet received with s. It never blocks,
+// also when the leader and follower send concurrently with Pipeline.
 func (c *Controller) sendState(s State) {
-	select {
-	case c.stateCh <- s:
-	default:
+	c.latest.Store(&s)
+	for {
+		select {
+		case c.stateCh <- s:
+			return
+		default:
+		}
 		// Drop old state if channel full, replace with new
 		select {
 		case <-c.stateCh:
 		default:
 		}
-		c.stateCh <- s
 	}
 }
 
-func (c *Controller) shutdown() {
-	c.mu.Lock()
-	c.running = false
-	c.mu.Unlock()
+// releaseOnPanic disables the follower's torque if the calling goroutine
+// is panicking, then panics on. Deferred in every goroutine that drives the
+// follower, it keeps a crash from leaving the arm stiff at its last target.
+func (c *Controller) releaseOnPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.follower.Disable(ctx); err != nil {
+		c.logger.Error("Failed to disable torque after panic", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+	} else {
+		c.logger.Error("Torque disabled after panic", "component", "follower", "panic", r)
+	}
+	panic(r)
+}
+
+// shutdown disables the follower's torque after the loop stopped, parking
+// both arms first if a rest pose is set and park is true.
+func (c *Controller) shutdown(park bool) {
+	c.stopped()
 
 	ctx := context.Background()
+	if park && c.restPose != nil {
+		c.park(ctx)
+	}
 	if err := c.follower.Disable(ctx); err != nil {
-		c.log("Warning: failed to disable follower: %v", err)
+		c.logger.Warn("Failed to disable torque", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
 	} else {
-		c.log("Follower arm: torque disabled")
+		c.logger.Info("Torque disabled", "component", "follower")
+	}
+	m := c.Metrics()
+	c.logger.Info("Teleoperation stopped", "component", "controller",
+		"cycles", m.Cycles, "hz", m.Hz, "effective_hz", math.Round(m.EffectiveHz*10)/10, "overruns", m.Overruns)
+}
+
+// park drives both arms to the rest pose. The leader is passive during
+// teleoperation, so it is torqued only for the move.
+func (c *Controller) park(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, parkTimeout)
+	defer cancel()
+	c.logger.Info("Moving to rest pose", "component", "controller")
+
+	leaderReady := false
+	if c.leader != nil {
+		if err := c.leader.Hold(ctx); err != nil {
+			c.logger.Warn("Cannot park", "component", "leader", "kind", robot.ErrorKind(err), "error", err)
+		} else if err := c.leader.Enable(ctx); err != nil {
+			c.logger.Warn("Cannot park", "component", "leader", "kind", robot.ErrorKind(err), "error", err)
+		} else {
+			leaderReady = true
+		}
+	}
+
+	var wg sync.WaitGroup
+	move := func(name string, arm robot.Positioner) {
+		wg.Go(func() {
+			if err := robot.MoveTo(ctx, arm, c.restPose, parkSpeed); err != nil {
+				c.logger.Warn("Parking failed", "component", name, "kind", robot.ErrorKind(err), "error", err)
+			}
+		})
+	}
+	if leaderReady {
+		move("leader", c.leader)
+	}
+	move("follower", c.follower)
+	wg.Wait()
+
+	if leaderReady {
+		// The leader must go limp even when parking ran out of time
+		dctx, cancel := context.WithTimeout(context.Background(), disableTimeout)
+		defer cancel()
+		if err := c.leader.Disable(dctx); err != nil {
+			c.logger.Warn("Failed to disable torque", "component", "leader", "kind", robot.ErrorKind(err), "error", err)
+		}
 	}
-	c.log("Teleoperation stopped")
 }
@@ -0,0 +1,1062 @@
+package teleop
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+func TestApplyDeadband(t *testing.T) {
+	deadband := map[robot.MotorName]float64{
+		robot.ShoulderPan: 1.0,
+		robot.Gripper:     0.5,
+	}
+	last := map[robot.MotorName]float64{
+		robot.ShoulderPan: 10.0,
+		robot.Gripper:     -20.0,
+	}
+
+	tests := []struct {
+		name      string
+		positions map[robot.MotorName]float64
+		want      []robot.MotorName
+	}{
+		{"within deadband", map[robot.MotorName]float64{robot.ShoulderPan: 10.8, robot.Gripper: -20.4}, nil},
+		{"one moved", map[robot.MotorName]float64{robot.ShoulderPan: 11.5, robot.Gripper: -20.4}, []robot.MotorName{robot.ShoulderPan}},
+		{"never written", map[robot.MotorName]float64{robot.ElbowFlex: 0}, []robot.MotorName{robot.ElbowFlex}},
+	}
+
+	for _, tt := range tests {
+		got := applyDeadband(tt.positions, last, deadband)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: applyDeadband() = %v, want motors %v", tt.name, got, tt.want)
+			continue
+		}
+		for _, name := range tt.want {
+			if _, ok := got[name]; !ok {
+				t.Errorf("%s: applyDeadband() missing %s", tt.name, name)
+			}
+		}
+	}
+
+	// Without a previous write everything goes through
+	if got := applyDeadband(last, nil, deadband); len(got) != len(last) {
+		t.Errorf("applyDeadband() with no last write = %v, want all motors", got)
+	}
+}
+
+func TestApplyMapping(t *testing.T) {
+	c := &Controller{middleware: buildMiddleware(Config{
+		Mirror: true,
+		Mapping: map[robot.MotorName]robot.JointMapping{
+			robot.ElbowFlex: {Scale: 0.5, Offset: 10},
+			robot.WristRoll: {Invert: true},
+		},
+	})}
+	leader := map[robot.MotorName]float64{
+		robot.ShoulderPan: 40,
+		robot.ElbowFlex:   20,
+		robot.WristRoll:   30,
+		robot.Gripper:     -50,
+	}
+	var seen Frame
+	c.Use(func(f Frame) Frame {
+		seen = f
+		f.Targets = maps.Clone(f.Targets)
+		f.Targets[robot.Gripper] /= 2
+		return f
+	})
+	got := c.transform(time.Now(), leader)
+	if seen.Targets[robot.Gripper] != -50 || leader[robot.ShoulderPan] != 40 {
+		t.Errorf("middleware saw %v, leader changed to %v", seen.Targets, leader)
+	}
+
+	want := map[robot.MotorName]float64{
+		robot.ShoulderPan: -40, // mirrored
+		robot.ElbowFlex:   20,  // 20*0.5 + 10
+		robot.WristRoll:   30,  // inverted twice: config + mirror
+		robot.Gripper:     -25, // unmapped, halved by the last middleware
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("transform()[%s] = %v, want %v", name, got[name], w)
+		}
+	}
+}
+
+func TestSmooth(t *testing.T) {
+	smooth := Smooth(10)
+	start := time.Now()
+	at := func(ms int, pos float64) float64 {
+		f := smooth(Frame{Time: start.Add(time.Duration(ms) * time.Millisecond), Targets: map[robot.MotorName]float64{robot.Gripper: pos}})
+		return f.Targets[robot.Gripper]
+	}
+
+	if got := at(0, 0); got != 0 {
+		t.Fatalf("first target = %v, want it unfiltered", got)
+	}
+	// A step reaches 1-1/e of the way after one time constant, 1/(2π·10Hz)
+	got := at(16, 100)
+	if got < 60 || got > 66 {
+		t.Errorf("target after 16ms = %v, want about 63", got)
+	}
+	if got := at(1016, 100); math.Abs(got-100) > 0.01 {
+		t.Errorf("target after 1s = %v, want 100", got)
+	}
+}
+
+func TestMissedTicks(t *testing.T) {
+	period := 10 * time.Millisecond
+	tests := []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{10 * time.Millisecond, 0},
+		{14 * time.Millisecond, 0}, // jitter
+		{20 * time.Millisecond, 1},
+		{41 * time.Millisecond, 3},
+	}
+	for _, tt := range tests {
+		if got := missedTicks(tt.elapsed, period); got != tt.want {
+			t.Errorf("missedTicks(%v) = %d, want %d", tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestLatenciesSummary(t *testing.T) {
+	var l latencies
+	for i := 1; i <= metricsWindow+100; i++ {
+		l.add(time.Duration(i) * time.Microsecond)
+	}
+	if len(l.samples) != metricsWindow {
+		t.Fatalf("window holds %d samples, want %d", len(l.samples), metricsWindow)
+	}
+	s := l.summary()
+	if s.Max != time.Duration(metricsWindow+100)*time.Microsecond {
+		t.Errorf("max = %v", s.Max)
+	}
+	if s.P50 <= 100*time.Microsecond || s.P50 >= s.P95 {
+		t.Errorf("p50 = %v, p95 = %v: oldest samples not evicted or wrong order", s.P50, s.P95)
+	}
+}
+
+func TestCheckOverrun(t *testing.T) {
+	slow := 25 * time.Millisecond // too slow for 60 Hz
+	newController := func(policy OverrunPolicy) *Controller {
+		return &Controller{hz: 60, overrunPolicy: policy, logger: slog.New(slog.DiscardHandler)}
+	}
+
+	c := newController(OverrunDegrade)
+	for i := 1; i < overrunLimit; i++ {
+		if hz, _ := c.checkOverrun(slow); hz != 0 {
+			t.Fatalf("degraded after %d overruns", i)
+		}
+	}
+	if hz, _ := c.checkOverrun(slow); hz != 32 || c.Hz() != 32 {
+		t.Errorf("degraded to %d Hz, want 32", hz)
+	}
+	if hz, _ := c.checkOverrun(time.Millisecond); hz != 0 || c.consecutiveOverruns != 0 {
+		t.Error("fast cycle should reset the overrun count")
+	}
+
+	c = newController(OverrunError)
+	var err error
+	for range overrunLimit {
+		_, err = c.checkOverrun(slow)
+	}
+	if !errors.Is(err, ErrOverrun) {
+		t.Errorf("err = %v, want ErrOverrun", err)
+	}
+
+	c = newController(OverrunSkip)
+	for range 2 * overrunLimit {
+		if hz, err := c.checkOverrun(slow); hz != 0 || err != nil {
+			t.Fatal("skip policy should not act")
+		}
+	}
+	if c.Metrics().Overruns != 2*overrunLimit {
+		t.Errorf("overruns = %d", c.Metrics().Overruns)
+	}
+}
+
+func TestRamp(t *testing.T) {
+	start := time.Now()
+	r := &Ramp{start: start, duration: time.Second, from: map[robot.MotorName]float64{robot.ShoulderPan: 0}}
+	targets := map[robot.MotorName]float64{robot.ShoulderPan: 50, robot.Gripper: 20}
+
+	got, done := r.Apply(targets, start.Add(500*time.Millisecond))
+	if done || got[robot.ShoulderPan] != 25 {
+		t.Errorf("halfway: %v done=%v, want shoulder_pan 25", got, done)
+	}
+	if got[robot.Gripper] != 20 {
+		t.Errorf("motor without start position = %v, want target 20", got[robot.Gripper])
+	}
+	if got, done := r.Apply(targets, start.Add(2*time.Second)); !done || got[robot.ShoulderPan] != 50 {
+		t.Errorf("after duration: %v done=%v, want shoulder_pan 50", got, done)
+	}
+}
+
+func TestApplyOffset(t *testing.T) {
+	targets := map[robot.MotorName]float64{robot.ShoulderPan: 10, robot.ElbowFlex: 90, robot.Gripper: 5}
+	offset := map[robot.MotorName]float64{robot.ShoulderPan: -30, robot.ElbowFlex: 20}
+
+	got := applyOffset(targets, offset)
+	want := map[robot.MotorName]float64{robot.ShoulderPan: -20, robot.ElbowFlex: 100, robot.Gripper: 5}
+	for name, pos := range want {
+		if got[name] != pos {
+			t.Errorf("%s = %v, want %v", name, got[name], pos)
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	c := &Controller{}
+	c.Trim(robot.ElbowFlex, 1.5)
+	if got := c.Trim(robot.ElbowFlex, 1.5); got != 3 {
+		t.Errorf("trim = %v, want 3", got)
+	}
+	if got := c.Trim(robot.WristFlex, -25); got != -maxTrim {
+		t.Errorf("trim = %v, want %v", got, -maxTrim)
+	}
+	c.Trim(robot.Gripper, 2)
+	c.Trim(robot.Gripper, -2)
+	if trims := c.Trims(); len(trims) != 2 {
+		t.Errorf("trims = %v, want elbow_flex and wrist_flex", trims)
+	}
+
+	got := c.transform(time.Now(), map[robot.MotorName]float64{robot.ElbowFlex: 99, robot.Gripper: 5})
+	want := map[robot.MotorName]float64{robot.ElbowFlex: 102, robot.Gripper: 5}
+	if !maps.Equal(got, want) {
+		t.Errorf("targets = %v, want %v", got, want)
+	}
+}
+
+func TestWorstMismatch(t *testing.T) {
+	targets := map[robot.MotorName]float64{robot.ShoulderPan: 10, robot.ElbowFlex: -40, robot.Gripper: 50}
+	actual := map[robot.MotorName]float64{robot.ShoulderPan: 0, robot.ElbowFlex: 20}
+
+	name, diff := worstMismatch(targets, actual)
+	if name != robot.ElbowFlex || diff != 60 {
+		t.Errorf("worstMismatch = %s %v, want elbow_flex 60", name, diff)
+	}
+}
+
+func TestUpdateOutOfRange(t *testing.T) {
+	outside := make(map[robot.MotorName]bool)
+
+	crossed, back := updateOutOfRange(map[robot.MotorName]float64{robot.ShoulderPan: 120, robot.Gripper: 100}, outside)
+	if !slices.Equal(crossed, []robot.MotorName{robot.ShoulderPan}) || back != nil {
+		t.Errorf("crossed %v, back %v; want [shoulder_pan], []", crossed, back)
+	}
+	crossed, _ = updateOutOfRange(map[robot.MotorName]float64{robot.ShoulderPan: 130}, outside)
+	if crossed != nil {
+		t.Errorf("crossed %v again, want it logged once", crossed)
+	}
+	_, back = updateOutOfRange(map[robot.MotorName]float64{robot.ShoulderPan: 90}, outside)
+	if !slices.Equal(back, []robot.MotorName{robot.ShoulderPan}) {
+		t.Errorf("back = %v, want [shoulder_pan]", back)
+	}
+}
+
+func TestMailbox(t *testing.T) {
+	box := newMailbox()
+	if box.put(sample{readLatency: 1}) {
+		t.Error("first put replaced a sample")
+	}
+	if !box.put(sample{readLatency: 2}) {
+		t.Error("second put should replace the untaken sample")
+	}
+	if s := <-box.ch; s.readLatency != 2 {
+		t.Errorf("took sample %v, want the latest", s.readLatency)
+	}
+	select {
+	case s := <-box.ch:
+		t.Errorf("mailbox still holds %v", s.readLatency)
+	default:
+	}
+}
+
+// countingInput reports shoulder_pan at 1, 2, 3, ..., one step per read.
+type countingInput struct{ reads atomic.Int64 }
+
+func (in *countingInput) ReadPositions(context.Context) (map[robot.MotorName]float64, error) {
+	return map[robot.MotorName]float64{robot.ShoulderPan: float64(in.reads.Add(1))}, nil
+}
+
+func (in *countingInput) Close() error { return nil }
+
+// slowFollower takes delay for every write and records the shoulder_pan
+// targets written.
+type slowFollower struct {
+	fakeFollower
+	delay time.Duration
+
+	mu      sync.Mutex
+	written []float64
+}
+
+func (f *slowFollower) WritePositions(_ context.Context, p map[robot.MotorName]float64) error {
+	time.Sleep(f.delay)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.written = append(f.written, p[robot.ShoulderPan])
+	return nil
+}
+
+func TestPipeline(t *testing.T) {
+	input := &countingInput{}
+	follower := &slowFollower{delay: 15 * time.Millisecond}
+	logger := slog.New(slog.DiscardHandler)
+	c := &Controller{
+		input:         input,
+		follower:      follower,
+		hz:            200,
+		middleware:    buildMiddleware(Config{Mirror: true}),
+		pipeline:      true,
+		overrunPolicy: OverrunSkip,
+		budget:        &errorBudget{},
+		stateCh:       make(chan State, 1),
+		logger:        logger,
+		watchdog:      NewWatchdog(WatchdogConfig{}, follower, logger),
+		sched:         NewScheduler(logger),
+		estop:         make(chan struct{}),
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 300*time.Millisecond)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- c.Start(ctx) }()
+
+	// The state is of the processed sample: the mirrored leader
+	s := <-c.States()
+	if s.Targets[robot.ShoulderPan] != -s.Positions[robot.ShoulderPan] {
+		t.Errorf("targets %v for leader %v, want mirrored", s.Targets, s.Positions)
+	}
+	<-done
+
+	follower.mu.Lock()
+	defer follower.mu.Unlock()
+	reads, writes, dropped := int(input.reads.Load()), len(follower.written), c.Metrics().Dropped
+	if writes < 2 || dropped == 0 {
+		t.Fatalf("%d reads, %d writes, %d dropped; want the slow follower to skip samples", reads, writes, dropped)
+	}
+	// Every sample is written or dropped, but one may be left in the mailbox
+	if left := reads - writes - dropped; left < 0 || left > 1 {
+		t.Errorf("%d reads, %d writes, %d dropped", reads, writes, dropped)
+	}
+	// Each write is the mirror of a newer leader sample than the last
+	for i, w := range follower.written {
+		if w >= 0 || w < -float64(reads) || (i > 0 && w >= follower.written[i-1]) {
+			t.Fatalf("written %v, want mirrored and ever newer samples", follower.written)
+		}
+	}
+}
+
+func gripperChunk(values ...float64) []map[robot.MotorName]float64 {
+	chunk := make([]map[robot.MotorName]float64, len(values))
+	for i, v := range values {
+		chunk[i] = map[robot.MotorName]float64{robot.Gripper: v}
+	}
+	return chunk
+}
+
+func TestChunkExecutor(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ChunkConfig
+		want []float64 // actions for steps 2-4
+	}{
+		{"replace", ChunkConfig{}, []float64{40, 40, 40}},
+		{"average", ChunkConfig{Ensemble: true}, []float64{25, 25, 40}},
+		{"favor older", ChunkConfig{Ensemble: true, Decay: math.Log(3)}, []float64{17.5, 17.5, 40}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewChunkExecutor(tt.cfg)
+			e.Add(0, gripperChunk(0, 10, 10, 10))
+			for step, want := range []float64{0, 10} {
+				if a, ok := e.Next(step); !ok || a[robot.Gripper] != want {
+					t.Fatalf("Next(%d) = %v, %v, want %v", step, a, ok, want)
+				}
+			}
+			// Predicted from step 1, arriving after it was executed: the
+			// rest overlaps the first chunk up to step 3
+			e.Add(1, gripperChunk(40, 40, 40, 40))
+			if e.Len() != 3 {
+				t.Errorf("Len() = %d, want 3", e.Len())
+			}
+			for i, want := range tt.want {
+				a, ok := e.Next(i + 2)
+				if !ok || math.Abs(a[robot.Gripper]-want) > 1e-9 {
+					t.Errorf("Next(%d) = %v, %v, want %v", i+2, a, ok, want)
+				}
+			}
+			if _, ok := e.Next(5); ok {
+				t.Error("Next(5) on an empty executor succeeded")
+			}
+		})
+	}
+}
+
+// limitArm records torque limits and whether torque was disabled.
+type limitArm struct {
+	limits   []int
+	disabled bool
+}
+
+func (a *limitArm) Disable(context.Context) error { a.disabled = true; return nil }
+
+func (a *limitArm) SetTorqueLimit(_ context.Context, limit int) error {
+	a.limits = append(a.limits, limit)
+	return nil
+}
+
+func TestWatchdog(t *testing.T) {
+	ctx := context.Background()
+	arm := &limitArm{}
+	w := NewWatchdog(WatchdogConfig{Hold: 100 * time.Millisecond, Release: time.Second, Ramp: time.Second}, arm, nil)
+	start := w.last
+
+	steps := []struct {
+		at    time.Duration
+		want  WatchdogState
+		limit int // last torque limit written, -1 for none
+	}{
+		{50 * time.Millisecond, WatchdogActive, -1},
+		{200 * time.Millisecond, WatchdogHolding, -1},
+		{time.Second, WatchdogReleasing, 1000},
+		{1500 * time.Millisecond, WatchdogReleasing, 500},
+		{2 * time.Second, WatchdogReleased, 1000},
+	}
+	for _, s := range steps {
+		w.check(ctx, start.Add(s.at))
+		if got := w.State(); got != s.want {
+			t.Errorf("at %v: state %v, want %v", s.at, got, s.want)
+		}
+		if s.limit >= 0 && (len(arm.limits) == 0 || arm.limits[len(arm.limits)-1] != s.limit) {
+			t.Errorf("at %v: torque limits %v, want last %d", s.at, arm.limits, s.limit)
+		}
+	}
+	if !arm.disabled {
+		t.Error("torque not disabled after the ramp")
+	}
+	if prev := w.Feed(ctx); prev != WatchdogReleased || w.State() != WatchdogActive {
+		t.Errorf("Feed() = %v, state %v; want released, then active", prev, w.State())
+	}
+}
+
+func TestErrorBudget(t *testing.T) {
+	b := &errorBudget{max: 0.25}
+	now := time.Unix(1000, 0)
+	failed := errors.New("timeout")
+
+	// Too few transactions to judge
+	for range 10 {
+		b.add(now, failed)
+	}
+	if err := b.check(now); err != nil {
+		t.Fatalf("check after 10 failures = %v, want nil", err)
+	}
+
+	for range 40 {
+		b.add(now, nil)
+	}
+	if err := b.check(now); err != nil {
+		t.Fatalf("check at 20%% = %v, want nil", err)
+	}
+	for range 5 {
+		b.add(now.Add(time.Second), failed)
+	}
+	if err := b.check(now.Add(time.Second)); !errors.Is(err, ErrErrorBudget) {
+		t.Fatalf("check at 27%% = %v, want ErrErrorBudget", err)
+	}
+
+	// The failures age out of the window
+	later := now.Add(11 * time.Second)
+	for range 50 {
+		b.add(later, nil)
+	}
+	if err := b.check(later); err != nil {
+		t.Errorf("check after the window = %v, want nil", err)
+	}
+}
+
+func TestKeyboard(t *testing.T) {
+	k := NewKeyboard([]robot.MotorName{robot.ShoulderPan, robot.Gripper}, 5)
+	if _, err := k.ReadPositions(context.Background()); err == nil {
+		t.Fatal("ReadPositions before Seed: want error")
+	}
+	k.Nudge(1) // ignored until seeded
+
+	k.Seed(map[robot.MotorName]float64{robot.ShoulderPan: 10, robot.Gripper: 98, robot.ElbowFlex: 3})
+	k.Nudge(-1)
+	if k.Select(2) {
+		t.Error("Select(2) of 2 motors succeeded")
+	}
+	k.Select(1)
+	k.Nudge(1)
+	got, err := k.ReadPositions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[robot.MotorName]float64{robot.ShoulderPan: 5, robot.Gripper: 100}
+	if !maps.Equal(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+}
+
+func TestStream(t *testing.T) {
+	r, w := io.Pipe()
+	s := NewStream(r)
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	if _, err := s.ReadPositions(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ReadPositions before the first line = %v, want it to wait", err)
+	}
+	cancel()
+
+	// waitFor reads until pred holds, counting failed reads
+	waitFor := func(pred func(map[robot.MotorName]float64) bool) (map[robot.MotorName]float64, int) {
+		t.Helper()
+		failed := 0
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			got, err := s.ReadPositions(context.Background())
+			if err != nil {
+				failed++
+			} else if pred(got) {
+				return got, failed
+			}
+		}
+		t.Fatal("timed out waiting for positions")
+		return nil, 0
+	}
+
+	fmt.Fprintln(w, `{"shoulder_pan": 10, "gripper": -20}`)
+	fmt.Fprintln(w, `{"time": "2026-01-01T00:00:00Z", "positions": {"gripper": 30}}`)
+	got, _ := waitFor(func(p map[robot.MotorName]float64) bool { return p[robot.Gripper] == 30 })
+	if want := map[robot.MotorName]float64{robot.ShoulderPan: 10, robot.Gripper: 30}; !maps.Equal(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+
+	fmt.Fprintln(w, `not json`)
+	fmt.Fprintln(w, `{"shoulder_pan": 12}`)
+	if _, failed := waitFor(func(p map[robot.MotorName]float64) bool { return p[robot.ShoulderPan] == 12 }); failed != 1 {
+		t.Errorf("bad line reported %d times, want once", failed)
+	}
+
+	s.mu.Lock()
+	s.updated = time.Now().Add(-time.Second)
+	s.mu.Unlock()
+	if _, err := s.ReadPositions(context.Background()); !errors.Is(err, ErrStale) {
+		t.Errorf("ReadPositions after a second without lines = %v, want ErrStale", err)
+	}
+
+	w.Close()
+	time.Sleep(10 * time.Millisecond)
+	if _, err := s.ReadPositions(context.Background()); !errors.Is(err, io.EOF) {
+		t.Errorf("ReadPositions after the end = %v, want EOF", err)
+	}
+}
+
+func TestHand(t *testing.T) {
+	// -100 to 100 is -90° to 90° on every joint
+	cal := robot.Calibration{}
+	for _, m := range robot.SO101Motors() {
+		cal[m.Name] = robot.MotorCalibration{ID: m.ID, RangeMin: 1024, RangeMax: 3072}
+	}
+	follower := robot.ArmConfig{Calibration: cal}
+	h, err := ListenHand("127.0.0.1:0", HandConfig{Follower: follower, Scale: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	start := map[robot.MotorName]float64{robot.ShoulderLift: -20, robot.ElbowFlex: 10, robot.Gripper: 50}
+	h.Seed(start)
+	conn, err := net.Dial("tcp", h.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// The first frame anchors, the second moves the hand 1cm up
+	fmt.Fprintln(conn, `{"position": [0.3, 0, 0.2], "grip": 0}`)
+	fmt.Fprintln(conn, `{"position": [0.3, 0, 0.21], "grip": 1}`)
+
+	model := robot.SO101Kinematics()
+	from := model.Forward(follower.Degrees(start))
+	var got map[robot.MotorName]float64
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if got, err = h.ReadPositions(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got[robot.Gripper] == -100 {
+			break
+		}
+	}
+	if got[robot.Gripper] != -100 {
+		t.Fatalf("gripper = %v, want closed", got[robot.Gripper])
+	}
+	// Positions are rounded to whole counts, so the tip lands within a mm
+	to := model.Forward(follower.Degrees(got))
+	if want := from.Tip[2] + 0.02; math.Abs(to.Tip[2]-want) > 0.001 || math.Abs(to.Tip[0]-from.Tip[0]) > 0.001 {
+		t.Errorf("tip moved from %v to %v, want 2cm up", from.Tip, to.Tip)
+	}
+	if math.Abs(to.Pitch-from.Pitch) > 0.5 {
+		t.Errorf("pitch changed from %.1f to %.1f", from.Pitch, to.Pitch)
+	}
+
+	h.mu.Lock()
+	h.updated = time.Now().Add(-time.Second)
+	h.mu.Unlock()
+	if _, err := h.ReadPositions(context.Background()); !errors.Is(err, ErrStale) {
+		t.Errorf("ReadPositions after a second without frames = %v, want ErrStale", err)
+	}
+}
+
+func TestPhone(t *testing.T) {
+	cal := robot.Calibration{}
+	for _, m := range robot.SO101Motors() {
+		cal[m.Name] = robot.MotorCalibration{ID: m.ID, RangeMin: 1024, RangeMax: 3072}
+	}
+	follower := robot.ArmConfig{Calibration: cal}
+	p, err := ListenPhone("127.0.0.1:0", PhoneConfig{Follower: follower})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	p.Seed(map[robot.MotorName]float64{robot.ShoulderPan: 0, robot.WristFlex: 0, robot.WristRoll: 0, robot.Gripper: 100})
+
+	dial := func(token string) (*websocket.Conn, error) {
+		wsCfg, err := websocket.NewConfig("wss://"+p.Addr().String()+"/ws?token="+token, "https://"+p.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		wsCfg.TlsConfig = &tls.Config{InsecureSkipVerify: true}
+		return websocket.DialConfig(wsCfg)
+	}
+	if _, err := dial("wrong"); err == nil {
+		t.Error("connected without the pairing token")
+	}
+	u, err := url.Parse(p.URL("127.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := dial(u.Query().Get("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	// Anchor, then turn across north and tilt forward
+	websocket.JSON.Send(ws, phoneReading{Alpha: 355, Beta: 40, Engaged: true})
+	websocket.JSON.Send(ws, phoneReading{Alpha: 15, Beta: 50, Gamma: -9, Engaged: true, Grip: 0.5})
+
+	var got map[robot.MotorName]float64
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if got, err = p.ReadPositions(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got[robot.Gripper] == 0 {
+			break
+		}
+	}
+	want := map[robot.MotorName]float64{robot.ShoulderPan: 20, robot.WristFlex: 10, robot.WristRoll: -9, robot.Gripper: 0}
+	for name, deg := range follower.Degrees(got) {
+		if name != robot.Gripper && math.Abs(deg-want[name]) > 0.1 {
+			t.Errorf("%s at %.1f°, want %.1f°", name, deg, want[name])
+		}
+	}
+	if got[robot.Gripper] != 0 {
+		t.Errorf("gripper = %v, want half closed", got[robot.Gripper])
+	}
+	if _, err := dial(u.Query().Get("token")); err == nil {
+		t.Error("a second phone connected while the first is in control")
+	}
+
+	p.mu.Lock()
+	p.updated = time.Now().Add(-time.Second)
+	p.mu.Unlock()
+	if _, err := p.ReadPositions(context.Background()); !errors.Is(err, ErrStale) {
+		t.Errorf("ReadPositions after a second without readings = %v, want ErrStale", err)
+	}
+}
+
+func TestParseVRFrame(t *testing.T) {
+	s, c := math.Sin(15*math.Pi/180), math.Cos(15*math.Pi/180)
+	tests := []struct {
+		line        string
+		pitch, roll float64
+	}{
+		{`{"position": [0.1, 0.2, -0.3], "squeeze": true, "trigger": 0.4}`, 0, 0},
+		{fmt.Sprintf(`{"position": [0.1, 0.2, -0.3], "orientation": [%g, 0, 0, %g], "squeeze": true, "trigger": 0.4}`, s, c), 30, 0},
+		{fmt.Sprintf(`{"position": [0.1, 0.2, -0.3], "orientation": [0, 0, %g, %g], "squeeze": true, "trigger": 0.4}`, s, c), 0, -30},
+	}
+	for _, tt := range tests {
+		f, engaged, err := parseVRFrame([]byte(tt.line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !engaged || f.Grip != 0.4 || f.Position != (robot.Vec3{0.3, -0.1, 0.2}) {
+			t.Errorf("%s: got %+v, engaged %v", tt.line, f, engaged)
+		}
+		if math.Abs(f.Pitch-tt.pitch) > 1e-9 || math.Abs(f.Roll-tt.roll) > 1e-9 {
+			t.Errorf("%s: pitch %.1f roll %.1f, want %.1f and %.1f", tt.line, f.Pitch, f.Roll, tt.pitch, tt.roll)
+		}
+	}
+}
+
+func TestParseObservation(t *testing.T) {
+	for line, want := range map[string]Observation{
+		"fx=0.5, fy=-2 fz=1e3\r": {"fx": 0.5, "fy": -2, "fz": 1000},
+		"512\t498":               {"0": 512, "1": 498},
+		"":                       {},
+	} {
+		got, err := parseObservation(line)
+		if err != nil || !maps.Equal(got, want) {
+			t.Errorf("parseObservation(%q) = %v, %v, want %v", line, got, err, want)
+		}
+	}
+	if _, err := parseObservation("weight: 12g"); err == nil {
+		t.Error("parseObservation accepted text")
+	}
+}
+
+type fakeObserver struct {
+	obs Observation
+	err error
+}
+
+func (f *fakeObserver) Name() string { return "scale" }
+
+func (f *fakeObserver) Read(context.Context) (Observation, error) { return f.obs, f.err }
+
+func TestReadObservers(t *testing.T) {
+	scale := &fakeObserver{err: errors.New("timeout")}
+	s := newObserverSet([]Observer{scale})
+	logger := slog.New(slog.DiscardHandler)
+	if got := s.read(t.Context(), logger); len(got) != 0 || !s.failing["scale"] {
+		t.Fatalf("failing observer: got %v, failing %v", got, s.failing)
+	}
+	scale.obs, scale.err = Observation{"0": 12.5}, nil
+	got := s.read(t.Context(), logger)
+	if got["scale"]["0"] != 12.5 || s.failing["scale"] {
+		t.Errorf("recovered observer: got %v, failing %v", got, s.failing)
+	}
+
+	r, w := io.Pipe()
+	o := newLineObserver("ft", r)
+	defer o.Close()
+	if _, err := o.Read(t.Context()); !errors.Is(err, errNoReading) {
+		t.Errorf("Read before a line = %v, want errNoReading", err)
+	}
+	fmt.Fprintln(w, "booting")
+	fmt.Fprintln(w, "fx=1 fy=2")
+	time.Sleep(10 * time.Millisecond)
+	if obs, err := o.Read(t.Context()); err != nil || obs["fy"] != 2 {
+		t.Errorf("Read = %v, %v, want fy=2", obs, err)
+	}
+	w.Close()
+	time.Sleep(10 * time.Millisecond)
+	if _, err := o.Read(t.Context()); !errors.Is(err, io.EOF) {
+		t.Errorf("Read after EOF = %v, want io.EOF", err)
+	}
+}
+
+type fakeInput struct{}
+
+func (fakeInput) ReadPositions(context.Context) (map[robot.MotorName]float64, error) {
+	return map[robot.MotorName]float64{robot.Gripper: 10}, nil
+}
+
+func (fakeInput) Close() error { return nil }
+
+// fakeFollower implements the parts of Arm a controller driven by an
+// Input uses.
+type fakeFollower struct {
+	Arm
+	enabled atomic.Bool
+	closed  atomic.Bool
+}
+
+func (f *fakeFollower) Enable(context.Context) error  { f.enabled.Store(true); return nil }
+func (f *fakeFollower) Disable(context.Context) error { f.enabled.Store(false); return nil }
+
+func (f *fakeFollower) WritePositions(context.Context, map[robot.MotorName]float64) error {
+	return nil
+}
+
+func (f *fakeFollower) Close() error { f.closed.Store(true); return nil }
+
+func TestHooks(t *testing.T) {
+	var events []string
+	var mu sync.Mutex
+	event := func(e string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}
+	follower := &fakeFollower{}
+	logger := slog.New(slog.DiscardHandler)
+	c := &Controller{
+		input:         fakeInput{},
+		follower:      follower,
+		hz:            100,
+		overrunPolicy: OverrunSkip,
+		budget:        &errorBudget{},
+		stateCh:       make(chan State, 1),
+		logger:        logger,
+		watchdog:      NewWatchdog(WatchdogConfig{}, follower, logger),
+		sched:         NewScheduler(logger),
+		estop:         make(chan struct{}),
+		hooks: Hooks{
+			OnStart: func() { event("start") },
+			OnStop:  func(err error) { event("stop: " + err.Error()) },
+			OnEStop: func() { event("estop") },
+		},
+	}
+	done := make(chan error)
+	if s := c.Snapshot(); s.Positions != nil {
+		t.Errorf("Snapshot() before the first cycle = %+v", s)
+	}
+	go func() { done <- c.Start(t.Context()) }()
+	<-c.States()
+	// Readers of snapshots don't take states from the channel
+	for range 3 {
+		if s := c.Snapshot(); s.Positions[robot.Gripper] != 10 {
+			t.Errorf("Snapshot().Positions = %v", s.Positions)
+		}
+	}
+	<-c.States()
+	c.EmergencyStop()
+	if err := <-done; !errors.Is(err, ErrEmergencyStop) {
+		t.Errorf("Start() = %v, want ErrEmergencyStop", err)
+	}
+	if follower.enabled.Load() {
+		t.Error("follower torque still on after emergency stop")
+	}
+	want := []string{"start", "estop", "stop: emergency stop"}
+	if !slices.Equal(events, want) {
+		t.Errorf("events = %q, want %q", events, want)
+	}
+}
+
+func TestCheckTrigger(t *testing.T) {
+	var presses []bool
+	c := &Controller{
+		logger:  slog.New(slog.DiscardHandler),
+		trigger: &robot.Trigger{Press: -80, Release: -60},
+		hooks:   Hooks{OnTrigger: func(pressed bool) { presses = append(presses, pressed) }},
+	}
+	for _, pos := range []float64{0, -85, -90, -70, -50, -70, -81} {
+		c.checkTrigger(map[robot.MotorName]float64{robot.Gripper: pos})
+	}
+	c.checkTrigger(map[robot.MotorName]float64{robot.ShoulderPan: 0}) // gripper missing
+	if want := []bool{true, false, true}; !slices.Equal(presses, want) {
+		t.Errorf("presses = %v, want %v", presses, want)
+	}
+	if !c.triggered {
+		t.Error("trigger released without the gripper opening")
+	}
+
+	// The tool action switches the tool on and off in turn
+	out := filepath.Join(t.TempDir(), "tool")
+	c = &Controller{
+		logger:  slog.New(slog.DiscardHandler),
+		trigger: &robot.Trigger{Press: -80, Release: -60, Action: robot.TriggerTool, Command: []string{"sh", "-c", `echo $LEROBOT_TOOL >> "$0"`, out}},
+	}
+	for _, pos := range []float64{-90, 0, -90} {
+		c.checkTrigger(map[robot.MotorName]float64{robot.Gripper: pos})
+		for deadline := time.Now().Add(5 * time.Second); c.toolBusy.Load() && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if data, _ := os.ReadFile(out); string(data) != "on\noff\n" {
+		t.Errorf("tool switched %q, want on, off", data)
+	}
+}
+
+func TestClose(t *testing.T) {
+	follower := &fakeFollower{}
+	logger := slog.New(slog.DiscardHandler)
+	var flushed, torqueAtFlush bool
+	c := &Controller{
+		input:         fakeInput{},
+		follower:      follower,
+		hz:            100,
+		overrunPolicy: OverrunSkip,
+		budget:        &errorBudget{},
+		stateCh:       make(chan State, 1),
+		logger:        logger,
+		watchdog:      NewWatchdog(WatchdogConfig{}, follower, logger),
+		sched:         NewScheduler(logger),
+		estop:         make(chan struct{}),
+		flush: func(context.Context) error {
+			flushed, torqueAtFlush = true, follower.enabled.Load()
+			return nil
+		},
+	}
+	done := make(chan error)
+	go func() { done <- c.Start(context.Background()) }()
+	<-c.States()
+
+	if err := c.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Start() = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start still running after Close")
+	}
+	if !flushed || torqueAtFlush {
+		t.Errorf("flushed %v with torque on %v, want flushed after torque off", flushed, torqueAtFlush)
+	}
+	if c.Mode() != ModeIdle {
+		t.Errorf("mode after Close = %s, want idle", c.Mode())
+	}
+	if err := c.Close(t.Context()); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+}
+
+// stuckInput hangs in ReadPositions, regardless of its context, once stuck
+// is set and until release is closed.
+type stuckInput struct {
+	stuck   atomic.Bool
+	release chan struct{}
+}
+
+func (in *stuckInput) ReadPositions(context.Context) (map[robot.MotorName]float64, error) {
+	if in.stuck.Load() {
+		<-in.release
+	}
+	return map[robot.MotorName]float64{robot.Gripper: 10}, nil
+}
+
+func (in *stuckInput) Close() error { return nil }
+
+func TestClose_LoopStuck(t *testing.T) {
+	follower := &fakeFollower{}
+	input := &stuckInput{release: make(chan struct{})}
+	logger := slog.New(slog.DiscardHandler)
+	c := &Controller{
+		input:         input,
+		follower:      follower,
+		hz:            100,
+		overrunPolicy: OverrunSkip,
+		budget:        &errorBudget{},
+		stateCh:       make(chan State, 1),
+		logger:        logger,
+		watchdog:      NewWatchdog(WatchdogConfig{}, follower, logger),
+		sched:         NewScheduler(logger),
+		estop:         make(chan struct{}),
+	}
+	go c.Start(context.Background())
+	<-c.States()
+	input.stuck.Store(true)
+	time.Sleep(20 * time.Millisecond)
+
+	// The loop returns after Close gave up waiting for it, but before the
+	// hard deadline, so the arms are closed only once it did
+	time.AfterFunc(100*time.Millisecond, func() {
+		if follower.closed.Load() {
+			t.Error("follower closed while the loop was still running")
+		}
+		close(input.release)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.Close(ctx); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("Close() = %v, want the deadline reported", err)
+	}
+	if follower.enabled.Load() || !follower.closed.Load() {
+		t.Errorf("torque on %v, closed %v; want off and closed", follower.enabled.Load(), follower.closed.Load())
+	}
+}
+
+func TestModes(t *testing.T) {
+	c := &Controller{
+		modeCh: make(chan ModeChange, 10),
+		estop:  make(chan struct{}),
+		logger: slog.New(slog.DiscardHandler),
+	}
+	if err := c.EpisodeStarted(0); !errors.Is(err, ErrModeTransition) {
+		t.Errorf("EpisodeStarted() while idle = %v, want ErrModeTransition", err)
+	}
+	for _, to := range []Mode{ModeTeleop, ModeRecord, ModeTeleop} {
+		if err := c.setMode(to); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.EmergencyStop()
+	c.EmergencyStop()
+	if err := c.Start(t.Context()); !errors.Is(err, ErrEmergencyStop) {
+		t.Errorf("Start() after EmergencyStop = %v, want ErrEmergencyStop", err)
+	}
+	if err := c.setMode(ModeTeleop); !errors.Is(err, ErrModeTransition) {
+		t.Errorf("teleop while estopped = %v, want ErrModeTransition", err)
+	}
+	if err := c.ResetEStop(); err != nil || c.Mode() != ModeIdle {
+		t.Errorf("ResetEStop() = %v, mode %s", err, c.Mode())
+	}
+	var got []string
+	for len(c.modeCh) > 0 {
+		m := <-c.modeCh
+		got = append(got, m.From.String()+">"+m.To.String())
+	}
+	want := []string{"idle>teleop", "teleop>record", "record>teleop", "teleop>estopped", "estopped>idle"}
+	if !slices.Equal(got, want) {
+		t.Errorf("mode changes = %q, want %q", got, want)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	var fast, slow atomic.Int32
+	s := NewScheduler(nil,
+		Task{Name: "fast", Hz: 200, Run: func(context.Context) error {
+			fast.Add(1)
+			return nil
+		}},
+		Task{Name: "slow", Hz: 100, Run: func(context.Context) error {
+			slow.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			return errors.New("timeout")
+		}},
+		Task{Name: "off", Run: func(context.Context) error { panic("ran a task without a rate") }},
+	)
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	// The slow task doesn't hold up the fast one
+	if n := fast.Load(); n < 20 {
+		t.Errorf("fast task ran %d times in 200ms at 200 Hz", n)
+	}
+	stats := s.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("got stats of %d tasks, want 2", len(stats))
+	}
+	if st := stats[1]; st.Runs < 2 || st.Runs > 4 || st.Errors != st.Runs || st.Skipped == 0 {
+		t.Errorf("slow task stats = %+v", st)
+	}
+}
@@ -0,0 +1,45 @@
+package teleop
+
+import (
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// TraceRecord is one control cycle as written to Config.Trace, one JSON
+// object per line.
+type TraceRecord struct {
+	Time        time.Time                   `json:"time"`
+	Cycle       int                         `json:"cycle"`
+	LeaderRaw   map[robot.MotorName]int     `json:"leader_raw,omitempty"`
+	Leader      map[robot.MotorName]float64 `json:"leader,omitempty"`   // normalized
+	Written     map[robot.MotorName]float64 `json:"written,omitempty"`  // targets sent to the follower, after mapping and deadband
+	Follower    map[robot.MotorName]float64 `json:"follower,omitempty"` // observed, if ReadFollower is set
+	ReadUs      int64                       `json:"read_us"`
+	WriteUs     int64                       `json:"write_us"`
+	CycleUs     int64                       `json:"cycle_us"`
+	MissedTicks int                         `json:"missed_ticks"`
+	ReadError   string                      `json:"read_error,omitempty"`
+	WriteError  string                      `json:"write_error,omitempty"`
+}
+
+// writeTrace appends rec to the trace, if tracing is enabled. Trace write
+// errors disable tracing rather than disturbing the control loop.
+func (c *Controller) writeTrace(rec *TraceRecord) {
+	c.traceMu.Lock()
+	defer c.traceMu.Unlock()
+	if c.trace == nil {
+		return
+	}
+	if err := c.trace.Encode(rec); err != nil {
+		c.logger.Warn("Trace write failed, tracing disabled", "component", "controller", "error", err)
+		c.trace = nil
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
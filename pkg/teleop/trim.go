@@ -0,0 +1,54 @@
+package teleop
+
+import (
+	"maps"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// maxTrim is the largest trim of a joint either way, in normalized units.
+// Trims correct small misalignments; more calls for recalibrating.
+const maxTrim = 10
+
+// Trim adds delta to the trim of a follower joint: an offset in normalized
+// units added to its targets after the middleware, e.g. to correct a joint
+// that is mechanically a little off during a session. Trims are limited to
+// ±10 and can be kept with robot.MotorCalibration.Trimmed. It returns the
+// joint's new trim.
+func (c *Controller) Trim(name robot.MotorName, delta float64) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	trim := max(-maxTrim, min(maxTrim, c.trims[name]+delta))
+	trims := maps.Clone(c.trims) // the loop may be reading the old ones
+	if trims == nil {
+		trims = make(map[robot.MotorName]float64)
+	}
+	if trim == 0 {
+		delete(trims, name)
+	} else {
+		trims[name] = trim
+	}
+	c.trims = trims
+	return trim
+}
+
+// Trims returns the trim of every trimmed joint, see Trim.
+func (c *Controller) Trims() map[robot.MotorName]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return maps.Clone(c.trims)
+}
+
+// applyTrims returns targets with trims added.
+func applyTrims(targets, trims map[robot.MotorName]float64) map[robot.MotorName]float64 {
+	if len(trims) == 0 || targets == nil {
+		return targets
+	}
+	trimmed := maps.Clone(targets)
+	for name, trim := range trims {
+		if pos, ok := trimmed[name]; ok {
+			trimmed[name] = pos + trim
+		}
+	}
+	return trimmed
+}
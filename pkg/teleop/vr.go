@@ -0,0 +1,72 @@
+package teleop
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+// VRFrame is one pose of a VR controller, as sent by an OpenXR or SteamVR
+// bridge in OpenXR's axes: x to the right, y up and z towards the user, in
+// meters.
+type VRFrame struct {
+	Position    [3]float64 `json:"position"`
+	Orientation [4]float64 `json:"orientation"` // quaternion x, y, z, w
+	Trigger     float64    `json:"trigger"`     // 0 to 1, closes the gripper
+	Squeeze     bool       `json:"squeeze"`     // grip button: the follower only moves while it is held
+}
+
+// ListenVR is ListenHand for a VR controller sending a VRFrame per line.
+// The follower's gripper tip moves with the controller while its squeeze
+// button is held, its hand pitches and wrist_roll turns with the
+// controller, and the trigger closes the gripper. Releasing the button
+// works as a clutch.
+func ListenVR(addr string, cfg HandConfig) (*Hand, error) {
+	return listenHand(addr, cfg, parseVRFrame)
+}
+
+func parseVRFrame(line []byte) (HandFrame, bool, error) {
+	var v VRFrame
+	if err := json.Unmarshal(line, &v); err != nil {
+		return HandFrame{}, false, err
+	}
+	q := v.Orientation
+	if q == ([4]float64{}) {
+		q[3] = 1 // no orientation, keep it level
+	}
+
+	// The controller points along its -z and its right is +x
+	forward := xrToRobot(rotate(q, [3]float64{0, 0, -1}))
+	right := xrToRobot(rotate(q, [3]float64{1, 0, 0}))
+	up := xrToRobot(rotate(q, [3]float64{0, 1, 0}))
+
+	return HandFrame{
+		Position: xrToRobot(v.Position),
+		Pitch:    math.Atan2(forward[2], math.Hypot(forward[0], forward[1])) * 180 / math.Pi,
+		Roll:     math.Atan2(-right[2], up[2]) * 180 / math.Pi,
+		Grip:     v.Trigger,
+	}, v.Squeeze, nil
+}
+
+// xrToRobot converts a vector in OpenXR's axes to the robot's: x away from
+// the user, y to their left and z up.
+func xrToRobot(v [3]float64) robot.Vec3 {
+	return robot.Vec3{-v[2], -v[0], v[1]}
+}
+
+// rotate rotates v by the unit quaternion q (x, y, z, w).
+func rotate(q [4]float64, v [3]float64) [3]float64 {
+	// v + 2w(u×v) + 2u×(u×v), with u the vector part of q
+	u := [3]float64{q[0], q[1], q[2]}
+	cross := func(a, b [3]float64) [3]float64 {
+		return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+	}
+	t := cross(u, v)
+	uu := cross(u, t)
+	return [3]float64{
+		v[0] + 2*q[3]*t[0] + 2*uu[0],
+		v[1] + 2*q[3]*t[1] + 2*uu[1],
+		v[2] + 2*q[3]*t[2] + 2*uu[2],
+	}
+}
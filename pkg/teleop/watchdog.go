@@ -0,0 +1,183 @@
+package teleop
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gwillem/lerobot/pkg/robot"
+)
+
+const (
+	defaultWatchdogHold = 250 * time.Millisecond
+	defaultReleaseRamp  = 2 * time.Second
+
+	// watchdogPeriod is how often the watchdog checks for stalls and steps
+	// the torque ramp.
+	watchdogPeriod = 50 * time.Millisecond
+
+	// fullTorque is the torque limit of a servo at full strength.
+	fullTorque = 1000
+)
+
+// WatchdogConfig configures a Watchdog.
+type WatchdogConfig struct {
+	// Hold is how long without fresh actions before the follower is
+	// reported as holding its last pose. 0 means 250ms.
+	Hold time.Duration
+	// Release is how long without fresh actions before torque is ramped
+	// down and disabled, so a stalled arm doesn't stay stiff at a stale
+	// target. 0 holds indefinitely.
+	Release time.Duration
+	// Ramp is how long torque takes to go from full to off. 0 means 2s.
+	Ramp time.Duration
+}
+
+// WatchdogState is how the watchdog is treating the follower.
+type WatchdogState int
+
+const (
+	WatchdogActive    WatchdogState = iota // actions are fresh
+	WatchdogHolding                        // holding the last pose
+	WatchdogReleasing                      // ramping torque down
+	WatchdogReleased                       // torque disabled
+)
+
+func (s WatchdogState) String() string {
+	switch s {
+	case WatchdogHolding:
+		return "holding"
+	case WatchdogReleasing:
+		return "releasing"
+	case WatchdogReleased:
+		return "released"
+	default:
+		return "active"
+	}
+}
+
+// TorqueLimiter is implemented by arms whose torque can be limited, such as
+// robot.Arm. Others are released at once at the end of the ramp.
+type TorqueLimiter interface {
+	SetTorqueLimit(ctx context.Context, limit int) error
+}
+
+// Watchdog guards a follower against a stalled action source, such as a
+// disconnected leader or a policy server that stopped answering. The source
+// feeds it every fresh action; without them, the follower holds its last
+// pose, and after Release its torque is ramped down rather than holding a
+// stale target forever.
+type Watchdog struct {
+	cfg    WatchdogConfig
+	arm    interface{ Disable(context.Context) error }
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	last      time.Time // of the last fresh action
+	state     WatchdogState
+	rampStart time.Time
+}
+
+// NewWatchdog returns a watchdog for arm, fed as of now. A nil logger
+// discards its events.
+func NewWatchdog(cfg WatchdogConfig, arm interface{ Disable(context.Context) error }, logger *slog.Logger) *Watchdog {
+	cfg.Hold = cmp.Or(cfg.Hold, defaultWatchdogHold)
+	cfg.Ramp = cmp.Or(cfg.Ramp, defaultReleaseRamp)
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	return &Watchdog{cfg: cfg, arm: arm, logger: logger, last: time.Now()}
+}
+
+// Feed reports a fresh action. It ends holding and restores full torque if
+// a ramp was under way. It returns the state before, so the caller can
+// bring a released follower back, without jumping to the new action.
+func (w *Watchdog) Feed(ctx context.Context) WatchdogState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prev := w.state
+	w.last = time.Now()
+	w.state = WatchdogActive
+
+	switch prev {
+	case WatchdogHolding:
+		w.logger.Info("Actions resumed", "component", "follower")
+	case WatchdogReleasing:
+		w.setTorqueLimit(ctx, fullTorque)
+		w.logger.Info("Actions resumed, torque restored", "component", "follower")
+	case WatchdogReleased:
+		w.logger.Info("Actions resumed after release", "component", "follower")
+	}
+	return prev
+}
+
+// State returns the current state.
+func (w *Watchdog) State() WatchdogState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+// Run checks for stalls until ctx is cancelled. It runs apart from the
+// control loop, so it also acts while the loop is blocked, e.g. on a
+// reconnecting leader.
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(watchdogPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.check(ctx, now)
+		}
+	}
+}
+
+// check moves to the state due at time now and steps the torque ramp.
+func (w *Watchdog) check(ctx context.Context, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	idle := now.Sub(w.last)
+	if w.state == WatchdogActive && idle >= w.cfg.Hold {
+		w.state = WatchdogHolding
+		w.logger.Warn("No fresh actions, holding last pose", "component", "follower", "after", idle.Round(time.Millisecond))
+	}
+	if w.state == WatchdogHolding && w.cfg.Release > 0 && idle >= w.cfg.Release {
+		w.state = WatchdogReleasing
+		w.rampStart = now
+		w.logger.Warn("No fresh actions, ramping torque down", "component", "follower", "after", idle.Round(time.Millisecond), "ramp", w.cfg.Ramp)
+	}
+	if w.state != WatchdogReleasing {
+		return
+	}
+
+	frac := float64(now.Sub(w.rampStart)) / float64(w.cfg.Ramp)
+	if frac < 1 {
+		w.setTorqueLimit(ctx, int(math.Round(fullTorque*(1-frac))))
+		return
+	}
+	if err := w.arm.Disable(ctx); err != nil {
+		// Try again next check
+		w.logger.Warn("Failed to disable torque", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+		return
+	}
+	// Torque is off, so the next Enable starts at full strength
+	w.setTorqueLimit(ctx, fullTorque)
+	w.state = WatchdogReleased
+	w.logger.Warn("Torque disabled after stall", "component", "follower")
+}
+
+func (w *Watchdog) setTorqueLimit(ctx context.Context, limit int) {
+	l, ok := w.arm.(TorqueLimiter)
+	if !ok {
+		return
+	}
+	if err := l.SetTorqueLimit(ctx, limit); err != nil {
+		w.logger.Debug("Failed to set torque limit", "component", "follower", "kind", robot.ErrorKind(err), "error", err)
+	}
+}
@@ -0,0 +1,219 @@
+// Package timesync puts samples from independent sources, such as serial
+// reads of the arms and camera frames, on a common timeline, so that the
+// observations and actions combined into a recorded frame are known to be
+// at most a bounded time apart.
+//
+// All times come from time.Now, whose monotonic reading Go uses for
+// subtraction, so wall clock adjustments during a session cannot shift or
+// reorder samples.
+package timesync
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Clock is a monotonic session clock: times are offsets from its start.
+type Clock struct {
+	start time.Time
+}
+
+// NewClock returns a clock that started at start, e.g. the timestamp of an
+// episode's first frame.
+func NewClock(start time.Time) *Clock { return &Clock{start: start} }
+
+// Start returns when the clock started.
+func (c *Clock) Start() time.Time { return c.start }
+
+// Offset returns the session time of t.
+func (c *Clock) Offset(t time.Time) time.Duration { return t.Sub(c.start) }
+
+// Now returns the current session time.
+func (c *Clock) Now() time.Duration { return time.Since(c.start) }
+
+// Stamp is when a sample was taken, to within Uncertainty either way.
+type Stamp struct {
+	At          time.Time
+	Uncertainty time.Duration
+}
+
+// Between stamps a sample that was taken some time during start to end,
+// such as a serial read: the middle, give or take half the interval.
+func Between(start, end time.Time) Stamp {
+	half := end.Sub(start) / 2
+	return Stamp{At: start.Add(half), Uncertainty: half}
+}
+
+// IsZero reports whether s is unset.
+func (s Stamp) IsZero() bool { return s.At.IsZero() }
+
+// Skew returns the most two samples can be apart: the difference of their
+// stamps plus both uncertainties.
+func Skew(a, b Stamp) time.Duration {
+	d := a.At.Sub(b.At)
+	return max(d, -d) + a.Uncertainty + b.Uncertainty
+}
+
+// minFit is the number of samples needed before Source fits a timeline.
+const minFit = 10
+
+// Source estimates the timing of a periodic source, such as a camera,
+// from the arrival times of its samples. A line fitted through the arrival
+// times gives the actual period, which drifts from the nominal one as the
+// source's clock runs faster or slower than ours, and the scatter around it
+// gives the jitter of delivery.
+type Source struct {
+	nominal time.Duration
+
+	mu    sync.Mutex
+	first time.Time
+	last  time.Time
+	index float64 // of the last sample, counting dropped ones
+	// Least squares of arrival time (s since first) on index, with
+	// Welford's algorithm for the centered sums
+	n, meanX, meanY, cxx, cxy, cyy float64
+}
+
+// NewSource returns a source expected to deliver a sample every period.
+func NewSource(period time.Duration) *Source { return &Source{nominal: period} }
+
+// Add records a sample that arrived at t and returns its stamp on the fitted
+// timeline. Until enough samples have arrived, that is t, uncertain by a
+// nominal period.
+func (s *Source) Add(t time.Time) Stamp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.n == 0 {
+		s.first = t
+	} else {
+		// Count dropped samples so they don't bend the fit
+		period := s.nominal
+		if s.n >= minFit {
+			period = s.period()
+		}
+		step := 1.0
+		if period > 0 {
+			step = max(1, math.Round(float64(t.Sub(s.last))/float64(period)))
+		}
+		s.index += step
+	}
+	s.last = t
+
+	x, y := s.index, t.Sub(s.first).Seconds()
+	s.n++
+	dx, dy := x-s.meanX, y-s.meanY
+	s.meanX += dx / s.n
+	s.meanY += dy / s.n
+	s.cxx += dx * (x - s.meanX)
+	s.cxy += dx * (y - s.meanY)
+	s.cyy += dy * (y - s.meanY)
+
+	if s.n < minFit {
+		return Stamp{At: t, Uncertainty: s.nominal}
+	}
+	slope, intercept := s.fit()
+	fitted := s.first.Add(seconds(intercept + slope*x))
+	return Stamp{At: fitted, Uncertainty: 2 * s.jitter()}
+}
+
+// Estimate describes the timing of a source.
+type Estimate struct {
+	Samples  int
+	Period   time.Duration // fitted, the nominal period until enough samples
+	DriftPPM float64       // of Period relative to the nominal period
+	Jitter   time.Duration // standard deviation of arrival around the fit
+}
+
+// Estimate returns the current timing estimate.
+func (s *Source) Estimate() Estimate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := Estimate{Samples: int(s.n), Period: s.nominal}
+	if s.n < minFit {
+		return e
+	}
+	e.Period = s.period()
+	if s.nominal > 0 {
+		e.DriftPPM = (float64(e.Period) - float64(s.nominal)) / float64(s.nominal) * 1e6
+	}
+	e.Jitter = s.jitter()
+	return e
+}
+
+func (e Estimate) String() string {
+	if e.Period <= 0 {
+		return "no samples"
+	}
+	return fmt.Sprintf("%.2f Hz (%+.0f ppm), jitter %v", float64(time.Second)/float64(e.Period), e.DriftPPM, e.Jitter.Round(100*time.Microsecond))
+}
+
+func (s *Source) fit() (slope, intercept float64) {
+	if s.cxx == 0 {
+		return s.nominal.Seconds(), 0
+	}
+	slope = s.cxy / s.cxx
+	return slope, s.meanY - slope*s.meanX
+}
+
+func (s *Source) period() time.Duration {
+	slope, _ := s.fit()
+	return seconds(slope)
+}
+
+// jitter returns the residual standard deviation of the fit.
+func (s *Source) jitter() time.Duration {
+	if s.cxx == 0 {
+		return 0
+	}
+	sse := s.cyy - s.cxy*s.cxy/s.cxx
+	return seconds(math.Sqrt(max(sse, 0) / s.n))
+}
+
+func seconds(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
+
+// Alignment tracks the worst skew of each source against a reference over
+// the frames of an episode, the bound within which they are aligned.
+type Alignment struct {
+	names []string
+	max   map[string]time.Duration
+}
+
+// Add records the skew of a source's sample against the reference sample
+// of the same frame.
+func (a *Alignment) Add(name string, ref, sample Stamp) {
+	if a.max == nil {
+		a.max = make(map[string]time.Duration)
+	}
+	skew := Skew(ref, sample)
+	prev, ok := a.max[name]
+	if !ok {
+		a.names = append(a.names, name)
+	}
+	a.max[name] = max(prev, skew)
+}
+
+// Max returns the worst skew of every source.
+func (a *Alignment) Max() map[string]time.Duration { return a.max }
+
+// Bound returns the worst skew of any source.
+func (a *Alignment) Bound() time.Duration {
+	var bound time.Duration
+	for _, d := range a.max {
+		bound = max(bound, d)
+	}
+	return bound
+}
+
+// String lists the worst skew of every source, in the order they were
+// first added.
+func (a *Alignment) String() string {
+	parts := make([]string, len(a.names))
+	for i, name := range a.names {
+		parts[i] = fmt.Sprintf("%s %v", name, a.max[name].Round(100*time.Microsecond))
+	}
+	return strings.Join(parts, ", ")
+}
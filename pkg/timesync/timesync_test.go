@@ -0,0 +1,58 @@
+package timesync
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSource_Drift(t *testing.T) {
+	// A 30 fps camera whose clock runs 0.1% slow, delivering with 1ms of
+	// alternating jitter and one dropped frame
+	period := time.Second / 30
+	actual := period + period/1000
+	s := NewSource(period)
+	start := time.Unix(100, 0)
+	var stamp Stamp
+	for i := range 300 {
+		if i == 150 {
+			continue
+		}
+		jitter := time.Millisecond
+		if i%2 == 1 {
+			jitter = -jitter
+		}
+		stamp = s.Add(start.Add(time.Duration(i)*actual + jitter))
+	}
+
+	e := s.Estimate()
+	if e.DriftPPM < 900 || e.DriftPPM > 1100 {
+		t.Errorf("drift = %.0f ppm, want about 1000", e.DriftPPM)
+	}
+	if e.Jitter < 900*time.Microsecond || e.Jitter > 1100*time.Microsecond {
+		t.Errorf("jitter = %v, want about 1ms", e.Jitter)
+	}
+	// The last frame arrived 1ms late, its stamp is on the fitted timeline
+	want := start.Add(299 * actual)
+	if d := stamp.At.Sub(want); d < -200*time.Microsecond || d > 200*time.Microsecond {
+		t.Errorf("last stamp is %v off the timeline", d)
+	}
+}
+
+func TestSkew(t *testing.T) {
+	start := time.Unix(100, 0)
+	leader := Between(start, start.Add(4*time.Millisecond))
+	if leader.At != start.Add(2*time.Millisecond) || leader.Uncertainty != 2*time.Millisecond {
+		t.Fatalf("Between = %+v", leader)
+	}
+	frame := Stamp{At: start.Add(-10 * time.Millisecond), Uncertainty: time.Millisecond}
+	if got := Skew(leader, frame); got != 15*time.Millisecond {
+		t.Errorf("Skew = %v, want 15ms", got)
+	}
+
+	var a Alignment
+	a.Add("front", leader, frame)
+	a.Add("follower", leader, leader)
+	if a.Bound() != 15*time.Millisecond || a.String() != "front 15ms, follower 4ms" {
+		t.Errorf("alignment %v, bound %v", a.String(), a.Bound())
+	}
+}
@@ -0,0 +1,149 @@
+#!/usr/bin/env python3
+"""Simulated SO-101 follower for `lerobot teleoperate --sim`.
+
+Loads an SO-101 MuJoCo model and serves the line-delimited JSON protocol
+described in pkg/sim. Normalized positions [-100, 100] are mapped linearly
+onto each joint's range.
+
+    pip install mujoco
+    python scripts/mujoco_sim.py path/to/so101.xml --port 5555
+
+A model is available at https://github.com/TheRobotStudio/SO-ARM100
+(Simulation/SO101). Joints are matched by name (shoulder_pan, ...,
+gripper), falling back to model order.
+"""
+
+import argparse
+import json
+import socketserver
+import threading
+import time
+
+import mujoco
+import mujoco.viewer
+
+MOTORS = ["shoulder_pan", "shoulder_lift", "elbow_flex", "wrist_flex", "wrist_roll", "gripper"]
+
+
+class Sim:
+    def __init__(self, path):
+        self.model = mujoco.MjModel.from_xml_path(path)
+        self.data = mujoco.MjData(self.model)
+        self.lock = threading.Lock()
+        self.joints = {}  # motor -> (joint id, actuator id)
+
+        hinge = [j for j in range(self.model.njnt) if self.model.jnt_type[j] == mujoco.mjtJoint.mjJNT_HINGE]
+        for i, motor in enumerate(MOTORS):
+            j = mujoco.mj_name2id(self.model, mujoco.mjtObj.mjOBJ_JOINT, motor)
+            if j < 0 and i < len(hinge):
+                j = hinge[i]
+            if j < 0:
+                continue
+            actuators = [a for a in range(self.model.nu) if self.model.actuator_trnid[a, 0] == j]
+            if actuators:
+                self.joints[motor] = (j, actuators[0])
+
+        self.gains = self.model.actuator_gainprm[:, 0].copy()
+        self.biases = self.model.actuator_biasprm[:, 1].copy()
+
+    def _range(self, motor):
+        j, _ = self.joints[motor]
+        lo, hi = self.model.jnt_range[j]
+        return lo, hi
+
+    def read(self):
+        positions = {}
+        for motor, (j, _) in self.joints.items():
+            lo, hi = self._range(motor)
+            q = self.data.qpos[self.model.jnt_qposadr[j]]
+            positions[motor] = (q - lo) / (hi - lo) * 200 - 100 if hi > lo else 0
+        return positions
+
+    def loads(self):
+        # Actuator force in 0.1% of its maximum, like a servo's present load
+        loads = {}
+        for motor, (_, a) in self.joints.items():
+            limit = self.model.actuator_forcerange[a, 1] if self.model.actuator_forcelimited[a] else 0
+            if limit <= 0:
+                lo, hi = self._range(motor)
+                limit = self.gains[a] * (hi - lo)
+            loads[motor] = int(round(self.data.actuator_force[a] / limit * 1000)) if limit > 0 else 0
+        return loads
+
+    def write(self, positions):
+        for motor, norm in positions.items():
+            if motor not in self.joints:
+                continue
+            _, a = self.joints[motor]
+            lo, hi = self._range(motor)
+            self.data.ctrl[a] = lo + (min(max(norm, -100), 100) + 100) / 200 * (hi - lo)
+
+    def set_torque(self, enabled):
+        # Position actuators: force = kp * ctrl - kp * qpos. Zeroing kp makes them limp.
+        for _, a in self.joints.values():
+            self.model.actuator_gainprm[a, 0] = self.gains[a] if enabled else 0
+            self.model.actuator_biasprm[a, 1] = self.biases[a] if enabled else 0
+
+    def handle(self, req):
+        with self.lock:
+            cmd = req.get("cmd")
+            if cmd == "read":
+                return {"positions": self.read(), "loads": self.loads()}
+            if cmd == "write":
+                self.write(req.get("positions", {}))
+                return {}
+            if cmd in ("enable", "disable"):
+                self.set_torque(cmd == "enable")
+                return {}
+            return {"error": f"unknown command {cmd!r}"}
+
+    def step(self):
+        with self.lock:
+            mujoco.mj_step(self.model, self.data)
+
+
+def serve(sim, host, port):
+    class Handler(socketserver.StreamRequestHandler):
+        def handle(self):
+            for line in self.rfile:
+                try:
+                    resp = sim.handle(json.loads(line))
+                except Exception as e:  # keep serving on bad requests
+                    resp = {"error": str(e)}
+                self.wfile.write((json.dumps(resp) + "\n").encode())
+
+    socketserver.ThreadingTCPServer.allow_reuse_address = True
+    server = socketserver.ThreadingTCPServer((host, port), Handler)
+    threading.Thread(target=server.serve_forever, daemon=True).start()
+    print(f"Simulated follower listening on {host}:{port} ({len(sim.joints)} joints)")
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__, formatter_class=argparse.RawDescriptionHelpFormatter)
+    parser.add_argument("model", help="MuJoCo XML model of the SO-101")
+    parser.add_argument("--host", default="localhost")
+    parser.add_argument("--port", type=int, default=5555)
+    parser.add_argument("--headless", action="store_true", help="run without the viewer")
+    args = parser.parse_args()
+
+    sim = Sim(args.model)
+    serve(sim, args.host, args.port)
+
+    dt = sim.model.opt.timestep
+    if args.headless:
+        while True:
+            start = time.time()
+            sim.step()
+            time.sleep(max(0, dt - (time.time() - start)))
+
+    with mujoco.viewer.launch_passive(sim.model, sim.data) as viewer:
+        while viewer.is_running():
+            start = time.time()
+            sim.step()
+            with sim.lock:
+                viewer.sync()
+            time.sleep(max(0, dt - (time.time() - start)))
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,118 @@
+#!/usr/bin/env python3
+"""Policy server for `lerobot infer`.
+
+Loads a trained LeRobot policy (ACT, Diffusion, SmolVLA, pi0, ...) and serves
+the JSON WebSocket protocol described in pkg/policy: every observation is
+answered with the chunk of actions the policy predicts from it.
+
+    pip install lerobot websockets pillow
+    python scripts/policy_server.py --policy user/act_so101_pick --port 8765
+
+Run it on the machine with the GPU; the robot only needs network access to
+it. Observations that arrive while the policy is busy are skipped in favor of
+the newest one. The policy must have been trained on a dataset with the same
+motors and camera names, e.g. one recorded with `lerobot record`.
+"""
+
+import argparse
+import asyncio
+import base64
+import io
+import json
+
+import numpy as np
+import torch
+import websockets
+from PIL import Image
+
+from lerobot.configs.policies import PreTrainedConfig
+from lerobot.policies.factory import get_policy_class
+
+
+class Policy:
+    def __init__(self, path, device, actions_per_chunk):
+        cfg = PreTrainedConfig.from_pretrained(path)
+        cfg.device = device
+        self.policy = get_policy_class(cfg.type).from_pretrained(path, config=cfg)
+        self.policy.to(device)
+        self.policy.eval()
+        self.device = device
+        self.actions_per_chunk = actions_per_chunk
+
+        # Newer LeRobot versions normalize in separate processors
+        self.preprocess = self.postprocess = None
+        try:
+            from lerobot.policies.factory import make_pre_post_processors
+
+            self.preprocess, self.postprocess = make_pre_post_processors(cfg, pretrained_path=path)
+        except ImportError:
+            pass
+
+    def batch(self, obs):
+        batch = {"observation.state": torch.tensor([obs["state"]], dtype=torch.float32)}
+        for name, data in (obs.get("images") or {}).items():
+            img = np.asarray(Image.open(io.BytesIO(base64.b64decode(data))).convert("RGB"))
+            batch[f"observation.images.{name}"] = torch.from_numpy(img).permute(2, 0, 1).float().unsqueeze(0) / 255
+        batch["task"] = [obs.get("task") or ""]
+        if self.preprocess:
+            return self.preprocess(batch)
+        return {k: v.to(self.device) if isinstance(v, torch.Tensor) else v for k, v in batch.items()}
+
+    @torch.no_grad()
+    def infer(self, obs):
+        chunk = self.policy.predict_action_chunk(self.batch(obs))
+        if self.postprocess:
+            chunk = self.postprocess(chunk)
+        chunk = chunk[0]
+        if self.actions_per_chunk:
+            chunk = chunk[: self.actions_per_chunk]
+        return chunk.float().cpu().tolist()
+
+
+async def serve(policy, ws):
+    latest = None
+    ready = asyncio.Event()
+
+    async def infer():
+        while True:
+            await ready.wait()
+            ready.clear()
+            obs = latest
+            try:
+                actions = await asyncio.to_thread(policy.infer, obs)
+            except Exception as e:
+                await ws.send(json.dumps({"type": "error", "error": str(e)}))
+                return
+            await ws.send(json.dumps({"type": "actions", "timestep": obs["timestep"], "actions": actions}))
+
+    worker = asyncio.create_task(infer())
+    try:
+        async for raw in ws:
+            msg = json.loads(raw)
+            if msg["type"] == "setup":
+                policy.policy.reset()
+                print(f"client at {msg['fps']} fps, motors {msg['motors']}, cameras {msg.get('cameras') or []}")
+            elif msg["type"] == "observation":
+                latest = msg
+                ready.set()
+    finally:
+        worker.cancel()
+
+
+async def main():
+    parser = argparse.ArgumentParser(description=__doc__, formatter_class=argparse.RawDescriptionHelpFormatter)
+    parser.add_argument("--policy", required=True, help="Hugging Face repo or local directory of a trained policy")
+    parser.add_argument("--host", default="0.0.0.0")
+    parser.add_argument("--port", type=int, default=8765)
+    parser.add_argument("--device", default="cuda" if torch.cuda.is_available() else "cpu")
+    parser.add_argument("--actions-per-chunk", type=int, default=0, help="Truncate chunks to this many actions (0: all)")
+    args = parser.parse_args()
+
+    policy = Policy(args.policy, args.device, args.actions_per_chunk)
+    async with websockets.serve(lambda ws: serve(policy, ws), args.host, args.port, max_size=None):
+        print(f"Serving {args.policy} on ws://{args.host}:{args.port}")
+        await asyncio.Future()
+
+
+if __name__ == "__main__":
+    asyncio.run(main())